@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contractArgsEnv carries the command line when the test binary re-executes
+// itself as hclexp, so tests can observe real exit statuses and streams.
+const contractArgsEnv = "HCLEXP_CONTRACT_ARGS"
+
+func TestMain(m *testing.M) {
+	if args, ok := os.LookupEnv(contractArgsEnv); ok {
+		run(globalFlags(strings.Split(args, "\x1f")))
+		exit(exitOK)
+	}
+	os.Exit(m.Run())
+}
+
+// runHclexp runs hclexp with args in a child process and returns its stdout,
+// stderr and exit status.
+func runHclexp(t *testing.T, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), contractArgsEnv+"="+strings.Join(args, "\x1f"))
+	var out, errOut bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		code = exitErr.ExitCode()
+	default:
+		require.NoError(t, err)
+	}
+	return out.String(), errOut.String(), code
+}
+
+const contractSchema = `
+database "posthog" {
+  table "events" {
+    engine "merge_tree" {}
+    order_by = ["id"]
+    column "id" { type = "UInt64" }
+  }
+}
+`
+
+func TestContract_ExitStatuses(t *testing.T) {
+	good := writeTemp(t, "good.hcl", contractSchema)
+	broken := writeTemp(t, "broken.hcl", `
+database "posthog" {
+  materialized_view "mv" {
+    to_table = "posthog.missing"
+    query    = "SELECT id FROM posthog.events"
+  }
+}
+`)
+
+	for _, tc := range []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"unknown command", []string{"nope"}, exitUsage},
+		{"bad flag combination", []string{"validate", "-manifest", "m.hcl"}, exitUsage},
+		{"missing input", []string{"validate", "-config", filepath.Join(t.TempDir(), "absent.hcl")}, exitError},
+		{"validate passes", []string{"validate", "-config", good}, exitOK},
+		{"validate finds errors", []string{"validate", "-config", broken}, exitFindings},
+		{"lint passes", []string{"lint", "-config", good}, exitOK},
+		{"lint finds errors", []string{"lint", "-config", good, "-rules", "non-replicated-engine=error"}, exitFindings},
+		{"lint unknown rule", []string{"lint", "-config", good, "-rules", "nope=error"}, exitUsage},
+		{"drift found", []string{"drift", "-dir", filepath.Join("testdata", "drift")}, exitFindings},
+		{"diff with differences", []string{"diff", "-left", good, "-right", broken}, exitOK},
+		{"diff -exit-code with differences", []string{"diff", "-exit-code", "-left", good, "-right", broken}, exitFindings},
+		{"diff -exit-code without differences", []string{"diff", "-exit-code", "-left", good, "-right", good}, exitOK},
+		{"diff -exit-code json", []string{"diff", "-exit-code", "-format", "json", "-left", good, "-right", broken}, exitFindings},
+		{"diff -exit-code summary", []string{"diff", "-exit-code", "-format", "summary", "-left", good, "-right", broken}, exitFindings},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, stderr, code := runHclexp(t, tc.args...)
+			assert.Equal(t, tc.want, code, "stderr: %s", stderr)
+		})
+	}
+}
+
+// Data goes to stdout and nothing else does: a failing command leaves stdout
+// empty, and a succeeding one keeps its logs on stderr.
+func TestContract_Streams(t *testing.T) {
+	good := writeTemp(t, "good.hcl", contractSchema)
+
+	stdout, stderr, code := runHclexp(t, "validate", "-config", filepath.Join(t.TempDir(), "absent.hcl"))
+	assert.Equal(t, exitError, code)
+	assert.Empty(t, stdout)
+	assert.NotEmpty(t, stderr)
+
+	stdout, stderr, code = runHclexp(t, "load", "-config", good, "-out", "-")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, `database "posthog"`)
+	assert.NotContains(t, stdout, "level=")
+}
+
+// diff -format summary prints one line of per-kind counts, the compact form a
+// scheduled -exit-code check posts to an alert.
+func TestContract_DiffSummary(t *testing.T) {
+	left := writeTemp(t, "left.hcl", contractSchema)
+	right := writeTemp(t, "right.hcl", contractSchema+`
+database "posthog" {
+  table "sessions" {
+    engine "merge_tree" {}
+    order_by = ["id"]
+    column "id" { type = "UInt64" }
+  }
+}
+`)
+	stdout, stderr, code := runHclexp(t, "diff", "-exit-code", "-format", "summary", "-left", left, "-right", right)
+	require.Equal(t, exitFindings, code, stderr)
+	assert.Equal(t, "+1 table\n", stdout)
+
+	stdout, _, code = runHclexp(t, "diff", "-exit-code", "-format", "summary", "-left", left, "-right", left)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "no differences\n", stdout)
+}
+
+// validate reports each HCL problem on its own file:line:column line, so
+// editors and CI annotators can jump to the offending key.
+func TestContract_ValidateProblemLocations(t *testing.T) {
+	bad := writeTemp(t, "bad.hcl", `database "posthog" {
+  table "events" {
+    colum "id" { type = "UInt64" }
+    engne "merge_tree" {}
+  }
+}
+`)
+	stdout, stderr, code := runHclexp(t, "validate", "-config", bad)
+	assert.Equal(t, exitError, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, bad+`:3:5: Unsupported block type: Blocks of type "colum" are not expected here.`)
+	assert.Contains(t, stderr, bad+`:4:5: Unsupported block type`, "every problem in the file is reported, not just the first")
+	assert.Contains(t, stderr, "problems=2")
+}
+
+// The top-level keys of each -format json document are part of the contract.
+func TestContract_JSONShapes(t *testing.T) {
+	left := writeTemp(t, "left.hcl", contractSchema)
+	right := writeTemp(t, "right.hcl", strings.Replace(contractSchema,
+		`column "id" { type = "UInt64" }`, `column "id" { type = "UInt64" }
+    column "ts" { type = "DateTime" }`, 1))
+
+	for _, tc := range []struct {
+		name string
+		args []string
+		keys []string
+	}{
+		{"diff", []string{"diff", "-left", left, "-right", right, "-format", "json"},
+			[]string{"objects", "operations", "summary"}},
+		{"drift", []string{"drift", "-dir", filepath.Join("testdata", "drift"), "-format", "json"},
+			[]string{"groups", "summary"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stdout, stderr, _ := runHclexp(t, tc.args...)
+			var doc map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal([]byte(stdout), &doc), "stdout must be one JSON document; stderr: %s", stderr)
+			keys := make([]string, 0, len(doc))
+			for k := range doc {
+				keys = append(keys, k)
+			}
+			assert.Subset(t, keys, tc.keys)
+		})
+	}
+}
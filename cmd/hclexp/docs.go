@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"os"
+
+	hclload "github.com/posthog/chschema/internal/loader/hcl"
+)
+
+// runDocs renders a resolved schema as Markdown reference documentation —
+// a Mermaid data-flow diagram, then every object with its engine, keys,
+// columns and comments — so the docs are generated from the HCL rather than
+// maintained beside it.
+func runDocs(args []string) {
+	fs := flag.NewFlagSet("hclexp docs", flag.ExitOnError)
+	configFlag := configRootsFlag(fs)
+	layersFlag := fs.String("layer", "", "comma-separated layer stack: each entry a directory or a single .hcl file (loaded in order)")
+	outFlag := fs.String("out", "", "output Markdown file, or '-'/empty for stdout")
+	varFlags := addVarFlags(fs)
+	_ = fs.Parse(args)
+
+	schema, err := loadOpts(configFlag.String(), *layersFlag, varFlags.loadOptions())
+	if err != nil {
+		slog.Error("failed to load config", "err", err)
+		exit(exitError)
+	}
+	if err := hclload.Resolve(schema); err != nil {
+		slog.Error("failed to resolve schema", "err", err)
+		exit(exitError)
+	}
+
+	if stdoutTarget(*outFlag) {
+		err = hclload.RenderMarkdown(os.Stdout, schema)
+	} else {
+		var buf bytes.Buffer
+		if err = hclload.RenderMarkdown(&buf, schema); err == nil {
+			err = hclload.WriteFileAtomic(*outFlag, buf.Bytes())
+		}
+	}
+	if err != nil {
+		slog.Error("failed to write docs", "out", *outFlag, "err", err)
+		exit(exitError)
+	}
+}
@@ -51,29 +51,30 @@ func runDrift(args []string) {
 	details := fs.Bool("details", false, "print the full change set of each drifting node against its group reference")
 	excludeFlag := fs.String("exclude", "", "HCL exclude config: objects matching its patterns/object_types are dropped from every node before comparing")
 	formatFlag := fs.String("format", "text", "output format: text (default) or json")
+	queryChars := fs.Int("query-chars", 0, "text -details: show changed queries collapsed to one line and cut to N characters (0: only note the change; -1: in full)")
 	_ = fs.Parse(args)
 
 	if *dirFlag == "" {
 		fmt.Fprintln(os.Stderr, "drift: -dir is required")
-		os.Exit(2)
+		exit(exitUsage)
 	}
 	switch *zkFlag {
 	case "keep", "mask-uuid", "ignore":
 	default:
 		fmt.Fprintf(os.Stderr, "drift: invalid -zk-paths %q (want keep|mask-uuid|ignore)\n", *zkFlag)
-		os.Exit(2)
+		exit(exitUsage)
 	}
 	switch *formatFlag {
 	case "text", "json":
 	default:
 		fmt.Fprintf(os.Stderr, "drift: invalid -format %q (want text|json)\n", *formatFlag)
-		os.Exit(2)
+		exit(exitUsage)
 	}
 
 	nodes, err := loadDriftNodes(*dirFlag, *globFlag)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "drift: %v\n", err)
-		os.Exit(1)
+		exit(exitError)
 	}
 	for i := range nodes {
 		normalizeZKPaths(nodes[i].Schema, *zkFlag)
@@ -85,7 +86,7 @@ func runDrift(args []string) {
 	}
 	if len(nodes) == 0 {
 		fmt.Fprintf(os.Stderr, "drift: no .hcl files in %s match %q\n", *dirFlag, *globFlag)
-		os.Exit(1)
+		exit(exitError)
 	}
 
 	keys := splitList(*groupByFlag)
@@ -95,14 +96,14 @@ func runDrift(args []string) {
 		out, err := json.MarshalIndent(doc, "", "  ")
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "drift: render JSON: %v\n", err)
-			os.Exit(1)
+			exit(exitError)
 		}
 		fmt.Println(string(out))
 	} else {
-		renderDriftText(os.Stdout, doc, *details)
+		renderDriftText(os.Stdout, doc, *details, hclload.RenderOptions{QueryChars: *queryChars})
 	}
 	if doc.Summary.DriftingNodes > 0 {
-		os.Exit(1)
+		exit(exitFindings)
 	}
 }
 
@@ -144,8 +145,9 @@ func buildDriftDoc(nodes []driftNode, keys []string) hclload.DriftJSON {
 	return doc
 }
 
-// renderDriftText prints the classic prose report from the drift document.
-func renderDriftText(w io.Writer, doc hclload.DriftJSON, details bool) {
+// renderDriftText prints the classic prose report from the drift document;
+// with details, each drifter's change set renders under opts.
+func renderDriftText(w io.Writer, doc hclload.DriftJSON, details bool, opts hclload.RenderOptions) {
 	for _, g := range doc.Groups {
 		if g.Nodes < 2 {
 			fmt.Fprintf(w, "group %q — 1 node (no peers to compare): %s\n", g.Key, g.Reference)
@@ -160,7 +162,7 @@ func renderDriftText(w io.Writer, doc hclload.DriftJSON, details bool) {
 		for _, d := range g.Drifters {
 			fmt.Fprintf(w, "  ✗ %s: %s\n", d.Node, d.Summary.OneLiner())
 			if details {
-				hclload.RenderObjectComparisons(prefixWriter(w, "      "), d.Objects)
+				hclload.RenderObjectComparisonsOpts(prefixWriter(w, "      "), d.Objects, opts)
 			}
 		}
 	}
@@ -204,7 +206,7 @@ func loadDriftNodes(dir, glob string) ([]driftNode, error) {
 
 		n := driftNode{
 			File:   path,
-			Name:   strings.TrimSuffix(e.Name(), ".hcl"),
+			Name:   hclload.DumpNodeName(path),
 			Schema: schema,
 		}
 		if len(schema.Nodes) > 0 {
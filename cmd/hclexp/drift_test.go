@@ -75,6 +75,25 @@ func TestLoadDriftNodes_Glob(t *testing.T) {
 	assert.Error(t, err, "an invalid pattern in the list should error")
 }
 
+// Dumps without a node{} block are named after their file. Two hosts whose
+// short names collided were written as ch-1.hcl and ch-1~2.hcl; they must stay
+// two nodes rather than both decoding to "ch-1".
+func TestLoadDriftNodes_CollidingFileNames(t *testing.T) {
+	dir := t.TempDir()
+	schema := `database "posthog" {}` + "\n"
+	for _, name := range []string{"ch-1.hcl", "ch-1~2.hcl", "a%2Fb.hcl"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(schema), 0o644))
+	}
+
+	nodes, err := loadDriftNodes(dir, "*")
+	require.NoError(t, err)
+	names := make([]string, len(nodes))
+	for i, n := range nodes {
+		names[i] = n.Name
+	}
+	assert.ElementsMatch(t, []string{"ch-1", "ch-1~2", "a/b"}, names)
+}
+
 func TestNormalizeZKPaths(t *testing.T) {
 	schemaWithZK := func(path string) *hclload.Schema {
 		return &hclload.Schema{Databases: []hclload.DatabaseSpec{{
@@ -294,7 +313,7 @@ func TestRenderDriftText(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	renderDriftText(&buf, doc, true)
+	renderDriftText(&buf, doc, true, hclload.RenderOptions{})
 	assert.Equal(t, `group "ops" — 2 nodes, reference node-a — 1 drifting
   ✗ node-b: ~1 raw
       database "d"
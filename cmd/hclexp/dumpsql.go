@@ -26,43 +26,45 @@ const beautifyThreshold = 120
 // (see the round-trip fidelity test). Unlike `introspect` (which emits HCL),
 // dump-sql emits ClickHouse's own canonical DDL verbatim.
 func runDumpSQL(args []string) {
-	cfg := config.GetDefaultConfig()
-
 	fs := flag.NewFlagSet("hclexp dump-sql", flag.ExitOnError)
-	host := fs.String("host", cfg.Host, "ClickHouse host")
-	port := fs.Int("port", cfg.Port, "ClickHouse port")
-	dbFlag := fs.String("database", cfg.Database, "database to dump")
-	user := fs.String("user", cfg.User, "ClickHouse user")
-	password := fs.String("password", cfg.Password, "ClickHouse password")
+	connFlags := addConnFlags(fs, "ClickHouse host, or comma-separated host[:port] replicas to fail over between")
+	dbFlag := fs.String("database", config.GetDefaultConfig().Database, "database to dump")
 	outFlag := fs.String("out", "", "output .sql file; empty or '-' writes to stdout")
-	secure := fs.Bool("secure", cfg.Secure, "connect to ClickHouse over TLS")
-	skipVerify := fs.Bool("tls-skip-verify", cfg.TLSSkipVerify, "skip TLS certificate verification (requires -secure)")
 	showSecrets := fs.Bool("show-secrets", false, "capture real secret values instead of '[HIDDEN]'; requires server display_secrets_in_show_and_select=1 and the displaySecretsInShowAndSelect grant")
 	_ = fs.Parse(args)
 
-	if *dbFlag == "" {
+	cfg, dsnDatabases, err := connFlags.config()
+	if err != nil {
+		slog.Error("invalid connection flags", "err", err)
+		exit(exitUsage)
+	}
+	database := *dbFlag
+	if !flagWasSet(fs, "database") && len(dsnDatabases) > 0 {
+		if len(dsnDatabases) > 1 {
+			slog.Error("dump-sql dumps one database; the -dsn path names several", "databases", dsnDatabases)
+			exit(exitUsage)
+		}
+		database = dsnDatabases[0]
+	}
+	if database == "" {
 		slog.Error("no database specified")
-		os.Exit(1)
+		exit(exitError)
 	}
 
-	cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Database = *host, *port, *user, *password, *dbFlag
+	cfg.Database = database
 	cfg.ShowSecrets = *showSecrets
-	if err := applyTLSFlags(&cfg, *secure, *skipVerify); err != nil {
-		slog.Error("invalid TLS flag combination", "err", err)
-		os.Exit(2)
-	}
 
 	conn, err := config.NewConnection(cfg)
 	if err != nil {
 		slog.Error("failed to connect to ClickHouse", "host", cfg.Host, "port", cfg.Port, "err", err)
-		os.Exit(1)
+		exit(exitError)
 	}
 	defer conn.Close()
 
-	out, err := dumpCreateStatements(context.Background(), conn, *dbFlag)
+	out, err := dumpCreateStatements(context.Background(), conn, database)
 	if err != nil {
-		slog.Error("failed to dump create statements", "database", *dbFlag, "err", err)
-		os.Exit(1)
+		slog.Error("failed to dump create statements", "database", database, "err", err)
+		exit(exitError)
 	}
 
 	if stdoutTarget(*outFlag) {
@@ -71,9 +73,9 @@ func runDumpSQL(args []string) {
 	}
 	if err := os.WriteFile(*outFlag, []byte(out), 0o644); err != nil {
 		slog.Error("failed to write output file", "out", *outFlag, "err", err)
-		os.Exit(1)
+		exit(exitError)
 	}
-	slog.Info("wrote create statements", "database", *dbFlag, "out", *outFlag)
+	slog.Info("wrote create statements", "database", database, "out", *outFlag)
 }
 
 // dumpObject is one object's canonical CREATE DDL plus its engine, used to order
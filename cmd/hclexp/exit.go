@@ -0,0 +1,40 @@
+package main
+
+import "os"
+
+// Exit statuses. They are a contract for scripts wrapping hclexp, so a code's
+// meaning never changes once released:
+//
+//   - exitOK: the command did its job; a check command found nothing.
+//   - exitError: the command could not do its job (unreadable input, failed
+//     connection or query, render failure, timeout).
+//   - exitUsage: invalid flags or arguments; nothing was attempted.
+//   - exitFindings: a check command ran to completion and found problems —
+//     validate or lint errors, drift between nodes, locate misses or duplicates,
+//     files fmt -check would rewrite.
+//
+// Data (schemas, reports, JSON, SQL) goes to stdout; logs and diagnostics go
+// to stderr, so stdout can be piped or parsed whatever the exit status.
+// Reporting commands (diff, plan, show) exit 0 whether or not they find
+// differences; diff -exit-code opts into exitFindings.
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitFindings = 3
+)
+
+// atExit runs just before the process exits through exit. main sets it to
+// stop the -pprof profiler.
+var atExit func()
+
+// exit ends the process with the given status. Commands call it instead of
+// os.Exit, which skips deferred calls: it runs atExit first, so profiles are
+// complete whatever the status.
+func exit(code int) {
+	if atExit != nil {
+		atExit()
+		atExit = nil
+	}
+	os.Exit(code)
+}
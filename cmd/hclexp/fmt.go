@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	hclload "github.com/posthog/chschema/internal/loader/hcl"
+)
+
+// runFmt rewrites .hcl files into canonical HCL formatting: the indentation,
+// spacing and `=` alignment that dumps and `load -out` produce, so
+// hand-written layers and generated files diff cleanly. Content is untouched:
+// attribute order, comments and blank lines stay as written. With -check,
+// nothing is written and the command exits 3 when any file needs formatting,
+// for CI.
+func runFmt(args []string) {
+	fset := flag.NewFlagSet("hclexp fmt", flag.ExitOnError)
+	check := fset.Bool("check", false, "do not write; list files that need formatting and exit 3 if there are any")
+	_ = fset.Parse(args)
+
+	paths := fset.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := hclFilesUnder(paths)
+	if err != nil {
+		slog.Error("failed to list files", "err", err)
+		exit(exitError)
+	}
+
+	changed := 0
+	for _, path := range files {
+		differs, err := formatFile(path, !*check)
+		if err != nil {
+			slog.Error("failed to format", "file", path, "err", err)
+			exit(exitError)
+		}
+		if differs {
+			fmt.Println(path)
+			changed++
+		}
+	}
+	if *check && changed > 0 {
+		slog.Error("files need formatting", "files", changed)
+		exit(exitFindings)
+	}
+}
+
+// hclFilesUnder expands paths into .hcl files: a file is taken as given, a
+// directory contributes every .hcl beneath it, skipping hidden directories
+// (.git and the like). The result is sorted per argument, in argument order.
+func hclFilesUnder(paths []string) ([]string, error) {
+	var out []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			out = append(out, p)
+			continue
+		}
+		err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != p && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if filepath.Ext(path) == ".hcl" {
+				out = append(out, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// formatFile reports whether path differs from its canonical formatting and,
+// when write is set, rewrites it atomically (see hclload.WriteFileAtomic),
+// keeping its mode. A file that does not parse is an error rather than being
+// formatted into something else.
+func formatFile(path string, write bool) (bool, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	if _, diags := hclsyntax.ParseConfig(src, path, hcl.InitialPos); diags.HasErrors() {
+		return false, diags
+	}
+	formatted := hclwrite.Format(src)
+	if bytes.Equal(src, formatted) {
+		return false, nil
+	}
+	if write {
+		if err := hclload.WriteFileAtomic(path, formatted); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.hcl")
+	messy := "database \"posthog\" {\n    table \"events\" {\n  order_by=[\"id\"]\n      column \"id\" { type=\"UInt64\" } # key\n  }\n}\n"
+	require.NoError(t, os.WriteFile(path, []byte(messy), 0o600))
+
+	differs, err := formatFile(path, false)
+	require.NoError(t, err)
+	assert.True(t, differs)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, messy, string(data), "check mode must not write")
+
+	differs, err = formatFile(path, true)
+	require.NoError(t, err)
+	assert.True(t, differs)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "database \"posthog\" {\n  table \"events\" {\n    order_by = [\"id\"]\n    column \"id\" { type = \"UInt64\" } # key\n  }\n}\n", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "a rewrite keeps the mode")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temp file left behind")
+
+	differs, err = formatFile(path, true)
+	require.NoError(t, err)
+	assert.False(t, differs, "formatting is idempotent")
+}
+
+func TestFormatFile_ParseError(t *testing.T) {
+	path := writeTemp(t, "bad.hcl", "database \"posthog\" {\n")
+	_, err := formatFile(path, true)
+	require.Error(t, err)
+	data, readErr := os.ReadFile(path)
+	require.NoError(t, readErr)
+	assert.Equal(t, "database \"posthog\" {\n", string(data))
+}
+
+func TestHCLFilesUnder(t *testing.T) {
+	root := t.TempDir()
+	for _, p := range []string{"a.hcl", "layers/base/b.hcl", "layers/notes.md", ".git/x.hcl"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, p)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, p), nil, 0o644))
+	}
+	files, err := hclFilesUnder([]string{root})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "a.hcl"), filepath.Join(root, "layers", "base", "b.hcl")}, files)
+}
@@ -39,24 +39,24 @@ func runGitHubToken(args []string) {
 
 	if *appID == "" || *installationID == "" {
 		fmt.Fprintln(os.Stderr, "github-token: -app-id and -installation-id are required")
-		os.Exit(2)
+		exit(exitUsage)
 	}
 
 	pemBytes, err := loadAppPrivateKey(*keyFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "github-token: %v\n", err)
-		os.Exit(1)
+		exit(exitError)
 	}
 	key, err := parseRSAPrivateKey(pemBytes)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "github-token: %v\n", err)
-		os.Exit(1)
+		exit(exitError)
 	}
 
 	jwt, err := buildAppJWT(*appID, key, time.Now())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "github-token: %v\n", err)
-		os.Exit(1)
+		exit(exitError)
 	}
 
 	var repos []string
@@ -73,7 +73,7 @@ func runGitHubToken(args []string) {
 	resp, err := requestInstallationToken(context.Background(), githubAPIBase, *installationID, jwt, repos)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "github-token: %v\n", err)
-		os.Exit(1)
+		exit(exitError)
 	}
 
 	if *repo != "" {
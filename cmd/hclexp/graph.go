@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"os"
+
+	hclload "github.com/posthog/chschema/internal/loader/hcl"
+)
+
+// runGraph prints the data-flow graph of a resolved schema — materialized
+// views, views, Distributed/Buffer/TimeSeries tables and ClickHouse-sourced
+// dictionaries — as Mermaid or Graphviz DOT.
+func runGraph(args []string) {
+	fs := flag.NewFlagSet("hclexp graph", flag.ExitOnError)
+	configFlag := configRootsFlag(fs)
+	layersFlag := fs.String("layer", "", "comma-separated layer stack: each entry a directory or a single .hcl file (loaded in order)")
+	formatFlag := fs.String("format", hclload.GraphMermaid, "output format: mermaid or dot")
+	varFlags := addVarFlags(fs)
+	_ = fs.Parse(args)
+
+	if *formatFlag != hclload.GraphMermaid && *formatFlag != hclload.GraphDOT {
+		slog.Error("invalid -format (want mermaid or dot)", "format", *formatFlag)
+		exit(exitUsage)
+	}
+
+	schema, err := loadOpts(configFlag.String(), *layersFlag, varFlags.loadOptions())
+	if err != nil {
+		slog.Error("failed to load config", "err", err)
+		exit(exitError)
+	}
+	if err := hclload.Resolve(schema); err != nil {
+		slog.Error("failed to resolve schema", "err", err)
+		exit(exitError)
+	}
+	edges, err := hclload.DataFlowEdges(schema.Databases)
+	if err != nil {
+		slog.Error("failed to build graph", "err", err)
+		exit(exitError)
+	}
+	if err := hclload.RenderGraph(os.Stdout, schema.Databases, edges, *formatFlag); err != nil {
+		slog.Error("failed to render graph", "err", err)
+		exit(exitError)
+	}
+}
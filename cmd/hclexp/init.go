@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// scaffoldFile is one file written by `hclexp init`, its path relative to the
+// target directory.
+type scaffoldFile struct {
+	path    string
+	content string
+}
+
+// runInit creates the recommended starting layout for a new schema repo: a
+// manifest with one role across dev and prod, a base layer holding an example
+// table, an empty layer per environment, a .chschemaignore and an example of
+// the CLICKHOUSE_* connection variables. The result loads as-is, so a new
+// repo starts from a structure every command already understands.
+func runInit(args []string) {
+	fset := flag.NewFlagSet("hclexp init", flag.ExitOnError)
+	dirFlag := fset.String("dir", ".", "directory to create the layout in (created if missing)")
+	dbFlag := fset.String("database", "default", "database the example table is declared in")
+	force := fset.Bool("force", false, "overwrite files that already exist")
+	_ = fset.Parse(args)
+
+	if *dbFlag == "" {
+		slog.Error("-database must not be empty")
+		exit(exitUsage)
+	}
+	written, err := writeScaffold(*dirFlag, scaffoldFiles(*dbFlag), *force)
+	if err != nil {
+		slog.Error("failed to create layout", "dir", *dirFlag, "err", err)
+		exit(exitError)
+	}
+	for _, p := range written {
+		fmt.Println(p)
+	}
+	slog.Info("created schema layout", "dir", *dirFlag, "files", len(written))
+}
+
+// writeScaffold writes files under dir and returns the paths written. Unless
+// force is set it refuses to start when any of them already exists, so init
+// never half-overwrites an existing repo.
+func writeScaffold(dir string, files []scaffoldFile, force bool) ([]string, error) {
+	if !force {
+		for _, f := range files {
+			p := filepath.Join(dir, f.path)
+			if _, err := os.Stat(p); err == nil {
+				return nil, fmt.Errorf("%s already exists (use -force to overwrite)", p)
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+	written := make([]string, 0, len(files))
+	for _, f := range files {
+		p := filepath.Join(dir, f.path)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(p, []byte(f.content), 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, p)
+	}
+	return written, nil
+}
+
+// scaffoldFiles is the layout `hclexp init` writes, with the example table
+// declared in database.
+func scaffoldFiles(database string) []scaffoldFile {
+	return []scaffoldFile{
+		{"manifest.hcl", `# Roles × environments: each (role, env) is the ordered composition of its
+# layers. Add roles for node groups that run different schemas, and envs as
+# you deploy them. Layer paths are relative to -layer-root.
+#
+#   hclexp load -manifest manifest.hcl -env dev -layer-root .
+
+role "default" {
+  env "dev"  { layers = ["layers/base", "layers/env/dev"] }
+  env "prod" { layers = ["layers/base", "layers/env/prod"] }
+}
+
+# Each environment's server, selected by the commands that connect with
+# -env (e.g. hclexp introspect -env prod -out prod.hcl). The password comes
+# from CLICKHOUSE_PASSWORD or -password, never from this file.
+#
+# connection "prod" {
+#   host      = "clickhouse.example.com"
+#   port      = 9440
+#   secure    = true
+#   user      = "readonly"
+#   databases = ["` + database + `"]
+#   cluster   = "default"
+# }
+`},
+		{filepath.Join("layers", "base", database+".hcl"), fmt.Sprintf(`# Shared by every role and environment. Split objects across files as the
+# schema grows; every *.hcl in a layer directory is loaded.
+database %q {
+  table "events" {
+    engine "merge_tree" {}
+    order_by = ["timestamp", "uuid"]
+    column "uuid"      { type = "UUID" }
+    column "timestamp" { type = "DateTime" }
+    column "event"     { type = "String" }
+  }
+}
+`, database)},
+		{filepath.Join("layers", "env", "dev", "dev.hcl"), `# Dev-only declarations and patch_table blocks go here.
+`},
+		{filepath.Join("layers", "env", "prod", "prod.hcl"), `# Prod-only declarations and patch_table blocks go here.
+`},
+		{".chschemaignore", `# Objects introspect, diff, plan and drift treat as nonexistent (picked up
+# from the working directory when -exclude is not given). See
+# docs/README.hcl.md for the full syntax.
+exclude {
+  patterns = ["_tmp_replace_*", "tmp_*", "*_backup", "*_backup_*"]
+}
+`},
+		{"clickhouse.env.example", `# Connection defaults for commands that talk to ClickHouse. Copy to an
+# untracked file, fill in, and export before running hclexp.
+CLICKHOUSE_HOST=localhost
+CLICKHOUSE_PORT=9000
+CLICKHOUSE_DB=` + database + `
+CLICKHOUSE_USER=
+CLICKHOUSE_PASSWORD=
+CLICKHOUSE_SECURE=false
+CLICKHOUSE_TLS_SKIP_VERIFY=false
+`},
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	hclload "github.com/posthog/chschema/internal/loader/hcl"
+)
+
+// The scaffold must load as written: every env composes, the example table
+// resolves, and the ignore file is a valid exclude config.
+func TestWriteScaffold_Loads(t *testing.T) {
+	dir := t.TempDir()
+	written, err := writeScaffold(dir, scaffoldFiles("analytics"), false)
+	require.NoError(t, err)
+	assert.Contains(t, written, filepath.Join(dir, "layers", "base", "analytics.hcl"))
+
+	for _, env := range []string{"dev", "prod"} {
+		roles, err := parseManifest(filepath.Join(dir, "manifest.hcl"), env)
+		require.NoError(t, err)
+		composed, err := composeManifestRoles(roles, dir)
+		require.NoError(t, err, env)
+		require.Len(t, composed, 1)
+		s := composed[0].Schema
+		require.Len(t, s.Databases, 1)
+		assert.Equal(t, "analytics", s.Databases[0].Name)
+		require.Len(t, s.Databases[0].Tables, 1)
+		assert.Equal(t, "events", s.Databases[0].Tables[0].Name)
+	}
+
+	m, err := hclload.LoadExcludeConfig(filepath.Join(dir, hclload.DefaultExcludeFile))
+	require.NoError(t, err)
+	assert.True(t, m.Matches("analytics", "tmp_migration"))
+}
+
+func TestWriteScaffold_RefusesToOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "manifest.hcl")
+	require.NoError(t, os.WriteFile(manifest, []byte("# mine\n"), 0o644))
+
+	_, err := writeScaffold(dir, scaffoldFiles("default"), false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "use -force")
+	got, _ := os.ReadFile(manifest)
+	assert.Equal(t, "# mine\n", string(got), "nothing is written on refusal")
+	_, err = os.Stat(filepath.Join(dir, "layers"))
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = writeScaffold(dir, scaffoldFiles("default"), true)
+	require.NoError(t, err)
+	got, _ = os.ReadFile(manifest)
+	assert.Contains(t, string(got), `role "default"`)
+}
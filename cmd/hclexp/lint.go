@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	hclload "github.com/posthog/chschema/internal/loader/hcl"
+)
+
+// runLint checks a resolved schema against the built-in lint rules. Each
+// finding is printed to stderr with its severity, like validate's errors; the
+// command exits 3 when any finding is at error severity. -rules overrides
+// per-rule severities, so one rule set can be strict for prod and relaxed
+// elsewhere.
+func runLint(args []string) {
+	fs := flag.NewFlagSet("hclexp lint", flag.ExitOnError)
+	configFlag := configRootsFlag(fs)
+	layersFlag := fs.String("layer", "", "comma-separated layer stack: each entry a directory or a single .hcl file (loaded in order)")
+	rulesFlag := fs.String("rules", "", "comma-separated RULE=off|warn|error severity overrides")
+	listRules := fs.Bool("list-rules", false, "print the rules with their default severities and exit")
+	varFlags := addVarFlags(fs)
+	_ = fs.Parse(args)
+
+	if *listRules {
+		for _, r := range hclload.LintRules {
+			fmt.Printf("%-24s %-6s %s\n", r.Name, r.Default, r.Describe)
+		}
+		return
+	}
+	severities, err := hclload.ParseLintSeverities(*rulesFlag)
+	if err != nil {
+		slog.Error("invalid -rules", "err", err)
+		exit(exitUsage)
+	}
+
+	schema, err := loadOpts(configFlag.String(), *layersFlag, varFlags.loadOptions())
+	if err != nil {
+		slog.Error("failed to load config", "err", err)
+		exit(exitError)
+	}
+	if err := hclload.Resolve(schema); err != nil {
+		slog.Error("failed to resolve schema", "err", err)
+		exit(exitError)
+	}
+
+	errs := 0
+	findings := hclload.Lint(schema.Databases, severities)
+	for _, f := range findings {
+		fmt.Fprintf(os.Stderr, "lint %s: %s\n", f.Severity, f)
+		if f.Severity == hclload.LintError {
+			errs++
+		}
+	}
+	if errs > 0 {
+		slog.Error("lint failed", "errors", errs, "warnings", len(findings)-errs)
+		exit(exitFindings)
+	}
+	slog.Info("lint passed", "warnings", len(findings))
+}
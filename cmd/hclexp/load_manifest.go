@@ -16,11 +16,13 @@ import (
 
 // loadRoleJSON is one role's resolved layer stack in the `load -format json`
 // document. Layers is what the manifest declares; ResolvedLayers is those
-// paths joined under -layer-root, i.e. the dirs handed to the loader.
+// paths joined under -layer-root, i.e. the dirs handed to the loader; Only is
+// the env's object include list, when it sets one.
 type loadRoleJSON struct {
 	Role           string   `json:"role"`
 	Layers         []string `json:"layers"`
 	ResolvedLayers []string `json:"resolved_layers"`
+	Only           []string `json:"only,omitempty"`
 }
 
 // loadJSON is the document emitted by `hclexp load -manifest -format json`.
@@ -175,17 +177,19 @@ func checkOutTarget(out string, nRoles int) error {
 // non-empty), applies the object filters, and writes them out. In hcl format a
 // single role goes to a file or stdout and multiple roles go to the -out
 // directory, each named by the -out-name template (default <env>-<role>.hcl);
-// in json format the resolved layer stacks go to stdout or -out.
-func runLoadManifest(manifestPath, env, role, layerRoot, format, out, outName string, filters loadFilters) {
+// in json format the resolved layer stacks go to stdout or -out. opts (the
+// -var/-var-file/-allow-env flags) apply on top of each role's manifest vars.
+func runLoadManifest(manifestPath, env, role, layerRoot, format, out, outName string, opts hclload.LoadOptions, filters loadFilters, writeOpts hclload.WriteOptions) {
 	roles, err := parseManifest(manifestPath, env)
 	if err != nil {
 		slog.Error("failed to parse manifest", "file", manifestPath, "env", env, "err", err)
-		os.Exit(1)
+		exit(exitError)
 	}
+	roles = withLoadOptions(roles, opts)
 	roles, err = filterManifestRoles(roles, role, env)
 	if err != nil {
 		slog.Error("failed to select role", "file", manifestPath, "env", env, "err", err)
-		os.Exit(2)
+		exit(exitUsage)
 	}
 
 	// The JSON document is the stacks themselves, so it resolves structurally
@@ -193,7 +197,7 @@ func runLoadManifest(manifestPath, env, role, layerRoot, format, out, outName st
 	if format == "json" {
 		if err := writeLoadJSON(out, env, resolveManifestStacks(roles, layerRoot)); err != nil {
 			slog.Error("failed to write JSON", "err", err)
-			os.Exit(1)
+			exit(exitError)
 		}
 		return
 	}
@@ -201,7 +205,7 @@ func runLoadManifest(manifestPath, env, role, layerRoot, format, out, outName st
 	composed, err := composeManifestRoles(roles, layerRoot)
 	if err != nil {
 		slog.Error("failed to compose manifest roles", "file", manifestPath, "env", env, "err", err)
-		os.Exit(1)
+		exit(exitError)
 	}
 	for i := range composed {
 		filters.apply(composed[i].Schema)
@@ -209,19 +213,19 @@ func runLoadManifest(manifestPath, env, role, layerRoot, format, out, outName st
 
 	if err := checkOutTarget(out, len(composed)); err != nil {
 		slog.Error("invalid output target", "env", env, "err", err)
-		os.Exit(2)
+		exit(exitUsage)
 	}
 	// The template names files inside a directory; a single role headed for
 	// stdout or a plain file has nothing for it to name.
 	if outName != defaultOutName && !isDir(out) {
 		slog.Error("invalid output target", "env", env, "err",
 			fmt.Errorf("-out-name requires -out to be an existing directory"))
-		os.Exit(2)
+		exit(exitUsage)
 	}
 
-	if err := writeComposedRoles(out, env, outName, composed); err != nil {
+	if err := writeComposedRoles(out, env, outName, composed, writeOpts); err != nil {
 		slog.Error("failed to write composed schema", "err", err)
-		os.Exit(1)
+		exit(exitError)
 	}
 	slog.Info("composed manifest roles", "env", env, "roles", len(composed))
 }
@@ -257,6 +261,7 @@ func writeLoadJSON(out, env string, composed []composedRole) error {
 			Role:           c.Role,
 			Layers:         c.Layers,
 			ResolvedLayers: c.Resolved,
+			Only:           c.Only,
 		})
 	}
 	buf, err := json.MarshalIndent(doc, "", "  ")
@@ -277,12 +282,12 @@ func writeLoadJSON(out, env string, composed []composedRole) error {
 // its outName-templated path inside it; several roles always go to the -out
 // directory. Template subdirectories (e.g. '{env}/{role}') are created; two
 // roles rendering to the same path is an error rather than a silent overwrite.
-func writeComposedRoles(out, env, outName string, composed []composedRole) error {
+func writeComposedRoles(out, env, outName string, composed []composedRole, opts hclload.WriteOptions) error {
 	if len(composed) == 1 && stdoutTarget(out) {
-		return hclload.Write(os.Stdout, composed[0].Schema)
+		return hclload.WriteOpts(os.Stdout, composed[0].Schema, opts)
 	}
 	if len(composed) == 1 && !isDir(out) {
-		return writeSchemaFile(out, composed[0].Schema)
+		return writeSchemaFile(out, composed[0].Schema, opts)
 	}
 	pathRole := map[string]string{}
 	for _, c := range composed {
@@ -301,21 +306,20 @@ func writeComposedRoles(out, env, outName string, composed []composedRole) error
 		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 			return fmt.Errorf("role %q: %w", c.Role, err)
 		}
-		if err := writeSchemaFile(path, c.Schema); err != nil {
+		if err := writeSchemaFile(path, c.Schema, opts); err != nil {
 			return fmt.Errorf("role %q: %w", c.Role, err)
 		}
 	}
 	return nil
 }
 
-// writeSchemaFile writes one resolved schema to path as canonical HCL.
-func writeSchemaFile(path string, schema *hclload.Schema) error {
-	f, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("open %q: %w", path, err)
+// writeSchemaFile writes one resolved schema to path as canonical HCL,
+// atomically (see hclload.WriteFile).
+func writeSchemaFile(path string, schema *hclload.Schema, opts hclload.WriteOptions) error {
+	if _, err := hclload.WriteFileIfChangedOpts(path, schema, opts); err != nil {
+		return fmt.Errorf("write %q: %w", path, err)
 	}
-	defer f.Close()
-	return hclload.Write(f, schema)
+	return nil
 }
 
 // isDir reports whether path exists and is a directory.
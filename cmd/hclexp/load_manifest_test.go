@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
 
 	hclload "github.com/posthog/chschema/internal/loader/hcl"
 	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // twoRoleManifest writes a data/aux layer tree plus the manifest describing it,
@@ -72,6 +74,71 @@ func TestComposeManifestRoles(t *testing.T) {
 	require.Equal(t, "sharded_web_stats", composed[1].Schema.Databases[0].Tables[0].Name)
 }
 
+// An env block's `only` list narrows the composition to the selected objects,
+// so one layer tree drives envs with different (feature-flagged) subsets.
+func TestComposeManifestRoles_EnvOnly(t *testing.T) {
+	root := t.TempDir()
+	writeLayer(t, root, "base/base.hcl", `
+database "posthog" {
+  table "base" {
+    abstract = true
+    column "id" { type = "UUID" }
+  }
+  table "events" {
+    extend = "base"
+    engine "merge_tree" {}
+  }
+  table "beta_feature" {
+    extend = "base"
+    engine "merge_tree" {}
+  }
+}`)
+	manifest := writeTemp(t, "manifest.hcl", `
+role "data" {
+  env "dev"  { layers = ["base"] }
+  env "prod" {
+    layers = ["base"]
+    only   = ["posthog.events"]
+  }
+}`)
+
+	roles, err := parseManifest(manifest, "dev")
+	require.NoError(t, err)
+	composed, err := composeManifestRoles(roles, root)
+	require.NoError(t, err)
+	require.Equal(t, []string{"beta_feature", "events"}, sortedTableNames(composed[0].Schema.Databases[0]))
+
+	roles, err = parseManifest(manifest, "prod")
+	require.NoError(t, err)
+	require.Equal(t, []string{"posthog.events"}, roles[0].Only)
+	composed, err = composeManifestRoles(roles, root)
+	require.NoError(t, err)
+	require.Equal(t, []string{"events"}, tableNames(composed[0].Schema.Databases[0]),
+		"only the listed object is active; the abstract base still fed it")
+	require.Len(t, composed[0].Schema.Databases[0].Tables[0].Columns, 1)
+}
+
+func TestParseManifest_InvalidOnlyPattern(t *testing.T) {
+	manifest := writeTemp(t, "manifest.hcl", `
+role "data" {
+  env "dev" {
+    layers = ["base"]
+    only   = ["["]
+  }
+}`)
+	_, err := parseManifest(manifest, "dev")
+	require.ErrorContains(t, err, "invalid only pattern")
+
+	_, err = manifestCompositions(manifest, "")
+	require.ErrorContains(t, err, "invalid only pattern")
+}
+
+func sortedTableNames(db hclload.DatabaseSpec) []string {
+	names := tableNames(db)
+	sort.Strings(names)
+	return names
+}
+
 // A later layer in a role's stack overrides an earlier one, so composition
 // through the manifest honors the same precedence as -layer.
 func TestComposeManifestRoles_LayerPrecedence(t *testing.T) {
@@ -149,7 +216,7 @@ func TestRunLoadManifest_SingleRoleToFile(t *testing.T) {
 	composed, err := composeManifestRoles(roles, root)
 	require.NoError(t, err)
 	require.NoError(t, checkOutTarget(out, len(composed)))
-	require.NoError(t, writeComposedRoles(out, "dev", defaultOutName, composed))
+	require.NoError(t, writeComposedRoles(out, "dev", defaultOutName, composed, hclload.WriteOptions{}))
 
 	body, err := os.ReadFile(out)
 	require.NoError(t, err)
@@ -168,7 +235,7 @@ func TestRunLoadManifest_AllRolesToDir(t *testing.T) {
 	composed, err := composeManifestRoles(roles, root)
 	require.NoError(t, err)
 	require.NoError(t, checkOutTarget(out, len(composed)))
-	require.NoError(t, writeComposedRoles(out, "dev", defaultOutName, composed))
+	require.NoError(t, writeComposedRoles(out, "dev", defaultOutName, composed, hclload.WriteOptions{}))
 
 	for _, name := range []string{"dev-data.hcl", "dev-aux.hcl"} {
 		_, err := os.Stat(filepath.Join(out, name))
@@ -181,7 +248,7 @@ func TestRunLoadManifest_AllRolesToDir(t *testing.T) {
 	require.NoError(t, err)
 	stagingComposed, err := composeManifestRoles(stagingRoles, root)
 	require.NoError(t, err)
-	require.NoError(t, writeComposedRoles(stagingOut, "staging", defaultOutName, stagingComposed))
+	require.NoError(t, writeComposedRoles(stagingOut, "staging", defaultOutName, stagingComposed, hclload.WriteOptions{}))
 
 	entries, err := os.ReadDir(stagingOut)
 	require.NoError(t, err)
@@ -374,7 +441,7 @@ func TestWriteComposedRoles_OutNamePerEnvLayout(t *testing.T) {
 	require.NoError(t, err)
 	composed, err := composeManifestRoles(roles, root)
 	require.NoError(t, err)
-	require.NoError(t, writeComposedRoles(out, "dev", "{env}/{role}", composed))
+	require.NoError(t, writeComposedRoles(out, "dev", "{env}/{role}", composed, hclload.WriteOptions{}))
 
 	for _, name := range []string{"dev/data.hcl", "dev/aux.hcl"} {
 		_, err := os.Stat(filepath.Join(out, name))
@@ -399,11 +466,11 @@ func TestWriteComposedRoles_OutNameStaticAndCollision(t *testing.T) {
 	composed, err := composeManifestRoles(roles, root)
 	require.NoError(t, err)
 
-	err = writeComposedRoles(out, "dev", "{env}/schema", composed)
+	err = writeComposedRoles(out, "dev", "{env}/schema", composed, hclload.WriteOptions{})
 	require.ErrorContains(t, err, `roles "data" and "aux" both render`)
 
 	single := composed[:1]
-	require.NoError(t, writeComposedRoles(out, "dev", "{env}/schema", single))
+	require.NoError(t, writeComposedRoles(out, "dev", "{env}/schema", single, hclload.WriteOptions{}))
 	_, err = os.Stat(filepath.Join(out, "dev", "schema.hcl"))
 	require.NoError(t, err)
 }
@@ -418,9 +485,9 @@ func TestWriteComposedRoles_OutNameEscapes(t *testing.T) {
 	composed, err := composeManifestRoles(roles, root)
 	require.NoError(t, err)
 
-	require.ErrorContains(t, writeComposedRoles(out, "dev", "../{role}", composed),
+	require.ErrorContains(t, writeComposedRoles(out, "dev", "../{role}", composed, hclload.WriteOptions{}),
 		"escapes the -out directory")
-	require.ErrorContains(t, writeComposedRoles(out, "dev", "/abs/{role}", composed),
+	require.ErrorContains(t, writeComposedRoles(out, "dev", "/abs/{role}", composed, hclload.WriteOptions{}),
 		"must be relative to -out")
 }
 
@@ -449,7 +516,7 @@ func TestRunLoadManifest_RoundTrip(t *testing.T) {
 	require.NoError(t, err)
 	composed, err := composeManifestRoles(roles, root)
 	require.NoError(t, err)
-	require.NoError(t, writeComposedRoles(out, "dev", defaultOutName, composed))
+	require.NoError(t, writeComposedRoles(out, "dev", defaultOutName, composed, hclload.WriteOptions{}))
 
 	reloaded, err := loadSide(out)
 	require.NoError(t, err)
@@ -463,12 +530,84 @@ func TestRunLoadManifest_RoundTrip(t *testing.T) {
 func TestValidateManifest_RoleFilter(t *testing.T) {
 	root, manifest := twoRoleManifest(t)
 
-	results, err := validateManifest(manifest, "dev", root, "data", hclload.ParseSkipSet(""), hclload.ValidateOptions{}, nil)
+	results, err := validateManifest(manifest, "dev", root, "data", hclload.ParseSkipSet(""), hclload.ValidateOptions{}, true, nil, hclload.LoadOptions{})
 	require.NoError(t, err)
 	require.Len(t, results, 1, "only the selected role is validated")
 	require.Equal(t, "data", results[0].Role)
 	require.Empty(t, results[0].Errs, "its cross-role proxy resolves against the whole manifest's clusters")
 
-	_, err = validateManifest(manifest, "dev", root, "nope", hclload.ParseSkipSet(""), hclload.ValidateOptions{}, nil)
+	_, err = validateManifest(manifest, "dev", root, "nope", hclload.ParseSkipSet(""), hclload.ValidateOptions{}, true, nil, hclload.LoadOptions{})
 	require.ErrorIs(t, err, errUnknownRole)
 }
+
+// An env block's vars parameterize its layers, so dev and prod share one
+// layer with different zoo paths; -var overrides the manifest for one run.
+func TestComposeManifestRoles_Vars(t *testing.T) {
+	root := t.TempDir()
+	writeLayer(t, root, "layers/base/events.hcl", `
+database "posthog" {
+  table "events" {
+    order_by = ["day"]
+    column "day" { type = "Date" }
+    engine "replicated_merge_tree" {
+      zoo_path     = "/clickhouse/${var.cluster}/events"
+      replica_name = "{replica}"
+    }
+  }
+}`)
+	manifest := writeTemp(t, "manifest.hcl", `
+role "data" {
+  env "dev"  {
+    layers = ["layers/base"]
+    vars   = { cluster = "dev" }
+  }
+  env "prod" {
+    layers = ["layers/base"]
+    vars   = { cluster = "prod" }
+  }
+}`)
+	zooPath := func(env string, vars map[string]cty.Value) string {
+		t.Helper()
+		roles, err := parseManifest(manifest, env)
+		require.NoError(t, err)
+		composed, err := composeManifestRoles(withLoadOptions(roles, hclload.LoadOptions{Vars: vars}), root)
+		require.NoError(t, err)
+		return composed[0].Schema.Databases[0].Tables[0].Engine.Decoded.(hclload.EngineReplicatedMergeTree).ZooPath
+	}
+
+	require.Equal(t, "/clickhouse/dev/events", zooPath("dev", nil))
+	require.Equal(t, "/clickhouse/prod/events", zooPath("prod", nil))
+
+	var flags varListFlag
+	require.NoError(t, flags.Set("cluster=staging"))
+	require.Equal(t, "/clickhouse/staging/events", zooPath("prod", map[string]cty.Value{"cluster": cty.StringVal(flags.values["cluster"])}))
+
+	require.Error(t, flags.Set("no-equals"))
+	require.Error(t, flags.Set("9lives=x"), "a name var.<name> cannot reference is rejected")
+}
+
+// A manifest env selects its own overlay files: events.staging.hcl patches
+// events.hcl in the staging composition only.
+func TestComposeManifestRoles_EnvOverlay(t *testing.T) {
+	root, manifest := twoRoleManifest(t)
+	writeLayer(t, root, "layers/aux/aux.staging.hcl", `
+database "posthog" {
+  patch_table "sharded_web_stats" {
+    ttl = "day + INTERVAL 7 DAY"
+  }
+}`)
+
+	ttl := func(env string) *string {
+		roles, err := parseManifest(manifest, env)
+		require.NoError(t, err)
+		composed, err := composeManifestRoles(roles, root)
+		require.NoError(t, err)
+		aux := composed[len(composed)-1]
+		require.Equal(t, "aux", aux.Role)
+		return aux.Schema.Databases[0].Tables[0].TTL
+	}
+	require.Nil(t, ttl("dev"), "another env's overlay is skipped")
+	got := ttl("staging")
+	require.NotNil(t, got)
+	require.Equal(t, "day + INTERVAL 7 DAY", *got)
+}
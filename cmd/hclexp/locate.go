@@ -248,7 +248,7 @@ func buildLocateDoc(stacks []locateStack, layerRoot string, extraLayers []string
 			}
 			if node == "" {
 				// The filename stem, same as drift's fallback identity.
-				node = strings.TrimSuffix(filepath.Base(file), ".hcl")
+				node = hclload.DumpNodeName(file)
 			}
 			for _, d := range dumpDecls {
 				if !matchesAnyPattern(patterns, hits, d.Database, d.Name) {
@@ -428,11 +428,11 @@ func runLocate(args []string) {
 	patterns := fs.Args()
 	if err := locateFlagsError(*manifestFlag, *layersFlag, *dumpFlag, *formatFlag, *duplicatesFlag, patterns); err != nil {
 		slog.Error("invalid locate invocation", "err", err)
-		os.Exit(2)
+		exit(exitUsage)
 	}
 	if *dumpFlag != "" && !isDir(*dumpFlag) {
 		slog.Error("dump directory does not exist", "dir", *dumpFlag)
-		os.Exit(1)
+		exit(exitError)
 	}
 
 	var stacks []locateStack
@@ -441,21 +441,21 @@ func runLocate(args []string) {
 		stacks, err = parseManifestAllEnvs(*manifestFlag)
 		if err != nil {
 			slog.Error("failed to parse manifest", "file", *manifestFlag, "err", err)
-			os.Exit(1)
+			exit(exitError)
 		}
 	}
 
 	doc, unmatched, err := buildLocateDoc(stacks, *layerRootFlag, splitList(*layersFlag), *dumpFlag, patterns, *duplicatesFlag)
 	if err != nil {
 		slog.Error("locate failed", "err", err)
-		os.Exit(1)
+		exit(exitError)
 	}
 
 	if *formatFlag == "json" {
 		out, err := json.MarshalIndent(doc, "", "  ")
 		if err != nil {
 			slog.Error("failed to render JSON", "err", err)
-			os.Exit(1)
+			exit(exitError)
 		}
 		fmt.Println(string(out))
 	} else if *duplicatesFlag {
@@ -466,7 +466,7 @@ func runLocate(args []string) {
 
 	if *duplicatesFlag {
 		if len(doc.Duplicates) > 0 {
-			os.Exit(1)
+			exit(exitFindings)
 		}
 		return
 	}
@@ -474,6 +474,6 @@ func runLocate(args []string) {
 		for _, p := range unmatched {
 			fmt.Fprintf(os.Stderr, "locate: no objects match %q\n", p)
 		}
-		os.Exit(1)
+		exit(exitFindings)
 	}
 }
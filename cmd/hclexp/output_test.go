@@ -30,7 +30,7 @@ func TestWriteIntrospected_DashIsStdout(t *testing.T) {
 	require.NoError(t, err)
 	orig := os.Stdout
 	os.Stdout = w
-	werr := writeIntrospected("-", &hclload.Schema{Databases: []hclload.DatabaseSpec{{Name: "posthog"}}})
+	werr := writeIntrospected("-", &hclload.Schema{Databases: []hclload.DatabaseSpec{{Name: "posthog"}}}, hclload.WriteOptions{})
 	require.NoError(t, w.Close())
 	os.Stdout = orig
 	require.NoError(t, werr)
@@ -1,18 +1,22 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hclparse"
 	hclload "github.com/posthog/chschema/internal/loader/hcl"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // planManifest is the HCL manifest: role blocks, each with one env block per
@@ -32,6 +36,10 @@ type planManifest struct {
 	// one or more roles, so each cluster block lists the roles whose
 	// compositions (unioned) make up that cluster's schema.
 	Clusters []manifestClusterBlock `hcl:"cluster,block"`
+
+	// Connections optionally name each environment's live server, so the
+	// commands that connect can select it with -env.
+	Connections []manifestConnectionBlock `hcl:"connection,block"`
 }
 
 type manifestRoleBlock struct {
@@ -42,6 +50,18 @@ type manifestRoleBlock struct {
 type manifestEnvBlock struct {
 	Name   string   `hcl:"name,label"`
 	Layers []string `hcl:"layers"`
+
+	// Only is an optional object include list (name globs, bare or db.name):
+	// when set, only the matching objects of the composed stack are active in
+	// this (role, env). It lets one layer tree drive environments with
+	// different object subsets — feature-flagged tables — without duplicating
+	// directories.
+	Only []string `hcl:"only,optional"`
+
+	// Vars are the values this (role, env)'s layers read as var.<name>, so a
+	// zoo path, cluster name or shard count can differ per environment
+	// without duplicating the layer. -var and -var-file override them.
+	Vars map[string]cty.Value `hcl:"vars,optional"`
 }
 
 // manifestClusterBlock maps a ClickHouse cluster_name to the roles whose nodes
@@ -55,11 +75,59 @@ type manifestClusterBlock struct {
 	Absent  bool     `hcl:"absent,optional"`
 }
 
+// manifestConnectionBlock is the live server of one environment. Every
+// attribute is optional and only overrides the CLICKHOUSE_* default it names.
+// There is deliberately no password: it comes from CLICKHOUSE_PASSWORD or
+// -password, never from a file in the schema repo.
+type manifestConnectionBlock struct {
+	Env              string   `hcl:"env,label"`
+	Host             *string  `hcl:"host,optional"`
+	Port             *int     `hcl:"port,optional"`
+	User             *string  `hcl:"user,optional"`
+	Databases        []string `hcl:"databases,optional"`
+	Secure           *bool    `hcl:"secure,optional"`
+	TLSSkipVerify    *bool    `hcl:"tls_skip_verify,optional"`
+	Protocol         *string  `hcl:"protocol,optional"`
+	ConnOpenStrategy *string  `hcl:"conn_open_strategy,optional"`
+	Proxy            *string  `hcl:"proxy,optional"`
+
+	// Cluster is the system.clusters name dump-cluster enumerates by default.
+	Cluster *string `hcl:"cluster,optional"`
+
+	// Nodes optionally lists every node's host[:port] endpoint, so
+	// dump-cluster dumps this declared topology instead of asking
+	// system.clusters.
+	Nodes []string `hcl:"nodes,optional"`
+}
+
 // manifestRole is a resolved role for one selected environment: a node role and
-// the ordered layer dirs whose composition is that role's desired schema.
+// the ordered layer dirs whose composition is that role's desired schema,
+// narrowed to the env's Only include list when one is set.
 type manifestRole struct {
 	Role   string
 	Layers []string
+	Only   []string
+	Load   hclload.LoadOptions // the env block's vars
+}
+
+// applyManifestOnly keeps only the objects an env block's `only` list selects.
+// An empty list selects everything. It runs after Resolve, so an abstract base
+// outside the list still feeds the objects extending it.
+func applyManifestOnly(schema *hclload.Schema, only []string) {
+	if len(only) == 0 {
+		return
+	}
+	hclload.SelectSchema(schema, hclload.NewExcludeMatcher(only...))
+}
+
+// validManifestOnly checks every glob in an env block's `only` list.
+func validManifestOnly(role, env string, only []string) error {
+	for _, p := range only {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return fmt.Errorf("role %q env %q: invalid only pattern %q: %w", role, env, p, err)
+		}
+	}
+	return nil
 }
 
 // runPlan diffs every role in a manifest against a topology dump and emits one
@@ -73,65 +141,176 @@ func runPlan(args []string) {
 	envFlag := fs.String("env", "", "environment to plan (selects each role's matching env block in the manifest)")
 	layerRootFlag := fs.String("layer-root", ".", "root directory the manifest's layer paths resolve under (e.g. a committed snapshot)")
 	dumpFlag := fs.String("dump", "", "directory of per-node current-state HCL dumps; nodes are matched to roles by their hostClusterRole macro")
-	formatFlag := fs.String("format", "json", "output format: json (default) or text")
+	formatFlag := fs.String("format", "json", "output format: json (default), text, or sql (every statement in global order, as a reviewable script; nothing is executed)")
 	excludeFlag := fs.String("exclude", "", "HCL exclude config: objects matching its patterns/object_types are dropped from both sides before diffing")
+	streamFlag := fs.Bool("stream", false, "text format: print each role's operations as soon as that role is diffed, then the globally-ordered plan")
+	ifExists := fs.Bool("if-exists", false, "guard CREATE/DROP statements and ALTER clauses with IF [NOT] EXISTS so a partially applied plan can be re-run")
+	outFlag := fs.String("out", "", "write the plan to this file instead of stdout ('-' for stdout), e.g. a plan.json artifact for CI review")
+	watchFlag := fs.Bool("watch", false, "re-plan whenever a file under the manifest, -layer-root, -dump or the exclude config changes, until interrupted")
+	watchInterval := fs.Duration("watch-interval", time.Second, "with -watch, how often to check for changes")
+	varFlags := addVarFlags(fs)
 	_ = fs.Parse(args)
 
 	if *manifestFlag == "" || *dumpFlag == "" || *envFlag == "" {
 		slog.Error("-manifest, -env and -dump are required")
-		os.Exit(2)
+		exit(exitUsage)
+	}
+	if *formatFlag != "json" && *formatFlag != "text" && *formatFlag != "sql" {
+		slog.Error("invalid -format (want json, text or sql)", "format", *formatFlag)
+		exit(exitUsage)
 	}
-	if *formatFlag != "json" && *formatFlag != "text" {
-		slog.Error("invalid -format (want json or text)", "format", *formatFlag)
-		os.Exit(2)
+	if *streamFlag && *formatFlag != "text" {
+		slog.Error("-stream requires -format text")
+		exit(exitUsage)
+	}
+	if *streamFlag && !stdoutTarget(*outFlag) {
+		slog.Error("-stream prints to stdout and cannot be combined with -out")
+		exit(exitUsage)
+	}
+	if *watchFlag && *watchInterval <= 0 {
+		slog.Error("-watch-interval must be positive")
+		exit(exitUsage)
 	}
 
-	manifest, err := parseManifest(*manifestFlag, *envFlag)
-	if err != nil {
-		slog.Error("failed to parse manifest", "file", *manifestFlag, "env", *envFlag, "err", err)
-		os.Exit(1)
+	var stream io.Writer
+	if *streamFlag {
+		stream = os.Stdout
+	}
+	// build reads the -var-file and exclude config on every call: both are
+	// watched, so a -watch re-plan must see their current content.
+	build := func() (hclload.PlanResult, error) {
+		opts, err := varFlags.options()
+		if err != nil {
+			return hclload.PlanResult{}, fmt.Errorf("load schema variables: %w", err)
+		}
+		matcher, err := readExclude(*excludeFlag)
+		if err != nil {
+			return hclload.PlanResult{}, fmt.Errorf("load exclude config: %w", err)
+		}
+		return buildManifestPlan(*manifestFlag, *envFlag, *layerRootFlag, *dumpFlag, opts, matcher, *ifExists, stream)
+	}
+
+	if *watchFlag {
+		watched := []string{*manifestFlag, *layerRootFlag, *dumpFlag, excludePath(*excludeFlag), *varFlags.file}
+		var ignore []string
+		if !stdoutTarget(*outFlag) {
+			ignore = []string{*outFlag}
+		}
+		ctx, cancel := commandContext(0)
+		defer cancel()
+		watchFiles(ctx, watched, ignore, *watchInterval, func() {
+			plan, err := build()
+			if err == nil {
+				err = emitPlan(plan, *formatFlag, *outFlag)
+			}
+			if err != nil {
+				slog.Error("plan failed; waiting for the next change", "err", err)
+			}
+		})
+		return
 	}
 
-	current, err := currentByRole(*dumpFlag)
+	plan, err := build()
 	if err != nil {
-		slog.Error("failed to load dump", "dir", *dumpFlag, "err", err)
-		os.Exit(1)
+		slog.Error("failed to plan", "err", err)
+		exit(exitError)
+	}
+	if err := emitPlan(plan, *formatFlag, *outFlag); err != nil {
+		slog.Error("failed to write plan", "out", *outFlag, "err", err)
+		exit(exitError)
 	}
+}
 
-	matcher := loadExcludeFlag(*excludeFlag)
+// buildManifestPlan diffs every role env deploys in the manifest against the
+// dump and returns the globally-ordered plan. With stream set, each role's
+// operations are printed to it as soon as that role is diffed, followed by
+// the global-order header.
+func buildManifestPlan(manifestPath, env, layerRoot, dump string, opts hclload.LoadOptions, matcher *hclload.ExcludeMatcher, ifExists bool, stream io.Writer) (hclload.PlanResult, error) {
+	manifest, err := parseManifest(manifestPath, env)
+	if err != nil {
+		return hclload.PlanResult{}, fmt.Errorf("parse manifest %s (env %s): %w", manifestPath, env, err)
+	}
+	manifest = withLoadOptions(manifest, opts)
+	current, err := currentByRole(dump)
+	if err != nil {
+		return hclload.PlanResult{}, fmt.Errorf("load dump %s: %w", dump, err)
+	}
 
-	roleDiffs := make([]hclload.RoleDiff, 0, len(manifest))
+	builder := hclload.NewPlanBuilder()
+	builder.IfExists = ifExists
 	for _, mr := range manifest {
 		stack := make([]string, len(mr.Layers))
 		for i, l := range mr.Layers {
-			stack[i] = filepath.Join(*layerRootFlag, l)
+			stack[i] = filepath.Join(layerRoot, l)
+		}
+		desired, err := hclload.LoadLayersOpts(stack, mr.Load)
+		if err == nil {
+			err = hclload.Resolve(desired)
 		}
-		desired, err := loadSide(strings.Join(stack, ","))
 		if err != nil {
-			slog.Error("failed to resolve role layers", "role", mr.Role, "layers", stack, "err", err)
-			os.Exit(1)
+			return hclload.PlanResult{}, fmt.Errorf("resolve role %s layers %v: %w", mr.Role, stack, err)
 		}
 		cur := current[mr.Role]
 		if cur == nil {
 			cur = &hclload.Schema{} // role absent from the dump: everything is a CREATE
 		}
+		// `only` narrows both sides, like -exclude: a live object outside the
+		// env's selection is not this env's to manage, so it must not plan
+		// as a DROP.
+		applyManifestOnly(desired, mr.Only)
+		applyManifestOnly(cur, mr.Only)
 		hclload.FilterSchema(desired, matcher)
 		hclload.FilterSchema(cur, matcher)
-		roleDiffs = append(roleDiffs, hclload.RoleDiff{Role: mr.Role, Desired: desired, Current: cur})
+		rc := builder.Add(hclload.RoleDiff{Role: mr.Role, Desired: desired, Current: cur})
+		if stream != nil {
+			renderPlanRoleText(stream, rc)
+		}
+	}
+	if stream != nil {
+		fmt.Fprintln(stream, "-- plan (global order)")
 	}
+	return builder.Result(), nil
+}
 
-	plan := hclload.BuildPlan(roleDiffs)
+// emitPlan writes plan to stdout, or to out as a file artifact.
+func emitPlan(plan hclload.PlanResult, format, out string) error {
+	if stdoutTarget(out) {
+		return writePlan(os.Stdout, plan, format)
+	}
+	if err := writePlanFile(out, plan, format); err != nil {
+		return err
+	}
+	slog.Info("plan written", "path", out, "format", format, "operations", len(plan.Operations))
+	return nil
+}
 
-	if *formatFlag == "json" {
+// writePlan renders plan to w in format (json, sql or text).
+func writePlan(w io.Writer, plan hclload.PlanResult, format string) error {
+	switch format {
+	case "json":
 		out, err := json.MarshalIndent(plan, "", "  ")
 		if err != nil {
-			slog.Error("failed to render plan JSON", "err", err)
-			os.Exit(1)
+			return err
 		}
-		fmt.Println(string(out))
-		return
+		_, err = fmt.Fprintln(w, string(out))
+		return err
+	case "sql":
+		renderPlanSQL(w, plan)
+	default:
+		renderPlanText(w, plan)
+	}
+	return nil
+}
+
+// writePlanFile writes the rendered plan to path atomically (see
+// hclload.WriteFileAtomic), so a reader (a CI step picking up the artifact)
+// never sees a partial plan.
+func writePlanFile(path string, plan hclload.PlanResult, format string) error {
+	var buf bytes.Buffer
+	if err := writePlan(&buf, plan, format); err != nil {
+		return err
 	}
-	renderPlanText(os.Stdout, plan)
+	return hclload.WriteFileAtomic(path, buf.Bytes())
 }
 
 // decodeManifest parses a manifest file into its raw block structure. Every
@@ -171,7 +350,8 @@ func parseManifest(path, env string) ([]manifestRole, error) {
 		seenRole[rb.Name] = true
 
 		seenEnv := map[string]bool{}
-		var layers []string
+		var layers, only []string
+		var vars map[string]cty.Value
 		found := false
 		for _, eb := range rb.Envs {
 			if seenEnv[eb.Name] {
@@ -179,7 +359,7 @@ func parseManifest(path, env string) ([]manifestRole, error) {
 			}
 			seenEnv[eb.Name] = true
 			if eb.Name == env {
-				layers = eb.Layers
+				layers, only, vars = eb.Layers, eb.Only, eb.Vars
 				found = true
 			}
 		}
@@ -189,7 +369,10 @@ func parseManifest(path, env string) ([]manifestRole, error) {
 		if len(layers) == 0 {
 			return nil, fmt.Errorf("role %q env %q: layers is empty", rb.Name, env)
 		}
-		roles = append(roles, manifestRole{Role: rb.Name, Layers: layers})
+		if err := validManifestOnly(rb.Name, env, only); err != nil {
+			return nil, err
+		}
+		roles = append(roles, manifestRole{Role: rb.Name, Layers: layers, Only: only, Load: hclload.LoadOptions{Vars: vars, Overlay: env}})
 	}
 	if len(roles) == 0 {
 		return nil, fmt.Errorf("no roles deployed in env %q", env)
@@ -265,7 +448,7 @@ func currentByRole(dir string) (map[string]*hclload.Schema, error) {
 }
 
 // renderPlanText prints a human-readable, globally-ordered plan.
-func renderPlanText(w *os.File, plan hclload.PlanResult) {
+func renderPlanText(w io.Writer, plan hclload.PlanResult) {
 	for _, u := range plan.Unsafe {
 		fmt.Fprintf(w, "-- UNSAFE: %s: %s\n", qualifiedName(u.Database, u.Object), u.Reason)
 	}
@@ -283,5 +466,85 @@ func renderPlanText(w *os.File, plan hclload.PlanResult) {
 		}
 		fmt.Fprintf(w, "%3d  %-7s %-18s %s.%s  [%s]%s\n",
 			op.Order, op.Kind, op.ObjectType, op.Database, op.Object, strings.Join(op.Roles, ","), flag)
+		if op.Advice == hclload.AdviceCoordinate {
+			fmt.Fprintf(w, "     coordinate: %s\n", op.Guidance)
+		}
+	}
+}
+
+// renderPlanSQL prints the plan as the exact statements an executor would run,
+// in global order, each under a comment naming its operation and roles. It
+// follows diff -sql: unsafe warnings first, SET lines for required settings,
+// and manual statements commented out as "-- MANUAL:".
+func renderPlanSQL(w io.Writer, plan hclload.PlanResult) {
+	for _, u := range plan.Unsafe {
+		fmt.Fprintf(w, "-- UNSAFE: %s: %s\n", qualifiedName(u.Database, u.Object), u.Reason)
+	}
+	if len(plan.Operations) == 0 {
+		fmt.Fprintln(w, "-- no changes")
+		return
+	}
+	for _, op := range plan.Operations {
+		fmt.Fprintf(w, "-- %d %s %s %s [%s]\n",
+			op.Order, op.Kind, op.ObjectType, qualifiedName(op.Database, op.Object), strings.Join(op.Roles, ","))
+		if op.Advice == hclload.AdviceCoordinate {
+			fmt.Fprintf(w, "-- coordinate: %s\n", op.Guidance)
+		}
+		if op.Manual {
+			fmt.Fprintln(w, "-- MANUAL: "+op.SQL+";")
+			continue
+		}
+		for _, set := range hclload.SetStatements(op.Settings) {
+			fmt.Fprintln(w, set+";")
+		}
+		fmt.Fprintln(w, op.SQL+";")
+	}
+}
+
+// renderPlanRoleText prints one role's operations the moment the role is
+// diffed (plan -stream), so a large catalog gives feedback role by role
+// instead of only after every role is loaded. Operations appear in the role's
+// own diff order and are marked "~" rather than numbered: the global order
+// needs every role and is printed last by renderPlanText.
+func renderPlanRoleText(w io.Writer, rc hclload.RoleComparison) {
+	n := 0
+	for _, oc := range rc.Objects {
+		n += len(oc.Operations)
+	}
+	fmt.Fprintf(w, "-- role %s: %d operation(s)\n", rc.Role, n)
+	for _, oc := range rc.Objects {
+		if oc.Unsafe {
+			fmt.Fprintf(w, "-- UNSAFE: %s: %s\n", qualifiedName(oc.Database, oc.Object), oc.UnsafeReason)
+		}
+		for _, op := range oc.Operations {
+			flag := ""
+			if op.Manual {
+				flag = " (MANUAL)"
+			}
+			fmt.Fprintf(w, "  ~  %-7s %-18s %s.%s%s\n", op.Kind, op.ObjectType, op.Database, op.Object, flag)
+		}
+	}
+}
+
+// parseManifestConnection returns the connection block for env. A manifest
+// without one for env is an error, as is a duplicate.
+func parseManifestConnection(path, env string) (*manifestConnectionBlock, error) {
+	m, err := decodeManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	var found *manifestConnectionBlock
+	for i, c := range m.Connections {
+		if c.Env != env {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("duplicate connection %q", env)
+		}
+		found = &m.Connections[i]
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no connection block for env %q", env)
 	}
+	return found, nil
 }
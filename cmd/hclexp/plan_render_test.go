@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -12,13 +14,9 @@ import (
 
 func renderPlanToString(t *testing.T, plan hclload.PlanResult) string {
 	t.Helper()
-	f, err := os.Create(filepath.Join(t.TempDir(), "plan.txt"))
-	require.NoError(t, err)
-	renderPlanText(f, plan)
-	require.NoError(t, f.Close())
-	out, err := os.ReadFile(f.Name())
-	require.NoError(t, err)
-	return string(out)
+	var buf bytes.Buffer
+	renderPlanText(&buf, plan)
+	return buf.String()
 }
 
 func TestPlanRenderEmpty(t *testing.T) {
@@ -66,3 +64,83 @@ func TestPlanRenderOperations(t *testing.T) {
 `
 	assert.Equal(t, want, renderPlanToString(t, plan))
 }
+
+func TestPlanRenderRoleStream(t *testing.T) {
+	rc := hclload.RoleComparison{
+		Role: "ops",
+		Objects: []hclload.ObjectComparison{
+			{Database: "posthog", Object: "events", Operations: []hclload.JSONOperation{
+				{Kind: hclload.OpCreate, ObjectType: hclload.KindTable, Database: "posthog", Object: "events"},
+			}},
+			{Database: "posthog", Object: "sessions", Unsafe: true, UnsafeReason: "ORDER BY changed"},
+			{Database: "posthog", Object: "persons", Operations: []hclload.JSONOperation{
+				{Kind: hclload.OpAlter, ObjectType: hclload.KindTable, Database: "posthog", Object: "persons", Manual: true},
+			}},
+		},
+	}
+	var out bytes.Buffer
+	renderPlanRoleText(&out, rc)
+
+	want := `-- role ops: 2 operation(s)
+  ~  CREATE  table              posthog.events
+-- UNSAFE: posthog.sessions: ORDER BY changed
+  ~  ALTER   table              posthog.persons (MANUAL)
+`
+	assert.Equal(t, want, out.String())
+}
+
+func TestPlanRenderSQL(t *testing.T) {
+	plan := hclload.PlanResult{
+		Operations: []hclload.PlanOperation{
+			{Order: 0, Kind: hclload.OpCreate, ObjectType: hclload.KindTable, Database: "posthog", Object: "events", Roles: []string{"data", "ops"},
+				SQL: "CREATE TABLE posthog.events (id UInt64, p JSON) ENGINE = MergeTree ORDER BY id", Settings: map[string]string{"allow_experimental_json_type": "1"}},
+			{Order: 1, Kind: hclload.OpAlter, ObjectType: hclload.KindTable, Database: "posthog", Object: "events", Roles: []string{"ops"},
+				SQL: "ALTER TABLE posthog.events MATERIALIZE INDEX idx", Manual: true},
+		},
+		Unsafe: []hclload.JSONUnsafe{{Database: "reports", Object: "daily", Reason: "ORDER BY changed"}},
+	}
+	var out bytes.Buffer
+	renderPlanSQL(&out, plan)
+
+	want := `-- UNSAFE: reports.daily: ORDER BY changed
+-- 0 CREATE table posthog.events [data,ops]
+SET allow_experimental_json_type = 1;
+CREATE TABLE posthog.events (id UInt64, p JSON) ENGINE = MergeTree ORDER BY id;
+-- 1 ALTER table posthog.events [ops]
+-- MANUAL: ALTER TABLE posthog.events MATERIALIZE INDEX idx;
+`
+	assert.Equal(t, want, out.String())
+}
+
+func TestPlanRenderCoordinateGuidance(t *testing.T) {
+	plan := hclload.PlanResult{Operations: []hclload.PlanOperation{{
+		Order: 0, Kind: hclload.OpDrop, ObjectType: hclload.KindMaterializedView, Database: "posthog", Object: "events_mv",
+		Roles: []string{"ingest"}, Advice: hclload.AdviceCoordinate, Guidance: "inserts into the source stop reaching the target table from this point",
+	}}}
+	assert.Contains(t, renderPlanToString(t, plan),
+		"     coordinate: inserts into the source stop reaching the target table from this point\n")
+}
+
+// writePlanFile leaves exactly the finished artifact behind, and the JSON
+// artifact decodes back to the plan.
+func TestWritePlanFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plan.json")
+	plan := hclload.PlanResult{Operations: []hclload.PlanOperation{{
+		Order: 0, Kind: hclload.OpCreate, ObjectType: hclload.KindTable,
+		Database: "posthog", Object: "events", SQL: "CREATE TABLE posthog.events (id UInt64) ENGINE = Log",
+		Roles: []string{"data"}, Advice: hclload.AdviceOnline,
+	}}}
+	require.NoError(t, writePlanFile(path, plan, "json"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var got hclload.PlanResult
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, plan.Operations, got.Operations)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "no temporary file may be left behind")
+	assert.Equal(t, "plan.json", entries[0].Name())
+}
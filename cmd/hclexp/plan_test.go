@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	hclload "github.com/posthog/chschema/internal/loader/hcl"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -116,3 +117,37 @@ database "posthog" {
 	require.Len(t, byRole["ops"].Databases[0].Tables, 1)
 	assert.Equal(t, "from_1c", byRole["ops"].Databases[0].Tables[0].Name)
 }
+
+// An env's `only` list narrows the live side too: a live table outside the
+// selection is not this env's to manage and must not plan as a DROP.
+func TestBuildManifestPlan_OnlyNarrowsCurrent(t *testing.T) {
+	root := t.TempDir()
+	table := func(name string) string {
+		return `  table "` + name + `" {
+    engine "merge_tree" {}
+    order_by = ["id"]
+    column "id" { type = "UInt64" }
+  }
+`
+	}
+	writeFileT(t, filepath.Join(root, "base", "posthog.hcl"), "database \"posthog\" {\n"+table("events")+table("beta_feature")+"}\n")
+	manifest := filepath.Join(root, "manifest.hcl")
+	writeFileT(t, manifest, `
+role "data" {
+  env "prod" {
+    layers = ["base"]
+    only   = ["posthog.events", "posthog.new_table"]
+  }
+}`)
+	dump := filepath.Join(root, "dump")
+	writeFileT(t, filepath.Join(dump, "prod-ch-1a-data.hcl"), `node "prod-ch-1a-data" {
+  macros = { hostClusterRole = "data" }
+}
+database "posthog" {
+`+table("events")+table("legacy")+`}
+`)
+
+	plan, err := buildManifestPlan(manifest, "prod", root, dump, hclload.LoadOptions{}, hclload.NewExcludeMatcher(), false, nil)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Operations, "legacy is outside only and beta_feature is not selected: nothing to do")
+}
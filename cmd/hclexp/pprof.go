@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+)
+
+// splitPprofFlag pulls a leading -pprof PREFIX (or -pprof=PREFIX, either with
+// one or two dashes) off the argument list. It is a global flag: it must come
+// before the command name, so it never collides with a subcommand's own flags.
+// It returns the prefix ("" when absent) and the remaining arguments.
+func splitPprofFlag(args []string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", args, nil
+	}
+	name, value, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+	if !strings.HasPrefix(args[0], "-") || name != "pprof" {
+		return "", args, nil
+	}
+	if hasValue {
+		if value == "" {
+			return "", nil, fmt.Errorf("-pprof needs a file prefix")
+		}
+		return value, args[1:], nil
+	}
+	if len(args) < 2 || args[1] == "" {
+		return "", nil, fmt.Errorf("-pprof needs a file prefix")
+	}
+	return args[1], args[2:], nil
+}
+
+// startProfiling starts a CPU profile at PREFIX.cpu.pprof and returns the func
+// that stops it and writes the heap profile (in-use and cumulative
+// allocations) to PREFIX.heap.pprof. Inspect either with `go tool pprof`.
+// main registers the func as atExit, so the profiles are written however the
+// command ends, including nonzero exits through exit.
+func startProfiling(prefix string) (func(), error) {
+	cpu, err := os.Create(prefix + ".cpu.pprof")
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(cpu); err != nil {
+		cpu.Close()
+		return nil, err
+	}
+	return func() {
+		pprof.StopCPUProfile()
+		if err := cpu.Close(); err != nil {
+			slog.Error("failed to write CPU profile", "file", cpu.Name(), "err", err)
+		}
+		heap, err := os.Create(prefix + ".heap.pprof")
+		if err != nil {
+			slog.Error("failed to create heap profile", "err", err)
+			return
+		}
+		defer heap.Close()
+		runtime.GC() // up-to-date in-use statistics
+		if err := pprof.WriteHeapProfile(heap); err != nil {
+			slog.Error("failed to write heap profile", "file", heap.Name(), "err", err)
+		}
+	}, nil
+}
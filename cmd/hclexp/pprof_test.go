@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitPprofFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantPrefix string
+		wantRest   []string
+		wantErr    bool
+	}{
+		{"absent", []string{"plan", "-env", "dev"}, "", []string{"plan", "-env", "dev"}, false},
+		{"empty", nil, "", nil, false},
+		{"separate value", []string{"-pprof", "/tmp/p", "diff"}, "/tmp/p", []string{"diff"}, false},
+		{"double dash", []string{"--pprof", "/tmp/p", "diff"}, "/tmp/p", []string{"diff"}, false},
+		{"equals form", []string{"-pprof=/tmp/p", "diff"}, "/tmp/p", []string{"diff"}, false},
+		{"load flags untouched", []string{"-layer", "schema"}, "", []string{"-layer", "schema"}, false},
+		{"missing value", []string{"-pprof"}, "", nil, true},
+		{"empty equals", []string{"-pprof="}, "", nil, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			prefix, rest, err := splitPprofFlag(tc.args)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantPrefix, prefix)
+			assert.Equal(t, tc.wantRest, rest)
+		})
+	}
+}
+
+func TestStartProfiling_WritesProfiles(t *testing.T) {
+	prefix := filepath.Join(t.TempDir(), "run")
+	stop, err := startProfiling(prefix)
+	require.NoError(t, err)
+	stop()
+
+	for _, suffix := range []string{".cpu.pprof", ".heap.pprof"} {
+		st, err := os.Stat(prefix + suffix)
+		require.NoError(t, err, suffix)
+		assert.Positive(t, st.Size(), suffix)
+	}
+}
+
+// A command that exits with a nonzero status still writes both profiles.
+func TestPprof_WrittenOnNonzeroExit(t *testing.T) {
+	broken := writeTemp(t, "broken.hcl", `
+database "posthog" {
+  materialized_view "mv" {
+    to_table = "posthog.missing"
+    query    = "SELECT id FROM posthog.events"
+  }
+}
+`)
+	prefix := filepath.Join(t.TempDir(), "run")
+	_, _, code := runHclexp(t, "-pprof", prefix, "validate", "-config", broken)
+	assert.Equal(t, exitFindings, code)
+
+	for _, suffix := range []string{".cpu.pprof", ".heap.pprof"} {
+		st, err := os.Stat(prefix + suffix)
+		require.NoError(t, err, suffix)
+		assert.Positive(t, st.Size(), suffix)
+	}
+}
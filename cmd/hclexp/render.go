@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"os"
+
+	hclload "github.com/posthog/chschema/internal/loader/hcl"
+)
+
+// runRender prints the CREATE statements for every object in a resolved
+// schema, in dependency order, with no ClickHouse connection: the script a
+// first apply to an empty server would run. It is the offline counterpart of
+// dump-sql, for reviewing generated DDL or seeding a server with
+// clickhouse-client.
+func runRender(args []string) {
+	fs := flag.NewFlagSet("hclexp render", flag.ExitOnError)
+	configFlag := configRootsFlag(fs)
+	layersFlag := fs.String("layer", "", "comma-separated layer stack: each entry a directory or a single .hcl file (loaded in order)")
+	excludeFlag := fs.String("exclude", "", "HCL exclude config: objects matching its patterns/object_types are left out")
+	ifExists := fs.Bool("if-exists", false, "render CREATE ... IF NOT EXISTS so the script can be re-run")
+	outFlag := fs.String("out", "", "output .sql file; empty or '-' writes to stdout")
+	varFlags := addVarFlags(fs)
+	_ = fs.Parse(args)
+
+	schema, err := loadOpts(configFlag.String(), *layersFlag, varFlags.loadOptions())
+	if err != nil {
+		slog.Error("failed to load config", "err", err)
+		exit(exitError)
+	}
+	if err := hclload.Resolve(schema); err != nil {
+		slog.Error("failed to resolve schema", "err", err)
+		exit(exitError)
+	}
+	if m := loadExcludeFlag(*excludeFlag); m != nil {
+		hclload.FilterSchema(schema, m)
+	}
+
+	cs := hclload.Diff(&hclload.Schema{}, schema)
+	cs.IfExists = *ifExists
+	gen := hclload.GenerateSQL(cs)
+
+	if stdoutTarget(*outFlag) {
+		writeSQLScript(os.Stdout, gen)
+	} else {
+		var buf bytes.Buffer
+		writeSQLScript(&buf, gen)
+		if err := hclload.WriteFileAtomic(*outFlag, buf.Bytes()); err != nil {
+			slog.Error("failed to write SQL", "out", *outFlag, "err", err)
+			exit(exitError)
+		}
+	}
+	if len(gen.Unsafe) > 0 {
+		slog.Warn("some objects cannot be rendered; see the UNSAFE comments", "count", len(gen.Unsafe))
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender_CreatesInDependencyOrder(t *testing.T) {
+	schema := writeTemp(t, "schema.hcl", `
+database "posthog" {
+  materialized_view "counts_mv" {
+    to_table = "posthog.counts"
+    query    = "SELECT id FROM posthog.events"
+  }
+  table "counts" {
+    engine "merge_tree" {}
+    order_by = ["id"]
+    column "id" { type = "UInt64" }
+  }
+  table "events" {
+    engine "merge_tree" {}
+    order_by = ["id"]
+    column "id" { type = "UInt64" }
+  }
+}
+`)
+	stdout, stderr, code := runHclexp(t, "render", "-config", schema, "-if-exists")
+	require.Equal(t, exitOK, code, stderr)
+	mv := strings.Index(stdout, "CREATE MATERIALIZED VIEW IF NOT EXISTS posthog.counts_mv")
+	counts := strings.Index(stdout, "CREATE TABLE IF NOT EXISTS posthog.counts")
+	events := strings.Index(stdout, "CREATE TABLE IF NOT EXISTS posthog.events")
+	require.True(t, mv >= 0 && counts >= 0 && events >= 0, stdout)
+	assert.Less(t, counts, mv, "the MV's destination is created first")
+	assert.Less(t, events, mv, "the MV's source is created first")
+
+	out := filepath.Join(t.TempDir(), "schema.sql")
+	_, stderr, code = runHclexp(t, "render", "-config", schema, "-out", out)
+	require.Equal(t, exitOK, code, stderr)
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "CREATE TABLE posthog.events")
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	hclload "github.com/posthog/chschema/internal/loader/hcl"
+)
+
+func TestSideBySide_AlignsOnCommonLines(t *testing.T) {
+	got := sideBySide(
+		[]string{"a", "b", "c", "d"},
+		[]string{"a", "B", "c", "e", "f"},
+		3, "L", "R")
+	assert.Equal(t, []string{
+		"L     R",
+		"---   ---",
+		"a     a",
+		"b   | B",
+		"c     c",
+		"d   | e",
+		"    > f",
+	}, got)
+}
+
+func TestClip(t *testing.T) {
+	assert.Equal(t, "abc", clip("abc", 3))
+	assert.Equal(t, "ab…", clip("abcd", 3))
+}
+
+func showSchema(t *testing.T, content string) *hclload.Schema {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schema.hcl")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	s, err := hclload.ParseFile(path)
+	require.NoError(t, err)
+	require.NoError(t, hclload.Resolve(s))
+	return s
+}
+
+func TestRenderShow_MarksDifferingLinesAndFields(t *testing.T) {
+	left := showSchema(t, `
+database "posthog" {
+  table "events" {
+    column "id" { type = "UInt64" }
+    engine "merge_tree" {}
+    order_by = ["id"]
+  }
+}`)
+	right := showSchema(t, `
+database "posthog" {
+  table "events" {
+    column "id" { type = "UInt64" }
+    column "ts" { type = "DateTime" }
+    engine "merge_tree" {}
+    order_by = ["id"]
+  }
+}`)
+
+	var buf bytes.Buffer
+	require.NoError(t, renderShow(&buf, left, right, "posthog", "events", "hcl", 40))
+	out := buf.String()
+	assert.Contains(t, out, "posthog.events (table)")
+	assert.Regexp(t, `> +column "ts"`, out)
+	assert.Contains(t, out, "~ table events")
+	assert.Contains(t, out, "+ column ts")
+
+	buf.Reset()
+	require.NoError(t, renderShow(&buf, left, left, "posthog", "events", "sql", 40))
+	assert.Contains(t, buf.String(), "CREATE TABLE posthog.events")
+	assert.Contains(t, buf.String(), "no differences")
+}
+
+func TestRenderShowOne_EffectiveDefinition(t *testing.T) {
+	s := showSchema(t, `
+database "posthog" {
+  table "base" {
+    abstract = true
+    column "id" { type = "UInt64" }
+  }
+  table "events" {
+    extend = "base"
+    engine "merge_tree" {}
+    order_by = ["id"]
+  }
+  patch_table "events" {
+    column "ts" { type = "DateTime" }
+  }
+}`)
+
+	var buf bytes.Buffer
+	require.NoError(t, renderShowOne(&buf, s, "posthog", "events", "hcl"))
+	out := buf.String()
+	assert.Contains(t, out, "posthog.events (table)")
+	assert.Contains(t, out, `column "id"`)
+	assert.Contains(t, out, `column "ts"`)
+	assert.NotContains(t, out, "extend")
+
+	buf.Reset()
+	require.NoError(t, renderShowOne(&buf, s, "posthog", "events", "sql"))
+	assert.Contains(t, buf.String(), "CREATE TABLE posthog.events")
+
+	err := renderShowOne(&buf, s, "posthog", "missing", "hcl")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not declared")
+}
+
+func TestRenderShow_AbsentSide(t *testing.T) {
+	left := showSchema(t, `database "posthog" {}`)
+	right := showSchema(t, `
+database "posthog" {
+  view "v" { query = "SELECT 1" }
+}`)
+
+	var buf bytes.Buffer
+	require.NoError(t, renderShow(&buf, left, right, "posthog", "v", "hcl", 30))
+	assert.Contains(t, buf.String(), "posthog.v (view)")
+	assert.Contains(t, buf.String(), "(absent)")
+	assert.Contains(t, buf.String(), "+ view v")
+
+	err := renderShow(&buf, left, right, "posthog", "missing", "hcl", 30)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not declared on either side")
+}
@@ -22,17 +22,18 @@ func runSQL2HCL(args []string) {
 	outFlag := fs.String("out", "", "where to write updated HCL: empty or '-'=stdout, a directory=one <db>.hcl per database, else a single file")
 	dbFlag := fs.String("database", "", "default database for unqualified object names")
 	allowRaw := fs.Bool("allow-raw", false, "capture a CREATE the schema model can't express as a raw{} block instead of failing")
+	showSecrets := fs.Bool("show-secrets", false, "write engine credentials (S3 secret keys and session tokens, Kafka SASL passwords) in plaintext instead of '[HIDDEN]'")
 	_ = fs.Parse(args)
 
 	if *leftFlag == "" {
 		fmt.Fprintln(os.Stderr, "hclexp sql2hcl: -left is required (the HCL schema to modify)")
-		os.Exit(2)
+		exit(exitUsage)
 	}
 
-	applied, dbs, err := applySQL2HCL(*leftFlag, *inFlag, *outFlag, *dbFlag, *allowRaw)
+	applied, dbs, err := applySQL2HCL(*leftFlag, *inFlag, *outFlag, *dbFlag, *allowRaw, *showSecrets)
 	if err != nil {
 		slog.Error("sql2hcl failed", "err", err)
-		os.Exit(1)
+		exit(exitError)
 	}
 	slog.Info("SQL applied", "statements", applied, "databases", dbs)
 }
@@ -40,9 +41,10 @@ func runSQL2HCL(args []string) {
 // applySQL2HCL is the testable core of runSQL2HCL: it loads and resolves the
 // left schema, reads the SQL from in (file or stdin), folds the DDL into the
 // schema, and writes the updated HCL to out. All I/O is parameterized so it can
-// be driven from tests without touching os.Exit. Returns the number of applied
+// be driven from tests without touching os.Exit. With secrets set, engine
+// credentials are written in plaintext. Returns the number of applied
 // statements and the resulting database count.
-func applySQL2HCL(left, in, out, db string, allowRaw bool) (applied, databases int, err error) {
+func applySQL2HCL(left, in, out, db string, allowRaw, secrets bool) (applied, databases int, err error) {
 	schema, err := loadLeft(left)
 	if err != nil {
 		return 0, 0, fmt.Errorf("load -left schema: %w", err)
@@ -61,7 +63,7 @@ func applySQL2HCL(left, in, out, db string, allowRaw bool) (applied, databases i
 		return 0, 0, fmt.Errorf("apply SQL: %w", err)
 	}
 
-	if err := writeIntrospected(out, schema); err != nil {
+	if err := writeIntrospected(out, schema, hclload.WriteOptions{Secrets: secrets}); err != nil {
 		return 0, 0, fmt.Errorf("write updated schema: %w", err)
 	}
 	return applied, len(schema.Databases), nil
@@ -29,7 +29,7 @@ func TestApplySQL2HCL_EndToEnd(t *testing.T) {
 	require.NoError(t, os.WriteFile(sqlFile, []byte("ALTER TABLE posthog.events ADD COLUMN ts DateTime;"), 0o600))
 
 	out := filepath.Join(dir, "updated.hcl")
-	applied, databases, err := applySQL2HCL(left, sqlFile, out, "", false)
+	applied, databases, err := applySQL2HCL(left, sqlFile, out, "", false, false)
 	require.NoError(t, err)
 	assert.Equal(t, 1, applied)
 	assert.Equal(t, 1, databases)
@@ -40,6 +40,31 @@ func TestApplySQL2HCL_EndToEnd(t *testing.T) {
 	assert.Contains(t, string(got), "DateTime")
 }
 
+// A credential in the applied DDL is written as [HIDDEN] unless secrets is
+// set (-show-secrets).
+func TestApplySQL2HCL_RedactsSecrets(t *testing.T) {
+	dir := t.TempDir()
+	left := filepath.Join(dir, "schema.hcl")
+	require.NoError(t, os.WriteFile(left, []byte(sql2hclLeft), 0o600))
+	sqlFile := filepath.Join(dir, "change.sql")
+	require.NoError(t, os.WriteFile(sqlFile, []byte("CREATE TABLE posthog.export (`id` UInt64) "+
+		"ENGINE = S3('https://bucket.s3.amazonaws.com/export/*', 'AKIAEXAMPLE', 'wJalrXUtnFEMI', 'Parquet');"), 0o600))
+
+	out := filepath.Join(dir, "updated.hcl")
+	_, _, err := applySQL2HCL(left, sqlFile, out, "", false, false)
+	require.NoError(t, err)
+	got, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.NotContains(t, string(got), "wJalrXUtnFEMI")
+	assert.Contains(t, string(got), `"[HIDDEN]"`)
+
+	_, _, err = applySQL2HCL(left, sqlFile, out, "", false, true)
+	require.NoError(t, err)
+	got, err = os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "wJalrXUtnFEMI")
+}
+
 func TestApplySQL2HCL_PropagatesErrors(t *testing.T) {
 	dir := t.TempDir()
 	left := filepath.Join(dir, "schema.hcl")
@@ -49,7 +74,7 @@ func TestApplySQL2HCL_PropagatesErrors(t *testing.T) {
 	sqlFile := filepath.Join(dir, "bad.sql")
 	require.NoError(t, os.WriteFile(sqlFile, []byte("TRUNCATE TABLE posthog.events;"), 0o600))
 
-	_, _, err := applySQL2HCL(left, sqlFile, filepath.Join(dir, "out.hcl"), "", false)
+	_, _, err := applySQL2HCL(left, sqlFile, filepath.Join(dir, "out.hcl"), "", false, false)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "apply SQL")
 }
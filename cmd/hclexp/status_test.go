@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusRows(t *testing.T) {
+	current := showSchema(t, `
+database "posthog" {
+  table "events" {
+    column "id" { type = "UInt64" }
+    engine "merge_tree" {}
+    order_by = ["id"]
+  }
+  table "persons" {
+    column "id" { type = "UInt64" }
+    engine "merge_tree" {}
+    order_by = ["id"]
+  }
+  table "scratch" {
+    column "id" { type = "UInt64" }
+    engine "merge_tree" {}
+    order_by = ["id"]
+  }
+}`)
+	desired := showSchema(t, `
+database "posthog" {
+  table "events" {
+    column "id" { type = "UInt64" }
+    engine "merge_tree" {}
+    order_by = ["id"]
+  }
+  table "persons" {
+    column "id" { type = "UInt64" }
+    column "ts" { type = "DateTime" }
+    engine "merge_tree" {}
+    order_by = ["id"]
+  }
+  table "groups" {
+    column "id" { type = "UInt64" }
+    engine "merge_tree" {}
+    order_by = ["id"]
+  }
+  view "v" { query = "SELECT id FROM posthog.events" }
+}`)
+
+	rows := statusRows(current, desired)
+	require.Len(t, rows, len(statusKinds))
+	assert.Equal(t, statusRow{Kind: "table", Declared: 3, Live: 3, InSync: 1, Drifted: 1, Missing: 1, Unmanaged: 1}, rows[0])
+	assert.Equal(t, statusRow{Kind: "view", Declared: 1, Missing: 1}, rows[2])
+
+	var buf bytes.Buffer
+	renderStatus(&buf, rows)
+	assert.Contains(t, buf.String(), "kind               declared     live  in-sync  drifted  missing unmanaged  skipped\n")
+	assert.Contains(t, buf.String(), "total                     4        3        1        1        2         1        0\n")
+}
+
+// A table on an unmanaged engine is skipped by diff, so status counts it as
+// skipped rather than in sync, whichever side carries it.
+func TestStatusRows_Skipped(t *testing.T) {
+	current := showSchema(t, `
+database "posthog" {
+  table "events" {
+    column "id" { type = "UInt64" }
+    engine "merge_tree" {}
+    order_by = ["id"]
+  }
+  table "kv" {
+    column "k" { type = "String" }
+    engine "unmanaged" {
+      name = "EmbeddedRocksDB"
+      full = "EmbeddedRocksDB"
+    }
+    primary_key = ["k"]
+  }
+  table "cache" {
+    column "k" { type = "String" }
+    engine "unmanaged" {
+      name = "EmbeddedRocksDB"
+      full = "EmbeddedRocksDB"
+    }
+    primary_key = ["k"]
+  }
+}`)
+	desired := showSchema(t, `
+database "posthog" {
+  table "events" {
+    column "id" { type = "UInt64" }
+    engine "merge_tree" {}
+    order_by = ["id"]
+  }
+  table "kv" {
+    column "k" { type = "String" }
+    engine "unmanaged" {
+      name = "EmbeddedRocksDB"
+      full = "EmbeddedRocksDB"
+    }
+    primary_key = ["k"]
+  }
+}`)
+
+	rows := statusRows(current, desired)
+	assert.Equal(t, statusRow{Kind: "table", Declared: 2, Live: 3, InSync: 1, Skipped: 2}, rows[0])
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/posthog/chschema/config"
+	hclload "github.com/posthog/chschema/internal/loader/hcl"
+)
+
+// runSupportCheck connects to a live ClickHouse server, inventories every
+// object in system.tables and reports which ones chschema fully supports,
+// partially supports, or would skip — a pre-adoption survey that needs no
+// HCL. It only reads; the exit status is non-zero only when the check itself
+// fails.
+func runSupportCheck(args []string) {
+	fs := flag.NewFlagSet("hclexp support-check", flag.ExitOnError)
+	connFlags := addConnFlags(fs, "ClickHouse host, or comma-separated host[:port] replicas to fail over between")
+	dbFlag := fs.String("database", "", "comma-separated databases to check; empty checks every non-system database")
+	excludeFlag := fs.String("exclude", "", "HCL exclude config: matching objects are reported as skipped (see docs)")
+	showSecrets := fs.Bool("show-secrets", false, "read real secret values instead of '[HIDDEN]', so objects with secrets are checked like any other; requires server display_secrets_in_show_and_select=1 and the displaySecretsInShowAndSelect grant")
+	formatFlag := fs.String("format", "text", "output format: text (default) or json")
+	details := fs.Bool("details", false, "text format: also list supported objects, not only partial and skipped ones")
+	_ = fs.Parse(args)
+
+	switch *formatFlag {
+	case "text", "json":
+	default:
+		fmt.Fprintf(os.Stderr, "support-check: invalid -format %q (want text|json)\n", *formatFlag)
+		exit(exitUsage)
+	}
+	cfg, dsnDatabases, err := connFlags.config()
+	if err != nil {
+		slog.Error("invalid connection flags", "err", err)
+		exit(exitUsage)
+	}
+	databases := splitList(*dbFlag)
+	if !flagWasSet(fs, "database") {
+		databases = dsnDatabases
+	}
+	exclude := loadExclude(*excludeFlag)
+
+	if len(databases) > 0 {
+		cfg.Database = databases[0] // connection requires a database to bind to
+	}
+	cfg.ShowSecrets = *showSecrets
+
+	conn, err := config.NewConnection(cfg)
+	if err != nil {
+		slog.Error("failed to connect to ClickHouse", "host", cfg.Host, "port", cfg.Port, "err", err)
+		exit(exitError)
+	}
+	defer conn.Close()
+
+	report, err := hclload.CheckSupport(context.Background(), conn, databases, exclude)
+	if err != nil {
+		slog.Error("support check failed", "err", err)
+		exit(exitError)
+	}
+
+	if *formatFlag == "json" {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "support-check: render JSON: %v\n", err)
+			exit(exitError)
+		}
+		fmt.Println(string(out))
+		return
+	}
+	renderSupportText(os.Stdout, report, *details)
+}
+
+// renderSupportText prints the per-engine tally, then every partial and
+// skipped object with its reason (and supported ones too with details), then
+// a one-line summary.
+func renderSupportText(w io.Writer, r hclload.SupportReport, details bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ENGINE\tSUPPORTED\tPARTIAL\tSKIPPED")
+	for _, c := range r.Engines {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", c.Engine, c.Supported, c.Partial, c.Skipped)
+	}
+	_ = tw.Flush()
+
+	levels := []string{hclload.SupportPartial, hclload.SupportSkipped}
+	if details {
+		levels = append([]string{hclload.SupportFull}, levels...)
+	}
+	for _, level := range levels {
+		header := false
+		for _, e := range r.Objects {
+			if e.Level != level {
+				continue
+			}
+			if !header {
+				fmt.Fprintf(w, "\n%s:\n", level)
+				header = true
+			}
+			fmt.Fprintf(w, "  %s.%s (%s, %s)", e.Database, e.Object, e.Kind, e.Engine)
+			if e.Reason != "" {
+				fmt.Fprintf(w, ": %s", e.Reason)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+
+	fmt.Fprintf(w, "\nsummary: %d objects, %d supported, %d partial, %d skipped\n",
+		r.Summary.Objects, r.Summary.Supported, r.Summary.Partial, r.Summary.Skipped)
+}
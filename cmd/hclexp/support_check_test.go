@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	hclload "github.com/posthog/chschema/internal/loader/hcl"
+)
+
+func TestRenderSupportText(t *testing.T) {
+	r := hclload.SupportReport{
+		Objects: []hclload.SupportEntry{
+			{Database: "posthog", Object: "events", Kind: "table", Engine: "MergeTree", Level: hclload.SupportFull},
+			{Database: "posthog", Object: "kv", Kind: "table", Engine: "EmbeddedRocksDB", Level: hclload.SupportSkipped, Reason: "engine EmbeddedRocksDB is not modelled"},
+		},
+		Engines: []hclload.SupportEngineCount{
+			{Engine: "EmbeddedRocksDB", Skipped: 1},
+			{Engine: "MergeTree", Supported: 1},
+		},
+		Summary: hclload.SupportSummary{Objects: 2, Supported: 1, Skipped: 1},
+	}
+
+	var buf bytes.Buffer
+	renderSupportText(&buf, r, false)
+	assert.Equal(t, `ENGINE           SUPPORTED  PARTIAL  SKIPPED
+EmbeddedRocksDB  0          0        1
+MergeTree        1          0        0
+
+skipped:
+  posthog.kv (table, EmbeddedRocksDB): engine EmbeddedRocksDB is not modelled
+
+summary: 2 objects, 1 supported, 0 partial, 1 skipped
+`, buf.String())
+
+	buf.Reset()
+	renderSupportText(&buf, r, true)
+	assert.Contains(t, buf.String(), "supported:\n  posthog.events (table, MergeTree)\n")
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// commandContext returns the context a command that talks to ClickHouse runs
+// under: cancelled on SIGINT/SIGTERM and, when timeout is positive, once
+// timeout has elapsed. In-flight queries observe the cancellation, so the
+// command stops at the phase it was in instead of hanging.
+func commandContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	if timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// cancelReason says why ctx ended — "timed out after 5m0s" or
+// "interrupted" — or "" while it is still live.
+func cancelReason(ctx context.Context, timeout time.Duration) string {
+	switch err := ctx.Err(); {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timed out after " + timeout.String()
+	default:
+		return "interrupted"
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandContext_Timeout(t *testing.T) {
+	ctx, cancel := commandContext(10 * time.Millisecond)
+	defer cancel()
+	assert.Empty(t, cancelReason(ctx, 10*time.Millisecond))
+	<-ctx.Done()
+	assert.Equal(t, "timed out after 10ms", cancelReason(ctx, 10*time.Millisecond))
+}
+
+func TestCommandContext_NoTimeoutAndCancel(t *testing.T) {
+	ctx, cancel := commandContext(0)
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline, "0 means no limit")
+	cancel()
+	assert.Equal(t, "interrupted", cancelReason(ctx, 0))
+}
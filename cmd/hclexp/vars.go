@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hclload "github.com/posthog/chschema/internal/loader/hcl"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// varFlags are the -var, -var-file and -allow-env flags of commands that load
+// schema files: the values expressions read as var.<name> and env("NAME").
+type varFlags struct {
+	file     *string
+	vars     varListFlag
+	allowEnv *bool
+	overlay  *string
+}
+
+// varListFlag is the repeatable -var NAME=VALUE flag.
+type varListFlag struct {
+	names  []string
+	values map[string]string
+}
+
+func (v *varListFlag) String() string {
+	parts := make([]string, 0, len(v.names))
+	for _, n := range v.names {
+		parts = append(parts, n+"="+v.values[n])
+	}
+	return strings.Join(parts, ",")
+}
+
+func (v *varListFlag) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("invalid -var %q: want NAME=VALUE", s)
+	}
+	if !hclsyntax.ValidIdentifier(name) {
+		return fmt.Errorf("invalid -var %q: %q is not a valid variable name", s, name)
+	}
+	if v.values == nil {
+		v.values = map[string]string{}
+	}
+	if _, seen := v.values[name]; !seen {
+		v.names = append(v.names, name)
+	}
+	v.values[name] = value
+	return nil
+}
+
+// addVarFlags registers -var, -var-file, -allow-env and -overlay on fs.
+func addVarFlags(fs *flag.FlagSet) *varFlags {
+	v := &varFlags{
+		file:     fs.String("var-file", "", "HCL file of NAME = value attributes schema expressions read as var.NAME"),
+		allowEnv: fs.Bool("allow-env", false, "let schema files read environment variables with env(\"NAME\"); an unset variable is an error"),
+		overlay:  fs.String("overlay", "", "environment whose overlay files load: <file>.<NAME>.hcl is merged right after <file>.hcl (default in manifest mode: the env block's name)"),
+	}
+	fs.Var(&v.vars, "var", "repeatable NAME=VALUE schema variable, read as var.NAME; overrides -var-file and the manifest env's vars")
+	return v
+}
+
+// values returns the variables the flags set: -var-file's, then each -var on
+// top. A -var value is a string; HCL converts it where a number or bool is
+// expected (shards = var.shards).
+func (v *varFlags) values() (map[string]cty.Value, error) {
+	vars := map[string]cty.Value{}
+	if *v.file != "" {
+		fromFile, err := hclload.LoadVarFile(*v.file)
+		if err != nil {
+			return nil, fmt.Errorf("-var-file %s: %w", *v.file, err)
+		}
+		vars = fromFile
+	}
+	for _, name := range v.vars.names {
+		vars[name] = cty.StringVal(v.vars.values[name])
+	}
+	return vars, nil
+}
+
+// set reports whether any of the flags was given.
+func (v *varFlags) set() bool {
+	return *v.file != "" || len(v.vars.names) > 0 || *v.allowEnv || *v.overlay != ""
+}
+
+// loadOptions is values and -allow-env as hclload.LoadOptions, exiting like
+// loadExcludeFlag when the -var-file cannot be read.
+func (v *varFlags) loadOptions() hclload.LoadOptions {
+	opts, err := v.options()
+	if err != nil {
+		slog.Error("failed to load schema variables", "err", err)
+		exit(exitError)
+	}
+	return opts
+}
+
+// options is loadOptions returning the error, for callers that must not exit
+// on a bad -var-file (plan -watch re-reads it on every change).
+func (v *varFlags) options() (hclload.LoadOptions, error) {
+	vars, err := v.values()
+	if err != nil {
+		return hclload.LoadOptions{}, err
+	}
+	return hclload.LoadOptions{Vars: vars, AllowEnv: *v.allowEnv, Overlay: *v.overlay}, nil
+}
+
+// withLoadOptions returns roles with opts applied on top of each role's
+// manifest env vars, so a flag overrides the manifest for one run.
+func withLoadOptions(roles []manifestRole, opts hclload.LoadOptions) []manifestRole {
+	out := make([]manifestRole, len(roles))
+	for i, r := range roles {
+		r.Load = r.Load.Merge(opts)
+		out[i] = r
+	}
+	return out
+}
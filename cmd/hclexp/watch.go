@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// watchFiles runs fn once, then again every time the files under paths
+// change, until ctx ends. Changes are found by polling a stamp of every
+// file's path, size and modification time every interval; hidden files and
+// directories below a root (.git, editor swap files, the temp file of an
+// atomic write) are skipped, and so are the ignore paths, the files fn itself
+// writes, which would otherwise re-trigger it forever. Empty paths are
+// ignored.
+func watchFiles(ctx context.Context, paths, ignore []string, interval time.Duration, fn func()) {
+	var roots []string
+	for _, p := range paths {
+		if p != "" {
+			roots = append(roots, p)
+		}
+	}
+	skip := absPaths(ignore)
+	last := treeStamp(roots, skip)
+	fn()
+	slog.Info("watching for changes", "paths", roots, "interval", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		stamp := treeStamp(roots, skip)
+		if stamp == last {
+			continue
+		}
+		last = stamp
+		slog.Info("change detected; re-running")
+		fn()
+	}
+}
+
+// treeStamp summarizes every file under roots except those in skip (absolute
+// paths). Unreadable or missing paths contribute their error, so a file
+// appearing or disappearing is a change.
+func treeStamp(roots []string, skip map[string]bool) string {
+	h := sha256.New()
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			hidden := path != root && strings.HasPrefix(d.Name(), ".")
+			if d.IsDir() {
+				if hidden {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if hidden || skip[absPath(path)] {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(h, "%s\x00error %v\n", root, err)
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// absPaths returns the absolute forms of the non-empty paths, as a set.
+func absPaths(paths []string) map[string]bool {
+	out := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		if p != "" {
+			out[absPath(p)] = true
+		}
+	}
+	return out
+}
+
+// absPath is filepath.Abs, falling back to the cleaned path.
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return filepath.Clean(p)
+}
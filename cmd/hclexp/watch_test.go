@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTreeStamp(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.hcl")
+	require.NoError(t, os.WriteFile(path, []byte("a"), 0o644))
+	before := treeStamp([]string{dir}, nil)
+	assert.Equal(t, before, treeStamp([]string{dir}, nil))
+
+	require.NoError(t, os.WriteFile(path, []byte("ab"), 0o644))
+	edited := treeStamp([]string{dir}, nil)
+	assert.NotEqual(t, before, edited)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "index"), []byte("x"), 0o644))
+	assert.Equal(t, edited, treeStamp([]string{dir}, nil), "hidden directories are ignored")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".events.hcl.tmp-1"), []byte("x"), 0o644))
+	assert.Equal(t, edited, treeStamp([]string{dir}, nil), "hidden files are ignored")
+
+	out := filepath.Join(dir, "plan.sql")
+	require.NoError(t, os.WriteFile(out, []byte("x"), 0o644))
+	assert.Equal(t, edited, treeStamp([]string{dir}, absPaths([]string{out})), "skipped paths are ignored")
+
+	require.NoError(t, os.Remove(path))
+	assert.NotEqual(t, edited, treeStamp([]string{dir}, nil))
+}
+
+func TestWatchFiles_RerunsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.hcl")
+	require.NoError(t, os.WriteFile(path, []byte("a"), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var runs atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		watchFiles(ctx, []string{dir, ""}, nil, 5*time.Millisecond, func() { runs.Add(1) })
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, os.WriteFile(path, []byte("changed"), 0o644))
+	require.Eventually(t, func() bool { return runs.Load() == 2 }, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+	assert.Equal(t, int32(2), runs.Load())
+}
+
+func TestWatchFiles_IgnoresOutput(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "plan.sql")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var runs atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		watchFiles(ctx, []string{dir}, []string{out}, 5*time.Millisecond, func() {
+			runs.Add(1)
+			_ = os.WriteFile(out, []byte(time.Now().String()), 0o644)
+		})
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+	assert.Equal(t, int32(1), runs.Load(), "writing the output must not re-trigger the watcher")
+}
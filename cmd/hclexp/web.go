@@ -27,37 +27,40 @@ var webFS embed.FS
 // load/resolve flow of the other subcommands; no ClickHouse connection is made.
 func runWeb(args []string) {
 	flags := flag.NewFlagSet("hclexp web", flag.ExitOnError)
-	configFlag := flags.String("config", "./cmd/hclexp/node.conf", "path to a single HCL config file (mutually exclusive with -layer)")
+	configFlag := configRootsFlag(flags)
 	layersFlag := flags.String("layer", "", "comma-separated layer stack: each entry a directory or a single .hcl file (loaded in order)")
 	manifestFlag := flags.String("manifest", "", "HCL manifest (role/env/layers, like `plan`): browse every composed schema")
 	envFlag := flags.String("env", "", "with -manifest: only browse this env (default: all envs)")
 	layerRootFlag := flags.String("layer-root", ".", "with -manifest: root directory the manifest's layer paths resolve under")
 	addrFlag := flags.String("addr", ":8080", "address to listen on (host:port)")
 	reloadFlag := flags.Duration("reload-interval", 2*time.Second, "re-stat the source files at most this often and reload on change; 0 disables")
+	varFlags := addVarFlags(flags)
 	_ = flags.Parse(args)
+	opts := varFlags.loadOptions()
 
 	if *manifestFlag != "" {
-		runWebManifest(*manifestFlag, *envFlag, *layerRootFlag, *addrFlag, *reloadFlag)
+		runWebManifest(*manifestFlag, *envFlag, *layerRootFlag, *addrFlag, opts, *reloadFlag)
 		return
 	}
 
-	schema, err := load(*configFlag, *layersFlag)
+	schema, err := loadOpts(configFlag.String(), *layersFlag, opts)
 	if err != nil {
 		slog.Error("failed to load config", "err", err)
-		os.Exit(1)
+		exit(exitError)
 	}
 	if err := hclload.Resolve(schema); err != nil {
 		slog.Error("failed to resolve schema", "err", err)
-		os.Exit(1)
+		exit(exitError)
 	}
 
 	srv, err := newWebServer(schema)
 	if err != nil {
 		slog.Error("failed to build web server", "err", err)
-		os.Exit(1)
+		exit(exitError)
 	}
+	srv.loadOpts = opts
 	if *reloadFlag > 0 {
-		srv.enableReload(*configFlag, *layersFlag, *reloadFlag)
+		srv.enableReload(configFlag.String(), *layersFlag, *reloadFlag)
 		slog.Info("auto-reload enabled", "interval", reloadFlag.String())
 	}
 
@@ -71,7 +74,7 @@ func runWeb(args []string) {
 	slog.Info("serving schema browser", "addr", *addrFlag, "url", "http://localhost"+*addrFlag+"/")
 	if err := http.ListenAndServe(*addrFlag, mux); err != nil {
 		slog.Error("web server stopped", "err", err)
-		os.Exit(1)
+		exit(exitError)
 	}
 }
 
@@ -97,6 +100,8 @@ type webServer struct {
 	// Reload config + bookkeeping. now is injectable for tests.
 	configFlag string
 	layersFlag string
+	only       []string // manifest env `only` list, re-applied on every reload
+	loadOpts   hclload.LoadOptions
 	interval   time.Duration
 	now        func() time.Time
 
@@ -234,7 +239,7 @@ func (s *webServer) maybeReload() {
 		return
 	}
 
-	schema, err := load(s.configFlag, s.layersFlag)
+	schema, err := loadOpts(s.configFlag, s.layersFlag, s.loadOpts)
 	if err != nil {
 		slog.Warn("reload: load failed; keeping current schema", "err", err)
 		return // keep old fp so the next interval retries
@@ -243,6 +248,7 @@ func (s *webServer) maybeReload() {
 		slog.Warn("reload: resolve failed; keeping current schema", "err", err)
 		return
 	}
+	applyManifestOnly(schema, s.only)
 
 	s.mu.Lock()
 	s.rebuildState(schema)
@@ -253,7 +259,7 @@ func (s *webServer) maybeReload() {
 
 // sourceFiles returns the HCL files the loader reads: what each layer path
 // contributes (re-listed each call so files added to or removed from a layer
-// dir register), or the single -config file.
+// dir register), or the files of each -config root.
 func sourceFiles(configFlag, layersFlag string) ([]string, error) {
 	if layersFlag != "" {
 		var files []string
@@ -266,7 +272,15 @@ func sourceFiles(configFlag, layersFlag string) ([]string, error) {
 		}
 		return files, nil
 	}
-	return []string{configFlag}, nil
+	var files []string
+	for _, root := range splitList(configFlag) {
+		f, err := hclload.RootFiles(root)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f...)
+	}
+	return files, nil
 }
 
 // sourceFingerprint maps each source file to its mod time. A changed mod time,
@@ -678,6 +692,17 @@ func mvProps(mv hclload.MaterializedViewSpec) []kv {
 	if mv.ToTable != "" {
 		props = append(props, kv{"to_table", mv.ToTable})
 	}
+	if in := mv.Inner; in != nil {
+		props = append(props, engineProps(in.Engine)...)
+		props = appendList(props, "order_by", in.OrderBy)
+		props = appendPtr(props, "partition_by", in.PartitionBy)
+	}
+	if r := mv.Refresh; r != nil {
+		props = appendPtr(props, "refresh every", r.Every)
+		props = appendPtr(props, "refresh after", r.After)
+		props = appendPtr(props, "refresh offset", r.Offset)
+		props = appendList(props, "depends_on", r.DependsOn)
+	}
 	props = appendPtr(props, "cluster", mv.Cluster)
 	props = appendPtr(props, "comment", mv.Comment)
 	return props
@@ -7,7 +7,6 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -15,13 +14,17 @@ import (
 
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hclparse"
+	hclload "github.com/posthog/chschema/internal/loader/hcl"
 )
 
-// composition is one (env, role) the manifest declares, with its layer stack.
+// composition is one (env, role) the manifest declares, with its layer stack
+// and the env's optional object include list and load options (its vars).
 type composition struct {
 	Env    string
 	Role   string
 	Layers []string
+	Only   []string
+	Load   hclload.LoadOptions
 }
 
 // manifestCompositions decodes the plan manifest (role blocks with nested env
@@ -61,7 +64,10 @@ func manifestCompositions(path, envFilter string) ([]composition, error) {
 			if len(eb.Layers) == 0 {
 				return nil, fmt.Errorf("role %q env %q: layers is empty", rb.Name, eb.Name)
 			}
-			out = append(out, composition{Env: eb.Name, Role: rb.Name, Layers: eb.Layers})
+			if err := validManifestOnly(rb.Name, eb.Name, eb.Only); err != nil {
+				return nil, err
+			}
+			out = append(out, composition{Env: eb.Name, Role: rb.Name, Layers: eb.Layers, Only: eb.Only, Load: hclload.LoadOptions{Vars: eb.Vars, Overlay: eb.Name}})
 		}
 	}
 	if len(out) == 0 {
@@ -134,14 +140,20 @@ func buildMultiServer(comps []composition, layerRoot string, reloadInterval time
 			stack[i] = filepath.Join(layerRoot, l)
 		}
 		layers := strings.Join(stack, ",")
-		schema, err := loadSide(layers)
+		schema, err := hclload.LoadLayersOpts(stack, c.Load)
+		if err == nil {
+			err = hclload.Resolve(schema)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("compose %s/%s: %w", c.Env, c.Role, err)
 		}
+		applyManifestOnly(schema, c.Only)
 		srv, err := newWebServer(schema)
 		if err != nil {
 			return nil, fmt.Errorf("build server %s/%s: %w", c.Env, c.Role, err)
 		}
+		srv.only = c.Only
+		srv.loadOpts = c.Load
 		base := schemaBasePath(c.Env, c.Role)
 		srv.basePath = base
 		srv.label = c.Env + " / " + c.Role
@@ -196,21 +208,25 @@ func (ms *multiServer) handler() http.Handler {
 }
 
 // runWebManifest composes every schema in the manifest and serves the
-// multi-schema browser.
-func runWebManifest(manifestPath, env, layerRoot, addr string, reloadInterval time.Duration) {
+// multi-schema browser. opts (the -var/-var-file/-allow-env flags) apply on
+// top of each env block's vars.
+func runWebManifest(manifestPath, env, layerRoot, addr string, opts hclload.LoadOptions, reloadInterval time.Duration) {
 	comps, err := manifestCompositions(manifestPath, env)
 	if err != nil {
 		slog.Error("failed to read manifest", "file", manifestPath, "err", err)
-		os.Exit(1)
+		exit(exitError)
+	}
+	for i := range comps {
+		comps[i].Load = comps[i].Load.Merge(opts)
 	}
 	ms, err := buildMultiServer(comps, layerRoot, reloadInterval)
 	if err != nil {
 		slog.Error("failed to build schema browser", "err", err)
-		os.Exit(1)
+		exit(exitError)
 	}
 	slog.Info("serving multi-schema browser", "addr", addr, "schemas", len(ms.servers), "url", "http://localhost"+addr+"/")
 	if err := http.ListenAndServe(addr, ms.handler()); err != nil {
 		slog.Error("web server stopped", "err", err)
-		os.Exit(1)
+		exit(exitError)
 	}
 }
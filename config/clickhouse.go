@@ -4,9 +4,11 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
@@ -14,6 +16,9 @@ import (
 
 // ClickHouseConfig holds the connection configuration for ClickHouse.
 type ClickHouseConfig struct {
+	// Host is one host or a comma-separated list of replicas to fail over
+	// between. An entry may carry its own port (host:port); entries without
+	// one use Port.
 	Host          string
 	Port          int
 	Database      string
@@ -22,6 +27,32 @@ type ClickHouseConfig struct {
 	Secure        bool // connect over native TLS (port 9440 by convention)
 	TLSSkipVerify bool // skip server certificate verification (internal-CA clusters)
 
+	// ConnOpenStrategy picks which of several hosts to dial first: in_order
+	// (the default; later hosts are only tried when earlier ones fail),
+	// round_robin or random.
+	ConnOpenStrategy string
+
+	// Protocol is native (the default, TCP port 9000/9440) or http (port
+	// 8123/8443), for deployments that only expose the HTTP interface.
+	// Secure selects HTTPS.
+	Protocol string
+
+	// Tuning for slow links and large introspections. Zero values keep
+	// clickhouse-go's defaults: 30s dial timeout, 5m read timeout, 5 idle
+	// connections and MaxIdleConns+5 open ones.
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	MaxOpenConns int
+	MaxIdleConns int
+
+	// Compression is none (the default), lz4 or zstd.
+	Compression string
+
+	// Proxy is an optional socks5://[user:pass@]host:port proxy every
+	// connection is dialed through, e.g. `ssh -D 1080 bastion` for a server
+	// only reachable via a bastion.
+	Proxy string
+
 	// ShowSecrets enables the format_display_secrets_in_show_and_select session
 	// setting so create_table_query / SHOW CREATE / system.named_collections
 	// return real secret values (passwords, broker lists) instead of the
@@ -34,16 +65,92 @@ type ClickHouseConfig struct {
 }
 
 // GetDefaultConfig returns default configuration based on environment.
+// Without CLICKHOUSE_USER/CLICKHOUSE_PASSWORD it falls back to ClickHouse's
+// own passwordless "default" user rather than any baked-in credentials.
 func GetDefaultConfig() ClickHouseConfig {
 	return ClickHouseConfig{
 		Host:          getEnvOrDefault("CLICKHOUSE_HOST", "localhost"),
 		Port:          getEnvIntOrDefault("CLICKHOUSE_PORT", 9000),
 		Database:      getEnvOrDefault("CLICKHOUSE_DB", "migration_test"),
-		User:          getEnvOrDefault("CLICKHOUSE_USER", "user1"),
-		Password:      getEnvOrDefault("CLICKHOUSE_PASSWORD", "pass1"),
+		User:          getEnvOrDefault("CLICKHOUSE_USER", "default"),
+		Password:      os.Getenv("CLICKHOUSE_PASSWORD"),
 		Secure:        getEnvBoolOrDefault("CLICKHOUSE_SECURE", false),
 		TLSSkipVerify: getEnvBoolOrDefault("CLICKHOUSE_TLS_SKIP_VERIFY", false),
+
+		ConnOpenStrategy: getEnvOrDefault("CLICKHOUSE_CONN_OPEN_STRATEGY", ConnOpenInOrder),
+		Protocol:         getEnvOrDefault("CLICKHOUSE_PROTOCOL", ProtocolNative),
+		Proxy:            os.Getenv("CLICKHOUSE_PROXY"),
+	}
+}
+
+// Connection open strategies accepted in ClickHouseConfig.ConnOpenStrategy.
+const (
+	ConnOpenInOrder    = "in_order"
+	ConnOpenRoundRobin = "round_robin"
+	ConnOpenRandom     = "random"
+)
+
+var connOpenStrategies = map[string]clickhouse.ConnOpenStrategy{
+	ConnOpenInOrder:    clickhouse.ConnOpenInOrder,
+	ConnOpenRoundRobin: clickhouse.ConnOpenRoundRobin,
+	ConnOpenRandom:     clickhouse.ConnOpenRandom,
+}
+
+// CheckConnOpenStrategy reports an error for an unknown strategy name. Empty
+// means in_order.
+func CheckConnOpenStrategy(s string) error {
+	if _, ok := connOpenStrategies[s]; !ok && s != "" {
+		return fmt.Errorf("unknown connection open strategy %q (want %s, %s or %s)", s, ConnOpenInOrder, ConnOpenRoundRobin, ConnOpenRandom)
+	}
+	return nil
+}
+
+// Protocols accepted in ClickHouseConfig.Protocol.
+const (
+	ProtocolNative = "native"
+	ProtocolHTTP   = "http"
+)
+
+// CheckProtocol reports an error for an unknown protocol name. Empty means
+// native.
+func CheckProtocol(s string) error {
+	switch s {
+	case "", ProtocolNative, ProtocolHTTP:
+		return nil
+	}
+	return fmt.Errorf("unknown protocol %q (want %s or %s)", s, ProtocolNative, ProtocolHTTP)
+}
+
+var compressionMethods = map[string]clickhouse.CompressionMethod{
+	"none": clickhouse.CompressionNone,
+	"lz4":  clickhouse.CompressionLZ4,
+	"zstd": clickhouse.CompressionZSTD,
+}
+
+// CheckCompression reports an error for an unknown compression method. Empty
+// means none.
+func CheckCompression(s string) error {
+	if _, ok := compressionMethods[s]; !ok && s != "" {
+		return fmt.Errorf("unknown compression %q (want none, lz4 or zstd)", s)
+	}
+	return nil
+}
+
+// Addrs returns the host:port addresses to dial, one per Host entry.
+func (cfg ClickHouseConfig) Addrs() []string {
+	var addrs []string
+	for _, h := range strings.Split(cfg.Host, ",") {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(h); err == nil {
+			addrs = append(addrs, h)
+		} else {
+			addrs = append(addrs, net.JoinHostPort(h, strconv.Itoa(cfg.Port)))
+		}
 	}
+	return addrs
 }
 
 // NewConnection creates a new ClickHouse connection from the config.
@@ -63,21 +170,42 @@ func NewConnection(cfg ClickHouseConfig) (driver.Conn, error) {
 }
 
 // buildOptions translates a ClickHouseConfig into clickhouse-go options.
-// Extracted so the TLS branch can be unit-tested without dialing.
+// Extracted so the TLS, protocol and tuning branches can be unit-tested
+// without dialing.
 func buildOptions(cfg ClickHouseConfig) *clickhouse.Options {
 	opts := &clickhouse.Options{
-		Addr: []string{fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)},
+		Addr: cfg.Addrs(),
 		Auth: clickhouse.Auth{
 			Database: cfg.Database,
 			Username: cfg.User,
 			Password: cfg.Password,
 		},
+		ConnOpenStrategy: connOpenStrategies[cfg.ConnOpenStrategy],
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		MaxOpenConns:     cfg.MaxOpenConns,
+		MaxIdleConns:     cfg.MaxIdleConns,
+	}
+	if m, ok := compressionMethods[cfg.Compression]; ok && m != clickhouse.CompressionNone {
+		opts.Compression = &clickhouse.Compression{Method: m}
+	}
+	if cfg.Protocol == ProtocolHTTP {
+		opts.Protocol = clickhouse.HTTP
 	}
 	if cfg.Secure {
 		opts.TLS = &tls.Config{
 			InsecureSkipVerify: cfg.TLSSkipVerify, //nolint:gosec // opted in via -tls-skip-verify
 		}
 	}
+	if cfg.Proxy != "" {
+		// Over HTTP the transport runs TLS on the tunnel itself; the native
+		// protocol leaves it to the dialer.
+		var tunnelTLS *tls.Config
+		if opts.Protocol != clickhouse.HTTP {
+			tunnelTLS = opts.TLS
+		}
+		opts.DialContext = socks5Dialer(cfg.Proxy, tunnelTLS, cfg.DialTimeout)
+	}
 	if cfg.ShowSecrets {
 		// Session-level format setting; the server config + grant still gate
 		// whether secrets are actually revealed.
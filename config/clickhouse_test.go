@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
+	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/stretchr/testify/require"
 )
 
@@ -79,3 +81,75 @@ func TestBuildOptions_TLS(t *testing.T) {
 
 // guard against accidental import shadowing
 var _ = os.Getenv
+
+func TestAddrs(t *testing.T) {
+	cfg := ClickHouseConfig{Host: "ch-0, ch-1:9001,::1", Port: 9000}
+	require.Equal(t, []string{"ch-0:9000", "ch-1:9001", "[::1]:9000"}, cfg.Addrs())
+
+	cfg = ClickHouseConfig{Host: "h", Port: 9440}
+	require.Equal(t, []string{"h:9440"}, cfg.Addrs())
+}
+
+func TestBuildOptions_ConnOpenStrategy(t *testing.T) {
+	opts := buildOptions(ClickHouseConfig{Host: "a,b", Port: 9000})
+	require.Equal(t, []string{"a:9000", "b:9000"}, opts.Addr)
+	require.Equal(t, clickhouse.ConnOpenInOrder, opts.ConnOpenStrategy)
+
+	opts = buildOptions(ClickHouseConfig{Host: "a,b", Port: 9000, ConnOpenStrategy: ConnOpenRoundRobin})
+	require.Equal(t, clickhouse.ConnOpenRoundRobin, opts.ConnOpenStrategy)
+
+	require.NoError(t, CheckConnOpenStrategy(""))
+	require.NoError(t, CheckConnOpenStrategy(ConnOpenRandom))
+	require.Error(t, CheckConnOpenStrategy("fastest"))
+}
+
+func TestBuildOptions_Protocol(t *testing.T) {
+	opts := buildOptions(ClickHouseConfig{Host: "h", Port: 9000})
+	require.Equal(t, clickhouse.Native, opts.Protocol)
+
+	opts = buildOptions(ClickHouseConfig{Host: "h", Port: 8443, Protocol: ProtocolHTTP, Secure: true})
+	require.Equal(t, clickhouse.HTTP, opts.Protocol)
+	require.NotNil(t, opts.TLS)
+
+	require.NoError(t, CheckProtocol(""))
+	require.NoError(t, CheckProtocol(ProtocolHTTP))
+	require.Error(t, CheckProtocol("grpc"))
+}
+
+func TestGetDefaultConfig_Credentials(t *testing.T) {
+	t.Setenv("CLICKHOUSE_USER", "")
+	t.Setenv("CLICKHOUSE_PASSWORD", "")
+	cfg := GetDefaultConfig()
+	require.Equal(t, "default", cfg.User)
+	require.Empty(t, cfg.Password, "no baked-in password")
+
+	t.Setenv("CLICKHOUSE_USER", "ro")
+	t.Setenv("CLICKHOUSE_PASSWORD", "secret")
+	cfg = GetDefaultConfig()
+	require.Equal(t, "ro", cfg.User)
+	require.Equal(t, "secret", cfg.Password)
+}
+
+func TestBuildOptions_Tuning(t *testing.T) {
+	opts := buildOptions(ClickHouseConfig{Host: "h", Port: 9000})
+	require.Zero(t, opts.DialTimeout)
+	require.Zero(t, opts.MaxOpenConns)
+	require.Nil(t, opts.Compression)
+
+	opts = buildOptions(ClickHouseConfig{
+		Host: "h", Port: 9000,
+		DialTimeout: 5 * time.Second, ReadTimeout: 30 * time.Minute,
+		MaxOpenConns: 20, MaxIdleConns: 10, Compression: "zstd",
+	})
+	require.Equal(t, 5*time.Second, opts.DialTimeout)
+	require.Equal(t, 30*time.Minute, opts.ReadTimeout)
+	require.Equal(t, 20, opts.MaxOpenConns)
+	require.Equal(t, 10, opts.MaxIdleConns)
+	require.Equal(t, clickhouse.CompressionZSTD, opts.Compression.Method)
+
+	opts = buildOptions(ClickHouseConfig{Host: "h", Port: 9000, Compression: "none"})
+	require.Nil(t, opts.Compression)
+
+	require.NoError(t, CheckCompression("lz4"))
+	require.Error(t, CheckCompression("snappy"))
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSOCKS5 accepts one connection, runs the server side of the handshake
+// (optionally requiring user/pass), records the requested target and then
+// echoes whatever the client sends.
+func fakeSOCKS5(t *testing.T, user, pass string) (addr string, target chan string) {
+	t.Helper()
+	return fakeSOCKS5To(t, user, pass, "")
+}
+
+// fakeSOCKS5To is fakeSOCKS5 relaying the tunnel to upstream instead of
+// echoing, whatever target the client asked for.
+func fakeSOCKS5To(t *testing.T, user, pass, upstream string) (addr string, target chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	target = make(chan string, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		buf := make([]byte, 512)
+		_, _ = io.ReadFull(c, buf[:2])
+		_, _ = io.ReadFull(c, buf[:buf[1]])
+		if user == "" {
+			_, _ = c.Write([]byte{0x05, 0x00})
+		} else {
+			_, _ = c.Write([]byte{0x05, 0x02})
+			_, _ = io.ReadFull(c, buf[:2])
+			u := make([]byte, buf[1])
+			_, _ = io.ReadFull(c, u)
+			_, _ = io.ReadFull(c, buf[:1])
+			p := make([]byte, buf[0])
+			_, _ = io.ReadFull(c, p)
+			if string(u) != user || string(p) != pass {
+				_, _ = c.Write([]byte{0x01, 0x01})
+				return
+			}
+			_, _ = c.Write([]byte{0x01, 0x00})
+		}
+		_, _ = io.ReadFull(c, buf[:4])
+		_, _ = io.ReadFull(c, buf[:1])
+		host := make([]byte, buf[0])
+		_, _ = io.ReadFull(c, host)
+		_, _ = io.ReadFull(c, buf[:2])
+		target <- net.JoinHostPort(string(host), "9440")
+		_, _ = c.Write([]byte{0x05, 0x00, 0x00, 0x01, 127, 0, 0, 1, 0, 0})
+		if upstream == "" {
+			_, _ = io.Copy(c, c)
+			return
+		}
+		up, err := net.Dial("tcp", upstream)
+		if err != nil {
+			return
+		}
+		defer up.Close()
+		go func() { _, _ = io.Copy(up, c) }()
+		_, _ = io.Copy(c, up)
+	}()
+	return ln.Addr().String(), target
+}
+
+func TestSOCKS5Dialer(t *testing.T) {
+	addr, target := fakeSOCKS5(t, "", "")
+	conn, err := socks5Dialer("socks5://"+addr, nil, 0)(context.Background(), "ch-0.internal:9440")
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Equal(t, "ch-0.internal:9440", <-target, "host names reach the proxy unresolved")
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	got := make([]byte, 4)
+	_, err = io.ReadFull(conn, got)
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(got))
+}
+
+func TestSOCKS5Dialer_Auth(t *testing.T) {
+	addr, _ := fakeSOCKS5(t, "tunnel", "s3cret")
+	conn, err := socks5Dialer("socks5://tunnel:s3cret@"+addr, nil, 0)(context.Background(), "ch:9440")
+	require.NoError(t, err)
+	_ = conn.Close()
+
+	addr, _ = fakeSOCKS5(t, "tunnel", "s3cret")
+	_, err = socks5Dialer("socks5://tunnel:wrong@"+addr, nil, 0)(context.Background(), "ch:9440")
+	require.ErrorContains(t, err, "rejected the username or password")
+
+	addr, _ = fakeSOCKS5(t, "tunnel", "s3cret")
+	_, err = socks5Dialer("socks5://"+addr, nil, 0)(context.Background(), "ch:9440")
+	require.ErrorContains(t, err, "requires a username and password")
+}
+
+// With TLS on, the dialer handshakes over the tunnel and verifies the
+// certificate against the target host, not the proxy.
+func TestSOCKS5Dialer_TLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	tlsConfig := &tls.Config{RootCAs: roots}
+
+	addr, _ := fakeSOCKS5To(t, "", "", srv.Listener.Addr().String())
+	conn, err := socks5Dialer("socks5://"+addr, tlsConfig, 0)(context.Background(), "example.com:9440")
+	require.NoError(t, err)
+	defer conn.Close()
+	tc, ok := conn.(*tls.Conn)
+	require.True(t, ok, "the tunnel is wrapped in TLS")
+	require.True(t, tc.ConnectionState().HandshakeComplete)
+	require.Equal(t, "example.com", tc.ConnectionState().ServerName)
+	require.Empty(t, tlsConfig.ServerName, "the caller's config is not modified")
+
+	addr, _ = fakeSOCKS5To(t, "", "", srv.Listener.Addr().String())
+	_, err = socks5Dialer("socks5://"+addr, tlsConfig, 0)(context.Background(), "ch:9440")
+	require.ErrorContains(t, err, "TLS handshake with ch:9440")
+}
+
+func TestCheckProxy(t *testing.T) {
+	require.NoError(t, CheckProxy(""))
+	require.NoError(t, CheckProxy("socks5://127.0.0.1:1080"))
+	require.NoError(t, CheckProxy("socks5h://u:p@bastion:1080"))
+	require.ErrorContains(t, CheckProxy("http://proxy:3128"), "unsupported proxy scheme")
+	require.ErrorContains(t, CheckProxy("socks5://bastion"), "needs host:port")
+}
+
+func TestBuildOptions_Proxy(t *testing.T) {
+	require.Nil(t, buildOptions(ClickHouseConfig{Host: "h", Port: 9000}).DialContext)
+	require.NotNil(t, buildOptions(ClickHouseConfig{Host: "h", Port: 9000, Proxy: "socks5://127.0.0.1:1080"}).DialContext)
+	opts := buildOptions(ClickHouseConfig{Host: "h", Port: 9440, Secure: true, Proxy: "socks5://127.0.0.1:1080"})
+	require.NotNil(t, opts.TLS, "TLS stays configured; the dialer runs the handshake")
+	require.NotNil(t, opts.DialContext)
+}
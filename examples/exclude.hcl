@@ -46,4 +46,13 @@ exclude {
   #   object_types = ["named_collection"]
   #
   # Valid: table, materialized_view, view, dictionary, raw, named_collection.
+  #
+  # databases drops whole databases; columns drops table columns, matched as
+  # "col", "table.col" or "db.table.col" — for scratch databases and columns
+  # that ops or vendor tools add on the live side:
+  #   databases = ["scratch_*"]
+  #   columns   = ["_airbyte_*"]
+  #
+  # Save this file as .chschemaignore in the working directory to have it
+  # applied without passing -exclude.
 }
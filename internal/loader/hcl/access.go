@@ -0,0 +1,72 @@
+package hcl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// AccessProblem is one thing introspection needs that the connected user
+// cannot read: a system table, or a database it cannot see.
+type AccessProblem struct {
+	Object string // "system.tables", or a database name
+	Reason string
+}
+
+func (p AccessProblem) String() string {
+	return fmt.Sprintf("%s: %s", p.Object, p.Reason)
+}
+
+// introspectSystemTables are the system tables every introspection reads.
+var introspectSystemTables = []string{"system.databases", "system.tables", "system.macros", "system.named_collections"}
+
+// CheckIntrospectAccess verifies up front that the connected user can read
+// every system table introspection queries and can see every database in
+// databases, so a missing grant fails before any work with one report instead
+// of partway through. Seeing the databases matters most: ClickHouse filters
+// system.databases and system.tables down to what the user may SHOW rather
+// than failing, so without the check a missing grant reads as an empty
+// database. settingsProfiles adds the settings profile tables. The error is
+// non-nil only when the check itself could not run.
+func CheckIntrospectAccess(ctx context.Context, conn driver.Conn, databases []string, settingsProfiles bool) ([]AccessProblem, error) {
+	tables := introspectSystemTables
+	if settingsProfiles {
+		tables = append(tables[:len(tables):len(tables)], "system.settings_profiles", "system.settings_profile_elements")
+	}
+	var problems []AccessProblem
+	for _, t := range tables {
+		if err := conn.Exec(ctx, "SELECT 1 FROM "+t+" LIMIT 0"); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			problems = append(problems, AccessProblem{Object: t, Reason: err.Error()})
+		}
+	}
+	if len(problems) > 0 {
+		return problems, nil // without system.databases the visibility check below is meaningless
+	}
+
+	rows, err := conn.Query(ctx, "SELECT name FROM system.databases")
+	if err != nil {
+		return nil, fmt.Errorf("query system.databases: %w", err)
+	}
+	defer rows.Close()
+	visible := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan system.databases: %w", err)
+		}
+		visible[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, db := range databases {
+		if !visible[db] {
+			problems = append(problems, AccessProblem{Object: db, Reason: "database does not exist or the user lacks SHOW on it"})
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,29 @@
+package hcl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/posthog/chschema/test/testhelpers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCHLive_CheckIntrospectAccess(t *testing.T) {
+	if !*clickhouseLive {
+		t.Skip("pass -clickhouse to run against a live ClickHouse")
+	}
+	t.Parallel()
+	conn := testhelpers.RequireClickHouse(t)
+	ctx := context.Background()
+
+	problems, err := CheckIntrospectAccess(ctx, conn, []string{"system"}, true)
+	require.NoError(t, err)
+	require.Empty(t, problems, "the test user can introspect")
+
+	problems, err = CheckIntrospectAccess(ctx, conn, []string{"system", "no_such_db_for_access_check"}, false)
+	require.NoError(t, err)
+	require.Equal(t, []AccessProblem{{
+		Object: "no_such_db_for_access_check",
+		Reason: "database does not exist or the user lacks SHOW on it",
+	}}, problems)
+}
@@ -0,0 +1,92 @@
+package hcl
+
+import "path"
+
+// Advice levels for a planned operation.
+const (
+	AdviceOnline     = "online"     // safe to run while the cluster serves traffic
+	AdviceCoordinate = "coordinate" // needs consumers detached, clients moved, or writes paused
+)
+
+// adviceRule maps an operation to its advice. kind, objectType and engine
+// are path.Match globs; empty matches anything. For a table the engine is its
+// own family (ReplicatedMergeTree, Kafka, …); for a materialized view it is
+// the family of the table it reads from, since that is what decides whether
+// changing the view interrupts ingestion.
+type adviceRule struct {
+	kind, objectType, engine string
+	level, guidance          string
+}
+
+// adviceRules is the table adviseOperation consults. The first matching rule
+// wins, so specific rules come before the general ones.
+var adviceRules = []adviceRule{
+	{"", KindMaterializedView, "Kafka", AdviceCoordinate,
+		"Kafka-fed materialized view: DETACH the Kafka table first so nothing is consumed mid-change, then ATTACH it after"},
+	{OpCreate, KindMaterializedView, "", AdviceOnline,
+		"processes inserts from creation on; backfill the target for earlier rows"},
+	{OpCreate, KindTable, "Kafka", AdviceOnline,
+		"consumption starts when a materialized view reads from it"},
+	{OpCreate, "", "", AdviceOnline,
+		"new object; nothing depends on it yet"},
+	{"", KindTable, "Kafka", AdviceCoordinate,
+		"materialized views reading this Kafka table stop consuming while it changes; detach them first"},
+	{OpDrop, KindMaterializedView, "", AdviceCoordinate,
+		"inserts into the source stop reaching the target table from this point"},
+	{OpDrop, KindTable, "Distributed", AdviceCoordinate,
+		"queries and inserts through this proxy fail once it is dropped; move clients first"},
+	{OpDrop, "", "", AdviceCoordinate,
+		"removes the object and its data; confirm no reader or writer still uses it"},
+	{OpRename, "", "", AdviceCoordinate,
+		"clients using the old name fail after the rename; switch them over together"},
+	{OpAlter, KindMaterializedView, "", AdviceOnline,
+		"MODIFY QUERY swaps the query atomically"},
+	{OpAlter, KindTable, "Replicated*", AdviceOnline,
+		"replicated through Keeper; data-rewriting changes continue as background mutations on every replica"},
+	{OpAlter, KindTable, "", AdviceOnline,
+		"data-rewriting changes continue as a background mutation"},
+	{OpAlter, "", "", AdviceOnline,
+		"replaced in place"},
+}
+
+// adviseOperation returns the advice level and guidance for an operation of
+// the given kind on an object of objectType with engine (see adviceRule). An
+// operation no rule covers is online with no guidance.
+func adviseOperation(kind, objectType, engine string) (level, guidance string) {
+	for _, r := range adviceRules {
+		if globMatch(r.kind, kind) && globMatch(r.objectType, objectType) && globMatch(r.engine, engine) {
+			return r.level, r.guidance
+		}
+	}
+	return AdviceOnline, ""
+}
+
+func globMatch(pattern, s string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, s)
+	return ok
+}
+
+// mvSourceEngines maps each materialized view to the engine family of the
+// table it reads from, preferring Kafka when it reads from several: a view
+// fed by any Kafka table needs its consumer detached.
+func mvSourceEngines(s *Schema) map[ObjectRef]string {
+	out := map[ObjectRef]string{}
+	deps, err := CollectDependencies(s.Databases)
+	if err != nil {
+		return out
+	}
+	for _, d := range deps {
+		if d.Kind != DepMVSource {
+			continue
+		}
+		engine := engineFor(d.To.Database, d.To.Name, s)
+		if engine == "" || out[d.From] == "Kafka" {
+			continue
+		}
+		out[d.From] = engine
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdviseOperation(t *testing.T) {
+	for _, tc := range []struct {
+		kind, objectType, engine string
+		want                     string
+	}{
+		{OpAlter, KindMaterializedView, "Kafka", AdviceCoordinate},
+		{OpCreate, KindMaterializedView, "Kafka", AdviceCoordinate},
+		{OpAlter, KindMaterializedView, "MergeTree", AdviceOnline},
+		{OpDrop, KindMaterializedView, "MergeTree", AdviceCoordinate},
+		{OpCreate, KindTable, "Kafka", AdviceOnline},
+		{OpAlter, KindTable, "Kafka", AdviceCoordinate},
+		{OpAlter, KindTable, "ReplicatedReplacingMergeTree", AdviceOnline},
+		{OpDrop, KindTable, "Distributed", AdviceCoordinate},
+		{OpRename, KindTable, "MergeTree", AdviceCoordinate},
+		{OpAlter, KindView, "", AdviceOnline},
+	} {
+		level, guidance := adviseOperation(tc.kind, tc.objectType, tc.engine)
+		assert.Equal(t, tc.want, level, "%s %s %s", tc.kind, tc.objectType, tc.engine)
+		assert.NotEmpty(t, guidance)
+	}
+	level, guidance := adviseOperation("UNKNOWN", "", "")
+	assert.Equal(t, AdviceOnline, level)
+	assert.Empty(t, guidance)
+}
+
+// A materialized view is advised by the engine of the table it reads from:
+// changing the query of a Kafka-fed view needs its consumer detached.
+func TestBuildPlan_AdviceUsesMVSourceEngine(t *testing.T) {
+	kafka := mkTable("kafka_events", EngineKafka{Collection: strPtr("events_kafka")}, ColumnSpec{Name: "id", Type: "UInt64"})
+	events := mkTable("events", EngineMergeTree{}, ColumnSpec{Name: "id", Type: "UInt64"})
+	events.OrderBy = []string{"id"}
+	schema := func(query string) *Schema {
+		db := mkDB("posthog", kafka, events)
+		db.MaterializedViews = []MaterializedViewSpec{{Name: "events_mv", ToTable: "posthog.events", Query: query}}
+		return &Schema{Databases: []DatabaseSpec{db}}
+	}
+
+	plan := BuildPlan([]RoleDiff{{
+		Role:    "ingest",
+		Desired: schema("SELECT id FROM posthog.kafka_events WHERE id > 0"),
+		Current: schema("SELECT id FROM posthog.kafka_events"),
+	}})
+	require.Len(t, plan.Operations, 1)
+	op := plan.Operations[0]
+	assert.Equal(t, KindMaterializedView, op.ObjectType)
+	assert.Equal(t, AdviceCoordinate, op.Advice)
+	assert.Contains(t, op.Guidance, "DETACH the Kafka table")
+}
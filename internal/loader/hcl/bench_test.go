@@ -0,0 +1,132 @@
+package hcl
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Benchmarks for the planning and introspection hot paths over synthetic
+// catalogs the size of a large production cluster (10k tables). Run with
+//
+//	go test ./internal/loader/hcl -run '^$' -bench . -benchmem
+//
+// and compare runs with benchstat to catch regressions.
+
+const (
+	benchTables    = 10_000
+	benchDatabases = 10
+)
+
+// benchSchema builds n tables spread over benchDatabases databases. Every
+// table is a ReplicatedMergeTree with a handful of columns, an ORDER BY, a
+// partition key and a skip index; every tenth is fronted by a Distributed
+// proxy. drift perturbs the result the way a real current state differs from
+// the desired one: every 10th table gains a column, every 50th is missing.
+func benchSchema(n int, drift bool) *Schema {
+	dbs := make([]DatabaseSpec, benchDatabases)
+	for i := range dbs {
+		dbs[i].Name = fmt.Sprintf("db%02d", i)
+	}
+	for i := 0; i < n; i++ {
+		if drift && i%50 == 0 {
+			continue
+		}
+		db := &dbs[i%benchDatabases]
+		t := benchTable(db.Name, fmt.Sprintf("t%05d", i))
+		if drift && i%10 == 0 {
+			t.Columns = append(t.Columns, ColumnSpec{Name: "extra", Type: "Nullable(String)"})
+		}
+		db.Tables = append(db.Tables, t)
+		if i%10 == 0 {
+			db.Tables = append(db.Tables, distTable("dist_"+t.Name, t.Name, t.Columns...))
+		}
+	}
+	return &Schema{Databases: dbs}
+}
+
+func benchTable(db, name string) TableSpec {
+	partition := "toYYYYMM(timestamp)"
+	t := mkTable(name, EngineReplicatedMergeTree{
+		ZooPath:     "/clickhouse/tables/{shard}/" + db + "." + name,
+		ReplicaName: "{replica}",
+	},
+		ColumnSpec{Name: "team_id", Type: "Int64"},
+		ColumnSpec{Name: "uuid", Type: "UUID"},
+		ColumnSpec{Name: "event", Type: "LowCardinality(String)"},
+		ColumnSpec{Name: "properties", Type: "String"},
+		ColumnSpec{Name: "timestamp", Type: "DateTime64(6, 'UTC')"},
+		ColumnSpec{Name: "_offset", Type: "UInt64"},
+	)
+	t.OrderBy = []string{"team_id", "toDate(timestamp)", "event", "uuid"}
+	t.PartitionBy = &partition
+	t.Indexes = []IndexSpec{{Name: "idx_event", Expr: "event", Type: "bloom_filter", Granularity: 4}}
+	return t
+}
+
+// benchCreateSQL renders the system.tables.create_table_query rows a 10k-table
+// cluster would return, for the introspection benchmark.
+func benchCreateSQL(n int) []fakeRow {
+	rows := make([]fakeRow, n)
+	for i := range rows {
+		name := fmt.Sprintf("t%05d", i)
+		rows[i] = fakeRow{
+			name: name,
+			sql: "CREATE TABLE db00." + name + " (`team_id` Int64, `uuid` UUID, " +
+				"`event` LowCardinality(String), `properties` String CODEC(ZSTD(3)), " +
+				"`timestamp` DateTime64(6, 'UTC'), `_offset` UInt64, " +
+				"INDEX idx_event event TYPE bloom_filter GRANULARITY 4) " +
+				"ENGINE = ReplicatedMergeTree('/clickhouse/tables/{shard}/db00." + name + "', '{replica}') " +
+				"PARTITION BY toYYYYMM(timestamp) ORDER BY (team_id, toDate(timestamp), event, uuid) " +
+				"SETTINGS index_granularity = 8192",
+			engine: "ReplicatedMergeTree",
+		}
+	}
+	return rows
+}
+
+func BenchmarkDiff10k(b *testing.B) {
+	current, desired := benchSchema(benchTables, true), benchSchema(benchTables, false)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Diff(current, desired)
+	}
+}
+
+func BenchmarkGenerateSQL10k(b *testing.B) {
+	cs := Diff(benchSchema(benchTables, true), benchSchema(benchTables, false))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GenerateSQL(cs)
+	}
+}
+
+// BenchmarkBuildPlan10k plans two roles sharing one 10k-table desired state:
+// per-role diff, cross-role dedup and dependency ordering end to end.
+func BenchmarkBuildPlan10k(b *testing.B) {
+	desired := benchSchema(benchTables, false)
+	roles := []RoleDiff{
+		{Role: "ops", Desired: desired, Current: benchSchema(benchTables, true)},
+		{Role: "data", Desired: desired, Current: benchSchema(benchTables, true)},
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		BuildPlan(roles)
+	}
+}
+
+// BenchmarkIntrospectRows10k parses 10k create_table_query rows into a
+// DatabaseSpec — the CPU side of introspection, without a live server.
+func BenchmarkIntrospectRows10k(b *testing.B) {
+	rows := benchCreateSQL(benchTables)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		db := &DatabaseSpec{Name: "db00"}
+		if err := processIntrospectRows(db, "db00", &fakeRows{rows: rows}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
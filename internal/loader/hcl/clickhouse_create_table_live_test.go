@@ -23,8 +23,8 @@ func TestCHLive_CreateTable(t *testing.T) {
 	if !*clickhouseLive {
 		t.Skip("pass -clickhouse to run against a live ClickHouse")
 	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 
 	for _, tc := range createTableCases {
 		tc := tc
@@ -0,0 +1,81 @@
+package hcl
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// IntrospectClusters returns the topology of the named clusters from
+// system.clusters, in name order; no names means every cluster the server
+// knows. A named cluster the server does not know is an error, so a typo does
+// not silently leave the topology out of a dump.
+func IntrospectClusters(ctx context.Context, conn driver.Conn, names []string) ([]ClusterSpec, error) {
+	const q = `SELECT cluster, shard_num, shard_weight, replica_num, host_name, port
+		FROM system.clusters
+		ORDER BY cluster, shard_num, replica_num`
+	rows, err := conn.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("query system.clusters: %w", err)
+	}
+	defer rows.Close()
+	clusters, err := processClusterRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return clusters, nil
+	}
+	var out []ClusterSpec
+	for _, c := range clusters {
+		if slices.Contains(names, c.Name) {
+			out = append(out, c)
+		}
+	}
+	for _, n := range names {
+		if !slices.ContainsFunc(out, func(c ClusterSpec) bool { return c.Name == n }) {
+			return nil, fmt.Errorf("cluster %q is not in system.clusters", n)
+		}
+	}
+	return out, nil
+}
+
+// processClusterRows builds one spec per cluster from (cluster, shard_num,
+// shard_weight, replica_num, host_name, port) rows ordered by cluster,
+// shard_num and replica_num.
+func processClusterRows(rows rowScanner) ([]ClusterSpec, error) {
+	var out []ClusterSpec
+	var lastShard uint32
+	for rows.Next() {
+		var (
+			cluster, host                string
+			shardNum, weight, replicaNum uint32
+			port                         uint16
+		)
+		if err := rows.Scan(&cluster, &shardNum, &weight, &replicaNum, &host, &port); err != nil {
+			return nil, fmt.Errorf("scan system.clusters: %w", err)
+		}
+		if len(out) == 0 || out[len(out)-1].Name != cluster {
+			out = append(out, ClusterSpec{Name: cluster})
+			lastShard = 0
+		}
+		c := &out[len(out)-1]
+		if len(c.Shards) == 0 || shardNum != lastShard {
+			sh := ClusterShard{}
+			if weight != 1 {
+				w := int(weight)
+				sh.Weight = &w
+			}
+			c.Shards = append(c.Shards, sh)
+			lastShard = shardNum
+		}
+		sh := &c.Shards[len(c.Shards)-1]
+		sh.Replicas = append(sh.Replicas, ClusterReplica{Host: host, Port: int(port)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return out, nil
+}
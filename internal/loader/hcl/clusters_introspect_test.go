@@ -0,0 +1,29 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessClusterRows(t *testing.T) {
+	clusters, err := processClusterRows(&anyRows{rows: [][]any{
+		{"posthog", uint32(1), uint32(1), uint32(1), "ch-0-0", uint16(9000)},
+		{"posthog", uint32(1), uint32(1), uint32(2), "ch-0-1", uint16(9000)},
+		{"posthog", uint32(2), uint32(2), uint32(1), "ch-1-0", uint16(9440)},
+		{"single", uint32(1), uint32(1), uint32(1), "ch-0-0", uint16(9000)},
+	}})
+	require.NoError(t, err)
+
+	two := 2
+	assert.Equal(t, []ClusterSpec{
+		{Name: "posthog", Shards: []ClusterShard{
+			{Replicas: []ClusterReplica{{Host: "ch-0-0", Port: 9000}, {Host: "ch-0-1", Port: 9000}}},
+			{Weight: &two, Replicas: []ClusterReplica{{Host: "ch-1-0", Port: 9440}}},
+		}},
+		{Name: "single", Shards: []ClusterShard{
+			{Replicas: []ClusterReplica{{Host: "ch-0-0", Port: 9000}}},
+		}},
+	}, clusters)
+}
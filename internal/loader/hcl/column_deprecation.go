@@ -0,0 +1,68 @@
+package hcl
+
+import "strings"
+
+// DeprecatedColumnMarker prefixes the COMMENT of a column declared with
+// `deprecated = true`. It is how the flag survives on the cluster: anyone
+// reading the column's comment sees the warning, and introspection turns the
+// marker back into ColumnSpec.Deprecated.
+const DeprecatedColumnMarker = "[deprecated]"
+
+// columnSQLComment is the COMMENT a column renders with: its declared comment,
+// prefixed with DeprecatedColumnMarker when it is deprecated.
+func columnSQLComment(c ColumnSpec) *string {
+	if !c.Deprecated {
+		return c.Comment
+	}
+	s := DeprecatedColumnMarker
+	if c.Comment != nil && *c.Comment != "" {
+		s += " " + *c.Comment
+	}
+	return &s
+}
+
+// splitDeprecatedComment reverses columnSQLComment for a live comment: it
+// strips the marker and reports whether it was there. A comment that was only
+// the marker yields a nil comment.
+func splitDeprecatedComment(comment string) (*string, bool) {
+	rest, ok := strings.CutPrefix(comment, DeprecatedColumnMarker)
+	if !ok {
+		return &comment, false
+	}
+	rest = strings.TrimPrefix(rest, " ")
+	if rest == "" {
+		return nil, true
+	}
+	return &rest, true
+}
+
+// undeprecatedDrops lists the columns td drops from database.td.Table that
+// were not deprecated in current, i.e. removed without the first step of the
+// two-step removal.
+func undeprecatedDrops(database string, td TableDiff, current *Schema) []string {
+	if len(td.DropColumns) == 0 || current == nil {
+		return nil
+	}
+	var cols []ColumnSpec
+	for _, db := range current.Databases {
+		if db.Name != database {
+			continue
+		}
+		for _, t := range db.Tables {
+			if t.Name == td.Table {
+				cols = t.Columns
+			}
+		}
+	}
+	deprecated := map[string]bool{}
+	for _, c := range cols {
+		deprecated[c.Name] = c.Deprecated
+	}
+	var out []string
+	for _, n := range td.DropColumns {
+		if !deprecated[n] {
+			out = append(out, n)
+		}
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A deprecated column renders its marker into COMMENT and introspects back to
+// the same declaration, so marking a column is one diffable ALTER.
+func TestDeprecatedColumn_RoundTrip(t *testing.T) {
+	for _, c := range []ColumnSpec{
+		{Name: "old", Type: "String", Deprecated: true},
+		{Name: "old", Type: "String", Deprecated: true, Comment: strPtr("use new instead")},
+		{Name: "old", Type: "String", Comment: strPtr("plain")},
+	} {
+		tbl := mkTable("events", EngineMergeTree{}, ColumnSpec{Name: "id", Type: "UInt64"}, c)
+		tbl.OrderBy = []string{"id"}
+		db := &DatabaseSpec{Name: "posthog"}
+		require.NoError(t, introspectOneObject(db, "posthog", "events", createTableSQL("posthog", tbl)))
+		require.Len(t, db.Tables, 1)
+		got := db.Tables[0].Columns[1]
+		assert.Equal(t, c.Deprecated, got.Deprecated)
+		assert.Equal(t, c.Comment, got.Comment)
+	}
+}
+
+func TestDeprecatedColumn_MarkIsAlter(t *testing.T) {
+	before := mkTable("events", EngineMergeTree{}, ColumnSpec{Name: "old", Type: "String"})
+	after := mkTable("events", EngineMergeTree{}, ColumnSpec{Name: "old", Type: "String", Deprecated: true})
+	gen := GenerateSQL(Diff(
+		&Schema{Databases: []DatabaseSpec{mkDB("posthog", before)}},
+		&Schema{Databases: []DatabaseSpec{mkDB("posthog", after)}},
+	))
+	require.Len(t, gen.Statements, 1)
+	assert.Equal(t, "ALTER TABLE posthog.events MODIFY COLUMN old String COMMENT '[deprecated]'", gen.Statements[0])
+}
+
+// Dropping a column that was never deprecated is flagged for coordination;
+// dropping one deprecated by an earlier apply keeps the ordinary advice.
+func TestBuildPlan_UndeprecatedColumnDrop(t *testing.T) {
+	id := ColumnSpec{Name: "id", Type: "UInt64"}
+	plan := func(old ColumnSpec) PlanOperation {
+		current := mkTable("events", EngineMergeTree{}, id, old)
+		desired := mkTable("events", EngineMergeTree{}, id)
+		p := BuildPlan([]RoleDiff{{
+			Role:    "data",
+			Desired: &Schema{Databases: []DatabaseSpec{mkDB("posthog", desired)}},
+			Current: &Schema{Databases: []DatabaseSpec{mkDB("posthog", current)}},
+		}})
+		require.Len(t, p.Operations, 1)
+		return p.Operations[0]
+	}
+
+	op := plan(ColumnSpec{Name: "old", Type: "String"})
+	assert.Equal(t, AdviceCoordinate, op.Advice)
+	assert.Contains(t, op.Guidance, "drops column(s) old without deprecating them first")
+
+	op = plan(ColumnSpec{Name: "old", Type: "String", Deprecated: true})
+	assert.Equal(t, AdviceOnline, op.Advice)
+	assert.NotContains(t, op.Guidance, "deprecat")
+}
+
+// The flag comes from the diff, not the SQL, so it survives -if-exists
+// rendering the drop as DROP COLUMN IF EXISTS.
+func TestBuildPlan_UndeprecatedColumnDrop_IfExists(t *testing.T) {
+	id := ColumnSpec{Name: "id", Type: "UInt64"}
+	b := NewPlanBuilder()
+	b.IfExists = true
+	b.Add(RoleDiff{
+		Role:    "data",
+		Desired: &Schema{Databases: []DatabaseSpec{mkDB("posthog", mkTable("events", EngineMergeTree{}, id))}},
+		Current: &Schema{Databases: []DatabaseSpec{mkDB("posthog", mkTable("events", EngineMergeTree{}, id, ColumnSpec{Name: "old", Type: "String"}))}},
+	})
+	p := b.Result()
+	require.Len(t, p.Operations, 1)
+	op := p.Operations[0]
+	assert.Contains(t, op.SQL, "DROP COLUMN IF EXISTS old")
+	assert.Equal(t, AdviceCoordinate, op.Advice)
+	assert.Contains(t, op.Guidance, "drops column(s) old without deprecating them first")
+}
@@ -62,6 +62,8 @@ type CompareSummary struct {
 	RawsDropped             int `json:"raws_dropped"`
 	RawsAltered             int `json:"raws_altered"`
 	NamedCollectionsChanged int `json:"named_collections_changed"`
+	SettingsProfilesChanged int `json:"settings_profiles_changed"`
+	FunctionsChanged        int `json:"functions_changed"`
 }
 
 // BuildObjectComparisons flattens a ChangeSet into one entry per differing
@@ -159,6 +161,33 @@ func BuildObjectComparisons(cs ChangeSet, gen GeneratedSQL, left, right *Schema)
 		i := add("", ncc.Name, KindNamedCollection, status, fieldChangesForNamedCollection(ncc))
 		out[i].Error = ncc.Error
 	}
+
+	for _, spc := range cs.SettingsProfiles {
+		status := StatusAltered
+		switch {
+		case spc.Add != nil:
+			status = StatusAdded
+		case spc.Drop:
+			status = StatusDropped
+		}
+		i := add("", spc.Name, KindSettingsProfile, status, fieldChangesForSettingsProfile(spc))
+		out[i].Error = spc.Error
+	}
+
+	for _, fc := range cs.Functions {
+		status := StatusAltered
+		switch {
+		case fc.Add != nil:
+			status = StatusAdded
+		case fc.Drop:
+			status = StatusDropped
+		}
+		var fields []FieldChange
+		if fc.AsChange != nil {
+			fields = append(fields, stringChangeField("as", fc.AsChange))
+		}
+		add("", fc.Name, KindFunction, status, fields)
+	}
 	return out
 }
 
@@ -181,6 +210,12 @@ func SummarizeComparisons(objs []ObjectComparison) CompareSummary {
 		case KindNamedCollection:
 			s.NamedCollectionsChanged++
 			continue
+		case KindSettingsProfile:
+			s.SettingsProfilesChanged++
+			continue
+		case KindFunction:
+			s.FunctionsChanged++
+			continue
 		default:
 			continue
 		}
@@ -219,6 +254,12 @@ func (s CompareSummary) OneLiner() string {
 	if s.NamedCollectionsChanged > 0 {
 		parts = append(parts, fmt.Sprintf("~%d named_collection", s.NamedCollectionsChanged))
 	}
+	if s.SettingsProfilesChanged > 0 {
+		parts = append(parts, fmt.Sprintf("~%d settings_profile", s.SettingsProfilesChanged))
+	}
+	if s.FunctionsChanged > 0 {
+		parts = append(parts, fmt.Sprintf("~%d function", s.FunctionsChanged))
+	}
 	if len(parts) == 0 {
 		return "changed"
 	}
@@ -299,7 +340,8 @@ func fieldChangesForTable(td TableDiff) []FieldChange {
 }
 
 // fieldChangesForMaterializedView flattens an MV diff. A structural change
-// (to_table / columns) implies recreation; query-only maps to MODIFY QUERY.
+// (to_table / columns / inner) implies recreation; query-only maps to MODIFY QUERY and
+// a schedule-only refresh change to MODIFY REFRESH.
 func fieldChangesForMaterializedView(mvd MaterializedViewDiff) []FieldChange {
 	var out []FieldChange
 	if c := mvd.ToTableChange; c != nil {
@@ -308,6 +350,12 @@ func fieldChangesForMaterializedView(mvd MaterializedViewDiff) []FieldChange {
 	if mvd.ColumnsChanged {
 		out = append(out, FieldChange{Field: "columns", Change: "modify"})
 	}
+	if c := mvd.InnerChange; c != nil {
+		out = append(out, stringChangeField("inner", c))
+	}
+	if c := mvd.RefreshChange; c != nil {
+		out = append(out, stringChangeField("refresh", c))
+	}
 	if c := mvd.QueryChange; c != nil {
 		out = append(out, stringChangeField("query", c))
 	}
@@ -374,6 +422,26 @@ func fieldChangesForNamedCollection(ncc NamedCollectionChange) []FieldChange {
 	return out
 }
 
+// fieldChangesForSettingsProfile lists the changed settings (new value
+// rendered as its SETTINGS element), then the inherit and TO transitions.
+func fieldChangesForSettingsProfile(spc SettingsProfileChange) []FieldChange {
+	var out []FieldChange
+	for _, s := range spc.SetSettings {
+		elem := settingsProfileElementsSQL(SettingsProfileSpec{Settings: []SettingsProfileSetting{s}})
+		out = append(out, FieldChange{Field: "setting:" + s.Name, Change: "modify", New: elem})
+	}
+	for _, name := range spc.DropSettings {
+		out = append(out, FieldChange{Field: "setting:" + name, Change: "drop"})
+	}
+	if c := spc.InheritChange; c != nil {
+		out = append(out, stringChangeField("inherit", c))
+	}
+	if c := spc.ToChange; c != nil {
+		out = append(out, stringChangeField("to", c))
+	}
+	return out
+}
+
 // stringChangeField maps an optional-string transition to a FieldChange; a
 // nil side stays empty and is omitted from JSON.
 func stringChangeField(field string, c *StringChange) FieldChange {
@@ -414,5 +482,8 @@ func columnDesc(c ColumnSpec) string {
 	if c.Comment != nil {
 		t += " COMMENT " + *c.Comment
 	}
+	if c.Deprecated {
+		t += " DEPRECATED"
+	}
 	return t
 }
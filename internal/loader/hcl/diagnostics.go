@@ -0,0 +1,66 @@
+package hcl
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// DiagnosticsError is a load error carrying the HCL diagnostics behind it, so
+// callers can report each problem at its file:line:column instead of one
+// formatted blob. Its Error text is unchanged from the formatted diagnostics.
+type DiagnosticsError struct {
+	Diags hcl.Diagnostics
+	text  string
+}
+
+func (e *DiagnosticsError) Error() string { return e.text }
+
+// diagnosticsError wraps diags with their plain one-line rendering.
+func diagnosticsError(diags hcl.Diagnostics) error {
+	return &DiagnosticsError{Diags: diags, text: diags.Error()}
+}
+
+// Problem is one located load error: where it is, and what is wrong there.
+type Problem struct {
+	File   string
+	Line   int
+	Column int
+	// Message is the diagnostic summary and detail, e.g. `Unsupported
+	// argument: An argument named "zoo" is not expected here.`
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.File == "" {
+		return p.Message
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", p.File, p.Line, p.Column, p.Message)
+}
+
+// Problems returns the located errors behind err, one per HCL error
+// diagnostic, or nil when err carries no diagnostics (a merge conflict, a
+// resolve failure) and should be reported as is.
+func Problems(err error) []Problem {
+	var de *DiagnosticsError
+	if !errors.As(err, &de) {
+		return nil
+	}
+	var out []Problem
+	for _, d := range de.Diags {
+		if d.Severity != hcl.DiagError {
+			continue
+		}
+		msg := d.Summary
+		if d.Detail != "" {
+			msg += ": " + d.Detail
+		}
+		p := Problem{Message: msg}
+		if d.Subject != nil {
+			p.File, p.Line, p.Column = d.Subject.Filename, d.Subject.Start.Line, d.Subject.Start.Column
+		}
+		out = append(out, p)
+	}
+	return out
+}
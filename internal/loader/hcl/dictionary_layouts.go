@@ -3,6 +3,7 @@ package hcl
 import (
 	"fmt"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
 )
 
@@ -117,34 +118,40 @@ func (LayoutDirect) Kind() string { return "direct" }
 // DecodeDictionaryLayout dispatches on spec.Kind and decodes the body into
 // the matching typed layout struct. Returns (nil, nil) when spec is nil.
 func DecodeDictionaryLayout(spec *DictionaryLayoutSpec) (DictionaryLayout, error) {
+	return decodeDictionaryLayout(spec, nil)
+}
+
+// decodeDictionaryLayout is DecodeDictionaryLayout with ctx (variables and functions) available to the body's
+// expressions; the parser passes the file's evaluation context.
+func decodeDictionaryLayout(spec *DictionaryLayoutSpec, ctx *hcl.EvalContext) (DictionaryLayout, error) {
 	if spec == nil {
 		return nil, nil
 	}
 	switch spec.Kind {
 	case "flat":
 		var l LayoutFlat
-		if d := gohcl.DecodeBody(spec.Body, nil, &l); d.HasErrors() {
-			return nil, fmt.Errorf("layout flat: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &l); d.HasErrors() {
+			return nil, fmt.Errorf("layout flat: %w", diagnosticsError(d))
 		}
 		return l, nil
 	case "hashed":
 		var l LayoutHashed
-		if d := gohcl.DecodeBody(spec.Body, nil, &l); d.HasErrors() {
-			return nil, fmt.Errorf("layout hashed: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &l); d.HasErrors() {
+			return nil, fmt.Errorf("layout hashed: %w", diagnosticsError(d))
 		}
 		return l, nil
 	case "sparse_hashed":
 		var l LayoutSparseHashed
-		if d := gohcl.DecodeBody(spec.Body, nil, &l); d.HasErrors() {
-			return nil, fmt.Errorf("layout sparse_hashed: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &l); d.HasErrors() {
+			return nil, fmt.Errorf("layout sparse_hashed: %w", diagnosticsError(d))
 		}
 		return l, nil
 	case "regexp_tree":
 		return LayoutRegexpTree{}, nil
 	case "complex_key_sparse_hashed":
 		var l LayoutComplexKeySparseHashed
-		if d := gohcl.DecodeBody(spec.Body, nil, &l); d.HasErrors() {
-			return nil, fmt.Errorf("layout complex_key_sparse_hashed: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &l); d.HasErrors() {
+			return nil, fmt.Errorf("layout complex_key_sparse_hashed: %w", diagnosticsError(d))
 		}
 		return l, nil
 	case "direct":
@@ -153,50 +160,50 @@ func DecodeDictionaryLayout(spec *DictionaryLayoutSpec) (DictionaryLayout, error
 		return LayoutComplexKeyDirect{}, nil
 	case "complex_key_hashed":
 		var l LayoutComplexKeyHashed
-		if d := gohcl.DecodeBody(spec.Body, nil, &l); d.HasErrors() {
-			return nil, fmt.Errorf("layout complex_key_hashed: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &l); d.HasErrors() {
+			return nil, fmt.Errorf("layout complex_key_hashed: %w", diagnosticsError(d))
 		}
 		return l, nil
 	case "range_hashed":
 		var l LayoutRangeHashed
-		if d := gohcl.DecodeBody(spec.Body, nil, &l); d.HasErrors() {
-			return nil, fmt.Errorf("layout range_hashed: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &l); d.HasErrors() {
+			return nil, fmt.Errorf("layout range_hashed: %w", diagnosticsError(d))
 		}
 		return l, nil
 	case "complex_key_range_hashed":
 		var l LayoutComplexKeyRangeHashed
-		if d := gohcl.DecodeBody(spec.Body, nil, &l); d.HasErrors() {
-			return nil, fmt.Errorf("layout complex_key_range_hashed: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &l); d.HasErrors() {
+			return nil, fmt.Errorf("layout complex_key_range_hashed: %w", diagnosticsError(d))
 		}
 		return l, nil
 	case "cache":
 		var l LayoutCache
-		if d := gohcl.DecodeBody(spec.Body, nil, &l); d.HasErrors() {
-			return nil, fmt.Errorf("layout cache: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &l); d.HasErrors() {
+			return nil, fmt.Errorf("layout cache: %w", diagnosticsError(d))
 		}
 		return l, nil
 	case "complex_key_cache":
 		var l LayoutComplexKeyCache
-		if d := gohcl.DecodeBody(spec.Body, nil, &l); d.HasErrors() {
-			return nil, fmt.Errorf("layout complex_key_cache: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &l); d.HasErrors() {
+			return nil, fmt.Errorf("layout complex_key_cache: %w", diagnosticsError(d))
 		}
 		return l, nil
 	case "hashed_array":
 		var l LayoutHashedArray
-		if d := gohcl.DecodeBody(spec.Body, nil, &l); d.HasErrors() {
-			return nil, fmt.Errorf("layout hashed_array: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &l); d.HasErrors() {
+			return nil, fmt.Errorf("layout hashed_array: %w", diagnosticsError(d))
 		}
 		return l, nil
 	case "complex_key_hashed_array":
 		var l LayoutComplexKeyHashedArray
-		if d := gohcl.DecodeBody(spec.Body, nil, &l); d.HasErrors() {
-			return nil, fmt.Errorf("layout complex_key_hashed_array: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &l); d.HasErrors() {
+			return nil, fmt.Errorf("layout complex_key_hashed_array: %w", diagnosticsError(d))
 		}
 		return l, nil
 	case "ip_trie":
 		var l LayoutIPTrie
-		if d := gohcl.DecodeBody(spec.Body, nil, &l); d.HasErrors() {
-			return nil, fmt.Errorf("layout ip_trie: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &l); d.HasErrors() {
+			return nil, fmt.Errorf("layout ip_trie: %w", diagnosticsError(d))
 		}
 		return l, nil
 	default:
@@ -3,8 +3,6 @@ package hcl
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 	"testing"
 
@@ -20,8 +18,8 @@ func TestCHLive_Dictionary_ApplyRoundTrip(t *testing.T) {
 	if !*clickhouseLive {
 		t.Skip("pass -clickhouse to run against a live ClickHouse")
 	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 
 	require.NoError(t, conn.Exec(ctx, fmt.Sprintf(
@@ -82,8 +80,8 @@ func TestCHLive_Dictionary_AlterAppliesCreateOrReplace(t *testing.T) {
 	if !*clickhouseLive {
 		t.Skip("pass -clickhouse to run against a live ClickHouse")
 	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 
 	require.NoError(t, conn.Exec(ctx, fmt.Sprintf(
@@ -139,8 +137,8 @@ func TestCHLive_Dictionary_LayoutMatrix(t *testing.T) {
 	if !*clickhouseLive {
 		t.Skip("pass -clickhouse to run against a live ClickHouse")
 	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 
 	require.NoError(t, conn.Exec(ctx, fmt.Sprintf(
@@ -296,8 +294,8 @@ func TestCHLive_Dictionary_LifetimeForms(t *testing.T) {
 	if !*clickhouseLive {
 		t.Skip("pass -clickhouse to run against a live ClickHouse")
 	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 
 	require.NoError(t, conn.Exec(ctx, fmt.Sprintf(
@@ -360,8 +358,8 @@ func TestCHLive_Dictionary_AttributeFlags(t *testing.T) {
 	if !*clickhouseLive {
 		t.Skip("pass -clickhouse to run against a live ClickHouse")
 	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 
 	require.NoError(t, conn.Exec(ctx, fmt.Sprintf(
@@ -405,57 +403,6 @@ func TestCHLive_Dictionary_AttributeFlags(t *testing.T) {
 	}
 }
 
-// TestCHLive_Dictionary_PosthogFixtures walks every fixture in
-// test/testdata/posthog-create-statements/Dictionary/, rewrites the database
-// reference to an isolated test DB, applies the statement, and asserts the
-// resulting dictionary introspects without error. Validates our introspect
-// path against real-world PostHog dictionary shapes.
-func TestCHLive_Dictionary_PosthogFixtures(t *testing.T) {
-	if !*clickhouseLive {
-		t.Skip("pass -clickhouse to run against a live ClickHouse")
-	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
-	ctx := context.Background()
-
-	// The fixtures reference tables in `default`. The dictionary CREATE itself
-	// doesn't require the source table to exist (it loads lazily), so we
-	// rewrite `default.` to <dbName>. and `default.tableX` keys aren't strictly
-	// required for introspection — but we DO need the dictionary's own qualifier
-	// to be in dbName so Introspect picks it up.
-	fixtureDir := filepath.Join("..", "..", "..", "test", "testdata", "posthog-create-statements", "Dictionary")
-	entries, err := os.ReadDir(fixtureDir)
-	require.NoError(t, err, "fixture dir: %s", fixtureDir)
-
-	count := 0
-	for _, e := range entries {
-		if !strings.HasSuffix(e.Name(), ".sql") {
-			continue
-		}
-		name := e.Name()
-		t.Run(strings.TrimSuffix(name, ".sql"), func(t *testing.T) {
-			body, err := os.ReadFile(filepath.Join(fixtureDir, name))
-			require.NoError(t, err)
-			sql := string(body)
-			// Rewrite the dictionary's own database qualifier (DICTIONARY default.X)
-			// to dbName so it lands in the isolated test database. References inside
-			// the QUERY remain pointing at `default.*`; that's fine since ClickHouse
-			// doesn't validate the SOURCE QUERY at CREATE time.
-			sql = strings.Replace(sql, "DICTIONARY default.", "DICTIONARY "+dbName+".", 1)
-			// Strip [HIDDEN] passwords to empty so the CREATE statement is valid.
-			sql = strings.ReplaceAll(sql, "PASSWORD '[HIDDEN]'", "PASSWORD ''")
-
-			require.NoError(t, conn.Exec(ctx, sql), "fixture %s rejected:\n%s", name, sql)
-			count++
-		})
-	}
-
-	// One Introspect call covers all fixtures created above.
-	db, err := Introspect(ctx, conn, dbName, false)
-	require.NoError(t, err, "Introspect failed on posthog fixtures")
-	assert.Len(t, db.Dictionaries, count, "all %d fixture dictionaries should round-trip through Introspect", count)
-}
-
 // findDictByName is a small linear search helper for live tests.
 func findDictByName(ds []DictionarySpec, name string) *DictionarySpec {
 	for i := range ds {
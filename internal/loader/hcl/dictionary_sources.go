@@ -3,6 +3,7 @@ package hcl
 import (
 	"fmt"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
 )
 
@@ -140,44 +141,50 @@ func dictSecretIsRedacted(s DictionarySource) (field string, redacted bool) {
 // DecodeDictionarySource dispatches on spec.Kind and decodes the body into
 // the matching typed source struct. Returns (nil, nil) when spec is nil.
 func DecodeDictionarySource(spec *DictionarySourceSpec) (DictionarySource, error) {
+	return decodeDictionarySource(spec, nil)
+}
+
+// decodeDictionarySource is DecodeDictionarySource with ctx (variables and functions) available to the body's
+// expressions; the parser passes the file's evaluation context.
+func decodeDictionarySource(spec *DictionarySourceSpec, ctx *hcl.EvalContext) (DictionarySource, error) {
 	if spec == nil {
 		return nil, nil
 	}
 	switch spec.Kind {
 	case "clickhouse":
 		var s SourceClickHouse
-		if d := gohcl.DecodeBody(spec.Body, nil, &s); d.HasErrors() {
-			return nil, fmt.Errorf("source clickhouse: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &s); d.HasErrors() {
+			return nil, fmt.Errorf("source clickhouse: %w", diagnosticsError(d))
 		}
 		return s, nil
 	case "mysql":
 		var s SourceMySQL
-		if d := gohcl.DecodeBody(spec.Body, nil, &s); d.HasErrors() {
-			return nil, fmt.Errorf("source mysql: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &s); d.HasErrors() {
+			return nil, fmt.Errorf("source mysql: %w", diagnosticsError(d))
 		}
 		return s, nil
 	case "postgresql":
 		var s SourcePostgreSQL
-		if d := gohcl.DecodeBody(spec.Body, nil, &s); d.HasErrors() {
-			return nil, fmt.Errorf("source postgresql: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &s); d.HasErrors() {
+			return nil, fmt.Errorf("source postgresql: %w", diagnosticsError(d))
 		}
 		return s, nil
 	case "http":
 		var s SourceHTTP
-		if d := gohcl.DecodeBody(spec.Body, nil, &s); d.HasErrors() {
-			return nil, fmt.Errorf("source http: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &s); d.HasErrors() {
+			return nil, fmt.Errorf("source http: %w", diagnosticsError(d))
 		}
 		return s, nil
 	case "file":
 		var s SourceFile
-		if d := gohcl.DecodeBody(spec.Body, nil, &s); d.HasErrors() {
-			return nil, fmt.Errorf("source file: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &s); d.HasErrors() {
+			return nil, fmt.Errorf("source file: %w", diagnosticsError(d))
 		}
 		return s, nil
 	case "executable":
 		var s SourceExecutable
-		if d := gohcl.DecodeBody(spec.Body, nil, &s); d.HasErrors() {
-			return nil, fmt.Errorf("source executable: %s", d.Error())
+		if d := gohcl.DecodeBody(spec.Body, ctx, &s); d.HasErrors() {
+			return nil, fmt.Errorf("source executable: %w", diagnosticsError(d))
 		}
 		return s, nil
 	case "null":
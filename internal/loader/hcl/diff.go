@@ -1,6 +1,7 @@
 package hcl
 
 import (
+	"log/slog"
 	"reflect"
 	"sort"
 	"strings"
@@ -9,8 +10,21 @@ import (
 // ChangeSet describes the changes required to evolve a `from` schema into a
 // `to` schema. Empty databases (no tables to add/drop/alter) are omitted.
 type ChangeSet struct {
+	// DefaultOnCluster is the target schema's project-wide ON CLUSTER
+	// default, carried through to GenerateSQL.
+	DefaultOnCluster *string
+	// Experimental is the target schema's enabled feature list; see
+	// Schema.Experimental.
+	Experimental []string
+	// IfExists makes GenerateSQL guard every statement that fails when re-run
+	// (see guardStatement), so a partially applied migration can be run again
+	// from the top. Set by the caller; Diff leaves it false.
+	IfExists bool
+
 	Databases        []DatabaseChange
 	NamedCollections []NamedCollectionChange
+	SettingsProfiles []SettingsProfileChange
+	Functions        []FunctionChange
 }
 
 // DatabaseChange holds the per-database differences.
@@ -88,15 +102,23 @@ func (d DictionaryDiff) IsUnsafe() bool { return false }
 type MaterializedViewDiff struct {
 	Name        string
 	QueryChange *StringChange // the AS SELECT body changed
-	Recreate    bool          // to_table or the column list changed
+	Recreate    bool          // to_table, the column list, refreshability or APPEND changed
+
+	// RefreshChange is the rendered schedule before/after (nil side = not
+	// refreshable). A schedule-only change maps to MODIFY REFRESH with
+	// NewRefresh; gaining or losing the schedule, or flipping APPEND, forces
+	// Recreate.
+	RefreshChange *StringChange
+	NewRefresh    *RefreshSpec
 
 	// Set alongside Recreate so consumers can tell WHAT forced it.
 	ToTableChange  *StringChange
 	ColumnsChanged bool
+	InnerChange    *StringChange // rendered inner storage, old/new (nil side = TO form)
 }
 
 func (mvd MaterializedViewDiff) IsEmpty() bool {
-	return mvd.QueryChange == nil && !mvd.Recreate
+	return mvd.QueryChange == nil && mvd.RefreshChange == nil && !mvd.Recreate
 }
 
 // IsUnsafe reports whether the diff requires recreating the view (ClickHouse
@@ -232,7 +254,8 @@ func columnsEqual(a, b ColumnSpec) bool {
 		eqStrPtr(a.Alias, b.Alias) &&
 		eqStrPtr(a.Codec, b.Codec) &&
 		eqStrPtr(a.TTL, b.TTL) &&
-		eqStrPtr(a.Comment, b.Comment)
+		eqStrPtr(a.Comment, b.Comment) &&
+		a.Deprecated == b.Deprecated
 }
 
 func eqStrPtr(a, b *string) bool {
@@ -285,6 +308,16 @@ func (cs ChangeSet) IsEmpty() bool {
 			return false
 		}
 	}
+	for _, spc := range cs.SettingsProfiles {
+		if !spc.IsEmpty() {
+			return false
+		}
+	}
+	for _, fc := range cs.Functions {
+		if !fc.IsEmpty() {
+			return false
+		}
+	}
 	return true
 }
 
@@ -357,7 +390,7 @@ func Diff(from, to *Schema) ChangeSet {
 		case !fOK:
 			dc = DatabaseChange{
 				Database:             name,
-				AddTables:            append([]TableSpec(nil), t.Tables...),
+				AddTables:            managedTables(name, t.Tables),
 				AddMaterializedViews: append([]MaterializedViewSpec(nil), t.MaterializedViews...),
 				AddViews:             append([]ViewSpec(nil), t.Views...),
 				AddDictionaries:      append([]DictionarySpec(nil), t.Dictionaries...),
@@ -365,7 +398,7 @@ func Diff(from, to *Schema) ChangeSet {
 			}
 		case !tOK:
 			dc = DatabaseChange{Database: name}
-			dc.DropTables = append(dc.DropTables, f.Tables...)
+			dc.DropTables = managedTables(name, f.Tables)
 			for _, mv := range f.MaterializedViews {
 				dc.DropMaterializedViews = append(dc.DropMaterializedViews, mv.Name)
 			}
@@ -386,9 +419,47 @@ func Diff(from, to *Schema) ChangeSet {
 		cs.Databases = append(cs.Databases, dc)
 	}
 	cs.NamedCollections = diffNamedCollections(from.NamedCollections, to.NamedCollections)
+	cs.SettingsProfiles = diffSettingsProfiles(from.SettingsProfiles, to.SettingsProfiles)
+	cs.Functions = diffFunctions(from.Functions, to.Functions)
+	cs.DefaultOnCluster = to.DefaultOnCluster
+	cs.Experimental = to.Experimental
 	return cs
 }
 
+// managedTables returns tables minus those on an unmanaged engine. hclexp
+// cannot reason about an engine it does not model, so such a table is never
+// created, dropped or altered — only reported, so the gap is not silent.
+func managedTables(database string, tables []TableSpec) []TableSpec {
+	var out []TableSpec
+	for i := range tables {
+		if isUnmanagedTable(&tables[i]) {
+			slog.Warn("skipping table with unmanaged engine", "table", database+"."+tables[i].Name)
+			continue
+		}
+		out = append(out, tables[i])
+	}
+	return out
+}
+
+func isUnmanagedTable(t *TableSpec) bool {
+	if t == nil {
+		return false
+	}
+	_, ok := engineOf(*t).(EngineUnmanaged)
+	return ok
+}
+
+func mergedTableNames(a, b map[string]*TableSpec) map[string]bool {
+	out := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		out[k] = true
+	}
+	for k := range b {
+		out[k] = true
+	}
+	return out
+}
+
 func indexDatabases(dbs []DatabaseSpec) map[string]*DatabaseSpec {
 	out := make(map[string]*DatabaseSpec, len(dbs))
 	for i := range dbs {
@@ -418,6 +489,15 @@ func diffDatabase(name string, from, to *DatabaseSpec, fromR, toR TableResolver)
 
 	fromTables := indexTables(from.Tables)
 	toTables := indexTables(to.Tables)
+	// A table unmanaged on either side is left alone on both: diffing it
+	// against a typed engine would plan a CREATE over an existing table.
+	for n := range mergedTableNames(fromTables, toTables) {
+		if isUnmanagedTable(fromTables[n]) || isUnmanagedTable(toTables[n]) {
+			slog.Warn("skipping table with unmanaged engine", "table", name+"."+n)
+			delete(fromTables, n)
+			delete(toTables, n)
+		}
+	}
 
 	for _, n := range sortedKeys(toTables) {
 		if _, ok := fromTables[n]; !ok {
@@ -701,9 +781,11 @@ func indexMaterializedViews(mvs []MaterializedViewSpec) map[string]*Materialized
 }
 
 // diffMaterializedView compares two materialized views with the same name. A
-// changed to_table or column list can't be applied in place, so it sets
-// Recreate; an otherwise-identical view with a changed query yields a
-// QueryChange that maps to ALTER TABLE ... MODIFY QUERY. Recreate supersedes
+// changed to_table, column list or inner storage can't be applied in place, so it sets
+// Recreate, as does a view becoming or ceasing to be refreshable or flipping
+// APPEND; an otherwise-identical view with a changed query yields a
+// QueryChange that maps to ALTER TABLE ... MODIFY QUERY, and a changed refresh
+// schedule a RefreshChange that maps to MODIFY REFRESH. Recreate supersedes
 // QueryChange — the two are mutually exclusive.
 func diffMaterializedView(from, to *MaterializedViewSpec) MaterializedViewDiff {
 	mvd := MaterializedViewDiff{Name: to.Name}
@@ -714,7 +796,16 @@ func diffMaterializedView(from, to *MaterializedViewSpec) MaterializedViewDiff {
 	if !reflect.DeepEqual(from.Columns, to.Columns) {
 		mvd.ColumnsChanged = true
 	}
-	if mvd.ToTableChange != nil || mvd.ColumnsChanged {
+	if o, n := innerText(from.Inner), innerText(to.Inner); !reflect.DeepEqual(o, n) {
+		mvd.InnerChange = &StringChange{Old: o, New: n}
+	}
+	if o, n := refreshText(from.Refresh), refreshText(to.Refresh); !reflect.DeepEqual(o, n) {
+		mvd.RefreshChange = &StringChange{Old: o, New: n}
+		mvd.NewRefresh = to.Refresh
+	}
+	refreshRecreate := mvd.RefreshChange != nil &&
+		(from.Refresh == nil || to.Refresh == nil || from.Refresh.Append != to.Refresh.Append)
+	if mvd.ToTableChange != nil || mvd.ColumnsChanged || mvd.InnerChange != nil || refreshRecreate {
 		mvd.Recreate = true
 		return mvd
 	}
@@ -725,6 +816,29 @@ func diffMaterializedView(from, to *MaterializedViewSpec) MaterializedViewDiff {
 	return mvd
 }
 
+// innerText renders an inner-engine MV's storage for comparison and
+// reporting, or nil for the TO form.
+func innerText(s *MVInnerSpec) *string {
+	if s == nil {
+		return nil
+	}
+	r := strings.TrimSpace(storageClauseSQL(s.table()))
+	return &r
+}
+
+// refreshText renders a refresh schedule for comparison and reporting, or nil
+// for an ordinary MV.
+func refreshText(r *RefreshSpec) *string {
+	if r == nil {
+		return nil
+	}
+	s := refreshClauseSQL(*r)
+	if r.Append {
+		s += " APPEND"
+	}
+	return &s
+}
+
 func indexTables(tables []TableSpec) map[string]*TableSpec {
 	out := make(map[string]*TableSpec, len(tables))
 	for i := range tables {
@@ -96,6 +96,36 @@ func TestDiff_AddTable(t *testing.T) {
 	assert.Equal(t, expected, cs)
 }
 
+// TestDiff_UnmanagedEngineSkipped: a table on an unmanaged engine is never
+// added, dropped or altered, whichever side carries it.
+func TestDiff_UnmanagedEngineSkipped(t *testing.T) {
+	rocks := EngineUnmanaged{Name: "EmbeddedRocksDB", Full: "EmbeddedRocksDB()"}
+	id := ColumnSpec{Name: "id", Type: "UUID"}
+	v := ColumnSpec{Name: "v", Type: "String"}
+
+	cases := []struct {
+		name     string
+		from, to []DatabaseSpec
+	}{
+		{"add", []DatabaseSpec{mkDB("posthog")}, []DatabaseSpec{mkDB("posthog", mkTable("kv", rocks, id))}},
+		{"drop", []DatabaseSpec{mkDB("posthog", mkTable("kv", rocks, id))}, []DatabaseSpec{mkDB("posthog")}},
+		{"alter", []DatabaseSpec{mkDB("posthog", mkTable("kv", rocks, id))}, []DatabaseSpec{mkDB("posthog", mkTable("kv", rocks, id, v))}},
+		{"typed desired", []DatabaseSpec{mkDB("posthog", mkTable("kv", rocks, id))}, []DatabaseSpec{mkDB("posthog", mkTable("kv", EngineMergeTree{}, id))}},
+		{"new database", nil, []DatabaseSpec{mkDB("posthog", mkTable("kv", rocks, id))}},
+		{"dropped database", []DatabaseSpec{mkDB("posthog", mkTable("kv", rocks, id))}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cs := Diff(&Schema{Databases: c.from}, &Schema{Databases: c.to})
+			for _, dc := range cs.Databases {
+				assert.Empty(t, dc.AddTables)
+				assert.Empty(t, dc.DropTables)
+				assert.Empty(t, dc.AlterTables)
+			}
+		})
+	}
+}
+
 func TestDiff_DropTable(t *testing.T) {
 	from := []DatabaseSpec{mkDB("posthog", mkTable("events", EngineMergeTree{}, ColumnSpec{Name: "id", Type: "UUID"}))}
 	to := []DatabaseSpec{mkDB("posthog")}
@@ -506,6 +536,78 @@ func TestDiff_AlterMaterializedViewToTableRecreate(t *testing.T) {
 	assert.Nil(t, mvd.QueryChange)
 }
 
+func TestDiff_AlterMaterializedViewInnerEngineRecreate(t *testing.T) {
+	mkInner := func(orderBy string) MaterializedViewSpec {
+		mv := mkMV("metrics_mv", "", "SELECT id FROM default.src")
+		mv.Inner = &MVInnerSpec{
+			Engine:  &EngineSpec{Kind: "merge_tree", Decoded: EngineMergeTree{}},
+			OrderBy: []string{orderBy},
+		}
+		return mv
+	}
+	from := []DatabaseSpec{mkDBWithMVs("posthog", mkInner("id"))}
+	to := []DatabaseSpec{mkDBWithMVs("posthog", mkInner("ts"))}
+
+	cs := Diff(&Schema{Databases: from}, &Schema{Databases: to})
+	require.Len(t, cs.Databases, 1)
+	require.Len(t, cs.Databases[0].AlterMaterializedViews, 1)
+	mvd := cs.Databases[0].AlterMaterializedViews[0]
+	assert.True(t, mvd.Recreate)
+	require.NotNil(t, mvd.InnerChange)
+	assert.Contains(t, *mvd.InnerChange.New, "ORDER BY (ts)")
+
+	out := GenerateSQL(cs)
+	require.Len(t, out.Unsafe, 1)
+	assert.Contains(t, out.Unsafe[0].Reason, "inner engine")
+}
+
+func TestDiff_AlterMaterializedViewRefreshSchedule(t *testing.T) {
+	mkRefreshing := func(every string) MaterializedViewSpec {
+		mv := mkMV("daily_mv", "default.daily", "SELECT 1")
+		mv.Refresh = &RefreshSpec{Every: ptr(every)}
+		return mv
+	}
+	from := []DatabaseSpec{mkDBWithMVs("posthog", mkRefreshing("1 DAY"))}
+	to := []DatabaseSpec{mkDBWithMVs("posthog", mkRefreshing("1 HOUR"))}
+
+	cs := Diff(&Schema{Databases: from}, &Schema{Databases: to})
+	require.Len(t, cs.Databases, 1)
+	require.Len(t, cs.Databases[0].AlterMaterializedViews, 1)
+	mvd := cs.Databases[0].AlterMaterializedViews[0]
+	assert.False(t, mvd.Recreate, "a schedule change applies in place")
+	assert.Equal(t, &StringChange{Old: ptr("REFRESH EVERY 1 DAY"), New: ptr("REFRESH EVERY 1 HOUR")}, mvd.RefreshChange)
+
+	out := GenerateSQL(cs)
+	assert.Equal(t, []string{"ALTER TABLE posthog.daily_mv MODIFY REFRESH EVERY 1 HOUR"}, out.Statements)
+	assert.Empty(t, out.Unsafe)
+}
+
+func TestDiff_AlterMaterializedViewRefreshabilityRecreate(t *testing.T) {
+	plain := mkMV("daily_mv", "default.daily", "SELECT 1")
+	refreshing := plain
+	refreshing.Refresh = &RefreshSpec{Every: ptr("1 DAY")}
+	appending := plain
+	appending.Refresh = &RefreshSpec{Every: ptr("1 DAY"), Append: true}
+
+	for name, pair := range map[string][2]MaterializedViewSpec{
+		"gain refresh": {plain, refreshing},
+		"lose refresh": {refreshing, plain},
+		"flip append":  {refreshing, appending},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cs := Diff(&Schema{Databases: []DatabaseSpec{mkDBWithMVs("posthog", pair[0])}},
+				&Schema{Databases: []DatabaseSpec{mkDBWithMVs("posthog", pair[1])}})
+			require.Len(t, cs.Databases, 1)
+			mvd := cs.Databases[0].AlterMaterializedViews[0]
+			assert.True(t, mvd.Recreate)
+			assert.NotNil(t, mvd.RefreshChange)
+			out := GenerateSQL(cs)
+			assert.Empty(t, out.Statements)
+			require.Len(t, out.Unsafe, 1)
+		})
+	}
+}
+
 func TestDiff_IdenticalMaterializedViewsEmpty(t *testing.T) {
 	mv := mkMV("metrics_mv", "default.metrics", "SELECT id FROM default.src")
 	cs := Diff(
@@ -11,7 +11,26 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
-// Write emits dbs as canonical HCL. Tables are sorted alphabetically; columns,
+// WriteOpts is Write with opts applied: with opts.Metadata set, the HCL is
+// preceded by the metadata's comment header, and with opts.Secrets set engine
+// credentials are written in plaintext.
+func WriteOpts(w io.Writer, schema *Schema, opts WriteOptions) error {
+	if schema == nil {
+		return errors.New("Write: nil schema")
+	}
+	if opts.Metadata != nil {
+		if _, err := w.Write(opts.Metadata.header(schema)); err != nil {
+			return err
+		}
+	}
+	if !opts.Secrets {
+		schema = redactSecrets(schema)
+	}
+	return write(w, schema)
+}
+
+// Write emits dbs as canonical HCL, with engine credentials redacted (see
+// WriteOptions.Secrets). Tables are sorted alphabetically; columns,
 // indexes, and engine fields keep their structural order. Settings entries
 // are sorted by key.
 //
@@ -19,12 +38,23 @@ import (
 // override are consumed, patches applied, engines decoded. Fields tagged
 // diff:"-" in the type definitions are intentionally never emitted.
 func Write(w io.Writer, schema *Schema) error {
-	if schema == nil {
-		return errors.New("Write: nil schema")
-	}
+	return WriteOpts(w, schema, WriteOptions{})
+}
+
+func write(w io.Writer, schema *Schema) error {
 	f := hclwrite.NewEmptyFile()
 	body := f.Body()
 
+	if schema.DefaultOnCluster != nil {
+		body.SetAttributeValue("default_on_cluster", cty.StringVal(*schema.DefaultOnCluster))
+	}
+	if schema.Experimental != nil {
+		body.SetAttributeValue("experimental", stringList(schema.Experimental))
+	}
+	if schema.DefaultOnCluster != nil || schema.Experimental != nil {
+		body.AppendNewline()
+	}
+
 	nodes := append([]NodeSpec(nil), schema.Nodes...)
 	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
 	for i, n := range nodes {
@@ -35,10 +65,20 @@ func Write(w io.Writer, schema *Schema) error {
 		writeNode(nodeBlock.Body(), n)
 	}
 
-	for i, db := range schema.Databases {
+	clusters := append([]ClusterSpec(nil), schema.Clusters...)
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Name < clusters[j].Name })
+	for i, c := range clusters {
 		if i > 0 || len(nodes) > 0 {
 			body.AppendNewline()
 		}
+		clusterBlock := body.AppendNewBlock("cluster", []string{c.Name})
+		writeCluster(clusterBlock.Body(), c)
+	}
+
+	for i, db := range schema.Databases {
+		if i > 0 || len(nodes) > 0 || len(clusters) > 0 {
+			body.AppendNewline()
+		}
 		dbBlock := body.AppendNewBlock("database", []string{db.Name})
 		writeDatabase(dbBlock.Body(), db)
 	}
@@ -53,6 +93,26 @@ func Write(w io.Writer, schema *Schema) error {
 		writeNamedCollection(ncBlock.Body(), nc)
 	}
 
+	sps := append([]SettingsProfileSpec(nil), schema.SettingsProfiles...)
+	sort.Slice(sps, func(i, j int) bool { return sps[i].Name < sps[j].Name })
+	for i, sp := range sps {
+		if len(schema.Databases) > 0 || len(ncs) > 0 || i > 0 {
+			body.AppendNewline()
+		}
+		spBlock := body.AppendNewBlock("settings_profile", []string{sp.Name})
+		writeSettingsProfile(spBlock.Body(), sp)
+	}
+
+	fns := append([]FunctionSpec(nil), schema.Functions...)
+	sort.Slice(fns, func(i, j int) bool { return fns[i].Name < fns[j].Name })
+	for i, fn := range fns {
+		if len(schema.Databases) > 0 || len(ncs) > 0 || len(sps) > 0 || i > 0 {
+			body.AppendNewline()
+		}
+		fnBlock := body.AppendNewBlock("function", []string{fn.Name})
+		writeFunction(fnBlock.Body(), fn)
+	}
+
 	_, err := w.Write(f.Bytes())
 	return err
 }
@@ -65,6 +125,22 @@ func writeNode(body *hclwrite.Body, n NodeSpec) {
 	}
 }
 
+// writeCluster emits a cluster block. Shards and replicas keep their order:
+// it is their shard_num and replica_num.
+func writeCluster(body *hclwrite.Body, c ClusterSpec) {
+	for _, sh := range c.Shards {
+		sb := body.AppendNewBlock("shard", nil).Body()
+		if sh.Weight != nil {
+			sb.SetAttributeValue("weight", cty.NumberIntVal(int64(*sh.Weight)))
+		}
+		for _, r := range sh.Replicas {
+			rb := sb.AppendNewBlock("replica", nil).Body()
+			rb.SetAttributeValue("host", cty.StringVal(r.Host))
+			rb.SetAttributeValue("port", cty.NumberIntVal(int64(r.Port)))
+		}
+	}
+}
+
 func writeDatabase(body *hclwrite.Body, db DatabaseSpec) {
 	if db.Cluster != nil {
 		body.SetAttributeValue("cluster", cty.StringVal(*db.Cluster))
@@ -115,7 +191,12 @@ func writeDatabase(body *hclwrite.Body, db DatabaseSpec) {
 	}
 
 	raws := append([]RawSpec(nil), db.Raws...)
-	sort.Slice(raws, func(i, j int) bool { return raws[i].Name < raws[j].Name })
+	sort.Slice(raws, func(i, j int) bool {
+		if raws[i].Name != raws[j].Name {
+			return raws[i].Name < raws[j].Name
+		}
+		return raws[i].Kind < raws[j].Kind // two kinds may share a name
+	})
 	for i, r := range raws {
 		if len(tables) > 0 || len(mvs) > 0 || len(views) > 0 || len(dicts) > 0 || i > 0 {
 			body.AppendNewline()
@@ -183,7 +264,9 @@ func writeView(body *hclwrite.Body, v ViewSpec) {
 }
 
 func writeMaterializedView(body *hclwrite.Body, mv MaterializedViewSpec) {
-	body.SetAttributeValue("to_table", cty.StringVal(mv.ToTable))
+	if mv.ToTable != "" {
+		body.SetAttributeValue("to_table", cty.StringVal(mv.ToTable))
+	}
 	setQueryAttribute(body, mv.Query)
 	if mv.Cluster != nil {
 		body.SetAttributeValue("cluster", cty.StringVal(*mv.Cluster))
@@ -191,6 +274,54 @@ func writeMaterializedView(body *hclwrite.Body, mv MaterializedViewSpec) {
 	if mv.Comment != nil {
 		body.SetAttributeValue("comment", cty.StringVal(*mv.Comment))
 	}
+	if r := mv.Refresh; r != nil {
+		rb := body.AppendNewBlock("refresh", nil).Body()
+		if r.Every != nil {
+			rb.SetAttributeValue("every", cty.StringVal(*r.Every))
+		}
+		if r.After != nil {
+			rb.SetAttributeValue("after", cty.StringVal(*r.After))
+		}
+		if r.Offset != nil {
+			rb.SetAttributeValue("offset", cty.StringVal(*r.Offset))
+		}
+		if r.RandomizeFor != nil {
+			rb.SetAttributeValue("randomize_for", cty.StringVal(*r.RandomizeFor))
+		}
+		if len(r.DependsOn) > 0 {
+			rb.SetAttributeValue("depends_on", stringList(r.DependsOn))
+		}
+		if len(r.Settings) > 0 {
+			rb.SetAttributeValue("settings", stringMap(r.Settings))
+		}
+		if r.Append {
+			rb.SetAttributeValue("append", cty.True)
+		}
+	}
+	if in := mv.Inner; in != nil {
+		ib := body.AppendNewBlock("inner", nil).Body()
+		if len(in.PrimaryKey) > 0 {
+			ib.SetAttributeValue("primary_key", stringList(in.PrimaryKey))
+		}
+		if len(in.OrderBy) > 0 {
+			ib.SetAttributeValue("order_by", stringList(in.OrderBy))
+		}
+		if in.PartitionBy != nil {
+			ib.SetAttributeValue("partition_by", cty.StringVal(*in.PartitionBy))
+		}
+		if in.SampleBy != nil {
+			ib.SetAttributeValue("sample_by", cty.StringVal(*in.SampleBy))
+		}
+		if in.TTL != nil {
+			ib.SetAttributeValue("ttl", cty.StringVal(*in.TTL))
+		}
+		if len(in.Settings) > 0 {
+			ib.SetAttributeValue("settings", stringMap(in.Settings))
+		}
+		if in.Engine != nil && in.Engine.Decoded != nil {
+			writeEngine(ib, in.Engine.Decoded)
+		}
+	}
 	for _, c := range mv.Columns {
 		writeColumn(body, c)
 	}
@@ -290,6 +421,9 @@ func writeColumn(parent *hclwrite.Body, c ColumnSpec) {
 	if c.Comment != nil {
 		cb.SetAttributeValue("comment", cty.StringVal(*c.Comment))
 	}
+	if c.Deprecated {
+		cb.SetAttributeValue("deprecated", cty.True)
+	}
 }
 
 func writeEngine(parent *hclwrite.Body, e Engine) {
@@ -297,12 +431,15 @@ func writeEngine(parent *hclwrite.Body, e Engine) {
 	b := block.Body()
 	switch v := e.(type) {
 	case EngineMergeTree, EngineAggregatingMergeTree, EngineLog,
-		EngineNull, EngineMemory:
+		EngineNull, EngineMemory, EngineSet:
 		// no fields
 	case EngineJoin:
 		b.SetAttributeValue("strictness", cty.StringVal(v.Strictness))
 		b.SetAttributeValue("type", cty.StringVal(v.JoinType))
 		b.SetAttributeValue("keys", stringList(v.Keys))
+	case EngineUnmanaged:
+		b.SetAttributeValue("name", cty.StringVal(v.Name))
+		b.SetAttributeValue("full", cty.StringVal(v.Full))
 	case EngineMerge:
 		b.SetAttributeValue("db_regex", cty.StringVal(v.DBRegex))
 		b.SetAttributeValue("table_regex", cty.StringVal(v.TableRegex))
@@ -363,6 +500,20 @@ func writeEngine(parent *hclwrite.Body, e Engine) {
 	case EngineReplicatedAggregatingMergeTree:
 		b.SetAttributeValue("zoo_path", cty.StringVal(v.ZooPath))
 		b.SetAttributeValue("replica_name", cty.StringVal(v.ReplicaName))
+	case EngineVersionedCollapsingMergeTree:
+		b.SetAttributeValue("sign_column", cty.StringVal(v.SignColumn))
+		b.SetAttributeValue("version_column", cty.StringVal(v.VersionColumn))
+	case EngineReplicatedVersionedCollapsingMergeTree:
+		b.SetAttributeValue("zoo_path", cty.StringVal(v.ZooPath))
+		b.SetAttributeValue("replica_name", cty.StringVal(v.ReplicaName))
+		b.SetAttributeValue("sign_column", cty.StringVal(v.SignColumn))
+		b.SetAttributeValue("version_column", cty.StringVal(v.VersionColumn))
+	case EngineGraphiteMergeTree:
+		b.SetAttributeValue("config_section", cty.StringVal(v.ConfigSection))
+	case EngineReplicatedGraphiteMergeTree:
+		b.SetAttributeValue("zoo_path", cty.StringVal(v.ZooPath))
+		b.SetAttributeValue("replica_name", cty.StringVal(v.ReplicaName))
+		b.SetAttributeValue("config_section", cty.StringVal(v.ConfigSection))
 	case EngineDistributed:
 		b.SetAttributeValue("cluster_name", cty.StringVal(v.ClusterName))
 		b.SetAttributeValue("remote_database", cty.StringVal(v.RemoteDatabase))
@@ -425,6 +576,23 @@ func writeEngine(parent *hclwrite.Body, e Engine) {
 		if len(v.Extra) > 0 {
 			b.SetAttributeValue("extra", stringMap(v.Extra))
 		}
+	case EngineS3:
+		writeObjectStorage(b, v.storageArgs())
+	case EngineS3Queue:
+		writeObjectStorage(b, v.storageArgs())
+	case EngineURL:
+		b.SetAttributeValue("url", cty.StringVal(v.URL))
+		if v.Format != nil {
+			b.SetAttributeValue("format", cty.StringVal(*v.Format))
+		}
+		if v.Compression != nil {
+			b.SetAttributeValue("compression", cty.StringVal(*v.Compression))
+		}
+	case EngineFile:
+		b.SetAttributeValue("format", cty.StringVal(v.Format))
+		if v.Compression != nil {
+			b.SetAttributeValue("compression", cty.StringVal(*v.Compression))
+		}
 	case EngineTimeSeries:
 		if len(v.Settings) > 0 {
 			b.SetAttributeValue("settings", stringMap(v.Settings))
@@ -476,6 +644,28 @@ func writeEngine(parent *hclwrite.Body, e Engine) {
 	}
 }
 
+// writeObjectStorage emits the S3/S3Queue engine attributes.
+func writeObjectStorage(b *hclwrite.Body, a objectStorageArgs) {
+	setStr := func(name string, p *string) {
+		if p != nil {
+			b.SetAttributeValue(name, cty.StringVal(*p))
+		}
+	}
+	setStr("collection", a.Collection)
+	if len(a.Overrides) > 0 {
+		b.SetAttributeValue("overrides", stringMap(a.Overrides))
+	}
+	setStr("path", a.Path)
+	if a.NoSign {
+		b.SetAttributeValue("nosign", cty.True)
+	}
+	setStr("access_key_id", a.AccessKeyID)
+	setStr("secret_access_key", a.SecretAccessKey)
+	setStr("session_token", a.SessionToken)
+	setStr("format", a.Format)
+	setStr("compression", a.Compression)
+}
+
 func stringList(items []string) cty.Value {
 	if len(items) == 0 {
 		return cty.ListValEmpty(cty.String)
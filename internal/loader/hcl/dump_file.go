@@ -0,0 +1,156 @@
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile dumps schema to path as canonical HCL without ever leaving a
+// partial file behind. The dump goes to a temp file in path's directory, is
+// fsynced, and is parsed back with ParseFile; only a dump that loads and
+// declares the same objects replaces path, via an atomic rename (the
+// directory is fsynced too where the platform allows, so the rename survives
+// a crash). On any failure the temp file is removed and path keeps its
+// previous content. A path already holding exactly this dump is left
+// untouched, mtime included, so a periodic re-dump of an unchanged schema
+// gives git and make nothing to notice.
+func WriteFile(path string, schema *Schema) error {
+	_, err := WriteFileIfChanged(path, schema)
+	return err
+}
+
+// WriteFileIfChanged is WriteFile, reporting whether path was written: false
+// means it already held byte-identical content.
+func WriteFileIfChanged(path string, schema *Schema) (bool, error) {
+	return WriteFileIfChangedOpts(path, schema, WriteOptions{})
+}
+
+// WriteFileIfChangedOpts is WriteFileIfChanged with opts applied. A dump
+// with a metadata header counts as unchanged when path already holds the
+// same schema under a header: only the schema is compared, so a re-dump that
+// would merely refresh the timestamp and table sizes leaves the file alone.
+func WriteFileIfChangedOpts(path string, schema *Schema, opts WriteOptions) (bool, error) {
+	var buf bytes.Buffer
+	if err := WriteOpts(&buf, schema, opts); err != nil {
+		return false, err
+	}
+	if old, err := os.ReadFile(path); err == nil {
+		oldHeader, oldSchema := splitDumpHeader(old)
+		newHeader, newSchema := splitDumpHeader(buf.Bytes())
+		if (oldHeader == nil) == (newHeader == nil) && bytes.Equal(oldSchema, newSchema) {
+			return false, nil
+		}
+	}
+
+	verify := func(tmpPath string) error {
+		if err := verifyDump(tmpPath, schema); err != nil {
+			return fmt.Errorf("verify %s: %w", path, err)
+		}
+		return nil
+	}
+	if err := replaceFile(path, buf.Bytes(), verify); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WriteFileAtomic writes data to path the way WriteFile writes a dump: through
+// a fsynced temp file in path's directory that is renamed over path, keeping
+// the mode of the file it replaces (0644 for a new one). On failure path
+// keeps its previous content. It is for output that is not a Schema: plans,
+// rendered SQL and docs, formatted sources.
+func WriteFileAtomic(path string, data []byte) error {
+	return replaceFile(path, data, nil)
+}
+
+// replaceFile is the shared body of WriteFileIfChangedOpts and
+// WriteFileAtomic. verify, when set, checks the synced temp file before it is
+// renamed into place.
+func replaceFile(path string, data []byte, verify func(tmpPath string) error) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// CreateTemp makes the file 0600. A rewritten file keeps the mode of the
+	// file it replaces; a new one is as readable as os.Create's.
+	mode := os.FileMode(0o644)
+	if st, err := os.Stat(path); err == nil {
+		mode = st.Mode().Perm()
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return err
+	}
+
+	if verify != nil {
+		if err := verify(tmpPath); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	committed = true
+	syncDir(dir)
+	return nil
+}
+
+// verifyDump loads a freshly written dump and checks it declares exactly the
+// objects of the schema it was written from, so a dump the loader would
+// later reject (or silently read short) fails now, naming the file.
+func verifyDump(path string, want *Schema) error {
+	got, err := ParseFile(path)
+	if err != nil {
+		return fmt.Errorf("written dump does not load: %w", err)
+	}
+	if g, w := dumpObjectCount(got), dumpObjectCount(want); g != w {
+		return fmt.Errorf("written dump declares %d objects, want %d", g, w)
+	}
+	return nil
+}
+
+// dumpObjectCount counts every block Write emits: databases, their objects,
+// named collections, settings profiles, functions, nodes and clusters.
+func dumpObjectCount(s *Schema) int {
+	n := len(s.Databases) + len(s.NamedCollections) + len(s.SettingsProfiles) +
+		len(s.Functions) + len(s.Nodes) + len(s.Clusters)
+	for _, db := range s.Databases {
+		n += len(db.Tables) + len(db.MaterializedViews) + len(db.Views) +
+			len(db.Dictionaries) + len(db.Raws)
+	}
+	return n
+}
+
+// syncDir fsyncs a directory so a rename into it is durable. It is best
+// effort: some platforms (Windows) cannot sync a directory handle, and the
+// rename itself has already succeeded.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+	_ = d.Sync()
+}
@@ -0,0 +1,135 @@
+package hcl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile_RoundTripsAndLeavesNoTemp(t *testing.T) {
+	before, err := ParseFile(filepath.Join("testdata", "dump_round_trip_full.hcl"))
+	require.NoError(t, err)
+	require.NoError(t, Resolve(before))
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "posthog.hcl")
+	require.NoError(t, WriteFile(path, before))
+
+	after, err := ParseFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, dumpObjectCount(before), dumpObjectCount(after))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temp file left behind")
+}
+
+func TestWriteFile_ReplacesExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.hcl")
+	require.NoError(t, os.WriteFile(path, []byte("stale"), 0o644))
+
+	require.NoError(t, WriteFile(path, &Schema{Databases: []DatabaseSpec{{Name: "posthog"}}}))
+
+	got, err := ParseFile(path)
+	require.NoError(t, err)
+	require.Len(t, got.Databases, 1)
+	assert.Equal(t, "posthog", got.Databases[0].Name)
+}
+
+// Rewriting an existing dump keeps its permissions.
+func TestWriteFile_KeepsExistingMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.hcl")
+	require.NoError(t, os.WriteFile(path, []byte("stale"), 0o600))
+	require.NoError(t, os.Chmod(path, 0o640))
+
+	require.NoError(t, WriteFile(path, &Schema{Databases: []DatabaseSpec{{Name: "posthog"}}}))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+}
+
+// Re-dumping an unchanged schema leaves the file alone, mtime included.
+func TestWriteFileIfChanged_SkipsIdenticalContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.hcl")
+	schema := &Schema{Databases: []DatabaseSpec{{Name: "posthog"}}}
+
+	changed, err := WriteFileIfChanged(path, schema)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, old, old))
+	changed, err = WriteFileIfChanged(path, schema)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(old), "an unchanged dump must not be rewritten")
+
+	schema.Databases = append(schema.Databases, DatabaseSpec{Name: "analytics"})
+	changed, err = WriteFileIfChanged(path, schema)
+	require.NoError(t, err)
+	assert.True(t, changed)
+}
+
+// A dump the loader rejects must fail the write and keep the previous file
+// intact instead of replacing it with something that fails loading later.
+func TestWriteFile_UnloadableDumpKeepsPrevious(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "db.hcl")
+	require.NoError(t, os.WriteFile(path, []byte("previous"), 0o644))
+
+	bad := &Schema{Databases: []DatabaseSpec{{
+		Name: "posthog",
+		Raws: []RawSpec{{Kind: "bogus", Name: "x", SQL: "CREATE TABLE x"}},
+	}}}
+	err := WriteFile(path, bad)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not load")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "previous", string(content))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temp file left behind")
+}
+
+func TestWriteFile_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "db.hcl")
+	require.Error(t, WriteFile(path, &Schema{}))
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plan.sql")
+	require.NoError(t, WriteFileAtomic(path, []byte("SELECT 1;\n")))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	require.NoError(t, os.Chmod(path, 0o640))
+	require.NoError(t, WriteFileAtomic(path, []byte("SELECT 2;\n")))
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT 2;\n", string(got))
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm(), "a rewrite keeps the mode")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temp file left behind")
+}
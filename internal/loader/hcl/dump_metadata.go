@@ -0,0 +1,131 @@
+package hcl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// dumpMetadataMarker opens the comment header WriteOpts puts before a dump
+// carrying DumpMetadata. It lets a later write recognize the header and
+// compare only the schema that follows it.
+const dumpMetadataMarker = "# hclexp dump metadata: comments only, not part of the schema"
+
+// DumpMetadata is non-semantic context a dump can carry for schema reviews:
+// the host it was taken from, when, and how big each table was. It is
+// rendered as comment lines at the top of the file, which the loader ignores.
+type DumpMetadata struct {
+	Host     string
+	DumpedAt time.Time
+	Tables   []TableStats
+}
+
+// TableStats is a table's size as system.tables reports it. Rows and Bytes
+// are nil when the engine does not track them (views, Kafka, Distributed, …).
+type TableStats struct {
+	Database string
+	Name     string
+	Rows     *uint64
+	Bytes    *uint64
+}
+
+// WriteOptions tunes WriteOpts and WriteFileIfChangedOpts.
+type WriteOptions struct {
+	// Metadata, when set, is written as a comment header listing the
+	// stats of the tables the dumped schema declares.
+	Metadata *DumpMetadata
+	// Secrets writes engine credentials (S3 and S3Queue secret keys and
+	// session tokens, the Kafka SASL password) as they are. Without it they
+	// are written as RedactedValue; see redactSecrets.
+	Secrets bool
+}
+
+// IntrospectTableStats returns the row count and on-disk size of every table
+// in databases, from system.tables.
+func IntrospectTableStats(ctx context.Context, conn driver.Conn, databases []string) ([]TableStats, error) {
+	const q = `SELECT database, name, total_rows, total_bytes
+		FROM system.tables
+		WHERE database IN ? AND NOT is_temporary
+		ORDER BY database, name`
+	rows, err := conn.Query(ctx, q, databases)
+	if err != nil {
+		return nil, fmt.Errorf("query system.tables: %w", err)
+	}
+	defer rows.Close()
+	return processTableStatsRows(rows)
+}
+
+// processTableStatsRows builds one entry per (database, name, total_rows,
+// total_bytes) row.
+func processTableStatsRows(rows rowScanner) ([]TableStats, error) {
+	var out []TableStats
+	for rows.Next() {
+		var s TableStats
+		if err := rows.Scan(&s.Database, &s.Name, &s.Rows, &s.Bytes); err != nil {
+			return nil, fmt.Errorf("scan system.tables: %w", err)
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return out, nil
+}
+
+// header renders m as the comment lines that open a dump of schema, ending
+// in a blank line. Only the tables (and raw tables) schema declares are
+// listed, so each file of a per-database dump carries its own.
+func (m *DumpMetadata) header(schema *Schema) []byte {
+	declared := map[ObjectRef]bool{}
+	for _, db := range schema.Databases {
+		for _, t := range db.Tables {
+			declared[ObjectRef{Database: db.Name, Name: t.Name}] = true
+		}
+		for _, r := range db.Raws {
+			if r.Kind == "table" {
+				declared[ObjectRef{Database: db.Name, Name: r.Name}] = true
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintln(&b, dumpMetadataMarker)
+	if m.Host != "" {
+		fmt.Fprintf(&b, "# host:      %s\n", m.Host)
+	}
+	if !m.DumpedAt.IsZero() {
+		fmt.Fprintf(&b, "# dumped at: %s\n", m.DumpedAt.UTC().Format(time.RFC3339))
+	}
+	for _, s := range m.Tables {
+		if !declared[ObjectRef{Database: s.Database, Name: s.Name}] || (s.Rows == nil && s.Bytes == nil) {
+			continue
+		}
+		var parts []string
+		if s.Rows != nil {
+			parts = append(parts, fmt.Sprintf("%d rows", *s.Rows))
+		}
+		if s.Bytes != nil {
+			parts = append(parts, fmt.Sprintf("%d bytes on disk", *s.Bytes))
+		}
+		fmt.Fprintf(&b, "# %s.%s: %s\n", s.Database, s.Name, strings.Join(parts, ", "))
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+// splitDumpHeader splits a dump into its metadata header (nil when there is
+// none) and the schema that follows.
+func splitDumpHeader(dump []byte) (header, schema []byte) {
+	if !bytes.HasPrefix(dump, []byte(dumpMetadataMarker+"\n")) {
+		return nil, dump
+	}
+	i := bytes.Index(dump, []byte("\n\n"))
+	if i < 0 {
+		return dump, nil
+	}
+	return dump[:i+2], dump[i+2:]
+}
@@ -0,0 +1,89 @@
+package hcl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessTableStatsRows(t *testing.T) {
+	var none *uint64
+	rows, bytesOnDisk := uint64(1200), uint64(4096)
+	stats, err := processTableStatsRows(&anyRows{rows: [][]any{
+		{"posthog", "events", &rows, &bytesOnDisk},
+		{"posthog", "events_mv", none, none},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []TableStats{
+		{Database: "posthog", Name: "events", Rows: &rows, Bytes: &bytesOnDisk},
+		{Database: "posthog", Name: "events_mv"},
+	}, stats)
+}
+
+func TestWriteOpts_MetadataHeader(t *testing.T) {
+	rows, size := uint64(1200), uint64(4096)
+	meta := &DumpMetadata{
+		Host:     "ch-0-0",
+		DumpedAt: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		Tables: []TableStats{
+			{Database: "posthog", Name: "events", Rows: &rows, Bytes: &size},
+			{Database: "posthog", Name: "events_mv"},
+			{Database: "other", Name: "t", Rows: &rows},
+		},
+	}
+	schema := &Schema{Databases: []DatabaseSpec{{Name: "posthog", Tables: []TableSpec{
+		mkTable("events", EngineMergeTree{}, ColumnSpec{Name: "id", Type: "UInt64"}),
+	}}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteOpts(&buf, schema, WriteOptions{Metadata: meta}))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte(dumpMetadataMarker+"\n"+
+		"# host:      ch-0-0\n"+
+		"# dumped at: 2026-10-16T12:00:00Z\n"+
+		"# posthog.events: 1200 rows, 4096 bytes on disk\n"+
+		"\n"+
+		"database \"posthog\" {")), buf.String())
+
+	// The loader ignores the header.
+	path := filepath.Join(t.TempDir(), "posthog.hcl")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+	got, err := ParseFile(path)
+	require.NoError(t, err)
+	require.Len(t, got.Databases, 1)
+	assert.Equal(t, "events", got.Databases[0].Tables[0].Name)
+}
+
+// Only the schema decides whether an annotated dump changed: a re-dump that
+// would just refresh the header leaves the file alone, while adding or
+// removing the header rewrites it.
+func TestWriteFileIfChangedOpts_IgnoresHeaderRefresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.hcl")
+	schema := &Schema{Databases: []DatabaseSpec{{Name: "posthog"}}}
+	opts := func(at time.Time) WriteOptions {
+		return WriteOptions{Metadata: &DumpMetadata{Host: "ch-0-0", DumpedAt: at}}
+	}
+	first := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	changed, err := WriteFileIfChangedOpts(path, schema, opts(first))
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	changed, err = WriteFileIfChangedOpts(path, schema, opts(first.Add(time.Hour)))
+	require.NoError(t, err)
+	assert.False(t, changed)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "2026-10-16T12:00:00Z")
+
+	changed, err = WriteFileIfChanged(path, schema)
+	require.NoError(t, err)
+	assert.True(t, changed, "dropping the header is a change")
+	changed, err = WriteFileIfChangedOpts(path, schema, opts(first))
+	require.NoError(t, err)
+	assert.True(t, changed, "adding the header is a change")
+}
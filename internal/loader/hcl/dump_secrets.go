@@ -0,0 +1,90 @@
+package hcl
+
+// storageSecretKeys are the S3/S3Queue overrides that carry a credential
+// when the engine reads its arguments from a named collection.
+var storageSecretKeys = []string{"secret_access_key", "session_token"}
+
+// redactSecrets returns schema with every engine credential replaced by
+// RedactedValue, copying only what it changes; schema itself is never
+// modified. A dump is meant to be committed, and a credential in it came
+// either from -show-secrets introspection or from a layer that resolved it
+// through var. or env(). The marker keeps the dump loadable, makes diff
+// report the field as unverifiable and stops sqlgen from ever writing it to
+// a cluster.
+func redactSecrets(schema *Schema) *Schema {
+	out := *schema
+	out.Databases = append([]DatabaseSpec(nil), schema.Databases...)
+	for di := range out.Databases {
+		db := &out.Databases[di]
+		copied := false
+		for ti, t := range db.Tables {
+			if t.Engine == nil {
+				continue
+			}
+			e, ok := redactEngineSecrets(t.Engine.Decoded)
+			if !ok {
+				continue
+			}
+			if !copied {
+				db.Tables = append([]TableSpec(nil), db.Tables...)
+				copied = true
+			}
+			spec := *t.Engine
+			spec.Decoded = e
+			db.Tables[ti].Engine = &spec
+		}
+	}
+	return &out
+}
+
+// redactEngineSecrets returns e with its credentials redacted, and whether
+// there was anything to redact.
+func redactEngineSecrets(e Engine) (Engine, bool) {
+	switch v := e.(type) {
+	case EngineS3:
+		changed := redactSecret(&v.SecretAccessKey)
+		changed = redactSecret(&v.SessionToken) || changed
+		changed = redactOverrides(&v.Overrides) || changed
+		return v, changed
+	case EngineS3Queue:
+		changed := redactSecret(&v.SecretAccessKey)
+		changed = redactOverrides(&v.Overrides) || changed
+		return v, changed
+	case EngineKafka:
+		return v, redactSecret(&v.SaslPassword)
+	}
+	return e, false
+}
+
+// redactSecret points *p at RedactedValue when it holds a real value.
+func redactSecret(p **string) bool {
+	if *p == nil || **p == RedactedValue {
+		return false
+	}
+	redacted := RedactedValue
+	*p = &redacted
+	return true
+}
+
+// redactOverrides replaces *m with a copy whose credential keys are redacted.
+func redactOverrides(m *map[string]string) bool {
+	var out map[string]string
+	for _, k := range storageSecretKeys {
+		v, ok := (*m)[k]
+		if !ok || v == RedactedValue {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string, len(*m))
+			for k, v := range *m {
+				out[k] = v
+			}
+		}
+		out[k] = RedactedValue
+	}
+	if out == nil {
+		return false
+	}
+	*m = out
+	return true
+}
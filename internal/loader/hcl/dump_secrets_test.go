@@ -0,0 +1,48 @@
+package hcl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite_RedactsEngineSecrets(t *testing.T) {
+	s3 := EngineS3{
+		Path:            strPtr("https://bucket.s3.amazonaws.com/events/*"),
+		AccessKeyID:     strPtr("AKIAEXAMPLE"),
+		SecretAccessKey: strPtr("wJalrXUtnFEMI"),
+		SessionToken:    strPtr("FwoGZXIvYXdz"),
+		Format:          strPtr("Parquet"),
+	}
+	queue := EngineS3Queue{
+		Collection: strPtr("s3_events"),
+		Overrides:  map[string]string{"secret_access_key": "wJalrXUtnFEMI", "format": "JSONEachRow"},
+	}
+	kafka := EngineKafka{BrokerList: strPtr("kafka:9092"), SaslUsername: strPtr("ch"), SaslPassword: strPtr("hunter2")}
+	schema := &Schema{Databases: []DatabaseSpec{{Name: "posthog", Tables: []TableSpec{
+		{Name: "s3", Engine: &EngineSpec{Kind: s3.Kind(), Decoded: s3}},
+		{Name: "queue", Engine: &EngineSpec{Kind: queue.Kind(), Decoded: queue}},
+		{Name: "kafka", Engine: &EngineSpec{Kind: kafka.Kind(), Decoded: kafka}},
+	}}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, schema))
+	out := buf.String()
+	for _, secret := range []string{"wJalrXUtnFEMI", "FwoGZXIvYXdz", "hunter2"} {
+		assert.NotContains(t, out, secret)
+	}
+	assert.Contains(t, out, `secret_access_key = "[HIDDEN]"`)
+	assert.Contains(t, out, `session_token     = "[HIDDEN]"`)
+	assert.Contains(t, out, `sasl_password = "[HIDDEN]"`)
+	assert.Contains(t, out, `access_key_id     = "AKIAEXAMPLE"`, "only the secret half of a key pair is redacted")
+	assert.Equal(t, "wJalrXUtnFEMI", *schema.Databases[0].Tables[0].Engine.Decoded.(EngineS3).SecretAccessKey, "the schema is not modified")
+	assert.Equal(t, "wJalrXUtnFEMI", queue.Overrides["secret_access_key"])
+
+	buf.Reset()
+	require.NoError(t, WriteOpts(&buf, schema, WriteOptions{Secrets: true}))
+	for _, secret := range []string{"wJalrXUtnFEMI", "FwoGZXIvYXdz", "hunter2"} {
+		assert.Contains(t, buf.String(), secret)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -25,6 +27,10 @@ func sortNamedCollections(ncs []NamedCollectionSpec) {
 	sort.Slice(ncs, func(i, j int) bool { return ncs[i].Name < ncs[j].Name })
 }
 
+func sortSettingsProfiles(sps []SettingsProfileSpec) {
+	sort.Slice(sps, func(i, j int) bool { return sps[i].Name < sps[j].Name })
+}
+
 // roundTrip parses, resolves, dumps, parses, resolves again. The before and
 // after schemas (with engine bodies cleared) must compare equal.
 func roundTrip(t *testing.T, file string) {
@@ -34,8 +40,9 @@ func roundTrip(t *testing.T, file string) {
 	require.NoError(t, err)
 	require.NoError(t, Resolve(before))
 
+	// Secrets: the round trip covers every field, credentials included.
 	var buf bytes.Buffer
-	require.NoError(t, Write(&buf, before))
+	require.NoError(t, WriteOpts(&buf, before, WriteOptions{Secrets: true}))
 
 	tmp := filepath.Join(t.TempDir(), "round_trip.hcl")
 	require.NoError(t, os.WriteFile(tmp, buf.Bytes(), 0o644))
@@ -50,6 +57,8 @@ func roundTrip(t *testing.T, file string) {
 	sortTables(after.Databases)
 	sortNamedCollections(before.NamedCollections)
 	sortNamedCollections(after.NamedCollections)
+	sortSettingsProfiles(before.SettingsProfiles)
+	sortSettingsProfiles(after.SettingsProfiles)
 
 	assert.Equal(t, before, after, "round-trip mismatch; dump output:\n%s", buf.String())
 }
@@ -136,10 +145,59 @@ func TestWrite_OutputIsStable(t *testing.T) {
 	assert.Equal(t, a.String(), b.String(), "dump output should be deterministic")
 }
 
+// The dump is canonical: already in fmt's layout, and independent of the
+// order objects were declared or introspected in, so two dumps of the same
+// schema are byte-identical.
+func TestWrite_OutputIsCanonical(t *testing.T) {
+	schema, err := ParseFile(filepath.Join("testdata", "dump_round_trip_full.hcl"))
+	require.NoError(t, err)
+	require.NoError(t, Resolve(schema))
+	schema.Databases[0].Raws = append(schema.Databases[0].Raws,
+		RawSpec{Kind: "view", Name: "shared", SQL: "CREATE VIEW db.shared AS SELECT 1\n"},
+		RawSpec{Kind: "table", Name: "shared", SQL: "CREATE TABLE db.shared (a UInt8) ENGINE = Memory\n"},
+	)
+
+	var a bytes.Buffer
+	require.NoError(t, Write(&a, schema))
+	assert.Equal(t, string(hclwrite.Format(a.Bytes())), a.String(), "dump output is not fmt-clean")
+
+	for di := range schema.Databases {
+		db := &schema.Databases[di]
+		slices.Reverse(db.Tables)
+		slices.Reverse(db.MaterializedViews)
+		slices.Reverse(db.Views)
+		slices.Reverse(db.Dictionaries)
+		slices.Reverse(db.Raws)
+	}
+	slices.Reverse(schema.NamedCollections)
+	slices.Reverse(schema.SettingsProfiles)
+	slices.Reverse(schema.Nodes)
+
+	var b bytes.Buffer
+	require.NoError(t, Write(&b, schema))
+	assert.Equal(t, a.String(), b.String(), "dump output depends on object order")
+}
+
+func TestWrite_RoundTrip_ProjectSettings(t *testing.T) {
+	roundTrip(t, filepath.Join("testdata", "project_settings.hcl"))
+}
+
 func TestWrite_RoundTrip_NamedCollection(t *testing.T) {
 	roundTrip(t, filepath.Join("testdata", "named_collection.hcl"))
 }
 
+func TestWrite_RoundTrip_SettingsProfile(t *testing.T) {
+	roundTrip(t, filepath.Join("testdata", "settings_profile.hcl"))
+}
+
+func TestWrite_RoundTrip_Function(t *testing.T) {
+	roundTrip(t, filepath.Join("testdata", "function.hcl"))
+}
+
+func TestWrite_RoundTrip_ClusterTopology(t *testing.T) {
+	roundTrip(t, filepath.Join("testdata", "cluster_topology.hcl"))
+}
+
 func TestWrite_RoundTrip_KafkaWithCollection(t *testing.T) {
 	roundTrip(t, filepath.Join("testdata", "kafka_with_collection.hcl"))
 }
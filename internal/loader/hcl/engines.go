@@ -1,7 +1,6 @@
 package hcl
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/hashicorp/hcl/v2"
@@ -74,6 +73,27 @@ type EngineReplicatedCollapsingMergeTree struct {
 
 func (EngineReplicatedCollapsingMergeTree) Kind() string { return "replicated_collapsing_merge_tree" }
 
+// EngineVersionedCollapsingMergeTree collapses sign-paired rows like
+// CollapsingMergeTree but orders the pairs by a version column, so rows may
+// arrive out of order. CH syntax: VersionedCollapsingMergeTree(sign, version).
+type EngineVersionedCollapsingMergeTree struct {
+	SignColumn    string `hcl:"sign_column"`
+	VersionColumn string `hcl:"version_column"`
+}
+
+func (EngineVersionedCollapsingMergeTree) Kind() string { return "versioned_collapsing_merge_tree" }
+
+type EngineReplicatedVersionedCollapsingMergeTree struct {
+	ZooPath       string `hcl:"zoo_path"`
+	ReplicaName   string `hcl:"replica_name"`
+	SignColumn    string `hcl:"sign_column"`
+	VersionColumn string `hcl:"version_column"`
+}
+
+func (EngineReplicatedVersionedCollapsingMergeTree) Kind() string {
+	return "replicated_versioned_collapsing_merge_tree"
+}
+
 type EngineAggregatingMergeTree struct{}
 
 func (EngineAggregatingMergeTree) Kind() string { return "aggregating_merge_tree" }
@@ -85,6 +105,23 @@ type EngineReplicatedAggregatingMergeTree struct {
 
 func (EngineReplicatedAggregatingMergeTree) Kind() string { return "replicated_aggregating_merge_tree" }
 
+// EngineGraphiteMergeTree thins and aggregates Graphite data according to a
+// rollup configuration declared in the server config. CH syntax:
+// GraphiteMergeTree('config_section').
+type EngineGraphiteMergeTree struct {
+	ConfigSection string `hcl:"config_section"`
+}
+
+func (EngineGraphiteMergeTree) Kind() string { return "graphite_merge_tree" }
+
+type EngineReplicatedGraphiteMergeTree struct {
+	ZooPath       string `hcl:"zoo_path"`
+	ReplicaName   string `hcl:"replica_name"`
+	ConfigSection string `hcl:"config_section"`
+}
+
+func (EngineReplicatedGraphiteMergeTree) Kind() string { return "replicated_graphite_merge_tree" }
+
 type EngineDistributed struct {
 	ClusterName    string  `hcl:"cluster_name"`
 	RemoteDatabase string  `hcl:"remote_database"`
@@ -127,6 +164,13 @@ type EngineMemory struct{}
 
 func (EngineMemory) Kind() string { return "memory" }
 
+// EngineSet is the always-in-RAM right-hand side of an IN operator: it
+// holds a set of distinct rows, optionally persisted to disk (the
+// `persistent` table setting). It takes no engine parameters.
+type EngineSet struct{}
+
+func (EngineSet) Kind() string { return "set" }
+
 // EngineMerge is a read-only union over multiple physical tables matched
 // by regex. CH syntax: Merge(db_regex, table_regex).
 type EngineMerge struct {
@@ -161,6 +205,81 @@ type EngineBuffer struct {
 
 func (EngineBuffer) Kind() string { return "buffer" }
 
+// EngineUnmanaged is the fallback for an engine the schema language does not
+// model (EmbeddedRocksDB, MySQL, …). Introspection records the verbatim engine
+// clause instead of aborting, so one exotic table never breaks a dump of the
+// whole cluster. The differ skips any table carrying it (with a warning):
+// hclexp cannot reason about parameters it does not understand, so it never
+// creates, drops or alters such a table.
+type EngineUnmanaged struct {
+	Name string `hcl:"name"` // engine name as ClickHouse reports it
+	Full string `hcl:"full"` // name plus parameter list, e.g. EmbeddedRocksDB(0, '/x')
+}
+
+func (EngineUnmanaged) Kind() string { return "unmanaged" }
+
+// EngineS3 reads and writes objects in S3-compatible storage. CH syntax:
+//
+//	S3(path [, NOSIGN | access_key_id, secret_access_key [, session_token]] [, format [, compression]])
+//	S3(named_collection [, key = value ...])
+//
+// Collection is mutually exclusive with the positional fields; Overrides
+// holds the collection form's key = value arguments. ClickHouse reports the
+// secret (and the session token) as "[HIDDEN]" unless the introspecting user
+// may see secrets; the marker is kept in-band, as for dictionary sources, and
+// sqlgen refuses to write it back.
+type EngineS3 struct {
+	Collection *string           `hcl:"collection,optional"`
+	Overrides  map[string]string `hcl:"overrides,optional"`
+
+	Path            *string `hcl:"path,optional"`
+	NoSign          bool    `hcl:"nosign,optional"`
+	AccessKeyID     *string `hcl:"access_key_id,optional"`
+	SecretAccessKey *string `hcl:"secret_access_key,optional"`
+	SessionToken    *string `hcl:"session_token,optional"`
+	Format          *string `hcl:"format,optional"`
+	Compression     *string `hcl:"compression,optional"`
+}
+
+func (EngineS3) Kind() string { return "s3" }
+
+// EngineS3Queue streams new objects from an S3 prefix. Same argument forms
+// as EngineS3 minus the session token, and format is required in the
+// positional form. Queue tuning (mode, keeper_path, …) stays in the
+// table's SETTINGS.
+type EngineS3Queue struct {
+	Collection *string           `hcl:"collection,optional"`
+	Overrides  map[string]string `hcl:"overrides,optional"`
+
+	Path            *string `hcl:"path,optional"`
+	NoSign          bool    `hcl:"nosign,optional"`
+	AccessKeyID     *string `hcl:"access_key_id,optional"`
+	SecretAccessKey *string `hcl:"secret_access_key,optional"`
+	Format          *string `hcl:"format,optional"`
+	Compression     *string `hcl:"compression,optional"`
+}
+
+func (EngineS3Queue) Kind() string { return "s3_queue" }
+
+// EngineURL reads and writes a remote HTTP(S) resource. CH syntax:
+// URL(url [, format [, compression]]).
+type EngineURL struct {
+	URL         string  `hcl:"url"`
+	Format      *string `hcl:"format,optional"`
+	Compression *string `hcl:"compression,optional"`
+}
+
+func (EngineURL) Kind() string { return "url" }
+
+// EngineFile stores rows in a file under the server's data directory.
+// CH syntax: File(format [, compression]).
+type EngineFile struct {
+	Format      string  `hcl:"format"`
+	Compression *string `hcl:"compression,optional"`
+}
+
+func (EngineFile) Kind() string { return "file" }
+
 type EngineKafka struct {
 	// Collection is the named-collection reference. Mutually exclusive
 	// with every other field; when set, no inline setting may be set.
@@ -248,6 +367,30 @@ func (EngineAggregatingMergeTree) Virtuals() []DeclaredColumn {
 func (EngineReplicatedAggregatingMergeTree) Virtuals() []DeclaredColumn {
 	return mergeTreeFamilyVirtuals
 }
+func (EngineVersionedCollapsingMergeTree) Virtuals() []DeclaredColumn {
+	return mergeTreeFamilyVirtuals
+}
+func (EngineReplicatedVersionedCollapsingMergeTree) Virtuals() []DeclaredColumn {
+	return mergeTreeFamilyVirtuals
+}
+func (EngineGraphiteMergeTree) Virtuals() []DeclaredColumn { return mergeTreeFamilyVirtuals }
+func (EngineReplicatedGraphiteMergeTree) Virtuals() []DeclaredColumn {
+	return mergeTreeFamilyVirtuals
+}
+
+// fileVirtuals are the per-row source-object columns every file-backed
+// engine (S3, S3Queue, URL, File) exposes.
+var fileVirtuals = []DeclaredColumn{
+	{Name: "_path", Type: "LowCardinality(String)"},
+	{Name: "_file", Type: "LowCardinality(String)"},
+	{Name: "_size", Type: "Nullable(UInt64)"},
+	{Name: "_time", Type: "Nullable(DateTime)"},
+}
+
+func (EngineS3) Virtuals() []DeclaredColumn      { return fileVirtuals }
+func (EngineS3Queue) Virtuals() []DeclaredColumn { return fileVirtuals }
+func (EngineURL) Virtuals() []DeclaredColumn     { return fileVirtuals }
+func (EngineFile) Virtuals() []DeclaredColumn    { return fileVirtuals }
 
 // kafkaBaseVirtuals is the always-on Kafka virtual set. `_headers` is
 // modelled in its dot-access form (`_headers.name`, `_headers.value`)
@@ -396,6 +539,12 @@ func distributedVirtuals(e EngineDistributed, r TableResolver, seen map[string]b
 // DecodeEngine dispatches on spec.Kind and decodes the body into a kind-specific
 // struct. Returns (nil, nil) when spec is nil.
 func DecodeEngine(spec *EngineSpec) (Engine, error) {
+	return decodeEngine(spec, nil)
+}
+
+// decodeEngine is DecodeEngine with ctx (variables and functions) available to the body's
+// expressions; the parser passes the file's evaluation context.
+func decodeEngine(spec *EngineSpec, ctx *hcl.EvalContext) (Engine, error) {
 	if spec == nil {
 		return nil, nil
 	}
@@ -408,79 +557,119 @@ func DecodeEngine(spec *EngineSpec) (Engine, error) {
 	switch spec.Kind {
 	case "merge_tree":
 		var e EngineMergeTree
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "replicated_merge_tree":
 		var e EngineReplicatedMergeTree
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "replacing_merge_tree":
 		var e EngineReplacingMergeTree
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "replicated_replacing_merge_tree":
 		var e EngineReplicatedReplacingMergeTree
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "summing_merge_tree":
 		var e EngineSummingMergeTree
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "replicated_summing_merge_tree":
 		var e EngineReplicatedSummingMergeTree
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "collapsing_merge_tree":
 		var e EngineCollapsingMergeTree
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "replicated_collapsing_merge_tree":
 		var e EngineReplicatedCollapsingMergeTree
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "aggregating_merge_tree":
 		var e EngineAggregatingMergeTree
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "replicated_aggregating_merge_tree":
 		var e EngineReplicatedAggregatingMergeTree
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
+		target = e
+	case "versioned_collapsing_merge_tree":
+		var e EngineVersionedCollapsingMergeTree
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
+		target = e
+	case "replicated_versioned_collapsing_merge_tree":
+		var e EngineReplicatedVersionedCollapsingMergeTree
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
+		target = e
+	case "graphite_merge_tree":
+		var e EngineGraphiteMergeTree
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
+		target = e
+	case "replicated_graphite_merge_tree":
+		var e EngineReplicatedGraphiteMergeTree
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "distributed":
 		var e EngineDistributed
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "log":
 		var e EngineLog
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "join":
 		var e EngineJoin
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "null":
 		var e EngineNull
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "memory":
 		var e EngineMemory
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
+		target = e
+	case "set":
+		var e EngineSet
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "merge":
 		var e EngineMerge
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "buffer":
 		var e EngineBuffer
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
+		target = e
+	case "unmanaged":
+		var e EngineUnmanaged
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
+		target = e
+	case "s3":
+		var e EngineS3
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
+		target = e
+	case "s3_queue":
+		var e EngineS3Queue
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
+		target = e
+	case "url":
+		var e EngineURL
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
+		target = e
+	case "file":
+		var e EngineFile
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "kafka":
 		var e EngineKafka
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		target = e
 	case "time_series":
 		var e EngineTimeSeries
-		diags = gohcl.DecodeBody(spec.Body, nil, &e)
+		diags = gohcl.DecodeBody(spec.Body, ctx, &e)
 		if !diags.HasErrors() {
 			// Recursively decode inner engines so resolver/sqlgen see
 			// typed values without each caller having to do it again.
@@ -488,7 +677,7 @@ func DecodeEngine(spec *EngineSpec) (Engine, error) {
 				if t == nil || t.Inner == nil || t.Inner.Engine == nil {
 					continue
 				}
-				inner, err := DecodeEngine(t.Inner.Engine)
+				inner, err := decodeEngine(t.Inner.Engine, ctx)
 				if err != nil {
 					return nil, fmt.Errorf("time_series inner engine: %w", err)
 				}
@@ -501,7 +690,7 @@ func DecodeEngine(spec *EngineSpec) (Engine, error) {
 	}
 
 	if diags.HasErrors() {
-		return nil, errors.New(diags.Error())
+		return nil, diagnosticsError(diags)
 	}
 	return target, nil
 }
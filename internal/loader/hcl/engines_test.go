@@ -88,10 +88,43 @@ func TestParseFile_AllEngineKinds(t *testing.T) {
 
 	assert.Equal(t, EngineNull{}, byName["t_null"])
 	assert.Equal(t, EngineMemory{}, byName["t_memory"])
+	assert.Equal(t, EngineSet{}, byName["t_set"])
+	assert.Equal(t, EngineS3{
+		Path:            ptr("https://bucket.s3.amazonaws.com/data/*.parquet"),
+		AccessKeyID:     ptr("AKIAEXAMPLE"),
+		SecretAccessKey: ptr(RedactedValue),
+		Format:          ptr("Parquet"),
+	}, byName["t_s3"])
+	assert.Equal(t, EngineS3Queue{
+		Collection: ptr("s3_ingest"),
+		Overrides:  map[string]string{"format": "JSONEachRow"},
+	}, byName["t_s3_queue"])
+	assert.Equal(t, EngineURL{URL: "https://example.com/data.csv", Format: ptr("CSV")}, byName["t_url"])
+	assert.Equal(t, EngineFile{Format: "TabSeparated", Compression: ptr("gzip")}, byName["t_file"])
+	assert.Equal(t, EngineVersionedCollapsingMergeTree{
+		SignColumn:    "sign",
+		VersionColumn: "ver",
+	}, byName["t_versioned_collapsing_merge_tree"])
+	assert.Equal(t, EngineReplicatedVersionedCollapsingMergeTree{
+		ZooPath:       "/clickhouse/tables/{shard}/t_rvcmt",
+		ReplicaName:   "{replica}",
+		SignColumn:    "sign",
+		VersionColumn: "ver",
+	}, byName["t_replicated_versioned_collapsing_merge_tree"])
+	assert.Equal(t, EngineGraphiteMergeTree{ConfigSection: "graphite_rollup"}, byName["t_graphite_merge_tree"])
+	assert.Equal(t, EngineReplicatedGraphiteMergeTree{
+		ZooPath:       "/clickhouse/tables/{shard}/t_rgmt",
+		ReplicaName:   "{replica}",
+		ConfigSection: "graphite_rollup",
+	}, byName["t_replicated_graphite_merge_tree"])
 	assert.Equal(t, EngineMerge{
 		DBRegex:    "default",
 		TableRegex: "^shard_.*",
 	}, byName["t_merge"])
+	assert.Equal(t, EngineUnmanaged{
+		Name: "EmbeddedRocksDB",
+		Full: "EmbeddedRocksDB(0, '/var/lib/rocks')",
+	}, byName["t_unmanaged"])
 
 	flushTime := int64(30)
 	assert.Equal(t, EngineBuffer{
@@ -188,6 +221,15 @@ func TestEngine_KindMethods(t *testing.T) {
 		{EngineJoin{}, "join"},
 		{EngineNull{}, "null"},
 		{EngineMemory{}, "memory"},
+		{EngineSet{}, "set"},
+		{EngineS3{}, "s3"},
+		{EngineS3Queue{}, "s3_queue"},
+		{EngineURL{}, "url"},
+		{EngineFile{}, "file"},
+		{EngineVersionedCollapsingMergeTree{}, "versioned_collapsing_merge_tree"},
+		{EngineReplicatedVersionedCollapsingMergeTree{}, "replicated_versioned_collapsing_merge_tree"},
+		{EngineGraphiteMergeTree{}, "graphite_merge_tree"},
+		{EngineReplicatedGraphiteMergeTree{}, "replicated_graphite_merge_tree"},
 		{EngineMerge{}, "merge"},
 		{EngineBuffer{}, "buffer"},
 	}
@@ -13,19 +13,35 @@ import (
 // list of glob patterns (filepath.Match syntax) matched against both the bare
 // object name and its database-qualified "<database>.<name>" form, so patterns
 // like "tmp_*" or "posthog.*_backup" both work, plus an optional set of object
-// types that are excluded wholesale regardless of name. A nil matcher excludes
+// types that are excluded wholesale regardless of name. Database and column
+// globs go further: a matching database is dropped whole, and a matching
+// column is removed from its table, so ops scratch databases and columns
+// added by vendor tools look as if they did not exist. A nil matcher excludes
 // nothing.
 type ExcludeMatcher struct {
 	patterns    []string
 	objectTypes map[string]bool
+	databases   []string
+	columns     []string
+
+	// include, when non-empty, excludes every database object whose name
+	// matches none of its globs (see WithObjectFilters).
+	include []string
 }
 
+// DefaultExcludeFile is the exclude config the CLI picks up from the working
+// directory when no -exclude flag is given.
+const DefaultExcludeFile = ".chschemaignore"
+
 // excludeFile is the on-disk config: a single
-// `exclude { patterns = [...] object_types = [...] }` block.
+// `exclude { patterns = [...] object_types = [...] databases = [...] columns = [...] }`
+// block.
 type excludeFile struct {
 	Exclude *struct {
-		Patterns    []string `hcl:"patterns"`
+		Patterns    []string `hcl:"patterns,optional"`
 		ObjectTypes []string `hcl:"object_types,optional"`
+		Databases   []string `hcl:"databases,optional"`
+		Columns     []string `hcl:"columns,optional"`
 	} `hcl:"exclude,block"`
 }
 
@@ -33,6 +49,7 @@ type excludeFile struct {
 var validExcludeObjectTypes = map[string]bool{
 	KindTable: true, KindMaterializedView: true, KindView: true,
 	KindDictionary: true, KindRaw: true, KindNamedCollection: true,
+	KindSettingsProfile: true, KindFunction: true,
 }
 
 // LoadExcludeConfig parses an HCL exclude config:
@@ -40,11 +57,13 @@ var validExcludeObjectTypes = map[string]bool{
 //	exclude {
 //	  patterns     = ["tmp_*", "_tmp_replace_*", "*_backup", ...]
 //	  object_types = ["named_collection"]   # optional: drop a whole class
+//	  databases    = ["scratch_*"]          # optional: drop whole databases
+//	  columns      = ["_airbyte_*"]         # optional: column, table.column or db.table.column
 //	}
 //
 // Patterns are validated as globs and object types against the known object
-// kinds at load time. A file with no exclude block (or no patterns and no
-// object types) yields a matcher that excludes nothing.
+// kinds at load time. A file with no exclude block (or nothing in it) yields
+// a matcher that excludes nothing.
 func LoadExcludeConfig(path string) (*ExcludeMatcher, error) {
 	parser := hclparse.NewParser()
 	f, diags := parser.ParseHCLFile(path)
@@ -59,10 +78,14 @@ func LoadExcludeConfig(path string) (*ExcludeMatcher, error) {
 	m := &ExcludeMatcher{}
 	if cfg.Exclude != nil {
 		m.patterns = cfg.Exclude.Patterns
+		m.databases = cfg.Exclude.Databases
+		m.columns = cfg.Exclude.Columns
 	}
-	for _, p := range m.patterns {
-		if _, err := filepath.Match(p, ""); err != nil {
-			return nil, fmt.Errorf("invalid exclude pattern %q: %w", p, err)
+	for _, globs := range [][]string{m.patterns, m.databases, m.columns} {
+		for _, p := range globs {
+			if _, err := filepath.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("invalid exclude pattern %q: %w", p, err)
+			}
 		}
 	}
 	if cfg.Exclude != nil && len(cfg.Exclude.ObjectTypes) > 0 {
@@ -95,6 +118,23 @@ func NewExcludeMatcherWithTypes(objectTypes []string, patterns ...string) *Exclu
 	return m
 }
 
+// WithObjectFilters returns a copy of m that also excludes the objects
+// matching any exclude glob and, when include is non-empty, every object in a
+// database whose name matches none of the include globs — so a dump can take
+// just the analytics tables and skip hundreds of staging ones. Globs match
+// like patterns: the bare name or "<database>.<name>". Cluster-scoped objects
+// (named collections, settings profiles, functions) are not subject to
+// include. m may be nil.
+func (m *ExcludeMatcher) WithObjectFilters(include, exclude []string) *ExcludeMatcher {
+	out := &ExcludeMatcher{}
+	if m != nil {
+		*out = *m
+	}
+	out.patterns = append(append([]string(nil), out.patterns...), exclude...)
+	out.include = append(append([]string(nil), out.include...), include...)
+	return out
+}
+
 // MatchesObject reports whether an object is excluded, by its type or by a
 // name pattern.
 func (m *ExcludeMatcher) MatchesObject(objectType, database, name string) bool {
@@ -107,15 +147,66 @@ func (m *ExcludeMatcher) MatchesObject(objectType, database, name string) bool {
 	return m.Matches(database, name)
 }
 
+// MatchesDatabase reports whether a whole database is excluded.
+func (m *ExcludeMatcher) MatchesDatabase(name string) bool {
+	if m == nil {
+		return false
+	}
+	for _, p := range m.databases {
+		if matched, _ := filepath.Match(p, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesColumn reports whether a table column is excluded. Column globs are
+// tried against the bare column name, "<table>.<column>" and
+// "<database>.<table>.<column>".
+func (m *ExcludeMatcher) MatchesColumn(database, table, column string) bool {
+	if m == nil {
+		return false
+	}
+	forms := []string{column, table + "." + column, database + "." + table + "." + column}
+	for _, p := range m.columns {
+		for _, f := range forms {
+			if matched, _ := filepath.Match(p, f); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FilterColumns removes the excluded columns from every table of db, in
+// place. Introspection runs it on each database it reads; FilterSchema runs
+// it on both sides of a comparison.
+func FilterColumns(db *DatabaseSpec, m *ExcludeMatcher) {
+	if m == nil || len(m.columns) == 0 {
+		return
+	}
+	for i := range db.Tables {
+		t := &db.Tables[i]
+		t.Columns = filterSlice(t.Columns, func(c ColumnSpec) bool {
+			return m.MatchesColumn(db.Name, t.Name, c.Name)
+		})
+	}
+}
+
 // FilterSchema removes every object the matcher excludes, in place. Both
 // sides of a comparison are filtered before Diff so excluded objects appear
-// in no output and no count. Databases are kept even when emptied.
+// in no output and no count. Databases matched by a database glob are
+// dropped; other databases are kept even when emptied.
 func FilterSchema(s *Schema, m *ExcludeMatcher) {
 	if s == nil || m.Empty() {
 		return
 	}
+	s.Databases = filterSlice(s.Databases, func(db DatabaseSpec) bool {
+		return m.MatchesDatabase(db.Name)
+	})
 	for di := range s.Databases {
 		db := &s.Databases[di]
+		FilterColumns(db, m)
 		db.Tables = filterSlice(db.Tables, func(t TableSpec) bool {
 			return m.MatchesObject(KindTable, db.Name, t.Name)
 		})
@@ -135,6 +226,12 @@ func FilterSchema(s *Schema, m *ExcludeMatcher) {
 	s.NamedCollections = filterSlice(s.NamedCollections, func(nc NamedCollectionSpec) bool {
 		return m.MatchesObject(KindNamedCollection, "", nc.Name)
 	})
+	s.SettingsProfiles = filterSlice(s.SettingsProfiles, func(sp SettingsProfileSpec) bool {
+		return m.MatchesObject(KindSettingsProfile, "", sp.Name)
+	})
+	s.Functions = filterSlice(s.Functions, func(f FunctionSpec) bool {
+		return m.MatchesObject(KindFunction, "", f.Name)
+	})
 }
 
 // SelectSchema keeps only the objects the matcher matches, in place — the
@@ -169,6 +266,30 @@ func SelectSchema(s *Schema, m *ExcludeMatcher) {
 	s.NamedCollections = filterSlice(s.NamedCollections, func(nc NamedCollectionSpec) bool {
 		return !m.MatchesObject(KindNamedCollection, "", nc.Name)
 	})
+	s.SettingsProfiles = filterSlice(s.SettingsProfiles, func(sp SettingsProfileSpec) bool {
+		return !m.MatchesObject(KindSettingsProfile, "", sp.Name)
+	})
+	s.Functions = filterSlice(s.Functions, func(f FunctionSpec) bool {
+		return !m.MatchesObject(KindFunction, "", f.Name)
+	})
+}
+
+// ScopeDatabases keeps only the named databases, in place, so a comparison
+// covers just the databases one project (or one databases/<db>/ subtree of
+// the per-database layout) owns. Cluster-scoped objects (named collections,
+// settings profiles, functions) and node blocks are untouched. No names means
+// no scope.
+func ScopeDatabases(s *Schema, names []string) {
+	if s == nil || len(names) == 0 {
+		return
+	}
+	keep := make(map[string]bool, len(names))
+	for _, n := range names {
+		keep[n] = true
+	}
+	s.Databases = filterSlice(s.Databases, func(db DatabaseSpec) bool {
+		return !keep[db.Name]
+	})
 }
 
 func filterSlice[T any](in []T, drop func(T) bool) []T {
@@ -183,13 +304,27 @@ func filterSlice[T any](in []T, drop func(T) bool) []T {
 
 // Match reports whether an object is excluded and, if so, the pattern that
 // matched (for logging). It tries each pattern against the bare name and the
-// "<database>.<name>" qualified form.
+// "<database>.<name>" qualified form. An object in a database that no include
+// glob matches is excluded too, reported as pattern "!include".
 func (m *ExcludeMatcher) Match(database, name string) (pattern string, ok bool) {
 	if m == nil {
 		return "", false
 	}
+	if p, ok := matchName(m.patterns, database, name); ok {
+		return p, true
+	}
+	if len(m.include) > 0 && database != "" {
+		if _, ok := matchName(m.include, database, name); !ok {
+			return "!include", true
+		}
+	}
+	return "", false
+}
+
+// matchName returns the first glob matching name or "<database>.<name>".
+func matchName(globs []string, database, name string) (string, bool) {
 	qualified := database + "." + name
-	for _, p := range m.patterns {
+	for _, p := range globs {
 		if matched, _ := filepath.Match(p, name); matched {
 			return p, true
 		}
@@ -206,8 +341,9 @@ func (m *ExcludeMatcher) Matches(database, name string) bool {
 	return ok
 }
 
-// Empty reports whether the matcher has no patterns and no object types (so
-// it excludes nothing).
+// Empty reports whether the matcher has no patterns, object types, database,
+// column or include globs (so it excludes nothing).
 func (m *ExcludeMatcher) Empty() bool {
-	return m == nil || (len(m.patterns) == 0 && len(m.objectTypes) == 0)
+	return m == nil || (len(m.patterns) == 0 && len(m.objectTypes) == 0 &&
+		len(m.databases) == 0 && len(m.columns) == 0 && len(m.include) == 0)
 }
@@ -41,6 +41,44 @@ func TestExcludeMatcher_Matches(t *testing.T) {
 	assert.True(t, nilM.Empty())
 }
 
+func TestExcludeMatcher_WithObjectFilters(t *testing.T) {
+	base := NewExcludeMatcher("tmp_*")
+	m := base.WithObjectFilters([]string{"analytics.*", "posthog.events"}, []string{"*_staging"})
+
+	assert.True(t, m.Matches("analytics", "tmp_load"), "the base patterns still apply")
+	assert.True(t, m.Matches("analytics", "sessions_staging"), "exclude globs win over include")
+	assert.False(t, m.Matches("analytics", "sessions"))
+	assert.False(t, m.Matches("posthog", "events"))
+	pat, ok := m.Match("posthog", "persons")
+	assert.True(t, ok, "an object no include glob matches is excluded")
+	assert.Equal(t, "!include", pat)
+	assert.False(t, m.MatchesObject(KindNamedCollection, "", "s3_creds"), "cluster-scoped objects are not subject to include")
+
+	assert.False(t, base.Matches("posthog", "persons"), "the receiver is unchanged")
+	assert.False(t, base.Matches("analytics", "sessions_staging"))
+
+	var nilM *ExcludeMatcher
+	only := nilM.WithObjectFilters([]string{"events"}, nil)
+	assert.False(t, only.Empty())
+	assert.False(t, only.Matches("posthog", "events"))
+	assert.True(t, only.Matches("posthog", "persons"))
+}
+
+// Include/exclude globs skip objects before their DDL is parsed, so a
+// filtered-out object that could not be parsed does not fail the dump.
+func TestIntrospect_WithObjectFilters(t *testing.T) {
+	rows := &fakeRows{rows: []fakeRow{
+		{name: "events", sql: "CREATE TABLE db.events (`id` UInt64) ENGINE = MergeTree ORDER BY id"},
+		{name: "events_staging", sql: "CREATE TABLE db.events_staging (`id` UInt64) ENGINE = MergeTree ORDER BY id"},
+		{name: "scratch", sql: "not sql at all"},
+	}}
+	db := &DatabaseSpec{Name: "db"}
+	m := (*ExcludeMatcher)(nil).WithObjectFilters([]string{"events*"}, []string{"*_staging"})
+	require.NoError(t, processIntrospectRowsOpt(db, "db", rows, false, m))
+	require.Len(t, db.Tables, 1)
+	assert.Equal(t, "events", db.Tables[0].Name)
+}
+
 func TestLoadExcludeConfig(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "exclude.hcl")
@@ -219,3 +257,64 @@ exclude {
 }`))
 	require.ErrorContains(t, err, "nonsense")
 }
+
+func TestLoadExcludeConfig_DatabasesAndColumns(t *testing.T) {
+	path := writeTempExclude(t, `
+exclude {
+  databases = ["scratch_*"]
+  columns   = ["_airbyte_*", "posthog.events.debug_*"]
+}`)
+	m, err := LoadExcludeConfig(path)
+	require.NoError(t, err)
+	assert.False(t, m.Empty(), "patterns are optional")
+
+	assert.True(t, m.MatchesDatabase("scratch_ops"))
+	assert.False(t, m.MatchesDatabase("posthog"))
+
+	assert.True(t, m.MatchesColumn("posthog", "persons", "_airbyte_emitted_at"))
+	assert.True(t, m.MatchesColumn("posthog", "events", "debug_flag"))
+	assert.False(t, m.MatchesColumn("other", "events", "debug_flag"), "qualified glob is scoped")
+	assert.False(t, m.MatchesColumn("posthog", "events", "uuid"))
+
+	var nilM *ExcludeMatcher
+	assert.False(t, nilM.MatchesDatabase("scratch_ops"))
+	assert.False(t, nilM.MatchesColumn("posthog", "persons", "_airbyte_emitted_at"))
+}
+
+func TestFilterSchema_DatabasesAndColumns(t *testing.T) {
+	m, err := LoadExcludeConfig(writeTempExclude(t, `
+exclude {
+  databases = ["scratch_*"]
+  columns   = ["events._vendor_*"]
+}`))
+	require.NoError(t, err)
+
+	s := &Schema{Databases: []DatabaseSpec{
+		{Name: "scratch_ops", Tables: []TableSpec{{Name: "t"}}},
+		{Name: "posthog", Tables: []TableSpec{
+			{Name: "events", Columns: []ColumnSpec{{Name: "uuid", Type: "UUID"}, {Name: "_vendor_sync", Type: "UInt8"}}},
+			{Name: "persons", Columns: []ColumnSpec{{Name: "_vendor_sync", Type: "UInt8"}}},
+		}},
+	}}
+	FilterSchema(s, m)
+
+	require.Len(t, s.Databases, 1)
+	assert.Equal(t, "posthog", s.Databases[0].Name)
+	assert.Equal(t, []ColumnSpec{{Name: "uuid", Type: "UUID"}}, s.Databases[0].Tables[0].Columns)
+	assert.Equal(t, []ColumnSpec{{Name: "_vendor_sync", Type: "UInt8"}}, s.Databases[0].Tables[1].Columns)
+}
+
+func TestScopeDatabases(t *testing.T) {
+	s := &Schema{
+		Databases:        []DatabaseSpec{{Name: "posthog"}, {Name: "ops"}, {Name: "system_logs"}},
+		NamedCollections: []NamedCollectionSpec{{Name: "kafka"}},
+	}
+	ScopeDatabases(s, nil)
+	require.Len(t, s.Databases, 3, "no names is no scope")
+
+	ScopeDatabases(s, []string{"ops", "posthog"})
+	require.Len(t, s.Databases, 2)
+	assert.Equal(t, "posthog", s.Databases[0].Name)
+	assert.Equal(t, "ops", s.Databases[1].Name)
+	assert.Len(t, s.NamedCollections, 1, "cluster-scoped objects are untouched")
+}
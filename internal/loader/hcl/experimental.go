@@ -0,0 +1,124 @@
+package hcl
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// experimentalFeature is one opt-in ClickHouse feature: the server setting
+// that unlocks it and a pattern recognizing its syntax in a generated
+// statement (matched with string literals and quoted identifiers masked).
+type experimentalFeature struct {
+	Setting string
+	uses    *regexp.Regexp
+}
+
+// experimentalFeatures are the names a top-level `experimental` list accepts.
+var experimentalFeatures = map[string]experimentalFeature{
+	"json_type": {
+		Setting: "allow_experimental_json_type",
+		uses:    regexp.MustCompile(`\bJSON\b`),
+	},
+	"refreshable_mvs": {
+		Setting: "allow_experimental_refreshable_materialized_view",
+		uses:    regexp.MustCompile(`\bREFRESH\s+(EVERY|AFTER)\b|\bMODIFY\s+REFRESH\b`),
+	},
+	"time_series_table": {
+		Setting: "allow_experimental_time_series_table",
+		uses:    regexp.MustCompile(`\bENGINE\s*=\s*TimeSeries\b`),
+	},
+}
+
+// validateExperimental rejects names that are not known features.
+func validateExperimental(names []string) error {
+	for _, n := range names {
+		if _, ok := experimentalFeatures[n]; !ok {
+			return fmt.Errorf("experimental: unknown feature %q (want one of %s)",
+				n, strings.Join(sortedKeys(experimentalFeatures), ", "))
+		}
+	}
+	return nil
+}
+
+// mergeExperimental returns the sorted union of two feature lists. A nil
+// result means neither side declared `experimental`; a declared empty list
+// stays non-nil, because declaring it is what turns gating on.
+func mergeExperimental(a, b []string) []string {
+	if a == nil && b == nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	out := []string{}
+	for _, n := range append(append([]string(nil), a...), b...) {
+		if !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// experimentalSettings inspects one statement against the enabled features.
+// It returns the allow_experimental_* settings the statement needs for the
+// enabled features it uses, and the names of features it uses that are not
+// enabled.
+func experimentalSettings(sql string, enabled []string) (settings map[string]string, missing []string) {
+	on := map[string]bool{}
+	for _, n := range enabled {
+		on[n] = true
+	}
+	masked := maskQuoted(sql)
+	for _, name := range sortedKeys(experimentalFeatures) {
+		f := experimentalFeatures[name]
+		if !f.uses.MatchString(masked) {
+			continue
+		}
+		if !on[name] {
+			missing = append(missing, name)
+			continue
+		}
+		if settings == nil {
+			settings = map[string]string{}
+		}
+		settings[f.Setting] = "1"
+	}
+	return settings, missing
+}
+
+// maskQuoted blanks the contents of '...' string literals and `...`/"..."
+// quoted identifiers, so a feature pattern never matches a column named JSON
+// or a comment mentioning REFRESH EVERY.
+func maskQuoted(sql string) string {
+	b := []byte(sql)
+	var quote byte
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && i+1 < len(b) {
+				b[i], b[i+1] = ' ', ' '
+				i++
+			} else if c == quote {
+				quote = 0
+			} else {
+				b[i] = ' '
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		}
+	}
+	return string(b)
+}
+
+// SetStatements renders settings as SET statements (without trailing ';'),
+// sorted by name, for text output that replays a statement in one session.
+func SetStatements(settings map[string]string) []string {
+	out := make([]string, 0, len(settings))
+	for _, k := range sortedKeys(settings) {
+		out = append(out, fmt.Sprintf("SET %s = %s", k, settings[k]))
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jsonTableChange() ChangeSet {
+	return ChangeSet{Databases: []DatabaseChange{{
+		Database: "db",
+		AddTables: []TableSpec{{
+			Name:    "events",
+			Columns: []ColumnSpec{{Name: "id", Type: "UInt64"}, {Name: "props", Type: "JSON"}},
+			Engine:  &EngineSpec{Kind: "merge_tree", Decoded: EngineMergeTree{}},
+			OrderBy: []string{"id"},
+		}},
+	}}}
+}
+
+func TestGenerateSQL_ExperimentalUndeclaredPassesThrough(t *testing.T) {
+	out := GenerateSQL(jsonTableChange())
+	require.Len(t, out.Ops, 1)
+	assert.Nil(t, out.Ops[0].Settings)
+	assert.Empty(t, out.Unsafe)
+}
+
+func TestGenerateSQL_ExperimentalEnabledCarriesSetting(t *testing.T) {
+	cs := jsonTableChange()
+	cs.Experimental = []string{"json_type"}
+	out := GenerateSQL(cs)
+	require.Len(t, out.Ops, 1)
+	assert.Equal(t, map[string]string{"allow_experimental_json_type": "1"}, out.Ops[0].Settings)
+	assert.Equal(t, []string{"SET allow_experimental_json_type = 1"}, SetStatements(out.Ops[0].Settings))
+}
+
+func TestGenerateSQL_ExperimentalNotEnabledIsUnsafe(t *testing.T) {
+	cs := jsonTableChange()
+	cs.Experimental = []string{}
+	out := GenerateSQL(cs)
+	assert.Empty(t, out.Statements)
+	require.Len(t, out.Unsafe, 1)
+	assert.Equal(t, "events", out.Unsafe[0].Table)
+	assert.Contains(t, out.Unsafe[0].Reason, "json_type")
+}
+
+func TestExperimentalSettings_Detection(t *testing.T) {
+	all := []string{"json_type", "refreshable_mvs", "time_series_table"}
+	for _, tc := range []struct {
+		sql  string
+		want map[string]string
+	}{
+		{"ALTER TABLE db.t ADD COLUMN p Nullable(JSON)", map[string]string{"allow_experimental_json_type": "1"}},
+		{"CREATE MATERIALIZED VIEW db.mv REFRESH EVERY 1 DAY TO db.t AS SELECT 1", map[string]string{"allow_experimental_refreshable_materialized_view": "1"}},
+		{"ALTER TABLE db.mv MODIFY REFRESH AFTER 1 HOUR", map[string]string{"allow_experimental_refreshable_materialized_view": "1"}},
+		{"CREATE TABLE db.m ENGINE = TimeSeries", map[string]string{"allow_experimental_time_series_table": "1"}},
+		// Quoted text and function names do not count as a use.
+		{"CREATE TABLE db.t (`JSON` String COMMENT 'REFRESH EVERY JSON') ENGINE = Memory", nil},
+		{"CREATE VIEW db.v AS SELECT JSONExtractString(s, 'a') FROM db.t", nil},
+	} {
+		got, missing := experimentalSettings(tc.sql, all)
+		assert.Empty(t, missing, tc.sql)
+		assert.Equal(t, tc.want, got, tc.sql)
+	}
+}
+
+func TestParseFile_Experimental(t *testing.T) {
+	dir := t.TempDir()
+	path := writeHCL(t, dir, "project.hcl", `experimental = ["refreshable_mvs", "json_type"]`)
+	s, err := ParseFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"refreshable_mvs", "json_type"}, s.Experimental)
+
+	path = writeHCL(t, dir, "empty.hcl", `experimental = []`)
+	s, err = ParseFile(path)
+	require.NoError(t, err)
+	assert.NotNil(t, s.Experimental, "a declared empty list turns gating on")
+
+	path = writeHCL(t, dir, "bad.hcl", `experimental = ["window_views"]`)
+	_, err = ParseFile(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown feature "window_views"`)
+}
+
+func TestLoadLayers_ExperimentalUnion(t *testing.T) {
+	dir := t.TempDir()
+	a := writeHCL(t, dir, "a.hcl", `experimental = ["json_type"]`)
+	b := writeHCL(t, dir, "b.hcl", `experimental = ["refreshable_mvs", "json_type"]`)
+	s, err := LoadLayers([]string{a, b})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"json_type", "refreshable_mvs"}, s.Experimental)
+}
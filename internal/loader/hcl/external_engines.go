@@ -0,0 +1,328 @@
+package hcl
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	chparser "github.com/orian/clickhouse-sql-parser/parser"
+)
+
+// objectStorageArgs is the argument list shared by the S3 and S3Queue
+// engines, decoded once and copied into the kind-specific struct.
+type objectStorageArgs struct {
+	Collection      *string
+	Overrides       map[string]string
+	Path            *string
+	NoSign          bool
+	AccessKeyID     *string
+	SecretAccessKey *string
+	SessionToken    *string
+	Format          *string
+	Compression     *string
+}
+
+// knownFormats are the input/output format names ClickHouse accepts in an
+// engine argument list. ClickHouse itself tells `S3(url, format,
+// compression)` from `S3(url, key, secret)` by asking its format registry
+// whether the second argument names a format; this set stands in for that
+// registry. Matching is case-insensitive, like ClickHouse's.
+var knownFormats = map[string]bool{}
+
+func init() {
+	for _, f := range []string{
+		"auto", "Arrow", "ArrowStream", "Avro", "AvroConfluent", "BSONEachRow",
+		"CapnProto", "CSV", "CSVWithNames", "CSVWithNamesAndTypes",
+		"CustomSeparated", "CustomSeparatedWithNames", "CustomSeparatedWithNamesAndTypes",
+		"Form", "JSON", "JSONAsObject", "JSONAsString", "JSONColumns",
+		"JSONCompact", "JSONCompactColumns", "JSONCompactEachRow",
+		"JSONCompactEachRowWithNames", "JSONCompactEachRowWithNamesAndTypes",
+		"JSONCompactStringsEachRow", "JSONEachRow", "JSONLines", "JSONObjectEachRow",
+		"JSONStringsEachRow", "LineAsString", "LineAsStringWithNames", "MsgPack",
+		"MySQLDump", "Native", "NDJSON", "Npy", "One", "ORC", "Parquet",
+		"Protobuf", "ProtobufList", "ProtobufSingle", "RawBLOB", "Regexp",
+		"RowBinary", "RowBinaryWithNames", "RowBinaryWithNamesAndTypes",
+		"SQLInsert", "TabSeparated", "TabSeparatedRaw", "TabSeparatedRawWithNames",
+		"TabSeparatedRawWithNamesAndTypes", "TabSeparatedWithNames",
+		"TabSeparatedWithNamesAndTypes", "Template", "TSKV", "TSV", "TSVRaw",
+		"TSVRawWithNames", "TSVRawWithNamesAndTypes", "TSVWithNames",
+		"TSVWithNamesAndTypes", "Values",
+	} {
+		knownFormats[strings.ToLower(f)] = true
+	}
+}
+
+func isFormatName(s string) bool { return knownFormats[strings.ToLower(s)] }
+
+// parseObjectStorageArgs decodes an S3 or S3Queue engine argument list (raw
+// holds each argument as formatted SQL, quotes intact). A first argument that
+// is not a string literal is a named collection, optionally followed by
+// key = value overrides. Otherwise the positional form is disambiguated the
+// way ClickHouse does it: NOSIGN in second place, then by whether the
+// argument after the path (or after the credentials) names a format.
+// withToken allows S3's session_token; formatRequired is S3Queue's rule that
+// the positional form always carries a format.
+func parseObjectStorageArgs(engine string, raw []string, withToken, formatRequired bool) (objectStorageArgs, error) {
+	var a objectStorageArgs
+	if len(raw) == 0 {
+		return a, fmt.Errorf("engine %s needs a path or a named collection", engine)
+	}
+	if !strings.HasPrefix(raw[0], "'") {
+		name := raw[0]
+		a.Collection = &name
+		for _, kv := range raw[1:] {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return a, fmt.Errorf("engine %s(%s, ...): override %q is not key = value", engine, name, kv)
+			}
+			if a.Overrides == nil {
+				a.Overrides = map[string]string{}
+			}
+			a.Overrides[strings.TrimSpace(k)] = unquoteString(strings.TrimSpace(v))
+		}
+		return a, nil
+	}
+
+	p := make([]string, len(raw))
+	for i, r := range raw {
+		p[i] = unquoteString(r)
+	}
+	a.Path = &p[0]
+	rest := p[1:]
+	tooMany := func() error {
+		return fmt.Errorf("engine %s: unrecognised argument list %v (unmodeled — refusing to drop arguments)", engine, p)
+	}
+	setFormat := func(fc []string) error {
+		if len(fc) > 2 {
+			return tooMany()
+		}
+		if len(fc) > 0 {
+			a.Format = &fc[0]
+		}
+		if len(fc) > 1 {
+			a.Compression = &fc[1]
+		}
+		return nil
+	}
+
+	if len(rest) > 0 && strings.EqualFold(rest[0], "NOSIGN") {
+		a.NoSign = true
+		if err := setFormat(rest[1:]); err != nil {
+			return a, err
+		}
+	} else if formatRequired {
+		// (format [, compression]) or (key, secret, format [, compression]).
+		switch len(rest) {
+		case 1, 2:
+			if err := setFormat(rest); err != nil {
+				return a, err
+			}
+		case 3, 4:
+			a.AccessKeyID, a.SecretAccessKey = &rest[0], &rest[1]
+			if err := setFormat(rest[2:]); err != nil {
+				return a, err
+			}
+		default:
+			return a, tooMany()
+		}
+	} else {
+		switch {
+		case len(rest) <= 1:
+			_ = setFormat(rest)
+		case len(rest) == 2 && isFormatName(rest[0]):
+			_ = setFormat(rest)
+		case len(rest) <= 5:
+			a.AccessKeyID, a.SecretAccessKey = &rest[0], &rest[1]
+			tail := rest[2:]
+			// A session token sits between the credentials and the format;
+			// the argument after the secret is a token unless it names a
+			// format (or it is the 3rd of 3 trailing arguments).
+			if withToken && (len(tail) == 3 || (len(tail) > 0 && !isFormatName(tail[0]))) {
+				a.SessionToken = &tail[0]
+				tail = tail[1:]
+			}
+			if err := setFormat(tail); err != nil {
+				return a, err
+			}
+		default:
+			return a, tooMany()
+		}
+	}
+	if a.Format == nil && formatRequired {
+		return a, fmt.Errorf("engine %s needs a format", engine)
+	}
+	return a, nil
+}
+
+// warnRedactedStorageSecret logs when ClickHouse redacted an engine
+// credential: the marker round-trips, but sqlgen will refuse to recreate
+// the table until the real value is known.
+func warnRedactedStorageSecret(engine string, a objectStorageArgs) {
+	for field, v := range map[string]*string{"secret_access_key": a.SecretAccessKey, "session_token": a.SessionToken} {
+		if v != nil && *v == RedactedValue {
+			slog.Warn("engine credential is redacted; hclexp cannot generate DDL recreating this table",
+				"engine", engine, "field", field,
+				"hint", "grant displaySecretsInShowAndSelect AND set display_secrets_in_show_and_select=1")
+		}
+	}
+}
+
+// rawEngineParams formats each engine argument as SQL without unquoting,
+// so a string literal can be told apart from a bare identifier.
+func rawEngineParams(p *chparser.ParamExprList) []string {
+	if p == nil || p.Items == nil {
+		return nil
+	}
+	out := make([]string, 0, len(p.Items.Items))
+	for _, it := range p.Items.Items {
+		out = append(out, formatNode(it))
+	}
+	return out
+}
+
+// externalEngineFromAST decodes the S3, S3Queue, URL and File engines.
+// ok is false for any other engine name.
+func externalEngineFromAST(e *chparser.EngineExpr) (eng Engine, ok bool, err error) {
+	raw := rawEngineParams(e.Params)
+	switch e.Name {
+	case "S3":
+		a, err := parseObjectStorageArgs("S3", raw, true, false)
+		if err != nil {
+			return nil, true, err
+		}
+		warnRedactedStorageSecret("S3", a)
+		return EngineS3{
+			Collection: a.Collection, Overrides: a.Overrides,
+			Path: a.Path, NoSign: a.NoSign,
+			AccessKeyID: a.AccessKeyID, SecretAccessKey: a.SecretAccessKey, SessionToken: a.SessionToken,
+			Format: a.Format, Compression: a.Compression,
+		}, true, nil
+	case "S3Queue":
+		a, err := parseObjectStorageArgs("S3Queue", raw, false, true)
+		if err != nil {
+			return nil, true, err
+		}
+		warnRedactedStorageSecret("S3Queue", a)
+		return EngineS3Queue{
+			Collection: a.Collection, Overrides: a.Overrides,
+			Path: a.Path, NoSign: a.NoSign,
+			AccessKeyID: a.AccessKeyID, SecretAccessKey: a.SecretAccessKey,
+			Format: a.Format, Compression: a.Compression,
+		}, true, nil
+	case "URL":
+		p := engineParamStrings(e.Params)
+		if len(p) < 1 || len(p) > 3 {
+			return nil, true, fmt.Errorf("engine URL needs (url[, format[, compression]]); got %v", p)
+		}
+		u := EngineURL{URL: p[0]}
+		if len(p) > 1 {
+			u.Format = &p[1]
+		}
+		if len(p) > 2 {
+			u.Compression = &p[2]
+		}
+		return u, true, nil
+	case "File":
+		p := engineParamStrings(e.Params)
+		if len(p) < 1 || len(p) > 2 {
+			return nil, true, fmt.Errorf("engine File needs (format[, compression]); got %v", p)
+		}
+		f := EngineFile{Format: p[0]}
+		if len(p) > 1 {
+			f.Compression = &p[1]
+		}
+		return f, true, nil
+	}
+	return nil, false, nil
+}
+
+// objectStorageSQL renders an S3/S3Queue argument list in its canonical
+// form: the collection with sorted overrides, or the positional form with
+// a format placeholder ('auto') when only a compression is set.
+func objectStorageSQL(engine string, a objectStorageArgs) string {
+	var args []string
+	if a.Collection != nil {
+		args = append(args, *a.Collection)
+		keys := make([]string, 0, len(a.Overrides))
+		for k := range a.Overrides {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			args = append(args, k+" = "+quoteString(a.Overrides[k]))
+		}
+		return fmt.Sprintf("%s(%s)", engine, strings.Join(args, ", "))
+	}
+	if a.Path != nil {
+		args = append(args, quoteString(*a.Path))
+	}
+	switch {
+	case a.NoSign:
+		args = append(args, "NOSIGN")
+	case a.AccessKeyID != nil && a.SecretAccessKey != nil:
+		args = append(args, quoteString(*a.AccessKeyID), quoteString(*a.SecretAccessKey))
+		if a.SessionToken != nil {
+			args = append(args, quoteString(*a.SessionToken))
+		}
+	}
+	if a.Format != nil {
+		args = append(args, quoteString(*a.Format))
+	} else if a.Compression != nil {
+		args = append(args, quoteString("auto"))
+	}
+	if a.Compression != nil {
+		args = append(args, quoteString(*a.Compression))
+	}
+	return fmt.Sprintf("%s(%s)", engine, strings.Join(args, ", "))
+}
+
+func (e EngineS3) storageArgs() objectStorageArgs {
+	return objectStorageArgs{
+		Collection: e.Collection, Overrides: e.Overrides,
+		Path: e.Path, NoSign: e.NoSign,
+		AccessKeyID: e.AccessKeyID, SecretAccessKey: e.SecretAccessKey, SessionToken: e.SessionToken,
+		Format: e.Format, Compression: e.Compression,
+	}
+}
+
+func (e EngineS3Queue) storageArgs() objectStorageArgs {
+	return objectStorageArgs{
+		Collection: e.Collection, Overrides: e.Overrides,
+		Path: e.Path, NoSign: e.NoSign,
+		AccessKeyID: e.AccessKeyID, SecretAccessKey: e.SecretAccessKey,
+		Format: e.Format, Compression: e.Compression,
+	}
+}
+
+// validateObjectStorage checks an authored S3/S3Queue engine: collection XOR
+// path, NOSIGN XOR credentials, credentials as a pair, and a session token
+// only alongside them.
+func validateObjectStorage(kind string, a objectStorageArgs, formatRequired bool) error {
+	if a.Collection != nil {
+		if a.Path != nil || a.NoSign || a.AccessKeyID != nil || a.SecretAccessKey != nil ||
+			a.SessionToken != nil || a.Format != nil || a.Compression != nil {
+			return fmt.Errorf("%s engine `collection` is mutually exclusive with positional arguments (use overrides)", kind)
+		}
+		return nil
+	}
+	if len(a.Overrides) > 0 {
+		return fmt.Errorf("%s engine `overrides` requires `collection`", kind)
+	}
+	if a.Path == nil {
+		return fmt.Errorf("%s engine requires `path` or `collection`", kind)
+	}
+	if (a.AccessKeyID == nil) != (a.SecretAccessKey == nil) {
+		return fmt.Errorf("%s engine `access_key_id` and `secret_access_key` must be set together", kind)
+	}
+	if a.NoSign && a.AccessKeyID != nil {
+		return fmt.Errorf("%s engine `nosign` and credentials are mutually exclusive", kind)
+	}
+	if a.SessionToken != nil && a.AccessKeyID == nil {
+		return fmt.Errorf("%s engine `session_token` requires `access_key_id` and `secret_access_key`", kind)
+	}
+	if formatRequired && a.Format == nil {
+		return fmt.Errorf("%s engine requires `format`", kind)
+	}
+	return nil
+}
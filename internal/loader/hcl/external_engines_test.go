@@ -0,0 +1,144 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func introspectEngine(t *testing.T, engineClause string) Engine {
+	t.Helper()
+	sql := "CREATE TABLE db.t (`id` UInt64) ENGINE = " + engineClause
+	db := &DatabaseSpec{Name: "db"}
+	require.NoError(t, processIntrospectRows(db, "db", &fakeRows{rows: []fakeRow{{name: "t", sql: sql}}}))
+	require.Len(t, db.Tables, 1)
+	return db.Tables[0].Engine.Decoded
+}
+
+func TestIntrospect_S3Forms(t *testing.T) {
+	s := func(v string) *string { return &v }
+	cases := []struct {
+		clause string
+		want   EngineS3
+	}{
+		{"S3('https://b/x.csv')", EngineS3{Path: s("https://b/x.csv")}},
+		{"S3('https://b/x.csv', 'CSV')", EngineS3{Path: s("https://b/x.csv"), Format: s("CSV")}},
+		{"S3('https://b/x.csv.gz', 'CSV', 'gzip')", EngineS3{Path: s("https://b/x.csv.gz"), Format: s("CSV"), Compression: s("gzip")}},
+		{"S3('https://b/x', NOSIGN, 'Parquet')", EngineS3{Path: s("https://b/x"), NoSign: true, Format: s("Parquet")}},
+		{"S3('https://b/x', 'AK', '[HIDDEN]')", EngineS3{Path: s("https://b/x"), AccessKeyID: s("AK"), SecretAccessKey: s(RedactedValue)}},
+		{"S3('https://b/x', 'AK', 'SK', 'Parquet')", EngineS3{Path: s("https://b/x"), AccessKeyID: s("AK"), SecretAccessKey: s("SK"), Format: s("Parquet")}},
+		{"S3('https://b/x', 'AK', 'SK', 'TOKEN')", EngineS3{Path: s("https://b/x"), AccessKeyID: s("AK"), SecretAccessKey: s("SK"), SessionToken: s("TOKEN")}},
+		{"S3('https://b/x', 'AK', 'SK', 'CSV', 'zstd')", EngineS3{Path: s("https://b/x"), AccessKeyID: s("AK"), SecretAccessKey: s("SK"), Format: s("CSV"), Compression: s("zstd")}},
+		{"S3('https://b/x', 'AK', 'SK', 'TOKEN', 'CSV')", EngineS3{Path: s("https://b/x"), AccessKeyID: s("AK"), SecretAccessKey: s("SK"), SessionToken: s("TOKEN"), Format: s("CSV")}},
+		{"S3('https://b/x', 'AK', 'SK', 'TOKEN', 'CSV', 'gzip')", EngineS3{Path: s("https://b/x"), AccessKeyID: s("AK"), SecretAccessKey: s("SK"), SessionToken: s("TOKEN"), Format: s("CSV"), Compression: s("gzip")}},
+		{"S3(my_s3, url = 'https://b/x', format = 'CSV')", EngineS3{Collection: s("my_s3"), Overrides: map[string]string{"url": "https://b/x", "format": "CSV"}}},
+	}
+	for _, c := range cases {
+		t.Run(c.clause, func(t *testing.T) {
+			got := introspectEngine(t, c.clause)
+			assert.Equal(t, c.want, got)
+
+			// The canonical DDL introspects back to the same engine.
+			clause, _ := engineSQL(got)
+			assert.Equal(t, got, introspectEngine(t, clause), "round trip via %s", clause)
+		})
+	}
+}
+
+func TestIntrospect_S3Queue(t *testing.T) {
+	sql := "CREATE TABLE db.q (`id` UInt64) ENGINE = S3Queue('https://b/in/*', 'AK', '[HIDDEN]', 'JSONEachRow', 'gzip') SETTINGS mode = 'ordered', keeper_path = '/q'"
+	db := &DatabaseSpec{Name: "db"}
+	require.NoError(t, processIntrospectRows(db, "db", &fakeRows{rows: []fakeRow{{name: "q", sql: sql}}}))
+	e := db.Tables[0].Engine.Decoded.(EngineS3Queue)
+	assert.Equal(t, "https://b/in/*", *e.Path)
+	assert.Equal(t, "AK", *e.AccessKeyID)
+	assert.Equal(t, RedactedValue, *e.SecretAccessKey)
+	assert.Equal(t, "JSONEachRow", *e.Format)
+	assert.Equal(t, "gzip", *e.Compression)
+	// Queue tuning stays in the table's SETTINGS.
+	assert.Equal(t, map[string]string{"mode": "ordered", "keeper_path": "/q"}, db.Tables[0].Settings)
+}
+
+func TestIntrospect_S3Queue_RequiresFormat(t *testing.T) {
+	sql := "CREATE TABLE db.q (`id` UInt64) ENGINE = S3Queue('https://b/in/*', NOSIGN)"
+	db := &DatabaseSpec{Name: "db"}
+	err := processIntrospectRows(db, "db", &fakeRows{rows: []fakeRow{{name: "q", sql: sql}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "needs a format")
+}
+
+func TestIntrospect_URL_File(t *testing.T) {
+	s := func(v string) *string { return &v }
+	assert.Equal(t, EngineURL{URL: "https://e.com/d.csv", Format: s("CSV")}, introspectEngine(t, "URL('https://e.com/d.csv', CSV)"))
+	assert.Equal(t, EngineURL{URL: "https://e.com/d.gz", Format: s("CSV"), Compression: s("gzip")}, introspectEngine(t, "URL('https://e.com/d.gz', 'CSV', 'gzip')"))
+	assert.Equal(t, EngineFile{Format: "TabSeparated"}, introspectEngine(t, "File(TabSeparated)"))
+	assert.Equal(t, EngineFile{Format: "CSV", Compression: s("zstd")}, introspectEngine(t, "File(CSV, 'zstd')"))
+}
+
+func TestSQLGen_ExternalEngines(t *testing.T) {
+	s := func(v string) *string { return &v }
+	for _, c := range []struct {
+		dec  Engine
+		want string
+	}{
+		{EngineS3{Path: s("https://b/x"), NoSign: true, Format: s("CSV")}, "S3('https://b/x', NOSIGN, 'CSV')"},
+		{EngineS3{Path: s("https://b/x"), Compression: s("gzip")}, "S3('https://b/x', 'auto', 'gzip')"},
+		{EngineS3{Collection: s("nc"), Overrides: map[string]string{"url": "u", "format": "CSV"}}, "S3(nc, format = 'CSV', url = 'u')"},
+		{EngineS3Queue{Path: s("https://b/*"), Format: s("CSV")}, "S3Queue('https://b/*', 'CSV')"},
+		{EngineURL{URL: "https://e.com/d", Format: s("CSV"), Compression: s("gzip")}, "URL('https://e.com/d', CSV, 'gzip')"},
+		{EngineFile{Format: "Parquet"}, "File(Parquet)"},
+		// Quotes inside string arguments are escaped, not spliced into the DDL.
+		{EngineS3{Path: s("https://b/it's/x"), AccessKeyID: s("A'K"), SecretAccessKey: s("S'K")}, `S3('https://b/it\'s/x', 'A\'K', 'S\'K')`},
+		{EngineS3{Collection: s("nc"), Overrides: map[string]string{"url": "https://b/o'brien"}}, `S3(nc, url = 'https://b/o\'brien')`},
+		{EngineURL{URL: "https://e.com/?q='x'", Format: s("CSV")}, `URL('https://e.com/?q=\'x\'', CSV)`},
+		{EngineFile{Format: "CSV", Compression: s("g'zip")}, `File(CSV, 'g\'zip')`},
+	} {
+		got, _ := engineSQL(c.dec)
+		assert.Equal(t, c.want, got)
+	}
+}
+
+// A table whose engine carries a redacted credential is never created: the
+// placeholder would overwrite the real secret.
+func TestSQLGen_S3RedactedSecretIsBlocked(t *testing.T) {
+	s := func(v string) *string { return &v }
+	ts := TableSpec{Name: "t",
+		Columns: []ColumnSpec{{Name: "id", Type: "UUID"}},
+		Engine: &EngineSpec{Kind: "s3", Decoded: EngineS3{
+			Path: s("https://b/x"), AccessKeyID: s("AK"), SecretAccessKey: s(RedactedValue), Format: s("CSV"),
+		}},
+	}
+	out := GenerateSQL(ChangeSet{Databases: []DatabaseChange{{Database: "db", AddTables: []TableSpec{ts}}}})
+	assert.Empty(t, out.Statements)
+	require.Len(t, out.Unsafe, 1)
+	assert.Contains(t, out.Unsafe[0].Reason, RedactedValue)
+}
+
+func TestResolve_ExternalEngineValidation(t *testing.T) {
+	s := func(v string) *string { return &v }
+	for _, c := range []struct {
+		name string
+		dec  Engine
+		err  string
+	}{
+		{"collection_and_path", EngineS3{Collection: s("nc"), Path: s("p")}, "mutually exclusive"},
+		{"no_path", EngineS3{Format: s("CSV")}, "requires `path` or `collection`"},
+		{"key_without_secret", EngineS3{Path: s("p"), AccessKeyID: s("AK")}, "must be set together"},
+		{"nosign_and_keys", EngineS3{Path: s("p"), NoSign: true, AccessKeyID: s("AK"), SecretAccessKey: s("SK")}, "nosign"},
+		{"token_without_keys", EngineS3{Path: s("p"), SessionToken: s("T")}, "session_token"},
+		{"overrides_without_collection", EngineS3{Path: s("p"), Overrides: map[string]string{"a": "b"}}, "overrides"},
+		{"queue_without_format", EngineS3Queue{Path: s("p")}, "requires `format`"},
+		{"url_compression_without_format", EngineURL{URL: "u", Compression: s("gzip")}, "requires `format`"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			schema := &Schema{Databases: []DatabaseSpec{{Name: "db", Tables: []TableSpec{{
+				Name: "t", Columns: []ColumnSpec{{Name: "id", Type: "UUID"}},
+				Engine: &EngineSpec{Kind: c.dec.Kind(), Decoded: c.dec},
+			}}}}}
+			err := Resolve(schema)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), c.err)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package hcl
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// collisionMarker separates an encoded file name from the numeric suffix
+// FileNames appends when two names encode to the same file. A literal '~' in
+// a name is itself escaped, so a trailing "~N" is never ambiguous.
+const collisionMarker = '~'
+
+// windowsReserved are device names Windows refuses as a file name stem, in
+// any case and with any extension ("CON.hcl" is as invalid as "CON").
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// EncodeFileName turns an object or host name into a file name stem that is
+// valid on Linux, macOS and Windows. Characters no portable filesystem
+// accepts (path separators, <>:"|?*, control characters), the escape
+// character '%' and the collision marker '~' are percent-encoded as %XX; a
+// trailing dot or space (silently stripped by Windows) and a leading dot
+// (a hidden file) are encoded too, as is the first character of a Windows
+// device name such as "CON". Ordinary names pass through unchanged, so
+// existing dumps keep their file names. DecodeFileName reverses it.
+func EncodeFileName(name string) string {
+	if name == "" {
+		return "%"
+	}
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if needsEscape(c) ||
+			(i == 0 && c == '.') ||
+			(i == len(name)-1 && (c == '.' || c == ' ')) {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	out := b.String()
+	stem, _, _ := strings.Cut(out, ".")
+	if windowsReserved[strings.ToUpper(stem)] {
+		out = fmt.Sprintf("%%%02X", out[0]) + out[1:]
+	}
+	return out
+}
+
+func needsEscape(c byte) bool {
+	if c < 0x20 || c == 0x7f {
+		return true
+	}
+	switch c {
+	case '/', '\\', '<', '>', ':', '"', '|', '?', '*', '%', collisionMarker:
+		return true
+	}
+	return false
+}
+
+// DecodeFileName recovers the name a file name stem was encoded from: it
+// drops a FileNames collision suffix ("~2") and percent-decodes the rest.
+// Stems that were never encoded, or carry a malformed escape, come back
+// unchanged apart from the suffix, so hand-named files keep their identity.
+func DecodeFileName(stem string) string {
+	stem, _ = splitCollisionSuffix(stem)
+	return decodeStem(stem)
+}
+
+// DumpNodeName is the node identity of a per-node dump file that has no
+// node{} block: its file name stem, decoded. Unlike DecodeFileName it keeps a
+// FileNames collision suffix, so two hosts whose short names collided (and
+// were written to "ch-1.hcl" and "ch-1~2.hcl") stay two distinct nodes.
+func DumpNodeName(path string) string {
+	stem, suffix := splitCollisionSuffix(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	return decodeStem(stem) + suffix
+}
+
+// splitCollisionSuffix splits a trailing FileNames collision suffix ("~2")
+// off stem, returning the stem without it and the suffix ("" when absent).
+func splitCollisionSuffix(stem string) (string, string) {
+	if i := strings.LastIndexByte(stem, collisionMarker); i >= 0 {
+		if _, err := strconv.Atoi(stem[i+1:]); err == nil {
+			return stem[:i], stem[i:]
+		}
+	}
+	return stem, ""
+}
+
+// decodeStem percent-decodes an EncodeFileName stem.
+func decodeStem(stem string) string {
+	if stem == "%" {
+		return ""
+	}
+	if !strings.Contains(stem, "%") {
+		return stem
+	}
+	var b strings.Builder
+	for i := 0; i < len(stem); i++ {
+		if stem[i] == '%' && i+2 < len(stem) {
+			if v, err := strconv.ParseUint(stem[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(stem[i])
+	}
+	return b.String()
+}
+
+// FileNames encodes names with EncodeFileName and makes the results unique,
+// returned in input order. Two names can collide once encoded on a
+// case-insensitive filesystem (macOS, Windows: "Events" and "events"), or
+// when callers shorten them first (two hosts sharing a first DNS label); the
+// first keeps its plain stem and each later one gets a "~2", "~3", ...
+// suffix. The suffix depends on input order, so callers pass names sorted.
+func FileNames(names []string) []string {
+	out := make([]string, len(names))
+	taken := make(map[string]bool, len(names))
+	for i, n := range names {
+		stem := EncodeFileName(n)
+		candidate := stem
+		for k := 2; taken[strings.ToLower(candidate)]; k++ {
+			candidate = stem + string(collisionMarker) + strconv.Itoa(k)
+		}
+		taken[strings.ToLower(candidate)] = true
+		out[i] = candidate
+	}
+	return out
+}
@@ -0,0 +1,77 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeFileName(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"posthog", "posthog"},
+		{"chi-clickhouse-0-0", "chi-clickhouse-0-0"},
+		{"events_v2.backup", "events_v2.backup"},
+		{"a/b", "a%2Fb"},
+		{`a\b`, "a%5Cb"},
+		{"x:y*z?", "x%3Ay%2Az%3F"},
+		{`"q"|<>`, "%22q%22%7C%3C%3E"},
+		{"100%", "100%25"},
+		{"a~2", "a%7E2"},
+		{"tab\there", "tab%09here"},
+		{".hidden", "%2Ehidden"},
+		{"trailing.", "trailing%2E"},
+		{"trailing ", "trailing%20"},
+		{"CON", "%43ON"},
+		{"com1.data", "%63om1.data"},
+		{"console", "console"},
+		{"", "%"},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			assert.Equal(t, c.want, EncodeFileName(c.in))
+		})
+	}
+}
+
+func TestDecodeFileName_RoundTrips(t *testing.T) {
+	for _, name := range []string{
+		"posthog", "a/b", `a\b`, "x:y*z?", "100%", "a~2", ".hidden",
+		"trailing.", "trailing ", "CON", "com1.data", "", "ünïcode",
+	} {
+		assert.Equal(t, name, DecodeFileName(EncodeFileName(name)), "name %q", name)
+	}
+}
+
+func TestDecodeFileName_HandNamedStems(t *testing.T) {
+	// Stems that were never encoded keep their identity; only a numeric
+	// collision suffix is stripped.
+	assert.Equal(t, "node-1", DecodeFileName("node-1"))
+	assert.Equal(t, "bad%zz", DecodeFileName("bad%zz"))
+	assert.Equal(t, "odd~name", DecodeFileName("odd~name"))
+	assert.Equal(t, "events", DecodeFileName("events~3"))
+}
+
+func TestDumpNodeName_KeepsCollisionSuffix(t *testing.T) {
+	assert.Equal(t, "ch-1", DumpNodeName("dumps/ch-1.hcl"))
+	assert.Equal(t, "ch-1~2", DumpNodeName("dumps/ch-1~2.hcl"))
+	assert.Equal(t, "a/b~3", DumpNodeName(EncodeFileName("a/b")+"~3.hcl"))
+	assert.Equal(t, "odd~name", DumpNodeName("odd~name.hcl"))
+}
+
+func TestFileNames_ResolvesCollisions(t *testing.T) {
+	got := FileNames([]string{"events", "Events", "ch-1", "EVENTS", "ch-1"})
+	require.Equal(t, []string{"events", "Events~2", "ch-1", "EVENTS~3", "ch-1~2"}, got)
+	for i, n := range []string{"events", "Events", "ch-1", "EVENTS", "ch-1"} {
+		assert.Equal(t, n, DecodeFileName(got[i]))
+	}
+}
+
+func TestFileNames_SuffixDoesNotCollideWithEncodedName(t *testing.T) {
+	// A literal "a~2" encodes to "a%7E2", so it can never be mistaken for
+	// the second "a".
+	got := FileNames([]string{"a", "a~2", "a"})
+	require.Equal(t, []string{"a", "a%7E2", "a~2"}, got)
+}
@@ -0,0 +1,56 @@
+package hcl
+
+// FunctionChange describes a planned change to a SQL user-defined function.
+type FunctionChange struct {
+	Name string
+
+	// Add is set for a fresh function; Drop for one that is gone.
+	Add  *FunctionSpec
+	Drop bool
+
+	// AsChange is set when the body differs; Target is the function to
+	// replace it with.
+	AsChange *StringChange
+	Target   *FunctionSpec
+}
+
+func (c FunctionChange) IsEmpty() bool {
+	return c.Add == nil && !c.Drop && c.AsChange == nil
+}
+
+// diffFunctions returns the per-function changes between two schemas,
+// sorted by name. Only the body is compared.
+func diffFunctions(from, to []FunctionSpec) []FunctionChange {
+	fromIdx := map[string]*FunctionSpec{}
+	for i := range from {
+		fromIdx[from[i].Name] = &from[i]
+	}
+	toIdx := map[string]*FunctionSpec{}
+	for i := range to {
+		toIdx[to[i].Name] = &to[i]
+	}
+	names := map[string]bool{}
+	for n := range fromIdx {
+		names[n] = true
+	}
+	for n := range toIdx {
+		names[n] = true
+	}
+
+	var out []FunctionChange
+	for _, n := range sortedKeys(names) {
+		f, t := fromIdx[n], toIdx[n]
+		switch {
+		case f == nil:
+			cp := *t
+			out = append(out, FunctionChange{Name: n, Add: &cp})
+		case t == nil:
+			out = append(out, FunctionChange{Name: n, Drop: true})
+		case f.As != t.As:
+			cp := *t
+			o, nw := f.As, t.As
+			out = append(out, FunctionChange{Name: n, AsChange: &StringChange{Old: &o, New: &nw}, Target: &cp})
+		}
+	}
+	return out
+}
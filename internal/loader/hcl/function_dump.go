@@ -0,0 +1,16 @@
+package hcl
+
+import (
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func writeFunction(body *hclwrite.Body, f FunctionSpec) {
+	if f.Override {
+		body.SetAttributeValue("override", cty.True)
+	}
+	if f.Cluster != nil {
+		body.SetAttributeValue("cluster", cty.StringVal(*f.Cluster))
+	}
+	body.SetAttributeValue("as", cty.StringVal(f.As))
+}
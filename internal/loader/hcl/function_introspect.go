@@ -0,0 +1,46 @@
+package hcl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// IntrospectFunctions returns every SQL user-defined function of the live
+// server, from system.functions. Builtins, executable UDFs (defined in the
+// server config) and other non-SQL functions are not returned: hclexp cannot
+// create them.
+func IntrospectFunctions(ctx context.Context, conn driver.Conn) ([]FunctionSpec, error) {
+	const q = `SELECT name, create_query
+		FROM system.functions
+		WHERE origin = 'SQLUserDefined'
+		ORDER BY name`
+	rows, err := conn.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("query system.functions: %w", err)
+	}
+	defer rows.Close()
+	return processFunctionRows(rows)
+}
+
+// processFunctionRows builds one spec per (name, create_query) row, keeping
+// the lambda of the CREATE FUNCTION in canonical form.
+func processFunctionRows(rows rowScanner) ([]FunctionSpec, error) {
+	var out []FunctionSpec
+	for rows.Next() {
+		var name, createQuery string
+		if err := rows.Scan(&name, &createQuery); err != nil {
+			return nil, fmt.Errorf("scan system.functions: %w", err)
+		}
+		_, body, err := parseCreateFunction(createQuery)
+		if err != nil {
+			return nil, fmt.Errorf("function %q: %w", name, err)
+		}
+		out = append(out, FunctionSpec{Name: name, As: body})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return out, nil
+}
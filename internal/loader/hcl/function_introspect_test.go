@@ -0,0 +1,26 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessFunctionRows(t *testing.T) {
+	fns, err := processFunctionRows(&anyRows{rows: [][]any{
+		{"is_bot", "CREATE FUNCTION is_bot AS ua -> match(ua, 'bot')"},
+		{"linear", "CREATE FUNCTION linear AS (x, k, b) -> ((k * x) + b)"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []FunctionSpec{
+		{Name: "is_bot", As: "(ua) -> match(ua, 'bot')"},
+		{Name: "linear", As: "(x, k, b) -> (k * x) + b"},
+	}, fns)
+}
+
+func TestProcessFunctionRows_Unparseable(t *testing.T) {
+	_, err := processFunctionRows(&anyRows{rows: [][]any{{"f", "CREATE FUNCTION f AS 1"}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `function "f"`)
+}
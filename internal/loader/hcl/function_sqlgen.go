@@ -0,0 +1,62 @@
+package hcl
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	chparser "github.com/orian/clickhouse-sql-parser/parser"
+)
+
+func createFunctionSQL(f FunctionSpec) string {
+	return functionDDL("CREATE FUNCTION", f)
+}
+
+// replaceFunctionSQL renders an in-place body change. ClickHouse has no ALTER
+// FUNCTION; CREATE OR REPLACE swaps the body atomically, so queries calling
+// the function never see it missing.
+func replaceFunctionSQL(f FunctionSpec) string {
+	return functionDDL("CREATE OR REPLACE FUNCTION", f)
+}
+
+func dropFunctionSQL(name string) string {
+	return "DROP FUNCTION " + name
+}
+
+func functionDDL(verb string, f FunctionSpec) string {
+	sql := verb + " " + f.Name
+	if f.Cluster != nil {
+		sql += " ON CLUSTER " + *f.Cluster
+	}
+	return fmt.Sprintf("%s AS %s", sql, f.As)
+}
+
+// normalizeFunctionBody returns the lambda as in its canonical form: the
+// parameter list and body as the parser prints them, without redundant outer
+// parentheses around the body.
+func normalizeFunctionBody(as string) (string, error) {
+	_, body, err := parseCreateFunction("CREATE FUNCTION _ AS " + as)
+	return body, err
+}
+
+// bareLambdaParam matches a one-parameter lambda written without parentheses
+// (`AS x -> …`, as ClickHouse prints it), which the parser does not accept.
+var bareLambdaParam = regexp.MustCompile("(?is)^(.*?\\bAS\\s+)(\\w+|`[^`]+`)(\\s*->)")
+
+// parseCreateFunction splits a CREATE FUNCTION statement into the function
+// name and its canonical lambda.
+func parseCreateFunction(createSQL string) (name, body string, err error) {
+	createSQL = bareLambdaParam.ReplaceAllString(createSQL, "${1}(${2})${3}")
+	stmt, err := parseCreateStatement(createSQL)
+	if err != nil {
+		return "", "", err
+	}
+	cf, ok := stmt.(*chparser.CreateFunction)
+	if !ok || cf.Params == nil || cf.Expr == nil {
+		return "", "", errors.New("not a CREATE FUNCTION <name> AS (<params>) -> <expr> statement")
+	}
+	if cf.FunctionName != nil {
+		name = stripBackticks(cf.FunctionName.Name)
+	}
+	return name, formatNode(cf.Params) + " -> " + formatNode(unwrapRootParens(cf.Expr)), nil
+}
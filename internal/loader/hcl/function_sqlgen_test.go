@@ -0,0 +1,111 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunctionSQL(t *testing.T) {
+	cluster := "posthog"
+	f := FunctionSpec{Name: "linear", Cluster: &cluster, As: "(x, k, b) -> (k * x) + b"}
+	assert.Equal(t, "CREATE FUNCTION linear ON CLUSTER posthog AS (x, k, b) -> (k * x) + b", createFunctionSQL(f))
+	assert.Equal(t, "CREATE OR REPLACE FUNCTION linear ON CLUSTER posthog AS (x, k, b) -> (k * x) + b", replaceFunctionSQL(f))
+	assert.Equal(t, "DROP FUNCTION linear", dropFunctionSQL("linear"))
+}
+
+func TestNormalizeFunctionBody(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"(x, k, b) -> k*x + b", "(x, k, b) -> k * x + b"},
+		{"(x) -> (x + 1)", "(x) -> x + 1"},
+		{"x -> (x + 1)", "(x) -> x + 1"},
+		{"(ua) -> match(ua, 'bot')", "(ua) -> match(ua, 'bot')"},
+	} {
+		got, err := normalizeFunctionBody(tc.in)
+		require.NoError(t, err, tc.in)
+		assert.Equal(t, tc.want, got, tc.in)
+	}
+	_, err := normalizeFunctionBody("x + 1")
+	assert.Error(t, err)
+}
+
+func TestDiffFunctions(t *testing.T) {
+	from := []FunctionSpec{
+		{Name: "kept", As: "(x) -> x + 1"},
+		{Name: "changed", As: "(x) -> x + 1"},
+		{Name: "gone", As: "(x) -> x"},
+	}
+	to := []FunctionSpec{
+		{Name: "kept", As: "(x) -> x + 1", Cluster: ptr("posthog")},
+		{Name: "changed", As: "(x) -> x + 2"},
+		{Name: "fresh", As: "(x) -> x * 2"},
+	}
+	got := diffFunctions(from, to)
+	require.Len(t, got, 3)
+
+	assert.Equal(t, "changed", got[0].Name)
+	require.NotNil(t, got[0].AsChange)
+	assert.Equal(t, "(x) -> x + 1", *got[0].AsChange.Old)
+	assert.Equal(t, "(x) -> x + 2", *got[0].AsChange.New)
+
+	assert.Equal(t, "fresh", got[1].Name)
+	require.NotNil(t, got[1].Add)
+
+	assert.Equal(t, "gone", got[2].Name)
+	assert.True(t, got[2].Drop)
+}
+
+func TestGenerateSQL_Functions(t *testing.T) {
+	from := &Schema{Functions: []FunctionSpec{
+		{Name: "changed", As: "(x) -> x + 1"},
+		{Name: "gone", As: "(x) -> x"},
+	}}
+	to := &Schema{
+		DefaultOnCluster: ptr("posthog"),
+		Functions: []FunctionSpec{
+			{Name: "changed", As: "(x) -> x + 2"},
+			{Name: "fresh", As: "(x) -> x * 2"},
+		},
+	}
+	cs := Diff(from, to)
+	gen := GenerateSQL(cs)
+	assert.Equal(t, []string{
+		"CREATE FUNCTION fresh ON CLUSTER posthog AS (x) -> x * 2",
+		"CREATE OR REPLACE FUNCTION changed ON CLUSTER posthog AS (x) -> x + 2",
+		"DROP FUNCTION gone",
+	}, gen.Statements)
+	for _, op := range gen.Ops {
+		assert.Equal(t, KindFunction, op.ObjectType)
+		assert.Empty(t, op.Database)
+	}
+
+	sum := SummarizeComparisons(BuildObjectComparisons(cs, gen, from, to))
+	assert.Equal(t, 3, sum.FunctionsChanged)
+	assert.Equal(t, "~3 function", sum.OneLiner())
+}
+
+// A function is created before, and dropped after, the materialized views
+// that call it.
+func TestGenerateSQL_FunctionsAroundViews(t *testing.T) {
+	mv := MaterializedViewSpec{Name: "mv", ToTable: "dst", Query: "SELECT linear(a, 2, 1) AS a FROM src"}
+	tables := []TableSpec{
+		mkTable("src", EngineMergeTree{}, ColumnSpec{Name: "a", Type: "Int64"}),
+		mkTable("dst", EngineMergeTree{}, ColumnSpec{Name: "a", Type: "Int64"}),
+	}
+	withMV := &Schema{
+		Databases: []DatabaseSpec{{Name: "db", Tables: tables, MaterializedViews: []MaterializedViewSpec{mv}}},
+		Functions: []FunctionSpec{{Name: "linear", As: "(x, k, b) -> (k * x) + b"}},
+	}
+	empty := &Schema{Databases: []DatabaseSpec{{Name: "db", Tables: tables}}}
+
+	up := GenerateSQL(Diff(empty, withMV)).Statements
+	require.Len(t, up, 2)
+	assert.Contains(t, up[0], "CREATE FUNCTION linear")
+	assert.Contains(t, up[1], "CREATE MATERIALIZED VIEW db.mv")
+
+	down := GenerateSQL(Diff(withMV, empty)).Statements
+	require.Len(t, down, 2)
+	assert.Contains(t, down[0], "DROP VIEW db.mv")
+	assert.Equal(t, "DROP FUNCTION linear", down[1])
+}
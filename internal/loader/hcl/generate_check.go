@@ -0,0 +1,55 @@
+package hcl
+
+import "fmt"
+
+// GenerateCheckKind is the ValidationError.Kind of a CheckGenerate finding.
+const GenerateCheckKind = "generate"
+
+// CheckGenerate runs the rest of the pipeline offline. It diffs s against an
+// empty current state and generates the DDL that creates every object, just
+// as a first apply to a fresh cluster would. It reports:
+//   - a generator panic;
+//   - every object the generator refuses (an unsafe change);
+//   - every generated CREATE of a table, view, materialized view or
+//     dictionary that the SQL parser rejects, which catches engine and
+//     setting combinations the generator renders wrongly.
+//
+// No database is needed. Raw blocks are emitted verbatim and not re-parsed.
+func CheckGenerate(s *Schema) (errs []ValidationError) {
+	defer func() {
+		if r := recover(); r != nil {
+			errs = append(errs, ValidationError{
+				Object: ObjectRef{Name: "schema"},
+				Kind:   GenerateCheckKind,
+				Reason: fmt.Sprintf("SQL generator panicked: %v", r),
+			})
+		}
+	}()
+
+	gen := GenerateSQL(Diff(&Schema{}, s))
+	for _, u := range gen.Unsafe {
+		errs = append(errs, ValidationError{
+			Object: ObjectRef{Database: u.Database, Name: u.Table},
+			Kind:   GenerateCheckKind,
+			Reason: "cannot generate CREATE: " + u.Reason,
+		})
+	}
+	for _, op := range gen.Ops {
+		if op.Kind != OpCreate {
+			continue
+		}
+		switch op.ObjectType {
+		case KindTable, KindMaterializedView, KindView, KindDictionary:
+		default:
+			continue
+		}
+		if err := introspectOneObject(&DatabaseSpec{Name: op.Database}, op.Database, op.Object, op.SQL); err != nil {
+			errs = append(errs, ValidationError{
+				Object: ObjectRef{Database: op.Database, Name: op.Object},
+				Kind:   GenerateCheckKind,
+				Reason: fmt.Sprintf("generated CREATE does not parse: %v", err),
+			})
+		}
+	}
+	return errs
+}
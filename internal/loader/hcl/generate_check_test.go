@@ -0,0 +1,41 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckGenerate_CleanSchema(t *testing.T) {
+	s := &Schema{Databases: []DatabaseSpec{
+		mkDBMixed("posthog",
+			[]TableSpec{
+				mkTable("events_local", EngineMergeTree{}, ColumnSpec{Name: "team_id", Type: "UInt64"}),
+				mkTable("metrics", EngineMergeTree{}, ColumnSpec{Name: "team_id", Type: "UInt64"}),
+			},
+			[]MaterializedViewSpec{
+				mkMV("metrics_mv", "posthog.metrics", "SELECT team_id FROM posthog.events_local"),
+			},
+		),
+	}}
+	assert.Empty(t, CheckGenerate(s))
+}
+
+func TestCheckGenerate_RedactedValueRefused(t *testing.T) {
+	tbl := mkTable("events", EngineMergeTree{}, ColumnSpec{Name: "team_id", Type: "UInt64"})
+	tbl.Comment = strPtr("token " + RedactedValue)
+	errs := CheckGenerate(&Schema{Databases: []DatabaseSpec{mkDB("posthog", tbl)}})
+	require.Len(t, errs, 1)
+	assert.Equal(t, ObjectRef{Database: "posthog", Name: "events"}, errs[0].Object)
+	assert.Equal(t, GenerateCheckKind, errs[0].Kind)
+	assert.Contains(t, errs[0].Reason, "cannot generate CREATE")
+}
+
+func TestCheckGenerate_UnparsableCreate(t *testing.T) {
+	tbl := mkTable("events", EngineMergeTree{}, ColumnSpec{Name: "team_id", Type: "UInt64("})
+	errs := CheckGenerate(&Schema{Databases: []DatabaseSpec{mkDB("posthog", tbl)}})
+	require.Len(t, errs, 1)
+	assert.Equal(t, ObjectRef{Database: "posthog", Name: "events"}, errs[0].Object)
+	assert.Contains(t, errs[0].Reason, "generated CREATE does not parse")
+}
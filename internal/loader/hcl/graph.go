@@ -0,0 +1,185 @@
+package hcl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Graph formats RenderGraph accepts.
+const (
+	GraphMermaid = "mermaid"
+	GraphDOT     = "dot"
+)
+
+// DepDictionarySource is the GraphEdge.Kind of a dictionary loading from a
+// table on the same server. It is a data-flow edge only: CollectDependencies
+// does not report it, so validate does not require dictionary sources to be
+// declared.
+const DepDictionarySource = "dictionary_source"
+
+// GraphEdge is one data-flow edge, pointing the way rows move: from a source
+// table into the view or dictionary reading it, from a materialized view into
+// its destination, from a Distributed, Buffer or TimeSeries table into the
+// table behind it.
+type GraphEdge struct {
+	From ObjectRef
+	To   ObjectRef
+	Kind string // a Dep* constant
+}
+
+// DataFlowEdges returns the data-flow graph of a resolved schema: every
+// CollectDependencies dependency, oriented along the data, plus the local
+// tables ClickHouse-sourced dictionaries load from. Duplicate edges are
+// dropped; the order is otherwise CollectDependencies' order, then
+// dictionaries.
+func DataFlowEdges(dbs []DatabaseSpec) ([]GraphEdge, error) {
+	deps, err := CollectDependencies(dbs)
+	if err != nil {
+		return nil, err
+	}
+	var edges []GraphEdge
+	seen := map[GraphEdge]bool{}
+	add := func(e GraphEdge) {
+		if !seen[e] {
+			seen[e] = true
+			edges = append(edges, e)
+		}
+	}
+	for _, d := range deps {
+		if d.Kind == DepMVSource || d.Kind == DepViewSource {
+			add(GraphEdge{From: d.To, To: d.From, Kind: d.Kind})
+		} else {
+			add(GraphEdge{From: d.From, To: d.To, Kind: d.Kind})
+		}
+	}
+	for _, db := range dbs {
+		for _, dict := range db.Dictionaries {
+			srcs, err := dictionarySourceTables(db.Name, dict)
+			if err != nil {
+				return nil, fmt.Errorf("dictionary %s.%s: %w", db.Name, dict.Name, err)
+			}
+			for _, src := range srcs {
+				add(GraphEdge{From: src, To: ObjectRef{Database: db.Name, Name: dict.Name}, Kind: DepDictionarySource})
+			}
+		}
+	}
+	return edges, nil
+}
+
+// dictionarySourceTables returns the tables a dictionary's clickhouse source
+// reads on the local server. A source with a host set points at another
+// server and contributes nothing.
+func dictionarySourceTables(database string, d DictionarySpec) ([]ObjectRef, error) {
+	if d.Source == nil {
+		return nil, nil
+	}
+	src, ok := d.Source.Decoded.(SourceClickHouse)
+	if !ok || src.Host != nil {
+		return nil, nil
+	}
+	if src.DB != nil {
+		database = *src.DB
+	}
+	if src.Table != nil {
+		return []ObjectRef{splitQualified(*src.Table, database)}, nil
+	}
+	if src.Query == nil {
+		return nil, nil
+	}
+	refs, err := extractSourceTables(*src.Query)
+	if err != nil {
+		return nil, fmt.Errorf("parsing source query: %w", err)
+	}
+	for i := range refs {
+		if refs[i].Database == "" {
+			refs[i].Database = database
+		}
+	}
+	return refs, nil
+}
+
+// graphEdgeLabels names the edges that are not self-explanatory from their
+// endpoints' shapes.
+var graphEdgeLabels = map[string]string{
+	DepDistributedRemote: "remote",
+	DepBufferDestination: "flush",
+	DepTimeSeriesTarget:  "target",
+}
+
+// RenderGraph writes edges as a Mermaid flowchart or a Graphviz DOT digraph.
+// dbs supplies each node's kind, which picks its shape: tables are boxes,
+// materialized views rounded, plain views dashed, dictionaries hexagons.
+// Objects referenced but not declared in dbs (external or missing) are drawn
+// as tables.
+func RenderGraph(w io.Writer, dbs []DatabaseSpec, edges []GraphEdge, format string) error {
+	byName := map[string]*DatabaseSpec{}
+	for i := range dbs {
+		byName[dbs[i].Name] = &dbs[i]
+	}
+	ids := map[ObjectRef]string{}
+	var nodes []ObjectRef
+	id := func(r ObjectRef) string {
+		if _, ok := ids[r]; !ok {
+			ids[r] = fmt.Sprintf("n%d", len(nodes))
+			nodes = append(nodes, r)
+		}
+		return ids[r]
+	}
+	for _, e := range edges {
+		id(e.From)
+		id(e.To)
+	}
+	kindOf := func(r ObjectRef) string { return ObjectKind(byName[r.Database], r.Name) }
+
+	switch format {
+	case GraphMermaid:
+		fmt.Fprintln(w, "flowchart LR")
+		for _, r := range nodes {
+			label := fmt.Sprintf("%q", r.String())
+			switch kindOf(r) {
+			case KindMaterializedView:
+				label = "([" + label + "])"
+			case KindView:
+				label = "(" + label + ")"
+			case KindDictionary:
+				label = "{{" + label + "}}"
+			default:
+				label = "[" + label + "]"
+			}
+			fmt.Fprintf(w, "  %s%s\n", ids[r], label)
+		}
+		for _, e := range edges {
+			arrow := "-->"
+			if l := graphEdgeLabels[e.Kind]; l != "" {
+				arrow += "|" + l + "|"
+			}
+			fmt.Fprintf(w, "  %s %s %s\n", ids[e.From], arrow, ids[e.To])
+		}
+	case GraphDOT:
+		fmt.Fprintln(w, "digraph schema {\n  rankdir=LR;\n  node [shape=box];")
+		for _, r := range nodes {
+			attrs := []string{fmt.Sprintf("label=%q", r.String())}
+			switch kindOf(r) {
+			case KindMaterializedView:
+				attrs = append(attrs, `style=rounded`)
+			case KindView:
+				attrs = append(attrs, `style=dashed`)
+			case KindDictionary:
+				attrs = append(attrs, `shape=hexagon`)
+			}
+			fmt.Fprintf(w, "  %s [%s];\n", ids[r], strings.Join(attrs, ", "))
+		}
+		for _, e := range edges {
+			attr := ""
+			if l := graphEdgeLabels[e.Kind]; l != "" {
+				attr = fmt.Sprintf(" [label=%q]", l)
+			}
+			fmt.Fprintf(w, "  %s -> %s%s;\n", ids[e.From], ids[e.To], attr)
+		}
+		fmt.Fprintln(w, "}")
+	default:
+		return fmt.Errorf("unknown graph format %q (want %s or %s)", format, GraphMermaid, GraphDOT)
+	}
+	return nil
+}
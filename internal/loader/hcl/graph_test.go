@@ -0,0 +1,67 @@
+package hcl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func graphFixture() []DatabaseSpec {
+	db := mkDBMixed("posthog",
+		[]TableSpec{
+			mkTable("events", EngineMergeTree{}, ColumnSpec{Name: "id", Type: "UInt64"}),
+			mkTable("counts", EngineMergeTree{}, ColumnSpec{Name: "id", Type: "UInt64"}),
+			mkTable("events_dist", EngineDistributed{ClusterName: "main", RemoteDatabase: "posthog", RemoteTable: "events"}),
+		},
+		[]MaterializedViewSpec{mkMV("counts_mv", "posthog.counts", "SELECT id FROM posthog.events")},
+	)
+	db.Dictionaries = []DictionarySpec{
+		{Name: "names", Source: &DictionarySourceSpec{Kind: "clickhouse", Decoded: SourceClickHouse{Table: strPtr("counts")}}},
+		{Name: "remote", Source: &DictionarySourceSpec{Kind: "clickhouse", Decoded: SourceClickHouse{Host: strPtr("other"), Table: strPtr("x")}}},
+	}
+	return []DatabaseSpec{db}
+}
+
+func TestDataFlowEdges(t *testing.T) {
+	edges, err := DataFlowEdges(graphFixture())
+	require.NoError(t, err)
+	ref := func(n string) ObjectRef { return ObjectRef{Database: "posthog", Name: n} }
+	assert.Equal(t, []GraphEdge{
+		{From: ref("counts_mv"), To: ref("counts"), Kind: DepMVDest},
+		{From: ref("events"), To: ref("counts_mv"), Kind: DepMVSource},
+		{From: ref("events_dist"), To: ref("events"), Kind: DepDistributedRemote},
+		{From: ref("counts"), To: ref("names"), Kind: DepDictionarySource},
+	}, edges, "the remote-host dictionary source is not a local edge")
+}
+
+func TestRenderGraph(t *testing.T) {
+	dbs := graphFixture()
+	edges, err := DataFlowEdges(dbs)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderGraph(&buf, dbs, edges, GraphMermaid))
+	assert.Equal(t, `flowchart LR
+  n0(["posthog.counts_mv"])
+  n1["posthog.counts"]
+  n2["posthog.events"]
+  n3["posthog.events_dist"]
+  n4{{"posthog.names"}}
+  n0 --> n1
+  n2 --> n0
+  n3 -->|remote| n2
+  n1 --> n4
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, RenderGraph(&buf, dbs, edges, GraphDOT))
+	out := buf.String()
+	assert.Contains(t, out, "digraph schema {\n")
+	assert.Contains(t, out, `  n0 [label="posthog.counts_mv", style=rounded];`)
+	assert.Contains(t, out, `  n4 [label="posthog.names", shape=hexagon];`)
+	assert.Contains(t, out, `  n3 -> n2 [label="remote"];`)
+
+	assert.Error(t, RenderGraph(&buf, dbs, edges, "svg"))
+}
@@ -12,16 +12,45 @@ import (
 
 // evalContextForFile builds the HCL evaluation context used when decoding a
 // file, exposing helper functions whose paths resolve relative to that file's
-// directory. Today that is just file(); keeping the construction in one place
-// means every parse path (single file or layer stack) gets the same functions.
-func evalContextForFile(path string) *hcl.EvalContext {
+// directory, the load variables as var.<name>, and env(). Keeping the
+// construction in one place means every parse path (single file or layer
+// stack) gets the same functions and variables.
+func evalContextForFile(path string, opts LoadOptions) *hcl.EvalContext {
+	vars := opts.Vars
+	if vars == nil {
+		vars = map[string]cty.Value{}
+	}
 	return &hcl.EvalContext{
+		Variables: map[string]cty.Value{"var": cty.ObjectVal(vars)},
 		Functions: map[string]function.Function{
 			"file": fileFunc(filepath.Dir(path)),
+			"env":  envFunc(opts.AllowEnv),
 		},
 	}
 }
 
+// envFunc returns the HCL `env(name)` function, which reads an environment
+// variable and fails when it is unset. Unless allow is set it always fails, so
+// a schema that uses it says how to opt in rather than "call to unknown
+// function".
+func envFunc(allow bool) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "name", Type: cty.String}},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			name := args[0].AsString()
+			if !allow {
+				return cty.NilVal, fmt.Errorf("env(%q): environment variables are disabled (pass -allow-env to enable)", name)
+			}
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				return cty.NilVal, fmt.Errorf("env(%q): environment variable is not set", name)
+			}
+			return cty.StringVal(v), nil
+		},
+	})
+}
+
 // fileFunc returns the HCL `file(path)` function: it reads the file at path
 // (resolved relative to baseDir when not absolute) and returns its contents as
 // a string. It lets long values — most usefully a view/MV query — live in a
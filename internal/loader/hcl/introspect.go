@@ -41,7 +41,8 @@ func Introspect(ctx context.Context, conn driver.Conn, database string, allowRaw
 // whose name matches a pattern are skipped before their DDL is parsed, so
 // transient tables (e.g. ClickHouse's _tmp_replace_*, migration tmp_* tables)
 // neither appear in the dump nor abort introspection when their DDL can't be
-// parsed. A nil matcher excludes nothing.
+// parsed. Excluded columns are removed from the parsed tables. A nil matcher
+// excludes nothing.
 func IntrospectWithExclude(ctx context.Context, conn driver.Conn, database string, allowRaw bool, exclude *ExcludeMatcher) (*DatabaseSpec, error) {
 	db := &DatabaseSpec{Name: database}
 
@@ -82,8 +83,7 @@ func rawKindForEngine(engine string) string {
 
 // processIntrospectRows fills db with tables and materialized views parsed
 // from rows produced by a system.tables query. Each row must yield (name,
-// create_table_query) via Scan. Plain views (CREATE VIEW) are silently
-// skipped; inner-engine and refreshable MVs return an error.
+// create_table_query) via Scan.
 func processIntrospectRows(db *DatabaseSpec, database string, rows rowScanner) error {
 	return processIntrospectRowsOpt(db, database, rows, false, nil)
 }
@@ -106,6 +106,9 @@ func processIntrospectRowsOpt(db *DatabaseSpec, database string, rows rowScanner
 			slog.Info("skipping excluded object", "object", database+"."+name, "pattern", pattern)
 			continue
 		}
+		if isInnerMVTable(name) {
+			continue // stored with its parent materialized view
+		}
 		if err := introspectOneObject(db, database, name, createSQL); err != nil {
 			if !allowRaw {
 				return fmt.Errorf("%w (re-run with -allow-raw to capture this object as a raw SQL block instead of failing)", err)
@@ -113,8 +116,16 @@ func processIntrospectRowsOpt(db *DatabaseSpec, database string, rows rowScanner
 			kind := rawKindForEngine(engine)
 			db.Raws = append(db.Raws, RawSpec{Kind: kind, Name: name, SQL: normalizeRawSQL(createSQL)})
 			slog.Warn("captured object as raw SQL", "object", database+"."+name, "kind", kind, "reason", err)
+			continue
+		}
+		// An unmodelled engine parses, but hclexp will never manage the
+		// table, so it is held to the same gate as unparseable DDL.
+		if u, ok := unmanagedEngine(db, name); ok && !allowRaw {
+			return fmt.Errorf("introspect %s.%s: engine %s is not modelled (re-run with -allow-raw to record it as an unmanaged table instead of failing)",
+				database, name, u.Name)
 		}
 	}
+	FilterColumns(db, exclude)
 	// Canonicalize every expression-bearing field so an introspected schema
 	// diffs clean against the same schema composed from HCL (issue #136): both
 	// sides run the identical pass, so authored and live forms reduce to the
@@ -123,21 +134,120 @@ func processIntrospectRowsOpt(db *DatabaseSpec, database string, rows rowScanner
 	return nil
 }
 
+// unmanagedEngine reports the engine of db's table name when it is one the
+// schema language does not model.
+func unmanagedEngine(db *DatabaseSpec, name string) (EngineUnmanaged, bool) {
+	for i := range db.Tables {
+		if db.Tables[i].Name == name {
+			u, ok := engineOf(db.Tables[i]).(EngineUnmanaged)
+			return u, ok
+		}
+	}
+	return EngineUnmanaged{}, false
+}
+
 // introspectOneObject parses one create_table_query and appends the resulting
 // typed spec to db. It returns an error when the DDL cannot be parsed or the
 // object uses an engine/form the schema language does not express; callers
 // decide whether that is fatal (strict) or captured as raw.
 func introspectOneObject(db *DatabaseSpec, database, name, createSQL string) error {
 	stmt, err := parseCreateStatement(createSQL)
+	var innerCols []ColumnSpec
 	if err != nil {
-		return fmt.Errorf("parse create_table_query for %s.%s: %w", database, name, err)
+		// ClickHouse stores an inner-engine MV with its column list, which the
+		// parser rejects: parse the statement without it and the list apart.
+		stripped, list, ok := splitInnerMVColumns(createSQL)
+		if !ok {
+			return fmt.Errorf("parse create_table_query for %s.%s: %w", database, name, err)
+		}
+		if stmt, err = parseCreateStatement(stripped); err != nil {
+			return fmt.Errorf("parse create_table_query for %s.%s: %w", database, name, err)
+		}
+		if innerCols, err = columnsFromList(list); err != nil {
+			return fmt.Errorf("parse column list of %s.%s: %w", database, name, err)
+		}
 	}
 	if err := upsertObjectFromStmt(db, name, stmt); err != nil {
 		return fmt.Errorf("introspect %s.%s: %w", database, name, err)
 	}
+	if innerCols != nil {
+		for i := range db.MaterializedViews {
+			if db.MaterializedViews[i].Name == name {
+				db.MaterializedViews[i].Columns = innerCols
+			}
+		}
+	}
 	return nil
 }
 
+// innerMVTablePrefixes name the hidden tables ClickHouse creates to store an
+// inner-engine MV's rows: `.inner.<mv>` (Ordinary databases) and
+// `.inner_id.<uuid>` (Atomic). Their schema is already part of the parent's
+// CREATE, so introspection attaches it there and never emits them as tables.
+var innerMVTablePrefixes = []string{".inner.", ".inner_id."}
+
+// isInnerMVTable reports whether name is the hidden storage table of an
+// inner-engine materialized view.
+func isInnerMVTable(name string) bool {
+	for _, p := range innerMVTablePrefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitInnerMVColumns splits the column list off a CREATE MATERIALIZED VIEW
+// that has one directly before ENGINE (the form ClickHouse stores for an
+// inner-engine MV). It returns the statement without the list and the list
+// itself, parentheses included; ok is false for any other statement.
+func splitInnerMVColumns(createSQL string) (stripped, list string, ok bool) {
+	const prefix = "CREATE MATERIALIZED VIEW"
+	if len(createSQL) < len(prefix) || !strings.EqualFold(createSQL[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	open, depth := -1, 0
+	var quote byte
+	for i := len(prefix); i < len(createSQL); i++ {
+		c := createSQL[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			if depth == 0 {
+				open = i
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 && open >= 0 {
+				rest := strings.TrimSpace(createSQL[i+1:])
+				if len(rest) < len("ENGINE") || !strings.EqualFold(rest[:len("ENGINE")], "ENGINE") {
+					return "", "", false
+				}
+				return createSQL[:open] + createSQL[i+1:], createSQL[open : i+1], true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// columnsFromList parses a parenthesized column list by wrapping it in a
+// throwaway CREATE TABLE.
+func columnsFromList(list string) ([]ColumnSpec, error) {
+	t, err := buildTableFromCreateSQL("CREATE TABLE __columns__ " + list + " ENGINE = Memory")
+	if err != nil {
+		return nil, err
+	}
+	return t.Columns, nil
+}
+
 // upsertObjectFromStmt builds the typed spec for an already-parsed CREATE
 // statement and stores it on db under name. When an object of the same kind
 // already exists with that name it is replaced in place (otherwise appended),
@@ -151,6 +261,9 @@ func upsertObjectFromStmt(db *DatabaseSpec, name string, stmt chparser.Expr) err
 			return fmt.Errorf("table %s: %w", name, err)
 		}
 		ts.Name = name
+		if u, ok := engineOf(ts).(EngineUnmanaged); ok {
+			slog.Warn("table engine is not modelled; recorded verbatim and skipped by diff", "table", name, "engine", u.Name)
+		}
 		upsertTable(db, ts)
 	case *chparser.CreateMaterializedView:
 		mv, err := buildMaterializedViewFromCreateMV(s)
@@ -306,26 +419,7 @@ func buildTableFromCreateTable(ct *chparser.CreateTable) (TableSpec, error) {
 			eng = ts
 		}
 		t.Engine = &EngineSpec{Kind: eng.Kind(), Decoded: eng}
-
-		if ct.Engine.OrderBy != nil {
-			for _, it := range ct.Engine.OrderBy.Items {
-				t.OrderBy = append(t.OrderBy, flattenTupleExpr(it)...)
-			}
-		}
-		if ct.Engine.PartitionBy != nil {
-			t.PartitionBy = strPtr(formatNode(ct.Engine.PartitionBy.Expr))
-		}
-		if ct.Engine.SampleBy != nil {
-			t.SampleBy = strPtr(formatNode(ct.Engine.SampleBy.Expr))
-		}
-		if ct.Engine.PrimaryKey != nil {
-			if pk := exprList(ct.Engine.PrimaryKey.Expr); !stringSliceEqual(pk, t.OrderBy) {
-				t.PrimaryKey = pk
-			}
-		}
-		if ct.Engine.TTL != nil && len(ct.Engine.TTL.Items) > 0 {
-			t.TTL = strPtr(formatNode(ct.Engine.TTL.Items[0].Expr))
-		}
+		storageClausesFromAST(&t, ct.Engine)
 		if len(settings) > 0 {
 			t.Settings = settings
 		}
@@ -355,9 +449,6 @@ func buildMaterializedViewFromCreateSQL(createSQL string) (MaterializedViewSpec,
 	return buildMaterializedViewFromCreateMV(mv)
 }
 
-// buildMaterializedViewFromCreateMV walks an already-parsed CREATE
-// MATERIALIZED VIEW AST. Only the `TO <table>` form is supported;
-// inner-engine and refreshable MVs are rejected with a clear error.
 // buildViewFromCreateView walks a parsed CREATE VIEW AST and produces a
 // ViewSpec. The SELECT body is rendered back to text via formatNode,
 // matching the way MV bodies are captured.
@@ -409,22 +500,27 @@ func buildViewFromCreateView(cv *chparser.CreateView) (ViewSpec, error) {
 	return v, nil
 }
 
+// buildMaterializedViewFromCreateMV walks an already-parsed CREATE
+// MATERIALIZED VIEW AST, in either its `TO <table>` form or the inner-engine
+// form that stores rows itself (captured as Inner). The parser does not
+// accept the inner form's column list; introspectOneObject splits it off
+// first and attaches the columns afterwards.
 func buildMaterializedViewFromCreateMV(mv *chparser.CreateMaterializedView) (MaterializedViewSpec, error) {
-	if mv.Refresh != nil {
-		return MaterializedViewSpec{}, errors.New("unsupported: refreshable materialized view")
-	}
-	if mv.Engine != nil {
-		return MaterializedViewSpec{}, errors.New("unsupported: inner-engine materialized view (only the TO <table> form is supported)")
-	}
-	if mv.Destination == nil || mv.Destination.TableIdentifier == nil {
-		return MaterializedViewSpec{}, errors.New("unsupported: materialized view has no TO clause")
-	}
-
-	out := MaterializedViewSpec{
-		ToTable: tableIdentName(mv.Destination.TableIdentifier),
+	out := MaterializedViewSpec{}
+	switch {
+	case mv.Engine != nil:
+		inner, err := mvInnerFromAST(mv.Engine)
+		if err != nil {
+			return MaterializedViewSpec{}, err
+		}
+		out.Inner = inner
+	case mv.Destination == nil || mv.Destination.TableIdentifier == nil:
+		return MaterializedViewSpec{}, errors.New("unsupported: materialized view has neither a TO clause nor an ENGINE")
+	default:
+		out.ToTable = tableIdentName(mv.Destination.TableIdentifier)
 	}
 
-	if mv.Destination.TableSchema != nil {
+	if mv.Destination != nil && mv.Destination.TableSchema != nil {
 		for _, col := range mv.Destination.TableSchema.Columns {
 			if cd, ok := col.(*chparser.ColumnDef); ok {
 				// MV destination columns are introspected as name+type only,
@@ -451,10 +547,96 @@ func buildMaterializedViewFromCreateMV(mv *chparser.CreateMaterializedView) (Mat
 	if mv.Comment != nil {
 		out.Comment = strPtr(unquoteString(mv.Comment.Literal))
 	}
+	if mv.Refresh != nil {
+		out.Refresh = refreshFromAST(mv)
+	}
 
 	return out, nil
 }
 
+// mvInnerFromAST reads the storage of an MV created without TO: its ENGINE
+// and the clauses that follow it, exactly as for a table.
+func mvInnerFromAST(e *chparser.EngineExpr) (*MVInnerSpec, error) {
+	eng, settings, err := engineFromAST(e)
+	if err != nil {
+		return nil, fmt.Errorf("inner engine: %w", err)
+	}
+	var st TableSpec
+	storageClausesFromAST(&st, e)
+	inner := &MVInnerSpec{
+		Engine:      &EngineSpec{Kind: eng.Kind(), Decoded: eng},
+		PrimaryKey:  st.PrimaryKey,
+		OrderBy:     st.OrderBy,
+		PartitionBy: st.PartitionBy,
+		SampleBy:    st.SampleBy,
+		TTL:         st.TTL,
+	}
+	if len(settings) > 0 {
+		inner.Settings = settings
+	}
+	return inner, nil
+}
+
+// refreshFromAST collects a refreshable MV's schedule. The parser keeps the
+// REFRESH clause and its trailing RANDOMIZE FOR / DEPENDS ON / SETTINGS /
+// APPEND modifiers as separate fields of the CREATE node.
+func refreshFromAST(mv *chparser.CreateMaterializedView) *RefreshSpec {
+	r := &RefreshSpec{
+		Settings: engineSettingsMap(mv.Settings),
+		Append:   mv.HasAppend,
+	}
+	interval := strPtr(intervalText(mv.Refresh.Interval))
+	if strings.EqualFold(mv.Refresh.Frequency, "AFTER") {
+		r.After = interval
+	} else {
+		r.Every = interval
+	}
+	if mv.Refresh.Offset != nil {
+		r.Offset = strPtr(intervalText(mv.Refresh.Offset))
+	}
+	if mv.RandomizeFor != nil {
+		r.RandomizeFor = strPtr(intervalText(mv.RandomizeFor))
+	}
+	for _, t := range mv.DependsOn {
+		r.DependsOn = append(r.DependsOn, tableIdentName(t))
+	}
+	return r
+}
+
+// intervalText renders an interval as "<n> <UNIT>" without the INTERVAL
+// keyword, the form ClickHouse prints in a REFRESH clause.
+func intervalText(i *chparser.IntervalExpr) string {
+	if i == nil {
+		return ""
+	}
+	return formatNode(i.Expr) + " " + strings.ToUpper(i.Unit.String())
+}
+
+// storageClausesFromAST copies the ORDER BY, PARTITION BY, SAMPLE BY, PRIMARY
+// KEY and TTL clauses that follow an ENGINE onto t. A PRIMARY KEY equal to the
+// ORDER BY is implied by it and left unset.
+func storageClausesFromAST(t *TableSpec, e *chparser.EngineExpr) {
+	if e.OrderBy != nil {
+		for _, it := range e.OrderBy.Items {
+			t.OrderBy = append(t.OrderBy, flattenTupleExpr(it)...)
+		}
+	}
+	if e.PartitionBy != nil {
+		t.PartitionBy = strPtr(formatNode(e.PartitionBy.Expr))
+	}
+	if e.SampleBy != nil {
+		t.SampleBy = strPtr(formatNode(e.SampleBy.Expr))
+	}
+	if e.PrimaryKey != nil {
+		if pk := exprList(e.PrimaryKey.Expr); !stringSliceEqual(pk, t.OrderBy) {
+			t.PrimaryKey = pk
+		}
+	}
+	if ttl := ttlClauseString(e.TTL); ttl != "" {
+		t.TTL = strPtr(ttl)
+	}
+}
+
 // tableIdentName renders a TableIdentifier as `db.table` (or `table`),
 // stripping ClickHouse's backtick quoting so the value matches how it would
 // be written in HCL.
@@ -479,6 +661,25 @@ func formatNode(n chparser.Expr) string {
 	return v.String()
 }
 
+// ttlClauseString renders a table TTL clause without its TTL keyword: every
+// rule, each with its action (DELETE [WHERE], TO DISK/VOLUME, RECOMPRESS,
+// GROUP BY), comma-separated — the form the ttl attribute holds and
+// CREATE/MODIFY TTL emit verbatim. Keeping only the first rule's expression
+// would drop tiering and conditional-delete rules, so such a table would diff
+// against its own dump. Returns "" for a nil or empty clause.
+func ttlClauseString(c *chparser.TTLClause) string {
+	if c == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(c.Items))
+	for _, item := range c.Items {
+		if s := formatNode(item); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
 func columnFromAST(c *chparser.ColumnDef) ColumnSpec {
 	out := ColumnSpec{Name: identName(c.Name), Type: formatNode(c.Type)}
 	if c.DefaultExpr != nil {
@@ -501,8 +702,7 @@ func columnFromAST(c *chparser.ColumnDef) ColumnSpec {
 		out.TTL = &s
 	}
 	if c.Comment != nil {
-		s := unquoteString(c.Comment.Literal)
-		out.Comment = &s
+		out.Comment, out.Deprecated = splitDeprecatedComment(unquoteString(c.Comment.Literal))
 	}
 	return out
 }
@@ -574,6 +774,13 @@ func engineFromAST(e *chparser.EngineExpr) (Engine, map[string]string, error) {
 	params := engineParamStrings(e.Params)
 	allSettings := engineSettingsMap(e.Settings)
 
+	if ext, ok, err := externalEngineFromAST(e); ok {
+		if err != nil {
+			return nil, nil, err
+		}
+		return ext, allSettings, nil
+	}
+
 	switch e.Name {
 	case "MergeTree":
 		return EngineMergeTree{}, allSettings, nil
@@ -639,6 +846,26 @@ func engineFromAST(e *chparser.EngineExpr) (Engine, map[string]string, error) {
 			return nil, nil, fmt.Errorf("ReplicatedAggregatingMergeTree needs (zoo_path, replica_name)")
 		}
 		return EngineReplicatedAggregatingMergeTree{ZooPath: params[0], ReplicaName: params[1]}, allSettings, nil
+	case "VersionedCollapsingMergeTree":
+		if len(params) != 2 {
+			return nil, nil, fmt.Errorf("VersionedCollapsingMergeTree needs (sign_column, version_column)")
+		}
+		return EngineVersionedCollapsingMergeTree{SignColumn: params[0], VersionColumn: params[1]}, allSettings, nil
+	case "ReplicatedVersionedCollapsingMergeTree":
+		if len(params) != 4 {
+			return nil, nil, fmt.Errorf("ReplicatedVersionedCollapsingMergeTree needs (zoo_path, replica_name, sign_column, version_column)")
+		}
+		return EngineReplicatedVersionedCollapsingMergeTree{ZooPath: params[0], ReplicaName: params[1], SignColumn: params[2], VersionColumn: params[3]}, allSettings, nil
+	case "GraphiteMergeTree":
+		if len(params) != 1 {
+			return nil, nil, fmt.Errorf("GraphiteMergeTree needs (config_section)")
+		}
+		return EngineGraphiteMergeTree{ConfigSection: params[0]}, allSettings, nil
+	case "ReplicatedGraphiteMergeTree":
+		if len(params) != 3 {
+			return nil, nil, fmt.Errorf("ReplicatedGraphiteMergeTree needs (zoo_path, replica_name, config_section)")
+		}
+		return EngineReplicatedGraphiteMergeTree{ZooPath: params[0], ReplicaName: params[1], ConfigSection: params[2]}, allSettings, nil
 	case "Distributed":
 		if len(params) < 3 {
 			return nil, nil, fmt.Errorf("engine Distributed needs (cluster, db, table[, sharding_key[, policy_name]])")
@@ -670,6 +897,8 @@ func engineFromAST(e *chparser.EngineExpr) (Engine, map[string]string, error) {
 		return EngineNull{}, allSettings, nil
 	case "Memory":
 		return EngineMemory{}, allSettings, nil
+	case "Set":
+		return EngineSet{}, allSettings, nil
 	case "Merge":
 		if len(params) != 2 {
 			return nil, nil, fmt.Errorf("engine Merge needs (db_regex, table_regex)")
@@ -755,7 +984,13 @@ func engineFromAST(e *chparser.EngineExpr) (Engine, map[string]string, error) {
 		}
 		return ts, nil, nil
 	}
-	return nil, nil, fmt.Errorf("unsupported engine: %s", e.Name)
+	// An engine the schema language does not model is kept verbatim rather
+	// than aborting introspection; the differ leaves such tables alone.
+	full := e.Name
+	if e.Params != nil {
+		full += formatNode(e.Params)
+	}
+	return EngineUnmanaged{Name: e.Name, Full: full}, allSettings, nil
 }
 
 func engineParamStrings(p *chparser.ParamExprList) []string {
@@ -956,6 +1191,24 @@ func ParseEngineString(engineFull string) (Engine, error) {
 			return nil, fmt.Errorf("ReplicatedAggregatingMergeTree needs (zoo_path, replica_name); got %v", p)
 		}
 		return EngineReplicatedAggregatingMergeTree{ZooPath: p[0], ReplicaName: p[1]}, nil
+	case strings.HasPrefix(decl, "ReplicatedVersionedCollapsingMergeTree"):
+		p, err := extractEngineParams(decl)
+		if err != nil {
+			return nil, err
+		}
+		if len(p) != 4 {
+			return nil, fmt.Errorf("ReplicatedVersionedCollapsingMergeTree needs (zoo_path, replica_name, sign_column, version_column); got %v", p)
+		}
+		return EngineReplicatedVersionedCollapsingMergeTree{ZooPath: p[0], ReplicaName: p[1], SignColumn: p[2], VersionColumn: p[3]}, nil
+	case strings.HasPrefix(decl, "ReplicatedGraphiteMergeTree"):
+		p, err := extractEngineParams(decl)
+		if err != nil {
+			return nil, err
+		}
+		if len(p) != 3 {
+			return nil, fmt.Errorf("ReplicatedGraphiteMergeTree needs (zoo_path, replica_name, config_section); got %v", p)
+		}
+		return EngineReplicatedGraphiteMergeTree{ZooPath: p[0], ReplicaName: p[1], ConfigSection: p[2]}, nil
 	case strings.HasPrefix(decl, "ReplicatedSummingMergeTree"):
 		p, err := extractEngineParams(decl)
 		if err != nil {
@@ -996,6 +1249,24 @@ func ParseEngineString(engineFull string) (Engine, error) {
 			return nil, fmt.Errorf("CollapsingMergeTree needs (sign_column); got %v", p)
 		}
 		return EngineCollapsingMergeTree{SignColumn: p[0]}, nil
+	case strings.HasPrefix(decl, "VersionedCollapsingMergeTree"):
+		p, err := extractEngineParams(decl)
+		if err != nil {
+			return nil, err
+		}
+		if len(p) != 2 {
+			return nil, fmt.Errorf("VersionedCollapsingMergeTree needs (sign_column, version_column); got %v", p)
+		}
+		return EngineVersionedCollapsingMergeTree{SignColumn: p[0], VersionColumn: p[1]}, nil
+	case strings.HasPrefix(decl, "GraphiteMergeTree"):
+		p, err := extractEngineParams(decl)
+		if err != nil {
+			return nil, err
+		}
+		if len(p) != 1 {
+			return nil, fmt.Errorf("GraphiteMergeTree needs (config_section); got %v", p)
+		}
+		return EngineGraphiteMergeTree{ConfigSection: p[0]}, nil
 	case strings.HasPrefix(decl, "AggregatingMergeTree"):
 		return EngineAggregatingMergeTree{}, nil
 	case strings.HasPrefix(decl, "MergeTree"):
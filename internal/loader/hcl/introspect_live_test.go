@@ -25,8 +25,8 @@ func TestCHLive_Introspect(t *testing.T) {
 	if !*clickhouseLive {
 		t.Skip("pass -clickhouse to run against a live ClickHouse")
 	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 
 	for _, tc := range createTableCases {
@@ -83,8 +83,8 @@ func TestCHLive_IntrospectMaterializedView(t *testing.T) {
 	if !*clickhouseLive {
 		t.Skip("pass -clickhouse to run against a live ClickHouse")
 	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 
 	require.NoError(t, conn.Exec(ctx, fmt.Sprintf(
@@ -256,8 +256,8 @@ func TestCHLive_IntrospectDictionary(t *testing.T) {
 	if !*clickhouseLive {
 		t.Skip("pass -clickhouse to run against a live ClickHouse")
 	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 
 	runSQL := func(sql string) {
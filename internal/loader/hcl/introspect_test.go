@@ -1,6 +1,7 @@
 package hcl
 
 import (
+	"bytes"
 	"strings"
 	"testing"
 
@@ -126,6 +127,26 @@ func TestParseEngineString(t *testing.T) {
 				Format:     ptr("JSONEachRow"),
 			},
 		},
+		{
+			"versioned_collapsing_merge_tree",
+			"VersionedCollapsingMergeTree(sign, ver) ORDER BY id",
+			EngineVersionedCollapsingMergeTree{SignColumn: "sign", VersionColumn: "ver"},
+		},
+		{
+			"replicated_versioned_collapsing_merge_tree",
+			"ReplicatedVersionedCollapsingMergeTree('/p', '{replica}', sign, ver) ORDER BY id",
+			EngineReplicatedVersionedCollapsingMergeTree{ZooPath: "/p", ReplicaName: "{replica}", SignColumn: "sign", VersionColumn: "ver"},
+		},
+		{
+			"graphite_merge_tree",
+			"GraphiteMergeTree('graphite_rollup') ORDER BY (Path, Time)",
+			EngineGraphiteMergeTree{ConfigSection: "graphite_rollup"},
+		},
+		{
+			"replicated_graphite_merge_tree",
+			"ReplicatedGraphiteMergeTree('/p', '{replica}', 'graphite_rollup') ORDER BY (Path, Time)",
+			EngineReplicatedGraphiteMergeTree{ZooPath: "/p", ReplicaName: "{replica}", ConfigSection: "graphite_rollup"},
+		},
 		{
 			"kafka_constructor_form",
 			"Kafka('kafka:9092', 'events', 'g1', 'JSONEachRow')",
@@ -238,6 +259,39 @@ SETTINGS index_granularity = 8192, ttl_only_drop_parts = 1`
 	}
 }
 
+// A table TTL clause keeps every rule and its action, so tiering and
+// conditional deletes survive introspection and the table does not diff
+// against its own dump. The rendered form feeds CREATE back unchanged.
+func TestBuildTableFromCreateSQL_TableTTLRules(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  string
+		want string
+	}{
+		{"single expression", "TTL ts + toIntervalMonth(6)", "ts + toIntervalMonth(6)"},
+		{"move then delete", "TTL ts + toIntervalMonth(1) TO VOLUME 'cold', ts + toIntervalMonth(6) DELETE",
+			"ts + toIntervalMonth(1) TO VOLUME 'cold', ts + toIntervalMonth(6) DELETE"},
+		{"conditional delete", "TTL ts + toIntervalMonth(1) DELETE WHERE x = 1", "ts + toIntervalMonth(1) DELETE WHERE x = 1"},
+		{"disk and recompress", "TTL ts + toIntervalDay(1) RECOMPRESS CODEC(ZSTD(3)), ts + toIntervalMonth(1) TO DISK 'cold'",
+			"ts + toIntervalDay(1) RECOMPRESS CODEC(ZSTD(3)), ts + toIntervalMonth(1) TO DISK 'cold'"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			src := "CREATE TABLE db.t (`ts` DateTime, `x` UInt64) ENGINE = MergeTree ORDER BY x " + tc.ttl
+			got, err := buildTableFromCreateSQL(src)
+			require.NoError(t, err)
+			require.NotNil(t, got.TTL)
+			assert.Equal(t, tc.want, *got.TTL)
+
+			got.Name = "t"
+			again, err := buildTableFromCreateSQL(createTableSQL("db", got))
+			require.NoError(t, err)
+			require.NotNil(t, again.TTL)
+			assert.Equal(t, tc.want, *again.TTL, "generated CREATE must round-trip the TTL")
+		})
+	}
+}
+
 func TestBuildTableFromCreateSQL_ReplicatedMergeTreeArgs(t *testing.T) {
 	src := `CREATE TABLE db.t
 (
@@ -295,22 +349,114 @@ func TestBuildMaterializedViewFromCreateSQL_ToForm(t *testing.T) {
 	assert.Nil(t, got.Cluster)
 }
 
-func TestBuildMaterializedViewFromCreateSQL_InnerEngineUnsupported(t *testing.T) {
-	src := `CREATE MATERIALIZED VIEW db.mv ENGINE = MergeTree ORDER BY id ` +
-		`AS SELECT id FROM db.src`
-	_, err := buildMaterializedViewFromCreateSQL(src)
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "unsupported")
-	assert.Contains(t, err.Error(), "inner-engine")
+func TestBuildMaterializedViewFromCreateSQL_InnerEngine(t *testing.T) {
+	src := `CREATE MATERIALIZED VIEW db.mv ENGINE = MergeTree PARTITION BY toYYYYMM(ts) ORDER BY id ` +
+		`AS SELECT id, ts FROM db.src`
+	got, err := buildMaterializedViewFromCreateSQL(src)
+	require.NoError(t, err)
+	assert.Empty(t, got.ToTable)
+	require.NotNil(t, got.Inner)
+	require.NotNil(t, got.Inner.Engine)
+	assert.Equal(t, "merge_tree", got.Inner.Engine.Kind)
+	assert.Equal(t, []string{"id"}, got.Inner.OrderBy)
+	assert.Equal(t, ptr("toYYYYMM(ts)"), got.Inner.PartitionBy)
+}
+
+// ClickHouse stores an inner-engine MV with its column list before ENGINE,
+// a form the SQL parser rejects; introspection splits the list off and
+// attaches the columns to the view.
+func TestIntrospectOneObject_InnerEngineMVWithColumns(t *testing.T) {
+	src := "CREATE MATERIALIZED VIEW db.mv (`id` UInt64, `note` String DEFAULT 'a(b') " +
+		"ENGINE = MergeTree ORDER BY id SETTINGS index_granularity = 8192 " +
+		"AS SELECT id, note FROM db.src"
+	db := &DatabaseSpec{Name: "db"}
+	require.NoError(t, introspectOneObject(db, "db", "mv", src))
+	require.Len(t, db.MaterializedViews, 1)
+	mv := db.MaterializedViews[0]
+	require.NotNil(t, mv.Inner)
+	assert.Equal(t, []string{"id"}, mv.Inner.OrderBy)
+	assert.Equal(t, map[string]string{"index_granularity": "8192"}, mv.Inner.Settings)
+	require.Len(t, mv.Columns, 2)
+	assert.Equal(t, "id", mv.Columns[0].Name)
+	assert.Equal(t, "note", mv.Columns[1].Name)
+}
+
+func TestSplitInnerMVColumns(t *testing.T) {
+	stripped, list, ok := splitInnerMVColumns(
+		"CREATE MATERIALIZED VIEW db.mv (`a` String DEFAULT ')') ENGINE = Memory AS SELECT 1 AS a")
+	require.True(t, ok)
+	assert.Equal(t, "(`a` String DEFAULT ')')", list)
+	assert.Equal(t, "CREATE MATERIALIZED VIEW db.mv  ENGINE = Memory AS SELECT 1 AS a", stripped)
+
+	_, _, ok = splitInnerMVColumns("CREATE MATERIALIZED VIEW db.mv TO db.t (`a` String) AS SELECT 1 AS a")
+	assert.False(t, ok, "a column list not followed by ENGINE is left alone")
+	_, _, ok = splitInnerMVColumns("CREATE TABLE db.t (`a` String) ENGINE = Memory")
+	assert.False(t, ok)
+}
+
+func TestIntrospect_SkipsInnerMVTables(t *testing.T) {
+	rows := &fakeRows{rows: []fakeRow{
+		{name: ".inner_id.5b7c6a6e-0000-0000-0000-000000000000", sql: "CREATE TABLE db.`.inner_id.5b7c6a6e-0000-0000-0000-000000000000` (`id` UInt64) ENGINE = MergeTree ORDER BY id"},
+		{name: ".inner.mv", sql: "CREATE TABLE db.`.inner.mv` (`id` UInt64) ENGINE = MergeTree ORDER BY id"},
+		{name: "events", sql: "CREATE TABLE db.events (`id` UInt64) ENGINE = MergeTree ORDER BY id"},
+	}}
+	db := &DatabaseSpec{Name: "db"}
+	require.NoError(t, processIntrospectRows(db, "db", rows))
+	require.Len(t, db.Tables, 1)
+	assert.Equal(t, "events", db.Tables[0].Name)
+}
+
+// A dictionary row from system.tables (engine "Dictionary") becomes a typed
+// DictionarySpec, which Write then dumps as a dictionary block.
+func TestIntrospect_DumpsDictionaries(t *testing.T) {
+	rows := &fakeRows{rows: []fakeRow{
+		{name: "events", sql: "CREATE TABLE db.events (`id` UInt64) ENGINE = MergeTree ORDER BY id", engine: "MergeTree"},
+		{name: "kv_dict", sql: "CREATE DICTIONARY db.kv_dict (`k` UInt64, `v` String) PRIMARY KEY k " +
+			"SOURCE(NULL()) LIFETIME(0) LAYOUT(HASHED())", engine: "Dictionary"},
+	}}
+	db := &DatabaseSpec{Name: "db"}
+	require.NoError(t, processIntrospectRows(db, "db", rows))
+	require.Len(t, db.Dictionaries, 1)
+	assert.Equal(t, "kv_dict", db.Dictionaries[0].Name)
+	assert.Empty(t, db.Raws)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, &Schema{Databases: []DatabaseSpec{*db}}))
+	assert.Contains(t, buf.String(), `dictionary "kv_dict" {`)
 }
 
-func TestBuildMaterializedViewFromCreateSQL_RefreshableUnsupported(t *testing.T) {
-	src := `CREATE MATERIALIZED VIEW db.mv REFRESH EVERY 1 HOUR TO db.target ` +
+func TestBuildMaterializedViewFromCreateSQL_Refreshable(t *testing.T) {
+	src := `CREATE MATERIALIZED VIEW db.mv REFRESH EVERY 1 DAY OFFSET 2 HOUR RANDOMIZE FOR 10 MINUTE ` +
+		`DEPENDS ON db.upstream_mv SETTINGS refresh_retries = 3 APPEND TO db.target ` +
 		`AS SELECT id FROM db.src`
-	_, err := buildMaterializedViewFromCreateSQL(src)
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "unsupported")
-	assert.Contains(t, err.Error(), "refreshable")
+	got, err := buildMaterializedViewFromCreateSQL(src)
+	require.NoError(t, err)
+
+	assert.Equal(t, "db.target", got.ToTable)
+	assert.Equal(t, &RefreshSpec{
+		Every:        ptr("1 DAY"),
+		Offset:       ptr("2 HOUR"),
+		RandomizeFor: ptr("10 MINUTE"),
+		DependsOn:    []string{"db.upstream_mv"},
+		Settings:     map[string]string{"refresh_retries": "3"},
+		Append:       true,
+	}, got.Refresh)
+
+	// The generated CREATE reproduces the schedule verbatim.
+	got.Name = "mv"
+	assert.Equal(t, "CREATE MATERIALIZED VIEW db.mv REFRESH EVERY 1 DAY OFFSET 2 HOUR RANDOMIZE FOR 10 MINUTE "+
+		"DEPENDS ON db.upstream_mv SETTINGS refresh_retries = 3 APPEND TO db.target AS "+got.Query,
+		createMaterializedViewSQL("db", got))
+}
+
+func TestBuildMaterializedViewFromCreateSQL_RefreshAfter(t *testing.T) {
+	got, err := buildMaterializedViewFromCreateSQL(`CREATE MATERIALIZED VIEW db.mv REFRESH AFTER 30 MINUTE TO db.target AS SELECT 1`)
+	require.NoError(t, err)
+	assert.Equal(t, &RefreshSpec{After: ptr("30 MINUTE")}, got.Refresh)
+
+	plain, err := buildMaterializedViewFromCreateSQL(`CREATE MATERIALIZED VIEW db.mv TO db.target AS SELECT 1`)
+	require.NoError(t, err)
+	assert.Nil(t, plain.Refresh, "an ordinary MV has no refresh schedule")
 }
 
 // fakeRows is a minimal rowScanner backed by a slice of (name, createSQL)
@@ -693,6 +839,20 @@ func TestProcessIntrospectRows_ExcludeSkipsBeforeParse(t *testing.T) {
 	assert.Empty(t, db.Raws, "excluded objects are not captured as raw either")
 }
 
+func TestProcessIntrospectRows_ExcludeDropsColumns(t *testing.T) {
+	rows := &fakeRows{rows: []fakeRow{
+		{name: "events", sql: "CREATE TABLE db.events (`id` UInt64, `_airbyte_ab_id` String) ENGINE = MergeTree ORDER BY id", engine: "MergeTree"},
+	}}
+	db := &DatabaseSpec{Name: "db"}
+	exclude, err := LoadExcludeConfig(writeTempExclude(t, `exclude { columns = ["_airbyte_*"] }`))
+	require.NoError(t, err)
+
+	require.NoError(t, processIntrospectRowsOpt(db, "db", rows, false, exclude))
+	require.Len(t, db.Tables, 1)
+	require.Len(t, db.Tables[0].Columns, 1)
+	assert.Equal(t, "id", db.Tables[0].Columns[0].Name)
+}
+
 func TestParseKafkaEngine_Cases(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -914,15 +1074,84 @@ func TestIntrospect_Merge(t *testing.T) {
 	assert.Equal(t, "^shard_.*", e.TableRegex)
 }
 
-func TestIntrospect_Null_Memory(t *testing.T) {
+// TestIntrospect_UnmanagedEngine: an engine the schema language does not model
+// fails strict introspection like unparseable DDL, and with allowRaw is
+// recorded verbatim instead of aborting the whole introspection.
+func TestIntrospect_UnmanagedEngine(t *testing.T) {
+	sql := "CREATE TABLE db.kv (`k` String, `v` UInt64) ENGINE = EmbeddedRocksDB(0, '/var/lib/rocks') PRIMARY KEY k"
+	err := processIntrospectRows(&DatabaseSpec{Name: "db"}, "db", &fakeRows{rows: []fakeRow{{name: "kv", sql: sql}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "engine EmbeddedRocksDB is not modelled")
+	assert.Contains(t, err.Error(), "-allow-raw")
+
+	db := &DatabaseSpec{Name: "db"}
+	require.NoError(t, processIntrospectRowsOpt(db, "db", &fakeRows{rows: []fakeRow{{name: "kv", sql: sql}}}, true, nil))
+	require.Len(t, db.Tables, 1)
+	assert.Equal(t, "unmanaged", db.Tables[0].Engine.Kind)
+	assert.Equal(t, EngineUnmanaged{Name: "EmbeddedRocksDB", Full: "EmbeddedRocksDB(0, '/var/lib/rocks')"}, db.Tables[0].Engine.Decoded)
+	assert.Equal(t, []string{"k"}, db.Tables[0].PrimaryKey)
+	assert.Empty(t, db.Raws)
+}
+
+func TestIntrospect_Null_Memory_Set(t *testing.T) {
 	for _, c := range []struct {
 		sql, kind string
 	}{
 		{"CREATE TABLE db.n (`id` UUID) ENGINE = Null()", "null"},
 		{"CREATE TABLE db.n (`id` UUID) ENGINE = Memory()", "memory"},
+		{"CREATE TABLE db.n (`id` UUID) ENGINE = Set", "set"},
 	} {
 		db := &DatabaseSpec{Name: "db"}
 		require.NoError(t, processIntrospectRows(db, "db", &fakeRows{rows: []fakeRow{{name: "n", sql: c.sql}}}))
 		assert.Equal(t, c.kind, db.Tables[0].Engine.Kind)
 	}
 }
+
+// TestIntrospect_Set_PersistentSetting: Set takes no engine parameters; its
+// `persistent` knob is an ordinary table setting.
+func TestIntrospect_Set_PersistentSetting(t *testing.T) {
+	sql := "CREATE TABLE db.s (`id` UInt64) ENGINE = Set SETTINGS persistent = 0"
+	db := &DatabaseSpec{Name: "db"}
+	require.NoError(t, processIntrospectRows(db, "db", &fakeRows{rows: []fakeRow{{name: "s", sql: sql}}}))
+	require.Len(t, db.Tables, 1)
+	assert.Equal(t, EngineSet{}, db.Tables[0].Engine.Decoded)
+	assert.Equal(t, map[string]string{"persistent": "0"}, db.Tables[0].Settings)
+}
+
+func TestIntrospect_VersionedCollapsing_Graphite(t *testing.T) {
+	for _, c := range []struct {
+		sql  string
+		want Engine
+	}{
+		{
+			"CREATE TABLE db.t (`id` UInt64, `sign` Int8, `ver` UInt32) ENGINE = VersionedCollapsingMergeTree(sign, ver) ORDER BY id",
+			EngineVersionedCollapsingMergeTree{SignColumn: "sign", VersionColumn: "ver"},
+		},
+		{
+			"CREATE TABLE db.t (`id` UInt64, `sign` Int8, `ver` UInt32) ENGINE = ReplicatedVersionedCollapsingMergeTree('/zk/t', '{replica}', sign, ver) ORDER BY id",
+			EngineReplicatedVersionedCollapsingMergeTree{ZooPath: "/zk/t", ReplicaName: "{replica}", SignColumn: "sign", VersionColumn: "ver"},
+		},
+		{
+			"CREATE TABLE db.t (`Path` String, `Time` DateTime, `Value` Float64, `Timestamp` UInt32) ENGINE = GraphiteMergeTree('graphite_rollup') ORDER BY (Path, Time)",
+			EngineGraphiteMergeTree{ConfigSection: "graphite_rollup"},
+		},
+		{
+			"CREATE TABLE db.t (`Path` String, `Time` DateTime, `Value` Float64, `Timestamp` UInt32) ENGINE = ReplicatedGraphiteMergeTree('/zk/t', '{replica}', 'graphite_rollup') ORDER BY (Path, Time)",
+			EngineReplicatedGraphiteMergeTree{ZooPath: "/zk/t", ReplicaName: "{replica}", ConfigSection: "graphite_rollup"},
+		},
+	} {
+		db := &DatabaseSpec{Name: "db"}
+		require.NoError(t, processIntrospectRows(db, "db", &fakeRows{rows: []fakeRow{{name: "t", sql: c.sql}}}))
+		require.Len(t, db.Tables, 1)
+		assert.Equal(t, c.want.Kind(), db.Tables[0].Engine.Kind)
+		assert.Equal(t, c.want, db.Tables[0].Engine.Decoded)
+	}
+}
+
+func TestIntrospect_VersionedCollapsing_RejectsMissingVersion(t *testing.T) {
+	sql := "CREATE TABLE db.t (`id` UInt64, `sign` Int8) ENGINE = VersionedCollapsingMergeTree(sign) ORDER BY id"
+	db := &DatabaseSpec{Name: "db"}
+	err := processIntrospectRows(db, "db", &fakeRows{rows: []fakeRow{{name: "t", sql: sql}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "VersionedCollapsingMergeTree needs")
+}
@@ -20,8 +20,8 @@ func TestCHLive_Kafka_WithNamedCollection_E2E(t *testing.T) {
 	if !*clickhouseLive {
 		t.Skip("pass -clickhouse to run against a live ClickHouse")
 	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 	ncName := fmt.Sprintf("kafka_e2e_%d", time.Now().UnixNano())
 	t.Cleanup(func() { _ = conn.Exec(ctx, "DROP NAMED COLLECTION IF EXISTS "+ncName) })
@@ -94,8 +94,8 @@ func TestCHLive_Kafka_AllSettingsForm(t *testing.T) {
 	if !*clickhouseLive {
 		t.Skip("pass -clickhouse to run against a live ClickHouse")
 	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 
 	brokerList := "kafka:9092"
@@ -2,38 +2,64 @@ package hcl
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
+	"strings"
 )
 
 // LoadLayers parses the .hcl files each layer path contributes, in the given
 // order, and merges them into a combined raw spec set. A layer path is either
-// a directory (every *.hcl inside it, in lexical filename order) or a single
-// .hcl file. Across layers (and across files), a duplicate table name is an
-// error unless the later declaration sets override = true. patch_table blocks
-// always accumulate.
+// a directory (every *.hcl inside it, in lexical filename order), a single
+// .hcl file, or a glob such as schema/**/*.hcl (see LayerFiles). Across
+// layers (and across files), a duplicate table name is an error unless the
+// later declaration sets override = true. patch_table blocks always
+// accumulate.
 //
 // LoadLayers does NOT call Resolve; callers run that explicitly so they can
 // inspect the merged-but-unresolved input first.
 func LoadLayers(layerPaths []string) (*Schema, error) {
+	return LoadLayersOpts(layerPaths, LoadOptions{})
+}
+
+// LoadLayersOpts is LoadLayers with the variables in opts visible to every
+// file's expressions.
+func LoadLayersOpts(layerPaths []string, opts LoadOptions) (*Schema, error) {
 	registry := map[string]*DatabaseSpec{}
 	var ordered []string
 	ncByName := map[string]*NamedCollectionSpec{}
 	var ncOrder []string
+	spByName := map[string]*SettingsProfileSpec{}
+	var spOrder []string
+	fnByName := map[string]*FunctionSpec{}
+	var fnOrder []string
+	taByName := map[string]*TypeAliasSpec{}
+	var taOrder []string
+	mixinByName := map[string]*MixinSpec{}
+	var mixinOrder []string
 	nodeByName := map[string]*NodeSpec{}
+	clusterByName := map[string]*ClusterSpec{}
+	var clusterOrder []string
 	var nodeOrder []string
+	var defaultOnCluster *string
+	var experimental []string
 
 	for _, path := range layerPaths {
 		files, err := LayerFiles(path)
 		if err != nil {
 			return nil, err
 		}
-		for _, file := range files {
-			parsed, err := ParseFile(file)
+		for _, file := range selectOverlays(files, opts.Overlay) {
+			parsed, err := parseSchemaFile(file, opts)
 			if err != nil {
 				return nil, err
 			}
+			if parsed.DefaultOnCluster != nil {
+				defaultOnCluster = parsed.DefaultOnCluster // last declaration wins
+			}
+			experimental = mergeExperimental(experimental, parsed.Experimental)
 			for _, db := range parsed.Databases {
 				if existing, ok := registry[db.Name]; ok {
 					if err := mergeIntoDatabase(existing, db); err != nil {
@@ -57,6 +83,54 @@ func LoadLayers(layerPaths []string) (*Schema, error) {
 					ncOrder = append(ncOrder, nc.Name)
 				}
 			}
+			for _, sp := range parsed.SettingsProfiles {
+				if existing, ok := spByName[sp.Name]; ok {
+					if !sp.Override {
+						return nil, fmt.Errorf("%s: settings_profile %q redeclared without override = true", file, sp.Name)
+					}
+					*existing = sp
+				} else {
+					cp := sp
+					spByName[sp.Name] = &cp
+					spOrder = append(spOrder, sp.Name)
+				}
+			}
+			for _, fn := range parsed.Functions {
+				if existing, ok := fnByName[fn.Name]; ok {
+					if !fn.Override {
+						return nil, fmt.Errorf("%s: function %q redeclared without override = true", file, fn.Name)
+					}
+					*existing = fn
+				} else {
+					cp := fn
+					fnByName[fn.Name] = &cp
+					fnOrder = append(fnOrder, fn.Name)
+				}
+			}
+			for _, ta := range parsed.TypeAliases {
+				if existing, ok := taByName[ta.Name]; ok {
+					if !ta.Override {
+						return nil, fmt.Errorf("%s: type_alias %q redeclared without override = true", file, ta.Name)
+					}
+					*existing = ta
+				} else {
+					cp := ta
+					taByName[ta.Name] = &cp
+					taOrder = append(taOrder, ta.Name)
+				}
+			}
+			for _, m := range parsed.Mixins {
+				if existing, ok := mixinByName[m.Name]; ok {
+					if !m.Override {
+						return nil, fmt.Errorf("%s: mixin %q redeclared without override = true", file, m.Name)
+					}
+					*existing = m
+				} else {
+					cp := m
+					mixinByName[m.Name] = &cp
+					mixinOrder = append(mixinOrder, m.Name)
+				}
+			}
 			for _, n := range parsed.Nodes {
 				if existing, ok := nodeByName[n.Name]; ok {
 					*existing = n // last declaration wins
@@ -66,35 +140,69 @@ func LoadLayers(layerPaths []string) (*Schema, error) {
 					nodeOrder = append(nodeOrder, n.Name)
 				}
 			}
+			for _, c := range parsed.Clusters {
+				if existing, ok := clusterByName[c.Name]; ok {
+					*existing = c // last declaration wins, as for nodes
+				} else {
+					cp := c
+					clusterByName[c.Name] = &cp
+					clusterOrder = append(clusterOrder, c.Name)
+				}
+			}
 		}
 	}
 
-	out := &Schema{}
+	out := &Schema{DefaultOnCluster: defaultOnCluster, Experimental: experimental}
 	for _, name := range ordered {
 		out.Databases = append(out.Databases, *registry[name])
 	}
 	for _, name := range ncOrder {
 		out.NamedCollections = append(out.NamedCollections, *ncByName[name])
 	}
+	for _, name := range spOrder {
+		out.SettingsProfiles = append(out.SettingsProfiles, *spByName[name])
+	}
+	for _, name := range fnOrder {
+		out.Functions = append(out.Functions, *fnByName[name])
+	}
+	for _, name := range taOrder {
+		out.TypeAliases = append(out.TypeAliases, *taByName[name])
+	}
+	for _, name := range mixinOrder {
+		out.Mixins = append(out.Mixins, *mixinByName[name])
+	}
 	for _, name := range nodeOrder {
 		out.Nodes = append(out.Nodes, *nodeByName[name])
 	}
+	for _, name := range clusterOrder {
+		out.Clusters = append(out.Clusters, *clusterByName[name])
+	}
 	return out, nil
 }
 
-// LayerFiles returns the .hcl files a layer path contributes, in load order:
-// for a directory, every *.hcl inside it (lexical filename order); for a
-// regular file, the file itself, which must have the .hcl extension so a stack
-// entry pointing at a dump or a .sql script fails loudly rather than being
-// parsed as HCL.
+// LayerFiles returns the schema files a layer path contributes, in load
+// order: for a directory, every *.hcl inside it (lexical filename order); for
+// a regular file, the file itself, which must be .hcl, .json (HCL's JSON
+// syntax) or .sql (CREATE statements, see ParseSQLFile) so a stack entry
+// pointing at a dump fails loudly rather than being parsed as HCL. A path containing glob
+// metacharacters is a pattern instead (see globLayerFiles), so a schema
+// organized into per-domain subdirectories loads as one layer with
+// schema/**/*.hcl.
+//
+// A directory never contributes its .sql or .json files: those are usually
+// file() query bodies and dumps, not schema. Name such layers explicitly, or
+// with a *.sql / *.json glob.
 func LayerFiles(path string) ([]string, error) {
+	if isGlob(path) {
+		return globLayerFiles(path)
+	}
 	info, err := os.Stat(path)
 	if err != nil {
 		return nil, fmt.Errorf("read layer %q: %w", path, err)
 	}
 	if !info.IsDir() {
-		if filepath.Ext(path) != ".hcl" {
-			return nil, fmt.Errorf("layer %q: not an .hcl file", path)
+		if ext := filepath.Ext(path); ext != ".hcl" && ext != ".sql" && ext != ".json" {
+			return nil, fmt.Errorf("layer %q: not an .hcl, .json or .sql file", path)
 		}
 		return []string{path}, nil
 	}
@@ -114,7 +222,102 @@ func LayerFiles(path string) ([]string, error) {
 	return files, nil
 }
 
+func isGlob(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// globLayerFiles returns the .hcl files matching pattern, in lexical path
+// order. Segments match like path.Match, and a ** segment matches any number
+// of directories, including none. Only .hcl files are included — or .sql or
+// .json files, when the pattern's last segment ends in that extension
+// (schema/**/*.sql) — and hidden directories are not descended into. A pattern matching nothing
+// is an error, like a missing layer directory.
+func globLayerFiles(pattern string) ([]string, error) {
+	segs := strings.Split(filepath.ToSlash(filepath.Clean(pattern)), "/")
+	for _, s := range segs {
+		if _, err := path.Match(s, ""); err != nil {
+			return nil, fmt.Errorf("layer %q: %w", pattern, err)
+		}
+	}
+	i := 0
+	for i < len(segs)-1 && !isGlob(segs[i]) {
+		i++
+	}
+	root := filepath.FromSlash(strings.Join(segs[:i], "/"))
+	switch {
+	case i == 0:
+		root = "."
+	case root == "":
+		root = string(filepath.Separator) // pattern directly under /
+	}
+
+	ext := ".hcl"
+	for _, e := range []string{".sql", ".json"} {
+		if strings.HasSuffix(segs[len(segs)-1], e) {
+			ext = e
+		}
+	}
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(p) != ext {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if matchSegments(segs[i:], strings.Split(filepath.ToSlash(rel), "/")) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read layer %q: %w", pattern, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("layer %q matches no %s files", pattern, ext)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// matchSegments reports whether the slash-split name matches the split
+// pattern, with ** standing for zero or more whole segments.
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for k := 0; k <= len(name); k++ {
+			if matchSegments(pattern[1:], name[k:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], name[0])
+	return ok && matchSegments(pattern[1:], name[1:])
+}
+
 func mergeIntoDatabase(target *DatabaseSpec, incoming DatabaseSpec) error {
+	// The cluster default may come from any file naming the database — in
+	// the per-database layout typically databases/<db>/database.hcl, which
+	// need not be the first. The last declaration wins.
+	if incoming.Cluster != nil {
+		target.Cluster = incoming.Cluster
+	}
 	indexByName := make(map[string]int, len(target.Tables))
 	for i := range target.Tables {
 		indexByName[target.Tables[i].Name] = i
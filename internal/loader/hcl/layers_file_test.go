@@ -1,6 +1,7 @@
 package hcl
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -109,14 +110,52 @@ func TestLoadLayers_FileLayerCollisionWithoutOverrideErrors(t *testing.T) {
 	assert.Contains(t, err.Error(), "override")
 }
 
-func TestLoadLayers_FileLayerRejectsNonHCLFile(t *testing.T) {
+func TestLoadLayers_FileLayerRejectsNonSchemaFile(t *testing.T) {
 	_, err := LoadLayers([]string{
+		layerPath("file_layer", "base"),
+		layerPath("file_layer", "notes.txt"),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "notes.txt")
+	assert.Contains(t, err.Error(), "not an .hcl, .json or .sql file")
+}
+
+func TestLoadLayers_SQLFileLayer(t *testing.T) {
+	schema, err := LoadLayers([]string{
 		layerPath("file_layer", "base"),
 		layerPath("file_layer", "seed.sql"),
 	})
+	require.NoError(t, err)
+
+	require.Len(t, schema.Databases, 1)
+	tables := schema.Databases[0].Tables
+	require.Len(t, tables, 2)
+	assert.Equal(t, "events", tables[0].Name, "the HCL base layer is kept")
+	assert.Equal(t, "seed", tables[1].Name, "the SQL layer adds its CREATE TABLE")
+	assert.Equal(t, []string{"id"}, tables[1].OrderBy)
+}
+
+func TestParseSQLFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	require.NoError(t, os.WriteFile(path, []byte(`
+CREATE DATABASE analytics;
+CREATE TABLE events (id UInt64, ts DateTime) ENGINE = MergeTree ORDER BY id;
+CREATE VIEW analytics.recent AS SELECT id FROM default.events;
+`), 0o644))
+
+	schema, err := ParseSQLFile(path)
+	require.NoError(t, err)
+	require.Len(t, schema.Databases, 2)
+	assert.Equal(t, "analytics", schema.Databases[0].Name)
+	require.Len(t, schema.Databases[0].Views, 1)
+	assert.Equal(t, "recent", schema.Databases[0].Views[0].Name)
+	assert.Equal(t, "default", schema.Databases[1].Name, "unqualified names belong to the default database")
+	require.Len(t, schema.Databases[1].Tables, 1)
+
+	require.NoError(t, os.WriteFile(path, []byte("SELECT 1;\n"), 0o644))
+	_, err = ParseSQLFile(path)
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "seed.sql")
-	assert.Contains(t, err.Error(), "not an .hcl file")
+	assert.Contains(t, err.Error(), path, "the error names the file")
 }
 
 func TestLoadLayers_MissingEntryErrors(t *testing.T) {
@@ -142,3 +181,44 @@ func TestLayerFiles(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, []string{filepath.Join(dir, "a.hcl")}, files, "a file layer contributes itself")
 }
+
+// A glob entry loads a schema organized into subdirectories as one layer;
+// ** spans any depth, hidden directories and non-.hcl files are skipped.
+func TestLayerFiles_Glob(t *testing.T) {
+	dir := t.TempDir()
+	writeLayerFile(t, dir, "schema/root.hcl", "")
+	writeLayerFile(t, dir, "schema/tables/billing/invoices.hcl", "")
+	writeLayerFile(t, dir, "schema/tables/analytics/events.hcl", "")
+	writeLayerFile(t, dir, "schema/tables/analytics/README.md", "")
+	writeLayerFile(t, dir, "schema/.git/x.hcl", "")
+
+	files, err := LayerFiles(filepath.Join(dir, "schema", "**", "*.hcl"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "schema", "root.hcl"),
+		filepath.Join(dir, "schema", "tables", "analytics", "events.hcl"),
+		filepath.Join(dir, "schema", "tables", "billing", "invoices.hcl"),
+	}, files, "matches sorted by path, at every depth")
+
+	files, err = LayerFiles(filepath.Join(dir, "schema", "tables", "*", "*.hcl"))
+	require.NoError(t, err)
+	assert.Len(t, files, 2, "a single * matches exactly one directory level")
+
+	_, err = LayerFiles(filepath.Join(dir, "schema", "**", "*.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "matches no .hcl files")
+
+	writeLayerFile(t, dir, "schema/queries/top.sql", "")
+	files, err = LayerFiles(filepath.Join(dir, "schema", "**", "*.sql"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "schema", "queries", "top.sql")}, files,
+		"a *.sql pattern selects SQL layer files")
+	files, err = LayerFiles(filepath.Join(dir, "schema", "**", "*"))
+	require.NoError(t, err)
+	assert.Len(t, files, 3, "any other pattern only matches .hcl files")
+
+	files, err = RootFiles(filepath.Join(dir, "schema", "tables", "billing", "*.hcl"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "schema", "tables", "billing", "invoices.hcl")}, files,
+		"config roots accept globs too")
+}
@@ -307,3 +307,68 @@ func TestParseFile_PatchDisallowedAttribute(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "primary_key")
 }
+
+func TestLoadLayers_TypeAliasOverride(t *testing.T) {
+	dir := t.TempDir()
+	writeLayerFile(t, dir, "base/types.hcl", `type_alias "Money" { type = "Decimal(18, 4)" }`)
+	writeLayerFile(t, dir, "env/types.hcl", `type_alias "Money" {
+  type     = "Decimal(38, 8)"
+  override = true
+}`)
+	writeLayerFile(t, dir, "bad/types.hcl", `type_alias "Money" { type = "Float64" }`)
+
+	schema, err := LoadLayers([]string{filepath.Join(dir, "base"), filepath.Join(dir, "env")})
+	require.NoError(t, err)
+	require.Len(t, schema.TypeAliases, 1)
+	assert.Equal(t, "Decimal(38, 8)", schema.TypeAliases[0].Type, "a later layer redefines the alias")
+
+	_, err = LoadLayers([]string{filepath.Join(dir, "base"), filepath.Join(dir, "bad")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `type_alias "Money" redeclared without override = true`)
+}
+
+func TestLoadLayers_DatabaseLayout(t *testing.T) {
+	dir := t.TempDir()
+	writeLayerFile(t, dir, "databases/posthog/database.hcl", `cluster = "posthog"`)
+	writeLayerFile(t, dir, "databases/posthog/tables/events.hcl", `
+table "events" {
+  column "id" { type = "UInt64" }
+  engine "merge_tree" {}
+  order_by = ["id"]
+}`)
+	writeLayerFile(t, dir, "databases/posthog/patches/events.hcl", `
+patch_table "events" {
+  column "ts" { type = "DateTime" }
+}`)
+	writeLayerFile(t, dir, "databases/ops/views/uptime.hcl", `
+view "uptime" { query = "SELECT 1" }
+
+database "posthog" {
+  view "events_count" { query = "SELECT count() FROM posthog.events" }
+}`)
+
+	schema, err := LoadLayers([]string{filepath.Join(dir, "databases", "**", "*.hcl")})
+	require.NoError(t, err)
+	require.NoError(t, Resolve(schema))
+
+	require.Len(t, schema.Databases, 2)
+	ops, posthog := schema.Databases[0], schema.Databases[1]
+	assert.Equal(t, "ops", ops.Name)
+	require.Len(t, ops.Views, 1, "an explicit database block in a layout file still names its own database")
+	assert.Equal(t, "uptime", ops.Views[0].Name)
+
+	assert.Equal(t, "posthog", posthog.Name)
+	require.NotNil(t, posthog.Cluster)
+	assert.Equal(t, "posthog", *posthog.Cluster, "database attributes may sit at a layout file's top level")
+	require.Len(t, posthog.Tables, 1)
+	assert.Len(t, posthog.Tables[0].Columns, 2, "top-level patch_table blocks apply to the directory's database")
+	require.Len(t, posthog.Views, 1)
+}
+
+func TestParseFile_TopLevelTableOutsideLayout(t *testing.T) {
+	dir := t.TempDir()
+	writeLayerFile(t, dir, "schema/events.hcl", `table "events" {}`)
+	_, err := ParseFile(filepath.Join(dir, "schema", "events.hcl"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Unsupported block type")
+}
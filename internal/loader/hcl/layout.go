@@ -0,0 +1,29 @@
+package hcl
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// layoutDir is the directory name that switches a schema tree to the
+// per-database layout: every file under databases/<db>/ belongs to <db>.
+const layoutDir = "databases"
+
+// layoutDatabase returns the database a file's path places it in under the
+// per-database layout — <db> for any file below databases/<db>/, at any
+// depth (databases/posthog/tables/events.hcl) — or "" when the path does not
+// follow the layout. The innermost databases/ segment wins.
+//
+// Such a file may declare table, materialized_view, view, dictionary, raw
+// and patch_* blocks (and the database's cluster) at its top level instead
+// of repeating database "<db>" { ... } around them; ParseFileOpts wraps them
+// in that database. Explicit database blocks keep working there.
+func layoutDatabase(path string) string {
+	segs := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	for i := len(segs) - 3; i >= 0; i-- {
+		if segs[i] == layoutDir && segs[i+1] != "" {
+			return segs[i+1]
+		}
+	}
+	return ""
+}
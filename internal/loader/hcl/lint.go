@@ -0,0 +1,266 @@
+package hcl
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LintSeverity is how a lint rule's findings are reported. Only error
+// findings fail `hclexp lint`.
+type LintSeverity string
+
+const (
+	LintOff   LintSeverity = "off"
+	LintWarn  LintSeverity = "warn"
+	LintError LintSeverity = "error"
+)
+
+// LintFinding is one rule violation on one object (and column, when the rule
+// is per-column).
+type LintFinding struct {
+	Rule     string
+	Severity LintSeverity
+	Object   ObjectRef
+	Column   string
+	Message  string
+}
+
+func (f LintFinding) String() string {
+	where := f.Object.String()
+	if f.Column != "" {
+		where += "." + f.Column
+	}
+	return fmt.Sprintf("%s: %s: %s", where, f.Rule, f.Message)
+}
+
+// LintRule is a project convention checked against a resolved schema, one
+// database at a time.
+type LintRule struct {
+	Name     string
+	Default  LintSeverity
+	Describe string
+
+	check func(db *DatabaseSpec) []lintHit
+}
+
+// lintHit is a rule's raw finding before it is given a severity.
+type lintHit struct {
+	object  string
+	column  string
+	message string
+}
+
+// perTable lifts a table check into a rule check over every table.
+func perTable(fn func(t *TableSpec) []lintHit) func(db *DatabaseSpec) []lintHit {
+	return func(db *DatabaseSpec) []lintHit {
+		var hits []lintHit
+		for i := range db.Tables {
+			for _, h := range fn(&db.Tables[i]) {
+				h.object = db.Tables[i].Name
+				hits = append(hits, h)
+			}
+		}
+		return hits
+	}
+}
+
+// LintRules are the built-in rules, in report order. Defaults are tuned for
+// any environment; stricter settings (e.g. non-replicated-engine=error for
+// prod) are passed per run.
+var LintRules = []LintRule{
+	{
+		Name:     "missing-order-by",
+		Default:  LintError,
+		Describe: "MergeTree-family table with no order_by",
+		check:    perTable(lintMissingOrderBy),
+	},
+	{
+		Name:     "non-replicated-engine",
+		Default:  LintOff,
+		Describe: "MergeTree-family table on a non-Replicated engine",
+		check:    perTable(lintNonReplicated),
+	},
+	{
+		Name:     "low-cardinality-string",
+		Default:  LintWarn,
+		Describe: "String column whose name suggests an enumeration (type, status, kind, ...) not wrapped in LowCardinality",
+		check:    perTable(lintLowCardinality),
+	},
+	{
+		Name:     "missing-codec",
+		Default:  LintOff,
+		Describe: "String, JSON, Array or Map column without a codec",
+		check:    perTable(lintMissingCodec),
+	},
+	{
+		Name:     "naming",
+		Default:  LintWarn,
+		Describe: "object or column name that is not snake_case",
+		check:    lintNaming,
+	},
+}
+
+// ParseLintSeverities parses a comma-separated list of RULE=SEVERITY
+// overrides, e.g. "non-replicated-engine=error,naming=off". Unknown rules
+// and severities are errors so a typo cannot silently disable a check.
+func ParseLintSeverities(spec string) (map[string]LintSeverity, error) {
+	out := map[string]LintSeverity{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, sev, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("lint rule %q: want RULE=off|warn|error", entry)
+		}
+		if lintRuleByName(name) == nil {
+			return nil, fmt.Errorf("unknown lint rule %q", name)
+		}
+		switch s := LintSeverity(sev); s {
+		case LintOff, LintWarn, LintError:
+			out[name] = s
+		default:
+			return nil, fmt.Errorf("lint rule %q: unknown severity %q (want off, warn or error)", name, sev)
+		}
+	}
+	return out, nil
+}
+
+func lintRuleByName(name string) *LintRule {
+	for i := range LintRules {
+		if LintRules[i].Name == name {
+			return &LintRules[i]
+		}
+	}
+	return nil
+}
+
+// Lint checks a resolved schema against LintRules. severities overrides each
+// rule's default; rules set to off are skipped. Findings are sorted by
+// object, then column, then rule order.
+func Lint(dbs []DatabaseSpec, severities map[string]LintSeverity) []LintFinding {
+	var out []LintFinding
+	for _, rule := range LintRules {
+		sev := rule.Default
+		if s, ok := severities[rule.Name]; ok {
+			sev = s
+		}
+		if sev == LintOff {
+			continue
+		}
+		for i := range dbs {
+			for _, h := range rule.check(&dbs[i]) {
+				out = append(out, LintFinding{
+					Rule:     rule.Name,
+					Severity: sev,
+					Object:   ObjectRef{Database: dbs[i].Name, Name: h.object},
+					Column:   h.column,
+					Message:  h.message,
+				})
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Object != b.Object {
+			return a.Object.String() < b.Object.String()
+		}
+		return a.Column < b.Column
+	})
+	return out
+}
+
+// mergeTreeKind reports whether t is on a MergeTree-family engine.
+func mergeTreeKind(t *TableSpec) (string, bool) {
+	if t.Engine == nil || t.Engine.Decoded == nil {
+		return "", false
+	}
+	kind := t.Engine.Decoded.Kind()
+	return kind, strings.HasSuffix(kind, "merge_tree")
+}
+
+func lintMissingOrderBy(t *TableSpec) []lintHit {
+	if _, ok := mergeTreeKind(t); !ok || len(t.OrderBy) > 0 {
+		return nil
+	}
+	return []lintHit{{message: "no order_by; every query scans the whole table"}}
+}
+
+func lintNonReplicated(t *TableSpec) []lintHit {
+	kind, ok := mergeTreeKind(t)
+	if !ok || strings.HasPrefix(kind, "replicated_") {
+		return nil
+	}
+	return []lintHit{{message: fmt.Sprintf("engine %s is not replicated", kind)}}
+}
+
+// enumSuffixes are column-name endings that usually hold a small set of values.
+var enumSuffixes = []string{"type", "status", "kind", "state", "category", "level", "country", "region", "env", "environment"}
+
+func lintLowCardinality(t *TableSpec) []lintHit {
+	var hits []lintHit
+	for _, c := range t.Columns {
+		if c.Type != "String" {
+			continue
+		}
+		name := strings.ToLower(c.Name)
+		for _, suffix := range enumSuffixes {
+			if name == suffix || strings.HasSuffix(name, "_"+suffix) {
+				hits = append(hits, lintHit{column: c.Name, message: "String column looks like an enumeration; consider LowCardinality(String)"})
+				break
+			}
+		}
+	}
+	return hits
+}
+
+func lintMissingCodec(t *TableSpec) []lintHit {
+	var hits []lintHit
+	for _, c := range t.Columns {
+		if c.Codec != nil || c.Alias != nil || c.Ephemeral != nil {
+			continue
+		}
+		typ := c.Type
+		for _, wrap := range []string{"Nullable(", "LowCardinality("} {
+			typ = strings.TrimPrefix(typ, wrap)
+		}
+		switch {
+		case typ == "String", strings.HasPrefix(typ, "JSON"),
+			strings.HasPrefix(typ, "Array("), strings.HasPrefix(typ, "Map("):
+			hits = append(hits, lintHit{column: c.Name, message: fmt.Sprintf("%s column has no codec", c.Type)})
+		}
+	}
+	return hits
+}
+
+var snakeCase = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+func lintNaming(db *DatabaseSpec) []lintHit {
+	var hits []lintHit
+	check := func(kind, name string) {
+		if !snakeCase.MatchString(name) {
+			hits = append(hits, lintHit{object: name, message: kind + " name is not snake_case"})
+		}
+	}
+	for _, t := range db.Tables {
+		check("table", t.Name)
+		for _, c := range t.Columns {
+			if !snakeCase.MatchString(c.Name) {
+				hits = append(hits, lintHit{object: t.Name, column: c.Name, message: "column name is not snake_case"})
+			}
+		}
+	}
+	for _, mv := range db.MaterializedViews {
+		check("materialized view", mv.Name)
+	}
+	for _, v := range db.Views {
+		check("view", v.Name)
+	}
+	for _, d := range db.Dictionaries {
+		check("dictionary", d.Name)
+	}
+	return hits
+}
@@ -0,0 +1,56 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lintFixture() []DatabaseSpec {
+	events := mkTable("events", EngineMergeTree{},
+		ColumnSpec{Name: "id", Type: "UInt64"},
+		ColumnSpec{Name: "event_type", Type: "String"},
+		ColumnSpec{Name: "properties", Type: "String", Codec: strPtr("ZSTD(3)")},
+		ColumnSpec{Name: "teamId", Type: "UInt64"},
+	)
+	events.OrderBy = []string{"id"}
+	sessions := mkTable("Sessions", EngineReplicatedMergeTree{},
+		ColumnSpec{Name: "status", Type: "LowCardinality(String)"},
+	)
+	return []DatabaseSpec{mkDB("posthog", events, sessions)}
+}
+
+func TestLint_Defaults(t *testing.T) {
+	var got []string
+	for _, f := range Lint(lintFixture(), nil) {
+		got = append(got, string(f.Severity)+" "+f.String())
+	}
+	assert.Equal(t, []string{
+		"error posthog.Sessions: missing-order-by: no order_by; every query scans the whole table",
+		"warn posthog.Sessions: naming: table name is not snake_case",
+		"warn posthog.events.event_type: low-cardinality-string: String column looks like an enumeration; consider LowCardinality(String)",
+		"warn posthog.events.teamId: naming: column name is not snake_case",
+	}, got)
+}
+
+func TestLint_SeverityOverrides(t *testing.T) {
+	sev, err := ParseLintSeverities("non-replicated-engine=error, missing-codec=warn,naming=off,low-cardinality-string=off,missing-order-by=off")
+	require.NoError(t, err)
+
+	var got []string
+	for _, f := range Lint(lintFixture(), sev) {
+		got = append(got, string(f.Severity)+" "+f.String())
+	}
+	assert.Equal(t, []string{
+		"error posthog.events: non-replicated-engine: engine merge_tree is not replicated",
+		"warn posthog.events.event_type: missing-codec: String column has no codec",
+	}, got)
+}
+
+func TestParseLintSeverities_Errors(t *testing.T) {
+	for _, spec := range []string{"naming", "nmaing=off", "naming=fatal"} {
+		_, err := ParseLintSeverities(spec)
+		assert.Error(t, err, spec)
+	}
+}
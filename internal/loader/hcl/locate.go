@@ -16,8 +16,8 @@ import (
 // inheritance flags (abstract/override/extend/patch_table) that resolution
 // consumes and the resolved specs no longer carry.
 type Declaration struct {
-	ObjectType string // KindTable, KindMaterializedView, KindView, KindDictionary, KindRaw, KindNamedCollection
-	Database   string // empty for named collections (cluster-scoped)
+	ObjectType string // KindTable, KindMaterializedView, KindView, KindDictionary, KindRaw, KindNamedCollection, KindSettingsProfile, KindFunction
+	Database   string // empty for cluster-scoped objects (named collections, settings profiles, functions)
 	Name       string
 	File       string
 	Line       int
@@ -30,7 +30,8 @@ type Declaration struct {
 }
 
 // ScanDeclarations records every object declaration site in the given files,
-// in file order. Files must be native HCL syntax, same as the loader; a
+// in file order. Files are native HCL syntax, HCL's JSON syntax (.json) or
+// .sql schema files (each CREATE is a declaration), same as the loader; a
 // parse error aborts the scan.
 func ScanDeclarations(files []string) ([]Declaration, error) {
 	var out []Declaration
@@ -49,6 +50,13 @@ func ScanDeclarations(files []string) ([]Declaration, error) {
 // exactly one node block, so the name attributes the file's declarations to
 // their host.
 func ScanFileDeclarations(path string) ([]Declaration, string, error) {
+	switch filepath.Ext(path) {
+	case ".sql":
+		decls, err := scanSQLDeclarations(path)
+		return decls, "", err
+	case ".json":
+		return scanJSONDeclarations(path)
+	}
 	parser := hclparse.NewParser()
 	f, diags := parser.ParseHCLFile(path)
 	if diags.HasErrors() {
@@ -60,7 +68,14 @@ func ScanFileDeclarations(path string) ([]Declaration, string, error) {
 	}
 	var out []Declaration
 	node := ""
+	layoutDB := layoutDatabase(path)
 	for _, blk := range body.Blocks {
+		if layoutDB != "" {
+			if d, ok := objectDeclaration(blk, layoutDB, path); ok {
+				out = append(out, d)
+				continue
+			}
+		}
 		switch blk.Type {
 		case "database":
 			if len(blk.Labels) != 1 {
@@ -82,6 +97,21 @@ func ScanFileDeclarations(path string) ([]Declaration, string, error) {
 				Line:       blk.DefRange().Start.Line,
 				Override:   boolAttr(blk.Body, "override"),
 			})
+		case "settings_profile", "function":
+			if len(blk.Labels) != 1 {
+				continue
+			}
+			kind := KindSettingsProfile
+			if blk.Type == "function" {
+				kind = KindFunction
+			}
+			out = append(out, Declaration{
+				ObjectType: kind,
+				Name:       blk.Labels[0],
+				File:       path,
+				Line:       blk.DefRange().Start.Line,
+				Override:   boolAttr(blk.Body, "override"),
+			})
 		case "node":
 			if node == "" && len(blk.Labels) == 1 {
 				node = blk.Labels[0]
@@ -0,0 +1,132 @@
+package hcl
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// objectBlockSchemas are the object declarations a database holds, as block
+// headers for scanning a body that is not native syntax.
+var objectBlockSchemas = []hcl.BlockHeaderSchema{
+	{Type: "table", LabelNames: []string{"name"}},
+	{Type: "patch_table", LabelNames: []string{"name"}},
+	{Type: "materialized_view", LabelNames: []string{"name"}},
+	{Type: "view", LabelNames: []string{"name"}},
+	{Type: "patch_view", LabelNames: []string{"name"}},
+	{Type: "dictionary", LabelNames: []string{"name"}},
+	{Type: "patch_dictionary", LabelNames: []string{"name"}},
+	{Type: "raw", LabelNames: []string{"kind", "name"}},
+}
+
+// scanJSONDeclarations is ScanFileDeclarations for a file in HCL's JSON
+// syntax. It reads the same blocks through the generic hcl.Body API, since a
+// JSON body has no hclsyntax tree to walk.
+func scanJSONDeclarations(path string) ([]Declaration, string, error) {
+	parser := hclparse.NewParser()
+	f, diags := parser.ParseJSONFile(path)
+	if diags.HasErrors() {
+		return nil, "", formatDiagnostics(parser, diags)
+	}
+	top := []hcl.BlockHeaderSchema{
+		{Type: "database", LabelNames: []string{"name"}},
+		{Type: "named_collection", LabelNames: []string{"name"}},
+		{Type: "settings_profile", LabelNames: []string{"name"}},
+		{Type: "function", LabelNames: []string{"name"}},
+		{Type: "node", LabelNames: []string{"name"}},
+	}
+	layoutDB := layoutDatabase(path)
+	if layoutDB != "" {
+		top = append(top, objectBlockSchemas...)
+	}
+	content, _, diags := f.Body.PartialContent(&hcl.BodySchema{Blocks: top})
+	if diags.HasErrors() {
+		return nil, "", formatDiagnostics(parser, diags)
+	}
+
+	var out []Declaration
+	node := ""
+	for _, blk := range content.Blocks {
+		switch blk.Type {
+		case "database":
+			inner, _, diags := blk.Body.PartialContent(&hcl.BodySchema{Blocks: objectBlockSchemas})
+			if diags.HasErrors() {
+				return nil, "", formatDiagnostics(parser, diags)
+			}
+			for _, obj := range inner.Blocks {
+				out = append(out, jsonObjectDeclaration(obj, blk.Labels[0], path))
+			}
+		case "named_collection", "settings_profile", "function":
+			kind := KindNamedCollection
+			switch blk.Type {
+			case "settings_profile":
+				kind = KindSettingsProfile
+			case "function":
+				kind = KindFunction
+			}
+			d := Declaration{ObjectType: kind, Name: blk.Labels[0], File: path, Line: blk.DefRange.Start.Line}
+			d.Override, _ = jsonControlAttrs(blk.Body)["override"].(bool)
+			out = append(out, d)
+		case "node":
+			if node == "" {
+				node = blk.Labels[0]
+			}
+		default:
+			out = append(out, jsonObjectDeclaration(blk, layoutDB, path))
+		}
+	}
+	return out, node, nil
+}
+
+// jsonObjectDeclaration is objectDeclaration for a block of a JSON body.
+func jsonObjectDeclaration(blk *hcl.Block, database, path string) Declaration {
+	d := Declaration{Database: database, File: path, Line: blk.DefRange.Start.Line, Name: blk.Labels[len(blk.Labels)-1]}
+	switch blk.Type {
+	case "table", "patch_table":
+		d.ObjectType = KindTable
+	case "materialized_view":
+		d.ObjectType = KindMaterializedView
+	case "view", "patch_view":
+		d.ObjectType = KindView
+	case "dictionary", "patch_dictionary":
+		d.ObjectType = KindDictionary
+	case "raw":
+		d.ObjectType = KindRaw
+		d.RawKind = blk.Labels[0]
+		return d
+	}
+	d.Patch = strings.HasPrefix(blk.Type, "patch_")
+	attrs := jsonControlAttrs(blk.Body)
+	d.Abstract, _ = attrs["abstract"].(bool)
+	d.Override, _ = attrs["override"].(bool)
+	d.Extends, _ = attrs["extend"].(string)
+	return d
+}
+
+// jsonControlAttrs reads the literal abstract/override/extend attributes of a
+// block body. Like boolAttr and stringAttr, anything that does not evaluate
+// statically reads as unset.
+func jsonControlAttrs(body hcl.Body) map[string]any {
+	content, _, _ := body.PartialContent(&hcl.BodySchema{Attributes: []hcl.AttributeSchema{
+		{Name: "abstract"}, {Name: "override"}, {Name: "extend"},
+	}})
+	out := map[string]any{}
+	if content == nil {
+		return out
+	}
+	for name, attr := range content.Attributes {
+		v, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || v.IsNull() || !v.IsKnown() {
+			continue
+		}
+		switch v.Type() {
+		case cty.Bool:
+			out[name] = v.True()
+		case cty.String:
+			out[name] = v.AsString()
+		}
+	}
+	return out
+}
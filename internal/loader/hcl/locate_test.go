@@ -177,3 +177,55 @@ func TestFindDuplicates(t *testing.T) {
 	}
 	assert.Equal(t, want, got)
 }
+
+func TestScanDeclarations_DatabaseLayout(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "databases", "posthog")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	path := writeHCL(t, dir, "events.hcl", `
+table "events" {
+  engine "MergeTree" {}
+}
+`)
+	decls, err := ScanDeclarations([]string{path})
+	require.NoError(t, err)
+	require.Len(t, decls, 1)
+	assert.Equal(t, "posthog", decls[0].Database, "the database comes from the directory")
+	assert.Equal(t, "events", decls[0].Name)
+	assert.Equal(t, 2, decls[0].Line)
+}
+
+func TestScanDeclarations_SQLFile(t *testing.T) {
+	path := writeHCL(t, t.TempDir(), "schema.sql", `-- seed tables
+CREATE TABLE events (id UInt64) ENGINE = MergeTree ORDER BY id;
+
+CREATE VIEW analytics.recent AS SELECT id FROM default.events;
+`)
+	decls, err := ScanDeclarations([]string{path})
+	require.NoError(t, err)
+	require.Len(t, decls, 2)
+	assert.Equal(t, Declaration{ObjectType: KindTable, Database: "default", Name: "events", File: path, Line: 2}, decls[0])
+	assert.Equal(t, Declaration{ObjectType: KindView, Database: "analytics", Name: "recent", File: path, Line: 4}, decls[1])
+}
+
+func TestScanDeclarations_JSONFile(t *testing.T) {
+	path := writeHCL(t, t.TempDir(), "schema.json", `{
+  "database": {
+    "posthog": {
+      "table": {
+        "events_base": {"abstract": true},
+        "events": {"extend": "events_base", "order_by": ["uuid"]}
+      },
+      "patch_table": {"events": {}}
+    }
+  },
+  "named_collection": {"kafka": {"override": true}}
+}
+`)
+	decls, err := ScanDeclarations([]string{path})
+	require.NoError(t, err)
+	require.Len(t, decls, 4)
+	assert.Equal(t, Declaration{ObjectType: KindTable, Database: "posthog", Name: "events_base", File: path, Line: 5, Abstract: true}, decls[0])
+	assert.Equal(t, Declaration{ObjectType: KindTable, Database: "posthog", Name: "events", File: path, Line: 6, Extends: "events_base"}, decls[1])
+	assert.True(t, decls[2].Patch)
+	assert.Equal(t, Declaration{ObjectType: KindNamedCollection, Name: "kafka", File: path, Line: 11, Override: true}, decls[3])
+}
@@ -13,6 +13,7 @@ func TestCHLive_IntrospectNode(t *testing.T) {
 	if !*clickhouseLive {
 		t.Skip("pass -clickhouse to run against a live ClickHouse")
 	}
+	t.Parallel()
 	conn := testhelpers.RequireClickHouse(t)
 	ctx := context.Background()
 
@@ -0,0 +1,51 @@
+package hcl
+
+import "fmt"
+
+// applyMixins merges each table's mixins into its columns: the mixin's
+// columns are appended after the table's own, mixins in list order. It runs
+// before patches, so a patch_table can modify or drop a mixin column like any
+// other, and before extend, so a mixin on an abstract base reaches every
+// child. A mixin column that collides with a column the table already has is
+// an error, as a collision with an inherited column is.
+func applyMixins(s *Schema) error {
+	mixins := make(map[string]*MixinSpec, len(s.Mixins))
+	for i := range s.Mixins {
+		m := &s.Mixins[i]
+		if _, dup := mixins[m.Name]; dup {
+			return fmt.Errorf("mixin %q: duplicate", m.Name)
+		}
+		if len(m.Columns) == 0 {
+			return fmt.Errorf("mixin %q: declares no columns", m.Name)
+		}
+		mixins[m.Name] = m
+	}
+	for di := range s.Databases {
+		db := &s.Databases[di]
+		for ti := range db.Tables {
+			t := &db.Tables[ti]
+			if len(t.Mixins) == 0 {
+				continue
+			}
+			seen := make(map[string]bool, len(t.Columns))
+			for _, c := range t.Columns {
+				seen[c.Name] = true
+			}
+			for _, name := range t.Mixins {
+				m, ok := mixins[name]
+				if !ok {
+					return fmt.Errorf("%s.%s: mixins references unknown mixin %q", db.Name, t.Name, name)
+				}
+				for _, c := range m.Columns {
+					if seen[c.Name] {
+						return fmt.Errorf("%s.%s: column %q from mixin %q collides with another column", db.Name, t.Name, c.Name, name)
+					}
+					seen[c.Name] = true
+					t.Columns = append(t.Columns, c)
+				}
+			}
+			t.Mixins = nil
+		}
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ func TestCHLive_NamedCollection_ApplyRoundTrip(t *testing.T) {
 	if !*clickhouseLive {
 		t.Skip("pass -clickhouse to run against a live ClickHouse")
 	}
+	t.Parallel()
 	conn := testhelpers.RequireClickHouse(t)
 	ctx := context.Background()
 	name := uniqueNCName("hclexp_apply_rt")
@@ -70,6 +71,7 @@ func TestCHLive_NamedCollection_AlterSetDelete(t *testing.T) {
 	if !*clickhouseLive {
 		t.Skip("pass -clickhouse to run against a live ClickHouse")
 	}
+	t.Parallel()
 	conn := testhelpers.RequireClickHouse(t)
 	ctx := context.Background()
 	name := uniqueNCName("hclexp_alter")
@@ -0,0 +1,61 @@
+package hcl
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// selectOverlays applies environment overlays to a layer's file list. An
+// overlay is a file named <base>.<env>.hcl next to a <base>.hcl of the same
+// layer — events.prod.hcl beside events.hcl — holding that environment's
+// patch_table blocks (engine parameters, settings, TTLs). The overlay for
+// env is moved to directly after its base, so it patches what the base just
+// declared; overlays for other environments, and every overlay when env is
+// empty, are dropped. Files without a base sibling are ordinary layer files,
+// whatever their name.
+func selectOverlays(files []string, env string) []string {
+	present := make(map[string]bool, len(files))
+	for _, f := range files {
+		present[f] = true
+	}
+	overlays := map[string]string{} // base file -> its overlay for env
+	isOverlay := map[string]bool{}
+	for _, f := range files {
+		base, name, ok := overlayBase(f)
+		if !ok || !present[base] {
+			continue
+		}
+		isOverlay[f] = true
+		if name == env {
+			overlays[base] = f
+		}
+	}
+	if len(isOverlay) == 0 {
+		return files
+	}
+	out := make([]string, 0, len(files))
+	for _, f := range files {
+		if isOverlay[f] {
+			continue
+		}
+		out = append(out, f)
+		if o, ok := overlays[f]; ok {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// overlayBase splits an overlay file name: dir/events.prod.hcl is the overlay
+// "prod" of dir/events.hcl.
+func overlayBase(file string) (base, env string, ok bool) {
+	if filepath.Ext(file) != ".hcl" {
+		return "", "", false
+	}
+	stem := strings.TrimSuffix(filepath.Base(file), ".hcl")
+	i := strings.LastIndexByte(stem, '.')
+	if i <= 0 || i == len(stem)-1 {
+		return "", "", false
+	}
+	return filepath.Join(filepath.Dir(file), stem[:i]+".hcl"), stem[i+1:], true
+}
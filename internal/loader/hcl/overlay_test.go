@@ -0,0 +1,55 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectOverlays(t *testing.T) {
+	files := []string{
+		"l/events.hcl",
+		"l/events.dev.hcl",
+		"l/events.prod.hcl",
+		"l/events_v2.hcl",
+		"l/persons.v2.hcl", // no persons.hcl: an ordinary file
+	}
+	assert.Equal(t, []string{"l/events.hcl", "l/events.prod.hcl", "l/events_v2.hcl", "l/persons.v2.hcl"},
+		selectOverlays(files, "prod"), "the env's overlay follows its base; other overlays are dropped")
+	assert.Equal(t, []string{"l/events.hcl", "l/events_v2.hcl", "l/persons.v2.hcl"},
+		selectOverlays(files, ""), "no env loads no overlays")
+}
+
+func TestLoadLayersOpts_Overlay(t *testing.T) {
+	dir := t.TempDir()
+	writeLayerFile(t, dir, "events.hcl", `
+database "posthog" {
+  table "events" {
+    column "ts" { type = "DateTime" }
+    engine "merge_tree" {}
+    order_by = ["ts"]
+    settings = { index_granularity = "8192" }
+  }
+}`)
+	writeLayerFile(t, dir, "events.prod.hcl", `
+database "posthog" {
+  patch_table "events" {
+    ttl      = "ts + INTERVAL 90 DAY"
+    settings = { storage_policy = "tiered" }
+  }
+}`)
+
+	schema, err := LoadLayersOpts([]string{dir}, LoadOptions{Overlay: "prod"})
+	require.NoError(t, err)
+	require.NoError(t, Resolve(schema))
+	events := schema.Databases[0].Tables[0]
+	require.NotNil(t, events.TTL)
+	assert.Equal(t, "ts + INTERVAL 90 DAY", *events.TTL)
+	assert.Equal(t, map[string]string{"index_granularity": "8192", "storage_policy": "tiered"}, events.Settings)
+
+	schema, err = LoadRootsOpts([]string{dir}, LoadOptions{})
+	require.NoError(t, err)
+	require.NoError(t, Resolve(schema))
+	assert.Nil(t, schema.Databases[0].Tables[0].TTL, "config roots skip overlays of unselected envs too")
+}
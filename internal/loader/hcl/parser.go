@@ -2,8 +2,9 @@ package hcl
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
+	"path/filepath"
+	"reflect"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
@@ -11,25 +12,59 @@ import (
 )
 
 type fileSpec struct {
+	DefaultOnCluster *string               `hcl:"default_on_cluster,optional"`
+	Experimental     []string              `hcl:"experimental,optional"`
 	Databases        []DatabaseSpec        `hcl:"database,block"`
 	NamedCollections []NamedCollectionSpec `hcl:"named_collection,block"`
+	SettingsProfiles []SettingsProfileSpec `hcl:"settings_profile,block"`
+	Functions        []FunctionSpec        `hcl:"function,block"`
+	TypeAliases      []TypeAliasSpec       `hcl:"type_alias,block"`
+	Mixins           []MixinSpec           `hcl:"mixin,block"`
 	Nodes            []NodeSpec            `hcl:"node,block"`
+	Clusters         []ClusterSpec         `hcl:"cluster,block"`
+
+	// Rest is every other top-level item: database content declared
+	// without its database block, allowed only in the per-database layout
+	// (see layoutDatabase).
+	Rest hcl.Body `hcl:",remain"`
 }
 
 // ParseFile parses a single HCL file and returns the declared schema.
 // Diagnostics are formatted into the returned error.
 func ParseFile(path string) (*Schema, error) {
+	return ParseFileOpts(path, LoadOptions{})
+}
+
+// ParseFileOpts is ParseFile with the variables in opts visible to the file's
+// expressions as var.<name>, and env() when opts allows it.
+//
+// A .json file is read as HCL's JSON syntax — the same blocks and attributes
+// as JSON objects, e.g. {"database": {"posthog": {"table": {"events": {…}}}}}
+// — so a tool generating a schema can emit JSON instead of HCL text.
+func ParseFileOpts(path string, opts LoadOptions) (*Schema, error) {
 	parser := hclparse.NewParser()
-	f, diags := parser.ParseHCLFile(path)
+	f, diags := parseHCLOrJSON(parser, path)
 	if diags.HasErrors() {
 		return nil, formatDiagnostics(parser, diags)
 	}
 
 	var spec fileSpec
-	if diags := gohcl.DecodeBody(f.Body, evalContextForFile(path), &spec); diags.HasErrors() {
+	ctx := evalContextForFile(path, opts)
+	if diags := gohcl.DecodeBody(f.Body, ctx, &spec); diags.HasErrors() {
+		return nil, formatDiagnostics(parser, diags)
+	}
+
+	inline, diags := decodeLayoutDatabase(path, spec.Rest, ctx)
+	if diags.HasErrors() {
 		return nil, formatDiagnostics(parser, diags)
 	}
+	if inline != nil {
+		spec.Databases = append([]DatabaseSpec{*inline}, spec.Databases...)
+	}
 
+	if err := validateExperimental(spec.Experimental); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
 	for di := range spec.Databases {
 		db := &spec.Databases[di]
 		canonicalize(db)
@@ -45,12 +80,23 @@ func ParseFile(path string) (*Schema, error) {
 			if tbl.Engine == nil {
 				continue
 			}
-			decoded, err := DecodeEngine(tbl.Engine)
+			decoded, err := decodeEngine(tbl.Engine, ctx)
 			if err != nil {
 				return nil, fmt.Errorf("%s.%s: %w", db.Name, tbl.Name, err)
 			}
 			tbl.Engine.Decoded = decoded
 		}
+		for mi := range db.MaterializedViews {
+			mv := &db.MaterializedViews[mi]
+			if mv.Inner == nil || mv.Inner.Engine == nil {
+				continue
+			}
+			decoded, err := decodeEngine(mv.Inner.Engine, ctx)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: inner: %w", db.Name, mv.Name, err)
+			}
+			mv.Inner.Engine.Decoded = decoded
+		}
 		// Patch engine blocks decode exactly like table engines: the patch
 		// replaces the target's engine wholesale at resolution, so the
 		// decoded value must be ready before then.
@@ -59,7 +105,7 @@ func ParseFile(path string) (*Schema, error) {
 			if p.Engine == nil {
 				continue
 			}
-			decoded, err := DecodeEngine(p.Engine)
+			decoded, err := decodeEngine(p.Engine, ctx)
 			if err != nil {
 				return nil, fmt.Errorf("%s: patch_table %q: %w", db.Name, p.Name, err)
 			}
@@ -68,14 +114,14 @@ func ParseFile(path string) (*Schema, error) {
 		for i := range db.Dictionaries {
 			d := &db.Dictionaries[i]
 			if d.Source != nil {
-				decoded, err := DecodeDictionarySource(d.Source)
+				decoded, err := decodeDictionarySource(d.Source, ctx)
 				if err != nil {
 					return nil, fmt.Errorf("%s.%s: %w", db.Name, d.Name, err)
 				}
 				d.Source.Decoded = decoded
 			}
 			if d.Layout != nil {
-				decoded, err := DecodeDictionaryLayout(d.Layout)
+				decoded, err := decodeDictionaryLayout(d.Layout, ctx)
 				if err != nil {
 					return nil, fmt.Errorf("%s.%s: %w", db.Name, d.Name, err)
 				}
@@ -85,14 +131,14 @@ func ParseFile(path string) (*Schema, error) {
 		for pi := range db.DictionaryPatches {
 			p := &db.DictionaryPatches[pi]
 			if p.Source != nil {
-				decoded, err := DecodeDictionarySource(p.Source)
+				decoded, err := decodeDictionarySource(p.Source, ctx)
 				if err != nil {
 					return nil, fmt.Errorf("%s: patch_dictionary %q: %w", db.Name, p.Name, err)
 				}
 				p.Source.Decoded = decoded
 			}
 			if p.Layout != nil {
-				decoded, err := DecodeDictionaryLayout(p.Layout)
+				decoded, err := decodeDictionaryLayout(p.Layout, ctx)
 				if err != nil {
 					return nil, fmt.Errorf("%s: patch_dictionary %q: %w", db.Name, p.Name, err)
 				}
@@ -100,18 +146,61 @@ func ParseFile(path string) (*Schema, error) {
 			}
 		}
 	}
+	for i := range spec.Functions {
+		fn := &spec.Functions[i]
+		as, err := normalizeFunctionBody(fn.As)
+		if err != nil {
+			return nil, fmt.Errorf("%s: function %q: as: %w", path, fn.Name, err)
+		}
+		fn.As = as
+	}
 	return &Schema{
+		DefaultOnCluster: spec.DefaultOnCluster,
+		Experimental:     spec.Experimental,
 		Databases:        spec.Databases,
 		NamedCollections: spec.NamedCollections,
+		SettingsProfiles: spec.SettingsProfiles,
+		Functions:        spec.Functions,
+		TypeAliases:      spec.TypeAliases,
+		Mixins:           spec.Mixins,
 		Nodes:            spec.Nodes,
+		Clusters:         spec.Clusters,
 	}, nil
 }
 
+// parseHCLOrJSON parses path as HCL's JSON syntax when it has the .json
+// extension, as native HCL otherwise.
+func parseHCLOrJSON(parser *hclparse.Parser, path string) (*hcl.File, hcl.Diagnostics) {
+	if filepath.Ext(path) == ".json" {
+		return parser.ParseJSONFile(path)
+	}
+	return parser.ParseHCLFile(path)
+}
+
+// decodeLayoutDatabase decodes the top-level items a file declares outside
+// any block it knows. Under the per-database layout they are the content of
+// the database the path names, returned as its spec (nil when there is
+// none); anywhere else they are reported as unsupported, as they always were.
+func decodeLayoutDatabase(path string, rest hcl.Body, ctx *hcl.EvalContext) (*DatabaseSpec, hcl.Diagnostics) {
+	name := layoutDatabase(path)
+	if name == "" {
+		return nil, gohcl.DecodeBody(rest, ctx, &struct{}{})
+	}
+	db := DatabaseSpec{Name: name}
+	if diags := gohcl.DecodeBody(rest, ctx, &db); diags.HasErrors() {
+		return nil, diags
+	}
+	if reflect.DeepEqual(db, DatabaseSpec{Name: name}) {
+		return nil, nil
+	}
+	return &db, nil
+}
+
 func formatDiagnostics(parser *hclparse.Parser, diags hcl.Diagnostics) error {
 	var buf bytes.Buffer
 	wr := hcl.NewDiagnosticTextWriter(&buf, parser.Files(), 78, false)
 	if err := wr.WriteDiagnostics(diags); err != nil {
 		return fmt.Errorf("hcl diagnostic: %w (original: %s)", err, diags.Error())
 	}
-	return errors.New(buf.String())
+	return &DiagnosticsError{Diags: diags, text: buf.String()}
 }
@@ -1,6 +1,8 @@
 package hcl
 
 import (
+	"errors"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -114,9 +116,37 @@ func TestParseFile_MaterializedView(t *testing.T) {
 						{Name: "category", Type: "LowCardinality(String)"},
 					},
 				},
+				{
+					Name:    "daily_rollup_mv",
+					ToTable: "default.daily_rollup",
+					Query:   "SELECT team_id, count() AS c\nFROM default.events\nGROUP BY\n  team_id",
+					Refresh: &RefreshSpec{
+						Every:        ptr("1 DAY"),
+						Offset:       ptr("2 HOUR"),
+						RandomizeFor: ptr("10 MINUTE"),
+						DependsOn:    []string{"posthog.app_metrics_mv"},
+						Settings:     map[string]string{"refresh_retries": "3"},
+						Append:       true,
+					},
+				},
+				{
+					Name:  "team_counts_mv",
+					Query: "SELECT team_id, count() AS c\nFROM default.events\nGROUP BY\n  team_id",
+					Inner: &MVInnerSpec{
+						Engine:      &EngineSpec{Kind: "summing_merge_tree", Decoded: EngineSummingMergeTree{}},
+						OrderBy:     []string{"team_id"},
+						PartitionBy: ptr("tuple()"),
+						Settings:    map[string]string{"index_granularity": "8192"},
+					},
+					Columns: []ColumnSpec{
+						{Name: "team_id", Type: "Int64"},
+						{Name: "c", Type: "UInt64"},
+					},
+				},
 			},
 		},
 	}
+	stripEngineBodies(schema.Databases)
 	assert.Equal(t, expected, schema.Databases)
 }
 
@@ -220,6 +250,53 @@ func TestParseFile_NamedCollection(t *testing.T) {
 	assert.Empty(t, ext.Params)
 }
 
+func TestParseFile_Function(t *testing.T) {
+	schema, err := ParseFile(filepath.Join("testdata", "function.hcl"))
+	require.NoError(t, err)
+	require.NoError(t, Resolve(schema))
+
+	require.Len(t, schema.Functions, 2)
+	fn := schema.Functions[1]
+	assert.Equal(t, "linear", fn.Name)
+	require.NotNil(t, fn.Cluster)
+	assert.Equal(t, "posthog", *fn.Cluster)
+	// Canonical form: redundant parentheses around the body are dropped.
+	assert.Equal(t, "(x, k, b) -> (k * x) + b", fn.As)
+}
+
+func TestParseFile_FunctionBadBody(t *testing.T) {
+	path := writeHCL(t, t.TempDir(), "f.hcl", `function "f" { as = "not a lambda" }`)
+	_, err := ParseFile(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `function "f": as:`)
+}
+
+func TestParseFile_SettingsProfile(t *testing.T) {
+	schema, err := ParseFile(filepath.Join("testdata", "settings_profile.hcl"))
+	require.NoError(t, err)
+	require.NoError(t, Resolve(schema))
+
+	require.Len(t, schema.SettingsProfiles, 3)
+	assert.True(t, schema.SettingsProfiles[0].External)
+
+	sl := schema.SettingsProfiles[1]
+	assert.Equal(t, "service_limits", sl.Name)
+	require.NotNil(t, sl.Cluster)
+	assert.Equal(t, "posthog", *sl.Cluster)
+	assert.Equal(t, []string{"readonly"}, sl.Inherit)
+	assert.Equal(t, []string{"ingestion_svc", "query_svc"}, sl.To)
+	require.Len(t, sl.Settings, 2)
+	assert.Equal(t, "max_memory_usage", sl.Settings[0].Name)
+	assert.Equal(t, "10000000000", *sl.Settings[0].Value)
+	assert.Equal(t, "20000000000", *sl.Settings[0].Max)
+	assert.Nil(t, sl.Settings[0].Min)
+	assert.Equal(t, "const", *sl.Settings[1].Writability)
+
+	ev := schema.SettingsProfiles[2]
+	assert.True(t, ev.ToAll)
+	assert.Equal(t, []string{"admin"}, ev.ToExcept)
+}
+
 func TestParseFile_KafkaWithCollection(t *testing.T) {
 	schema, err := ParseFile(filepath.Join("testdata", "kafka_with_collection.hcl"))
 	require.NoError(t, err)
@@ -293,3 +370,41 @@ func TestParseFile_View_SQLSecurityCaseInsensitive(t *testing.T) {
 	require.NotNil(t, v.Definer)
 	assert.Equal(t, "alice", *v.Definer)
 }
+
+// Load errors keep their HCL diagnostics, including those raised while
+// decoding an engine body after the parse, so callers can report locations.
+func TestProblems(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.hcl")
+	require.NoError(t, os.WriteFile(path, []byte(`database "posthog" {
+  table "events" {
+    order_by = ["day"]
+    column "day" { type = "Date" }
+    engine "merge_tree" {
+      zoo = "x"
+    }
+  }
+}
+`), 0o600))
+
+	_, err := ParseFile(path)
+	require.Error(t, err)
+	problems := Problems(err)
+	require.Len(t, problems, 1)
+	assert.Equal(t, path+`:6:7: Unsupported argument: An argument named "zoo" is not expected here.`, problems[0].String())
+
+	assert.Nil(t, Problems(errors.New("plain")), "an error without diagnostics has no problems")
+}
+
+func TestParseFile_JSON(t *testing.T) {
+	hclSchema, err := ParseFile(filepath.Join("testdata", "json_schema.hcl"))
+	require.NoError(t, err)
+	jsonSchema, err := ParseFile(filepath.Join("testdata", "json_schema.json"))
+	require.NoError(t, err)
+
+	require.NoError(t, Resolve(hclSchema))
+	require.NoError(t, Resolve(jsonSchema))
+	stripEngineBodies(hclSchema.Databases)
+	stripEngineBodies(jsonSchema.Databases)
+	assert.Equal(t, hclSchema.Databases, jsonSchema.Databases, "the JSON syntax declares the same schema")
+}
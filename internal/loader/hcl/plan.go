@@ -1,6 +1,8 @@
 package hcl
 
 import (
+	"fmt"
+	"slices"
 	"sort"
 	"strings"
 )
@@ -21,18 +23,21 @@ type RoleDiff struct {
 // roles that contribute it; identical statements across roles collapse to one
 // operation carrying the union of roles.
 type PlanOperation struct {
-	Order        int      `json:"order"`
-	Kind         string   `json:"kind"`
-	ObjectType   string   `json:"object_type"`
-	Database     string   `json:"database"`
-	Object       string   `json:"object"`
-	Engine       string   `json:"engine"`
-	Replicated   bool     `json:"replicated"`
-	SQL          string   `json:"sql"`
-	Manual       bool     `json:"manual"` // operator-run only (e.g. MATERIALIZE INDEX); executors must skip it
-	Roles        []string `json:"roles"`
-	Unsafe       bool     `json:"unsafe"`
-	UnsafeReason string   `json:"unsafe_reason"`
+	Order        int               `json:"order"`
+	Kind         string            `json:"kind"`
+	ObjectType   string            `json:"object_type"`
+	Database     string            `json:"database"`
+	Object       string            `json:"object"`
+	Engine       string            `json:"engine"`
+	Replicated   bool              `json:"replicated"`
+	SQL          string            `json:"sql"`
+	Manual       bool              `json:"manual"`             // operator-run only (e.g. MATERIALIZE INDEX); executors must skip it
+	Settings     map[string]string `json:"settings,omitempty"` // query-level settings to run the statement with (allow_experimental_*)
+	Roles        []string          `json:"roles"`
+	Unsafe       bool              `json:"unsafe"`
+	UnsafeReason string            `json:"unsafe_reason"`
+	Advice       string            `json:"advice"`             // AdviceOnline or AdviceCoordinate
+	Guidance     string            `json:"guidance,omitempty"` // what the coordination involves
 }
 
 // RoleComparison is one role's per-object view of its diff. Unlike the
@@ -65,58 +70,125 @@ type PlanResult struct {
 // the MV); DROP runs in reverse. Identical statements across roles dedupe to a
 // single operation carrying the union of contributing roles.
 func BuildPlan(roles []RoleDiff) PlanResult {
-	type opKey struct{ kind, db, object, sql string }
+	b := NewPlanBuilder()
+	for _, rd := range roles {
+		b.Add(rd)
+	}
+	return b.Result()
+}
 
-	var firstSeen []opKey
-	byKey := make(map[opKey]*PlanOperation)
-	unsafeByRef := make(map[ObjectRef]string)
+// planOpKey identifies one operation across roles: identical statements on
+// the same object collapse to one PlanOperation.
+type planOpKey struct{ kind, db, object, sql string }
 
-	// Non-nil so an empty plan marshals roles as [], not null (same contract
-	// as DiffJSON.Objects).
-	roleComparisons := make([]RoleComparison, 0, len(roles))
-	for _, rd := range roles {
-		cs := Diff(rd.Current, rd.Desired)
-		gen := GenerateSQL(cs)
-		objs := BuildObjectComparisons(cs, gen, rd.Current, rd.Desired)
-		roleComparisons = append(roleComparisons, RoleComparison{
-			Role: rd.Role, Objects: objs, Summary: SummarizeComparisons(objs),
-		})
-		for _, op := range gen.Ops {
-			k := opKey{op.Kind, op.Database, op.Object, op.SQL}
-			po, ok := byKey[k]
-			if !ok {
-				po = &PlanOperation{
-					Kind:       op.Kind,
-					ObjectType: op.ObjectType,
-					Database:   op.Database,
-					Object:     op.Object,
-					SQL:        op.SQL,
-					Manual:     op.Manual,
-				}
-				byKey[k] = po
-				firstSeen = append(firstSeen, k)
+// PlanBuilder accumulates a plan one role at a time, so a caller can load,
+// diff and report each role as soon as it is ready instead of materializing
+// every role first (plan -stream on a large catalog). Result applies the
+// cross-role ordering, which needs every role and so only exists at the end.
+// BuildPlan is the one-shot form.
+type PlanBuilder struct {
+	// IfExists generates every operation in its re-runnable form; see
+	// ChangeSet.IfExists.
+	IfExists bool
+
+	roles       []RoleDiff
+	firstSeen   []planOpKey
+	byKey       map[planOpKey]*PlanOperation
+	unsafeByRef map[ObjectRef]string
+	// undeprecated holds, per table ALTER that drops columns, the ones some
+	// role drops without having deprecated them first.
+	undeprecated map[planOpKey][]string
+	comparisons  []RoleComparison
+}
+
+// NewPlanBuilder returns an empty PlanBuilder.
+func NewPlanBuilder() *PlanBuilder {
+	return &PlanBuilder{
+		byKey:        make(map[planOpKey]*PlanOperation),
+		unsafeByRef:  make(map[ObjectRef]string),
+		undeprecated: make(map[planOpKey][]string),
+		// Non-nil so an empty plan marshals roles as [], not null (same
+		// contract as DiffJSON.Objects).
+		comparisons: []RoleComparison{},
+	}
+}
+
+// Add diffs one role and folds its operations into the plan. It returns the
+// role's comparison with operations numbered in the role's own diff order;
+// Result renumbers them to the global order.
+func (b *PlanBuilder) Add(rd RoleDiff) RoleComparison {
+	b.roles = append(b.roles, rd)
+	cs := Diff(rd.Current, rd.Desired)
+	cs.IfExists = b.IfExists
+	gen := GenerateSQL(cs)
+	objs := BuildObjectComparisons(cs, gen, rd.Current, rd.Desired)
+	rc := RoleComparison{Role: rd.Role, Objects: objs, Summary: SummarizeComparisons(objs)}
+	b.comparisons = append(b.comparisons, rc)
+	for _, op := range gen.Ops {
+		k := planOpKey{op.Kind, op.Database, op.Object, op.SQL}
+		po, ok := b.byKey[k]
+		if !ok {
+			po = &PlanOperation{
+				Kind:       op.Kind,
+				ObjectType: op.ObjectType,
+				Database:   op.Database,
+				Object:     op.Object,
+				SQL:        op.SQL,
+				Manual:     op.Manual,
+				Settings:   op.Settings,
 			}
-			po.Roles = appendUniqueRole(po.Roles, rd.Role)
+			b.byKey[k] = po
+			b.firstSeen = append(b.firstSeen, k)
 		}
-		for _, u := range gen.Unsafe {
-			unsafeByRef[ObjectRef{Database: u.Database, Name: u.Table}] = u.Reason
+		po.Roles = appendUniqueRole(po.Roles, rd.Role)
+	}
+	for _, u := range gen.Unsafe {
+		b.unsafeByRef[ObjectRef{Database: u.Database, Name: u.Table}] = u.Reason
+	}
+	for _, dc := range cs.Databases {
+		for _, td := range dc.AlterTables {
+			// The DROP COLUMNs are all in the table's main ALTER, so the
+			// columns attach to that statement's key.
+			k := planOpKey{OpAlter, dc.Database, td.Table, alterTableSQL(dc.Database, td, cs.IfExists)}
+			for _, n := range undeprecatedDrops(dc.Database, td, rd.Current) {
+				if !slices.Contains(b.undeprecated[k], n) {
+					b.undeprecated[k] = append(b.undeprecated[k], n)
+				}
+			}
 		}
 	}
+	return rc
+}
 
-	merged := mergeDesiredSchemas(roles)
+// Result orders everything added so far into the global plan. It can be
+// called more than once; later Adds are reflected in the next Result.
+func (b *PlanBuilder) Result() PlanResult {
+	merged := mergeDesiredSchemas(b.roles)
 	rank := dependencyRank(merged.Databases)
+	mvSources := mvSourceEngines(merged)
 
-	ops := make([]*PlanOperation, 0, len(firstSeen))
-	for _, k := range firstSeen {
-		po := byKey[k]
+	ops := make([]PlanOperation, 0, len(b.firstSeen))
+	for _, k := range b.firstSeen {
+		po := *b.byKey[k]
+		po.Roles = append([]string(nil), po.Roles...)
 		if po.ObjectType == KindTable {
 			po.Engine = engineFor(po.Database, po.Object, merged)
 			po.Replicated = strings.HasPrefix(po.Engine, "Replicated")
 		}
-		if reason, ok := unsafeByRef[ObjectRef{Database: po.Database, Name: po.Object}]; ok {
+		if reason, ok := b.unsafeByRef[ObjectRef{Database: po.Database, Name: po.Object}]; ok {
 			po.Unsafe = true
 			po.UnsafeReason = reason
 		}
+		adviceEngine := po.Engine
+		if po.ObjectType == KindMaterializedView {
+			adviceEngine = mvSources[ObjectRef{Database: po.Database, Name: po.Object}]
+		}
+		po.Advice, po.Guidance = adviseOperation(po.Kind, po.ObjectType, adviceEngine)
+		if cols := b.undeprecated[k]; len(cols) > 0 {
+			po.Advice = AdviceCoordinate
+			po.Guidance = fmt.Sprintf("drops column(s) %s without deprecating them first; declare them deprecated = true and apply that before removing them",
+				strings.Join(cols, ", "))
+		}
 		ops = append(ops, po)
 	}
 
@@ -140,12 +212,11 @@ func BuildPlan(roles []RoleDiff) PlanResult {
 		return ri < rj
 	})
 
-	result := PlanResult{Operations: make([]PlanOperation, 0, len(ops))}
-	for i, po := range ops {
-		po.Order = i
-		result.Operations = append(result.Operations, *po)
+	result := PlanResult{Operations: ops}
+	for i := range result.Operations {
+		result.Operations[i].Order = i
 	}
-	for ref, reason := range unsafeByRef {
+	for ref, reason := range b.unsafeByRef {
 		result.Unsafe = append(result.Unsafe, JSONUnsafe{Database: ref.Database, Object: ref.Name, Reason: reason})
 	}
 	sort.Slice(result.Unsafe, func(i, j int) bool {
@@ -158,19 +229,25 @@ func BuildPlan(roles []RoleDiff) PlanResult {
 	// Each role's ops were numbered against that role's own diff. Renumber them
 	// to the merged global order so an object's nested operations and the flat
 	// execution list agree on sequencing.
-	orderByKey := map[opKey]int{}
+	orderByKey := map[planOpKey]int{}
 	for _, po := range result.Operations {
-		orderByKey[opKey{po.Kind, po.Database, po.Object, po.SQL}] = po.Order
+		orderByKey[planOpKey{po.Kind, po.Database, po.Object, po.SQL}] = po.Order
 	}
-	for ri := range roleComparisons {
-		for oi := range roleComparisons[ri].Objects {
-			ops := roleComparisons[ri].Objects[oi].Operations
+	result.Roles = make([]RoleComparison, len(b.comparisons))
+	for ri, rc := range b.comparisons {
+		objs := make([]ObjectComparison, len(rc.Objects))
+		copy(objs, rc.Objects)
+		for oi := range objs {
+			ops := make([]JSONOperation, len(objs[oi].Operations))
+			copy(ops, objs[oi].Operations)
 			for pi := range ops {
-				ops[pi].Order = orderByKey[opKey{ops[pi].Kind, ops[pi].Database, ops[pi].Object, ops[pi].SQL}]
+				ops[pi].Order = orderByKey[planOpKey{ops[pi].Kind, ops[pi].Database, ops[pi].Object, ops[pi].SQL}]
 			}
+			objs[oi].Operations = ops
 		}
+		rc.Objects = objs
+		result.Roles[ri] = rc
 	}
-	result.Roles = roleComparisons
 	return result
 }
 
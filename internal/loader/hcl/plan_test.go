@@ -159,6 +159,20 @@ func TestBuildPlan_ManualMaterializeIndexPropagates(t *testing.T) {
 	assert.Equal(t, "ALTER TABLE posthog.events MATERIALIZE INDEX idx_id", byManual[true].SQL)
 }
 
+func TestPlanBuilder_IfExists(t *testing.T) {
+	id := ColumnSpec{Name: "id", Type: "UInt64"}
+	b := NewPlanBuilder()
+	b.IfExists = true
+	b.Add(RoleDiff{
+		Role:    "data",
+		Desired: &Schema{Databases: []DatabaseSpec{mkDB("posthog", mkTable("events", EngineMergeTree{}, id, ColumnSpec{Name: "n", Type: "UInt8"}))}},
+		Current: &Schema{Databases: []DatabaseSpec{mkDB("posthog", mkTable("events", EngineMergeTree{}, id))}},
+	})
+	plan := b.Result()
+	require.Len(t, plan.Operations, 1)
+	assert.Equal(t, "ALTER TABLE posthog.events ADD COLUMN IF NOT EXISTS n UInt8", plan.Operations[0].SQL)
+}
+
 // Each role gets its own (non-deduped) object comparison list; a shared
 // object drifting on two roles appears under both, and nested op orders
 // reference the merged global list.
@@ -192,3 +206,37 @@ func TestBuildPlanRoleComparisons(t *testing.T) {
 	assert.Equal(t, plan.Operations[0].Order, plan.Roles[0].Objects[0].Operations[0].Order)
 	assert.Equal(t, plan.Operations[0].Order, plan.Roles[1].Objects[0].Operations[0].Order)
 }
+
+// PlanBuilder reports each role as it is added and produces the same plan as
+// BuildPlan; a Result taken mid-way is a snapshot that later Adds (and the
+// global renumbering) do not mutate.
+func TestPlanBuilderIncremental(t *testing.T) {
+	idCol := ColumnSpec{Name: "id", Type: "UInt64"}
+	a := mkTable("a", EngineMergeTree{}, idCol)
+	a.OrderBy = []string{"id"}
+	b := mkTable("b", EngineMergeTree{}, idCol)
+	b.OrderBy = []string{"id"}
+
+	roles := []RoleDiff{
+		{Role: "ops", Desired: &Schema{Databases: []DatabaseSpec{mkDB("d", a)}}, Current: &Schema{}},
+		{Role: "logs", Desired: &Schema{Databases: []DatabaseSpec{mkDB("d", a, b)}}, Current: &Schema{}},
+	}
+
+	builder := NewPlanBuilder()
+	rc := builder.Add(roles[0])
+	assert.Equal(t, "ops", rc.Role)
+	require.Len(t, rc.Objects, 1)
+	require.Len(t, rc.Objects[0].Operations, 1)
+
+	partial := builder.Result()
+	require.Len(t, partial.Operations, 1)
+	assert.Equal(t, []string{"ops"}, partial.Operations[0].Roles)
+
+	rc = builder.Add(roles[1])
+	assert.Equal(t, "logs", rc.Role)
+	require.Len(t, rc.Objects, 2)
+
+	assert.Equal(t, BuildPlan(roles), builder.Result())
+	assert.Equal(t, []string{"ops"}, partial.Operations[0].Roles, "earlier Result must not change")
+	assert.Len(t, partial.Roles, 1)
+}
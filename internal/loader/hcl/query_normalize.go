@@ -172,6 +172,9 @@ func canonicalize(db *DatabaseSpec) {
 		normalizeColumnExprs(t.Columns)
 		normalizeIndexExprs(t.Indexes)
 	}
+	for mi := range db.MaterializedViews {
+		normalizeColumnExprs(db.MaterializedViews[mi].Columns)
+	}
 	// Patch fields land verbatim on their targets at resolution, so they
 	// must be canonicalized exactly like declared fields — otherwise a
 	// patched expression would diff against its own introspected form.
@@ -91,6 +91,27 @@ func RenderObjectHCL(database, kind, name string, db *DatabaseSpec) (string, err
 	return string(f.Bytes()), nil
 }
 
+// ObjectKind returns the kind of the object named name in db — one of the
+// Kind* constants RenderObjectSQL and RenderObjectHCL accept — or "" when db
+// is nil or declares no such object.
+func ObjectKind(db *DatabaseSpec, name string) string {
+	switch {
+	case db == nil:
+		return ""
+	case findTable(db, name) != nil:
+		return KindTable
+	case findMaterializedView(db, name) != nil:
+		return KindMaterializedView
+	case findView(db, name) != nil:
+		return KindView
+	case findDictionary(db, name) != nil:
+		return KindDictionary
+	case findRaw(db, name) != nil:
+		return KindRaw
+	}
+	return ""
+}
+
 func notFound(kind, name string) error {
 	return fmt.Errorf("render: no %s named %q", kind, name)
 }
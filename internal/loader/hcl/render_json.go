@@ -11,17 +11,18 @@ import (
 // (Operations is parallel to GeneratedSQL.Statements), enriched with the
 // object's engine family and an unsafe flag.
 type JSONOperation struct {
-	Order        int    `json:"order"`
-	Kind         string `json:"kind"`        // CREATE | ALTER | DROP | RENAME
-	ObjectType   string `json:"object_type"` // table | materialized_view | view | dictionary | named_collection | raw
-	Database     string `json:"database"`    // empty for named collections
-	Object       string `json:"object"`
-	Engine       string `json:"engine"`     // ClickHouse engine family, tables only (e.g. ReplicatedMergeTree)
-	Replicated   bool   `json:"replicated"` // true when the engine family is a Replicated* variant
-	SQL          string `json:"sql"`        // statement without trailing ';'
-	Manual       bool   `json:"manual"`     // operator-run only (e.g. MATERIALIZE INDEX); executors must skip it
-	Unsafe       bool   `json:"unsafe"`     // this object has a change that can't be applied in place
-	UnsafeReason string `json:"unsafe_reason"`
+	Order        int               `json:"order"`
+	Kind         string            `json:"kind"`        // CREATE | ALTER | DROP | RENAME
+	ObjectType   string            `json:"object_type"` // table | materialized_view | view | dictionary | named_collection | raw
+	Database     string            `json:"database"`    // empty for named collections
+	Object       string            `json:"object"`
+	Engine       string            `json:"engine"`             // ClickHouse engine family, tables only (e.g. ReplicatedMergeTree)
+	Replicated   bool              `json:"replicated"`         // true when the engine family is a Replicated* variant
+	SQL          string            `json:"sql"`                // statement without trailing ';'
+	Manual       bool              `json:"manual"`             // operator-run only (e.g. MATERIALIZE INDEX); executors must skip it
+	Settings     map[string]string `json:"settings,omitempty"` // query-level settings to run the statement with (allow_experimental_*)
+	Unsafe       bool              `json:"unsafe"`             // this object has a change that can't be applied in place
+	UnsafeReason string            `json:"unsafe_reason"`
 }
 
 // JSONUnsafe is one destructive change that is never auto-emitted. The
@@ -87,6 +88,7 @@ func buildJSONOperations(gen GeneratedSQL, left, right *Schema) []JSONOperation
 			Replicated:   strings.HasPrefix(engine, "Replicated"),
 			SQL:          op.SQL,
 			Manual:       op.Manual,
+			Settings:     op.Settings,
 			Unsafe:       unsafe,
 			UnsafeReason: reason,
 		})
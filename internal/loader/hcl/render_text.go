@@ -8,10 +8,27 @@ import (
 
 // RenderObjectComparisons prints comparisons as the indented, +/-/~ marked
 // summary used by `diff` (text mode) and `drift -details`. Objects render in
-// input order under a header per database; named collections (empty
-// database) render under a "named_collections" header. It consumes the same
+// input order under a header per database; named collections, settings
+// profiles and functions (empty database) render under a "named_collections",
+// a "settings_profiles" and a "functions" header. It consumes the same
 // []ObjectComparison the JSON emits, so text and JSON cannot disagree.
 func RenderObjectComparisons(w io.Writer, objs []ObjectComparison) {
+	RenderObjectComparisonsOpts(w, objs, RenderOptions{})
+}
+
+// RenderOptions tunes the text rendering of RenderObjectComparisonsOpts.
+type RenderOptions struct {
+	// QueryChars sets how much of a changed query (or other multi-line value,
+	// such as raw DDL) is shown. MV queries run to tens of KB, so by default
+	// (0) the line only says the value changed. A positive value prints old
+	// and new collapsed to one line and cut to that many characters; a
+	// negative value prints them in full. JSON and generated SQL always carry
+	// the full text.
+	QueryChars int
+}
+
+// RenderObjectComparisonsOpts is RenderObjectComparisons with options.
+func RenderObjectComparisonsOpts(w io.Writer, objs []ObjectComparison, opts RenderOptions) {
 	statusMark := map[string]string{StatusAdded: "+", StatusDropped: "-", StatusAltered: "~"}
 	header := ""
 	printed := false
@@ -20,6 +37,12 @@ func RenderObjectComparisons(w io.Writer, objs []ObjectComparison) {
 		if o.ObjectType == KindNamedCollection {
 			h = "named_collections"
 		}
+		if o.ObjectType == KindSettingsProfile {
+			h = "settings_profiles"
+		}
+		if o.ObjectType == KindFunction {
+			h = "functions"
+		}
 		if !printed || h != header {
 			fmt.Fprintln(w, h)
 			header, printed = h, true
@@ -40,12 +63,12 @@ func RenderObjectComparisons(w io.Writer, objs []ObjectComparison) {
 		}
 		fmt.Fprintf(w, "  %s %s %s%s\n", statusMark[o.Status], objType, o.Object, suffix)
 		for _, fc := range o.Changes {
-			renderFieldChange(w, fc)
+			renderFieldChange(w, fc, opts)
 		}
 	}
 }
 
-func renderFieldChange(w io.Writer, fc FieldChange) {
+func renderFieldChange(w io.Writer, fc FieldChange, opts RenderOptions) {
 	field := strings.Replace(fc.Field, ":", " ", 1)
 	switch fc.Change {
 	case "add":
@@ -63,8 +86,14 @@ func renderFieldChange(w io.Writer, fc FieldChange) {
 		case fc.Field == "query" || strings.ContainsRune(fc.Old+fc.New, '\n'):
 			// Canonical queries and raw DDL are long and multi-line; inlining
 			// them would break the one-line-per-change layout. Presence is the
-			// signal here — the JSON carries the full old/new text.
-			fmt.Fprintf(w, "      ~ %s changed\n", field)
+			// signal unless QueryChars asks for a summary — the JSON carries
+			// the full old/new text.
+			if opts.QueryChars == 0 {
+				fmt.Fprintf(w, "      ~ %s changed\n", field)
+				break
+			}
+			fmt.Fprintf(w, "      ~ %s: %s -> %s\n", field,
+				summarizeText(fc.Old, opts.QueryChars), summarizeText(fc.New, opts.QueryChars))
 		case fc.Old == "" && fc.New == "":
 			fmt.Fprintf(w, "      ~ %s changed\n", field)
 		case fc.Old == "":
@@ -76,3 +105,17 @@ func renderFieldChange(w io.Writer, fc FieldChange) {
 		}
 	}
 }
+
+// summarizeText collapses s to one line and, when max is positive, cuts it to
+// max characters, noting the full length. An empty s reads "(unset)".
+func summarizeText(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return "(unset)"
+	}
+	r := []rune(s)
+	if max < 0 || len(r) <= max {
+		return s
+	}
+	return fmt.Sprintf("%s… (%d chars)", string(r[:max]), len(r))
+}
@@ -69,3 +69,23 @@ func TestRenderObjectComparisons_MultilineValue(t *testing.T) {
       ~ sql changed
 `, buf.String())
 }
+
+func TestRenderObjectComparisons_QueryChars(t *testing.T) {
+	objs := []ObjectComparison{{Database: "posthog", Object: "mv_events", ObjectType: KindMaterializedView, Status: StatusAltered,
+		Changes: []FieldChange{{Field: "query", Change: "modify",
+			Old: "SELECT id\nFROM posthog.events",
+			New: "SELECT id, ts\nFROM posthog.events\nWHERE id > 0"}}}}
+
+	for _, tc := range []struct {
+		chars int
+		want  string
+	}{
+		{0, "      ~ query changed\n"},
+		{12, "      ~ query: SELECT id FR… (29 chars) -> SELECT id, t… (46 chars)\n"},
+		{-1, "      ~ query: SELECT id FROM posthog.events -> SELECT id, ts FROM posthog.events WHERE id > 0\n"},
+	} {
+		var buf bytes.Buffer
+		RenderObjectComparisonsOpts(&buf, objs, RenderOptions{QueryChars: tc.chars})
+		assert.Equal(t, "database \"posthog\"\n  ~ materialized_view mv_events\n"+tc.want, buf.String(), "QueryChars=%d", tc.chars)
+	}
+}
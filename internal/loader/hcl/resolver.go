@@ -6,9 +6,9 @@ import (
 	"strings"
 )
 
-// Resolve walks each database, applies patch_table additions, resolves
-// extend chains, drops abstract tables, and validates that every remaining
-// table has an engine. All mutation happens in place on the supplied slice.
+// Resolve walks each database, merges mixin columns, applies patch_table
+// additions, expands type aliases, resolves extend chains, drops abstract tables, and validates that
+// every remaining table has an engine. All mutation happens in place on the supplied slice.
 func Resolve(s *Schema) error {
 	if s == nil {
 		return errors.New("Resolve: nil schema")
@@ -16,6 +16,15 @@ func Resolve(s *Schema) error {
 	if err := validateNamedCollections(s); err != nil {
 		return err
 	}
+	if err := validateSettingsProfiles(s); err != nil {
+		return err
+	}
+	if err := validateFunctions(s); err != nil {
+		return err
+	}
+	if err := applyMixins(s); err != nil {
+		return err
+	}
 	for di := range s.Databases {
 		if err := applyPatches(&s.Databases[di]); err != nil {
 			return err
@@ -26,6 +35,14 @@ func Resolve(s *Schema) error {
 		if err := applyDictionaryPatches(&s.Databases[di]); err != nil {
 			return err
 		}
+	}
+	if err := expandTypeAliases(s); err != nil {
+		return err
+	}
+	if err := importCrossDatabaseBases(s); err != nil {
+		return err
+	}
+	for di := range s.Databases {
 		if err := resolveDatabase(&s.Databases[di]); err != nil {
 			return err
 		}
@@ -45,6 +62,9 @@ func Resolve(s *Schema) error {
 	if err := validateDistributedEngines(s); err != nil {
 		return err
 	}
+	if err := validateExternalEngines(s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -75,6 +95,34 @@ func validateReplacingEngines(s *Schema) error {
 	return nil
 }
 
+// validateExternalEngines checks the argument combinations of S3 and S3Queue
+// engines (see validateObjectStorage) and that a URL compression comes with
+// the format that precedes it positionally.
+func validateExternalEngines(s *Schema) error {
+	for _, db := range s.Databases {
+		for _, t := range db.Tables {
+			if t.Engine == nil || t.Engine.Decoded == nil {
+				continue
+			}
+			var err error
+			switch e := t.Engine.Decoded.(type) {
+			case EngineS3:
+				err = validateObjectStorage("s3", e.storageArgs(), false)
+			case EngineS3Queue:
+				err = validateObjectStorage("s3_queue", e.storageArgs(), true)
+			case EngineURL:
+				if e.Compression != nil && e.Format == nil {
+					err = fmt.Errorf("url engine `compression` requires `format`")
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", db.Name, t.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
 // validateDistributedEngines enforces the positional Distributed
 // signature (cluster, db, table[, sharding_key[, policy_name]]): a
 // policy_name can only be sent to ClickHouse when a sharding_key
@@ -121,6 +169,60 @@ func validateNamedCollections(s *Schema) error {
 	return nil
 }
 
+// validateSettingsProfiles enforces name and setting uniqueness, valid
+// writability values, a coherent TO assignment, and that every inherited
+// profile is declared (external = true for users.xml profiles).
+func validateSettingsProfiles(s *Schema) error {
+	declared := map[string]bool{}
+	for _, p := range s.SettingsProfiles {
+		if declared[p.Name] {
+			return fmt.Errorf("settings_profile %q: duplicate", p.Name)
+		}
+		declared[p.Name] = true
+	}
+	for _, p := range s.SettingsProfiles {
+		if p.ToAll && len(p.To) > 0 {
+			return fmt.Errorf("settings_profile %q: to and to_all are mutually exclusive", p.Name)
+		}
+		if len(p.ToExcept) > 0 && !p.ToAll {
+			return fmt.Errorf("settings_profile %q: to_except requires to_all = true", p.Name)
+		}
+		names := map[string]bool{}
+		for _, st := range p.Settings {
+			if names[st.Name] {
+				return fmt.Errorf("settings_profile %q: duplicate setting %q", p.Name, st.Name)
+			}
+			names[st.Name] = true
+			if st.Value == nil && st.Min == nil && st.Max == nil && st.Writability == nil {
+				return fmt.Errorf("settings_profile %q: setting %q needs a value, min, max or writability", p.Name, st.Name)
+			}
+			if st.Writability != nil {
+				if _, ok := settingsProfileWritability[*st.Writability]; !ok {
+					return fmt.Errorf("settings_profile %q: setting %q: invalid writability %q (want const, writable or changeable_in_readonly)", p.Name, st.Name, *st.Writability)
+				}
+			}
+		}
+		for _, inh := range p.Inherit {
+			if !declared[inh] {
+				return fmt.Errorf("settings_profile %q: inherits profile %q which is not declared in the schema (declare with `settings_profile %q { external = true }` if it lives in users.xml)", p.Name, inh, inh)
+			}
+		}
+	}
+	return nil
+}
+
+// validateFunctions enforces function name uniqueness.
+func validateFunctions(s *Schema) error {
+	declared := map[string]bool{}
+	for _, f := range s.Functions {
+		if declared[f.Name] {
+			return fmt.Errorf("function %q: duplicate", f.Name)
+		}
+		declared[f.Name] = true
+	}
+	return nil
+}
+
 // validateKafkaEngines enforces XOR between collection and inline settings,
 // required-fields-when-inline, and that referenced collections exist.
 func validateKafkaEngines(s *Schema) error {
@@ -547,8 +649,11 @@ func resolveDatabase(db *DatabaseSpec) error {
 		}
 	}
 	for _, mv := range db.MaterializedViews {
-		if mv.ToTable == "" {
-			return fmt.Errorf("%s.%s: materialized_view requires to_table", db.Name, mv.Name)
+		if (mv.ToTable == "") == (mv.Inner == nil) {
+			return fmt.Errorf("%s.%s: materialized_view requires exactly one of to_table and inner", db.Name, mv.Name)
+		}
+		if mv.Inner != nil && mv.Inner.Engine == nil {
+			return fmt.Errorf("%s.%s: materialized_view inner requires an engine", db.Name, mv.Name)
 		}
 		if mv.Query == "" {
 			return fmt.Errorf("%s.%s: materialized_view requires query", db.Name, mv.Name)
@@ -558,6 +663,14 @@ func resolveDatabase(db *DatabaseSpec) error {
 				return fmt.Errorf("%s.%s.%s: after/first position a patch_table column add; they are not valid on a materialized_view column", db.Name, mv.Name, c.Name)
 			}
 		}
+		if r := mv.Refresh; r != nil {
+			if (r.Every == nil) == (r.After == nil) {
+				return fmt.Errorf("%s.%s: refresh requires exactly one of every or after", db.Name, mv.Name)
+			}
+			if r.Offset != nil && r.Every == nil {
+				return fmt.Errorf("%s.%s: refresh offset is only valid with every", db.Name, mv.Name)
+			}
+		}
 	}
 	return nil
 }
@@ -608,7 +721,11 @@ func isMergeTreeFamily(e Engine) bool {
 		EngineCollapsingMergeTree,
 		EngineReplicatedCollapsingMergeTree,
 		EngineAggregatingMergeTree,
-		EngineReplicatedAggregatingMergeTree:
+		EngineReplicatedAggregatingMergeTree,
+		EngineVersionedCollapsingMergeTree,
+		EngineReplicatedVersionedCollapsingMergeTree,
+		EngineGraphiteMergeTree,
+		EngineReplicatedGraphiteMergeTree:
 		return true
 	}
 	return false
@@ -677,6 +794,83 @@ func validateColumns(db string, t TableSpec) error {
 	return nil
 }
 
+// importCrossDatabaseBases lets a table or MV extend an abstract table in
+// another database, written extend = "db.table", so one base (team_id,
+// timestamps, shared settings) serves every database. Each referenced base is
+// copied, already patched, into the extending database under its qualified
+// name, with an unqualified extend of its own qualified against its home
+// database; resolveDatabase then treats it like a local abstract parent and
+// drops it with the other abstracts. A name that matches a local table is
+// never treated as qualified.
+func importCrossDatabaseBases(s *Schema) error {
+	for di := range s.Databases {
+		db := &s.Databases[di]
+		local := make(map[string]bool, len(db.Tables))
+		for _, t := range db.Tables {
+			local[t.Name] = true
+		}
+		var refs []string
+		for _, t := range db.Tables {
+			if t.Extend != nil {
+				refs = append(refs, *t.Extend)
+			}
+		}
+		for _, mv := range db.MaterializedViews {
+			if mv.Extend != nil {
+				refs = append(refs, *mv.Extend)
+			}
+		}
+		for _, ref := range refs {
+			if err := importBase(s, db, ref, local); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// importBase copies the base ref names into db (see importCrossDatabaseBases),
+// following its own extend chain. local holds the names db already has, so a
+// base is imported once and a cross-database cycle ends in resolveTable's
+// cycle error.
+func importBase(s *Schema, db *DatabaseSpec, ref string, local map[string]bool) error {
+	if local[ref] {
+		return nil
+	}
+	home, name, ok := strings.Cut(ref, ".")
+	if !ok || home == db.Name {
+		return nil
+	}
+	src := findDatabase(s, home)
+	if src == nil {
+		return nil // resolveTable reports the unknown table
+	}
+	var base *TableSpec
+	for i := range src.Tables {
+		if src.Tables[i].Name == name {
+			base = &src.Tables[i]
+		}
+	}
+	if base == nil {
+		return nil
+	}
+	if !base.Abstract {
+		return fmt.Errorf("%s: extend target %q is in another database and must be abstract", db.Name, ref)
+	}
+	cp := *base
+	cp.Name = ref
+	if cp.Extend != nil && !strings.Contains(*cp.Extend, ".") {
+		qualified := home + "." + *cp.Extend
+		cp.Extend = &qualified
+	}
+	db.Tables = append(db.Tables, cp)
+	local[ref] = true
+	if cp.Extend != nil {
+		return importBase(s, db, *cp.Extend, local)
+	}
+	return nil
+}
+
 func resolveTable(db *DatabaseSpec, idx int, indexByName map[string]int, resolved, visiting map[string]bool) error {
 	t := &db.Tables[idx]
 	if resolved[t.Name] {
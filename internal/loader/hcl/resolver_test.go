@@ -27,6 +27,11 @@ func stripEngineBodies(dbs []DatabaseSpec) {
 				d.Layout.Body = nil
 			}
 		}
+		for i := range dbs[di].MaterializedViews {
+			if in := dbs[di].MaterializedViews[i].Inner; in != nil && in.Engine != nil {
+				in.Engine.Body = nil
+			}
+		}
 	}
 }
 
@@ -221,6 +226,83 @@ func TestResolve_NamedCollection_Validation(t *testing.T) {
 	}
 }
 
+func TestResolve_SettingsProfile_Validation(t *testing.T) {
+	cases := []struct {
+		name    string
+		schema  *Schema
+		errSubs string
+	}{
+		{
+			name: "duplicate names",
+			schema: &Schema{SettingsProfiles: []SettingsProfileSpec{
+				{Name: "p"}, {Name: "p"},
+			}},
+			errSubs: "duplicate",
+		},
+		{
+			name: "duplicate settings",
+			schema: &Schema{SettingsProfiles: []SettingsProfileSpec{{Name: "p", Settings: []SettingsProfileSetting{
+				{Name: "max_threads", Value: ptr("4")},
+				{Name: "max_threads", Value: ptr("8")},
+			}}}},
+			errSubs: "duplicate setting",
+		},
+		{
+			name: "setting without value or constraint",
+			schema: &Schema{SettingsProfiles: []SettingsProfileSpec{{Name: "p", Settings: []SettingsProfileSetting{
+				{Name: "max_threads"},
+			}}}},
+			errSubs: "needs a value",
+		},
+		{
+			name: "invalid writability",
+			schema: &Schema{SettingsProfiles: []SettingsProfileSpec{{Name: "p", Settings: []SettingsProfileSetting{
+				{Name: "max_threads", Value: ptr("4"), Writability: ptr("readonly")},
+			}}}},
+			errSubs: "invalid writability",
+		},
+		{
+			name: "to and to_all",
+			schema: &Schema{SettingsProfiles: []SettingsProfileSpec{
+				{Name: "p", To: []string{"u"}, ToAll: true},
+			}},
+			errSubs: "mutually exclusive",
+		},
+		{
+			name: "to_except without to_all",
+			schema: &Schema{SettingsProfiles: []SettingsProfileSpec{
+				{Name: "p", ToExcept: []string{"u"}},
+			}},
+			errSubs: "requires to_all",
+		},
+		{
+			name: "inherit undeclared profile",
+			schema: &Schema{SettingsProfiles: []SettingsProfileSpec{
+				{Name: "p", Inherit: []string{"readonly"}},
+			}},
+			errSubs: "not declared",
+		},
+		{
+			name: "inherit external profile",
+			schema: &Schema{SettingsProfiles: []SettingsProfileSpec{
+				{Name: "readonly", External: true},
+				{Name: "p", Inherit: []string{"readonly"}, ToAll: true, ToExcept: []string{"admin"}},
+			}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Resolve(tc.schema)
+			if tc.errSubs == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errSubs)
+			}
+		})
+	}
+}
+
 func TestResolve_KafkaEngine_XOR(t *testing.T) {
 	mkTblWithKafka := func(eng EngineKafka) *Schema {
 		return &Schema{Databases: []DatabaseSpec{{
@@ -434,7 +516,16 @@ func TestResolve_NonAbstractMV_RequiresToTable(t *testing.T) {
 	mv.ToTable = ""
 	err := Resolve(s)
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "requires to_table")
+	assert.Contains(t, err.Error(), "exactly one of to_table and inner")
+}
+
+func TestResolve_MV_ToTableAndInnerConflict(t *testing.T) {
+	s := mvExtendBase()
+	mv := &s.Databases[0].MaterializedViews[0]
+	mv.Inner = &MVInnerSpec{Engine: &EngineSpec{Kind: "memory", Decoded: EngineMemory{}}}
+	err := Resolve(s)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exactly one of to_table and inner")
 }
 
 func TestResolve_NonAbstractMV_RequiresQuery(t *testing.T) {
@@ -446,6 +537,31 @@ func TestResolve_NonAbstractMV_RequiresQuery(t *testing.T) {
 	assert.Contains(t, err.Error(), "requires query")
 }
 
+func TestResolve_MVRefreshValidation(t *testing.T) {
+	for name, tc := range map[string]struct {
+		refresh RefreshSpec
+		wantErr string
+	}{
+		"neither every nor after": {RefreshSpec{}, "exactly one of every or after"},
+		"both every and after":    {RefreshSpec{Every: ptr("1 DAY"), After: ptr("1 DAY")}, "exactly one of every or after"},
+		"offset with after":       {RefreshSpec{After: ptr("1 DAY"), Offset: ptr("1 HOUR")}, "offset is only valid with every"},
+		"every with offset":       {RefreshSpec{Every: ptr("1 DAY"), Offset: ptr("1 HOUR")}, ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := mvExtendBase()
+			r := tc.refresh
+			s.Databases[0].MaterializedViews[0].Refresh = &r
+			err := Resolve(s)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
 func TestResolve_DatabaseClusterCascadesToMV(t *testing.T) {
 	s := mvExtendBase()
 	s.Databases[0].Cluster = ptr("posthog")
@@ -546,3 +662,120 @@ func TestResolve_TimeSeries_AllValidForms_OK(t *testing.T) {
 	}}}
 	require.NoError(t, Resolve(s))
 }
+
+// extend = "db.table" reaches an abstract base in another database, along
+// with the base's own (unqualified) chain in its home database.
+func TestResolve_CrossDatabaseExtend(t *testing.T) {
+	schema, err := ParseFile(filepath.Join("testdata", "resolve_cross_database.hcl"))
+	require.NoError(t, err)
+	require.NoError(t, Resolve(schema))
+
+	analytics := schema.Databases[1]
+	require.Len(t, analytics.Tables, 1, "imported bases are dropped like local abstracts")
+	sessions := analytics.Tables[0]
+	var cols []string
+	for _, c := range sessions.Columns {
+		cols = append(cols, c.Name)
+	}
+	assert.Equal(t, []string{"team_id", "timestamp", "session_id"}, cols)
+	assert.Equal(t, map[string]string{"index_granularity": "8192"}, sessions.Settings)
+
+	require.Len(t, schema.Databases[0].Tables, 1, "the home database resolves as before")
+}
+
+func TestResolve_CrossDatabaseExtendRequiresAbstract(t *testing.T) {
+	schema, err := ParseFile(filepath.Join("testdata", "resolve_cross_database_concrete.hcl"))
+	require.NoError(t, err)
+	err = Resolve(schema)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be abstract")
+}
+
+func TestResolve_TypeAliases(t *testing.T) {
+	schema, err := ParseFile(filepath.Join("testdata", "resolve_type_alias.hcl"))
+	require.NoError(t, err)
+	require.NoError(t, Resolve(schema))
+
+	require.Len(t, schema.Databases[0].Tables, 1)
+	cols := map[string]ColumnSpec{}
+	for _, c := range schema.Databases[0].Tables[0].Columns {
+		cols[c.Name] = c
+	}
+	require.Len(t, cols, 4)
+	assert.Equal(t, "UInt64", cols["id"].Type, "inherited columns expand too")
+	assert.Equal(t, "Delta, ZSTD", *cols["id"].Codec, "the alias codec applies when the column sets none")
+	assert.Equal(t, "Decimal(18, 4)", cols["amount"].Type)
+	assert.True(t, cols["amount"].Nullable)
+	assert.Equal(t, "ZSTD(3)", *cols["customer_id"].Codec, "a column codec wins over the alias codec")
+	assert.Equal(t, "Decimal(18, 4)", cols["refunded"].Type, "patch_table columns expand too")
+}
+
+func TestResolve_TypeAliasErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		aliases []TypeAliasSpec
+		want    string
+	}{
+		"chained": {
+			aliases: []TypeAliasSpec{{Name: "Money", Type: "Decimal(18, 4)"}, {Name: "Price", Type: "Money"}},
+			want:    `type_alias "Price": type "Money" is itself an alias`,
+		},
+		"empty type": {
+			aliases: []TypeAliasSpec{{Name: "Money"}},
+			want:    `type_alias "Money": type is empty`,
+		},
+		"duplicate": {
+			aliases: []TypeAliasSpec{{Name: "Money", Type: "Decimal(18, 4)"}, {Name: "Money", Type: "Float64"}},
+			want:    `type_alias "Money": duplicate`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := Resolve(&Schema{TypeAliases: tc.aliases})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.want)
+		})
+	}
+}
+
+func TestResolve_Mixins(t *testing.T) {
+	schema, err := ParseFile(filepath.Join("testdata", "resolve_mixin.hcl"))
+	require.NoError(t, err)
+	require.NoError(t, Resolve(schema))
+
+	require.Len(t, schema.Databases[0].Tables, 1)
+	users := schema.Databases[0].Tables[0]
+	var cols []string
+	for _, c := range users.Columns {
+		cols = append(cols, c.Name)
+	}
+	assert.Equal(t, []string{"team_id", "_timestamp", "id", "created_at"}, cols,
+		"mixin columns follow the table's own; the base's mixin is inherited; patches see mixin columns")
+	assert.Equal(t, "now64(3)", *users.Columns[3].Default)
+	assert.Equal(t, "DoubleDelta, ZSTD", *users.Columns[1].Codec)
+	assert.Nil(t, users.Mixins, "mixins is consumed by resolution")
+}
+
+func TestResolve_MixinErrors(t *testing.T) {
+	ts := MixinSpec{Name: "timestamps", Columns: []ColumnSpec{{Name: "created_at", Type: "DateTime"}}}
+	for name, tc := range map[string]struct {
+		table TableSpec
+		want  string
+	}{
+		"unknown": {
+			table: TableSpec{Name: "t", Mixins: []string{"audit"}},
+			want:  `app.t: mixins references unknown mixin "audit"`,
+		},
+		"collision": {
+			table: TableSpec{Name: "t", Mixins: []string{"timestamps"}, Columns: []ColumnSpec{{Name: "created_at", Type: "Date"}}},
+			want:  `app.t: column "created_at" from mixin "timestamps" collides with another column`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := Resolve(&Schema{
+				Mixins:    []MixinSpec{ts},
+				Databases: []DatabaseSpec{{Name: "app", Tables: []TableSpec{tc.table}}},
+			})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.want)
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package hcl
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	chparser "github.com/orian/clickhouse-sql-parser/parser"
+
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// Rewrite renames environment-specific identifiers in a schema, so a dump of
+// one environment can be imported as another (prod → dev, one cluster → its
+// clone). It holds three maps, each from an old value to its replacement:
+//
+//   - database prefixes: a database whose name starts with a key has that
+//     prefix replaced, and so does every reference to it;
+//   - ZooKeeper path prefixes of Replicated* engines;
+//   - cluster names, exact match.
+//
+// For prefix maps the longest matching key wins. A nil Rewrite changes
+// nothing.
+type Rewrite struct {
+	databasePrefix map[string]string
+	zooPathPrefix  map[string]string
+	cluster        map[string]string
+}
+
+// rewriteFile is the on-disk config: a single rewrite block of three
+// optional maps.
+type rewriteFile struct {
+	Rewrite *struct {
+		DatabasePrefix map[string]string `hcl:"database_prefix,optional"`
+		ZooPathPrefix  map[string]string `hcl:"zoo_path_prefix,optional"`
+		Cluster        map[string]string `hcl:"cluster,optional"`
+	} `hcl:"rewrite,block"`
+}
+
+// LoadRewriteConfig parses an HCL rewrite config:
+//
+//	rewrite {
+//	  database_prefix = { "posthog" = "posthog_dev" }
+//	  zoo_path_prefix = { "/clickhouse/prod/" = "/clickhouse/dev/" }
+//	  cluster         = { "posthog" = "posthog_dev" }
+//	}
+//
+// Empty keys are rejected: an empty prefix would match every name.
+func LoadRewriteConfig(path string) (*Rewrite, error) {
+	parser := hclparse.NewParser()
+	f, diags := parser.ParseHCLFile(path)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("%s", diags)
+	}
+	var cfg rewriteFile
+	if diags := gohcl.DecodeBody(f.Body, nil, &cfg); diags.HasErrors() {
+		return nil, fmt.Errorf("%s", diags)
+	}
+	if cfg.Rewrite == nil {
+		return &Rewrite{}, nil
+	}
+	r := NewRewrite(cfg.Rewrite.DatabasePrefix, cfg.Rewrite.ZooPathPrefix, cfg.Rewrite.Cluster)
+	for name, m := range map[string]map[string]string{
+		"database_prefix": r.databasePrefix, "zoo_path_prefix": r.zooPathPrefix, "cluster": r.cluster,
+	} {
+		if _, ok := m[""]; ok {
+			return nil, fmt.Errorf("rewrite: %s has an empty key", name)
+		}
+	}
+	return r, nil
+}
+
+// NewRewrite builds a Rewrite from explicit maps (used by tests and callers
+// that don't load a config file). Nil maps are fine.
+func NewRewrite(databasePrefix, zooPathPrefix, cluster map[string]string) *Rewrite {
+	return &Rewrite{databasePrefix: databasePrefix, zooPathPrefix: zooPathPrefix, cluster: cluster}
+}
+
+// Apply rewrites s in place: database names and every structured reference
+// to a database (materialized view to_table, table references in view and
+// materialized view queries, Distributed and Buffer targets, ClickHouse
+// dictionary sources), Replicated* ZooKeeper paths, and every cluster name
+// (ON CLUSTER targets and Distributed clusters). Raw blocks are opaque and
+// left untouched.
+func (r *Rewrite) Apply(s *Schema) error {
+	if r == nil || s == nil {
+		return nil
+	}
+	for di := range s.Databases {
+		db := &s.Databases[di]
+		db.Name = r.database(db.Name)
+		r.clusterPtr(db.Cluster)
+		for i := range db.Tables {
+			t := &db.Tables[i]
+			r.clusterPtr(t.Cluster)
+			if t.Engine != nil {
+				t.Engine.Decoded = r.engine(t.Engine.Decoded)
+			}
+		}
+		for i := range db.MaterializedViews {
+			mv := &db.MaterializedViews[i]
+			r.clusterPtr(mv.Cluster)
+			if mv.ToTable != "" {
+				mv.ToTable = r.qualified(mv.ToTable)
+			}
+			if mv.Inner != nil && mv.Inner.Engine != nil {
+				mv.Inner.Engine.Decoded = r.engine(mv.Inner.Engine.Decoded)
+			}
+			q, err := r.query(mv.Query)
+			if err != nil {
+				return fmt.Errorf("rewrite %s.%s query: %w", db.Name, mv.Name, err)
+			}
+			mv.Query = q
+		}
+		for i := range db.Views {
+			v := &db.Views[i]
+			r.clusterPtr(v.Cluster)
+			q, err := r.query(v.Query)
+			if err != nil {
+				return fmt.Errorf("rewrite %s.%s query: %w", db.Name, v.Name, err)
+			}
+			v.Query = q
+		}
+		for i := range db.Dictionaries {
+			d := &db.Dictionaries[i]
+			r.clusterPtr(d.Cluster)
+			if d.Source == nil {
+				continue
+			}
+			if src, ok := d.Source.Decoded.(SourceClickHouse); ok && src.DB != nil {
+				src.DB = strPtr(r.database(*src.DB))
+				d.Source.Decoded = src
+			}
+		}
+	}
+	for i := range s.NamedCollections {
+		r.clusterPtr(s.NamedCollections[i].Cluster)
+	}
+	for i := range s.SettingsProfiles {
+		r.clusterPtr(s.SettingsProfiles[i].Cluster)
+	}
+	for i := range s.Functions {
+		r.clusterPtr(s.Functions[i].Cluster)
+	}
+	for i := range s.Clusters {
+		r.clusterPtr(&s.Clusters[i].Name)
+	}
+	r.clusterPtr(s.DefaultOnCluster)
+	return nil
+}
+
+// ApplyMetadata renames the databases of m's table stats like Apply renames
+// the schema's, so they still name the tables of the rewritten dump.
+func (r *Rewrite) ApplyMetadata(m *DumpMetadata) {
+	if r == nil || m == nil {
+		return
+	}
+	for i := range m.Tables {
+		m.Tables[i].Database = r.database(m.Tables[i].Database)
+	}
+}
+
+// database applies the longest matching database prefix to name.
+func (r *Rewrite) database(name string) string {
+	return replacePrefix(name, r.databasePrefix)
+}
+
+// qualified rewrites the database part of a "db.name" reference; a bare
+// name is returned unchanged.
+func (r *Rewrite) qualified(ref string) string {
+	db, name, ok := strings.Cut(ref, ".")
+	if !ok {
+		return ref
+	}
+	return r.database(db) + "." + name
+}
+
+func (r *Rewrite) clusterPtr(c *string) {
+	if c == nil {
+		return
+	}
+	if to, ok := r.cluster[*c]; ok {
+		*c = to
+	}
+}
+
+// engine rewrites the database and cluster references of Distributed and
+// Buffer engines and the ZooKeeper path of any Replicated* engine. Every
+// Replicated* variant keeps its path in a ZooPath field, so it is set by
+// reflection rather than one case per variant.
+func (r *Rewrite) engine(e Engine) Engine {
+	switch v := e.(type) {
+	case EngineDistributed:
+		v.RemoteDatabase = r.database(v.RemoteDatabase)
+		if to, ok := r.cluster[v.ClusterName]; ok {
+			v.ClusterName = to
+		}
+		return v
+	case EngineBuffer:
+		v.Database = r.database(v.Database)
+		return v
+	case nil:
+		return nil
+	}
+	if len(r.zooPathPrefix) == 0 {
+		return e
+	}
+	cp := reflect.New(reflect.TypeOf(e)).Elem()
+	cp.Set(reflect.ValueOf(e))
+	f := cp.FieldByName("ZooPath")
+	if !f.IsValid() || f.Kind() != reflect.String {
+		return e
+	}
+	f.SetString(replacePrefix(f.String(), r.zooPathPrefix))
+	return cp.Interface().(Engine)
+}
+
+// query rewrites the database of every qualified table reference in a
+// SELECT and returns it re-rendered in canonical form. A query without a
+// matching reference is returned unchanged.
+func (r *Rewrite) query(q string) (string, error) {
+	if len(r.databasePrefix) == 0 || strings.TrimSpace(q) == "" {
+		return q, nil
+	}
+	stmt, err := parseCreateStatement("CREATE VIEW __rewrite__ AS " + q)
+	if err != nil {
+		return "", err
+	}
+	cv, ok := stmt.(*chparser.CreateView)
+	if !ok || cv.SubQuery == nil || cv.SubQuery.Select == nil {
+		return q, nil
+	}
+	changed := false
+	for _, n := range chparser.FindAll(cv.SubQuery.Select, isTableIdentifier) {
+		id := n.(*chparser.TableIdentifier)
+		if id.Database == nil {
+			continue
+		}
+		if to := r.database(id.Database.Name); to != id.Database.Name {
+			id.Database.Name = to
+			changed = true
+		}
+	}
+	if !changed {
+		return q, nil
+	}
+	return beautifyNode(cv.SubQuery.Select), nil
+}
+
+// replacePrefix swaps the longest key of m that prefixes s for its value.
+func replacePrefix(s string, m map[string]string) string {
+	best := ""
+	for from := range m {
+		if strings.HasPrefix(s, from) && len(from) > len(best) {
+			best = from
+		}
+	}
+	if best == "" {
+		return s
+	}
+	return m[best] + strings.TrimPrefix(s, best)
+}
@@ -0,0 +1,94 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewrite_Apply(t *testing.T) {
+	r := NewRewrite(
+		map[string]string{"posthog": "dev", "posthog_kafka": "dev_stream"},
+		map[string]string{"/clickhouse/prod/": "/clickhouse/dev/"},
+		map[string]string{"posthog": "posthog_dev"},
+	)
+	s := &Schema{
+		DefaultOnCluster: strPtr("posthog"),
+		Databases: []DatabaseSpec{
+			{
+				Name:    "posthog",
+				Cluster: strPtr("posthog"),
+				Tables: []TableSpec{
+					{Name: "sharded_events", Engine: &EngineSpec{Kind: "replicated_merge_tree", Decoded: EngineReplicatedMergeTree{
+						ZooPath: "/clickhouse/prod/tables/{shard}/sharded_events", ReplicaName: "{replica}",
+					}}},
+					{Name: "events", Engine: &EngineSpec{Kind: "distributed", Decoded: EngineDistributed{
+						ClusterName: "posthog", RemoteDatabase: "posthog", RemoteTable: "sharded_events",
+					}}},
+				},
+				MaterializedViews: []MaterializedViewSpec{{
+					Name: "events_mv", ToTable: "posthog.events", Query: "SELECT * FROM posthog_kafka.kafka_events",
+				}},
+				Views: []ViewSpec{{Name: "v", Query: "SELECT id FROM other.t"}},
+				Dictionaries: []DictionarySpec{{
+					Name:   "d",
+					Source: &DictionarySourceSpec{Kind: "clickhouse", Decoded: SourceClickHouse{DB: strPtr("posthog"), Table: strPtr("t")}},
+				}},
+			},
+			{Name: "posthog_kafka"},
+			{Name: "other"},
+		},
+		NamedCollections: []NamedCollectionSpec{{Name: "s3", Cluster: strPtr("posthog")}},
+		Clusters:         []ClusterSpec{{Name: "posthog"}, {Name: "posthog_writable"}},
+	}
+
+	require.NoError(t, r.Apply(s))
+
+	assert.Equal(t, "posthog_dev", *s.DefaultOnCluster)
+	db := s.Databases[0]
+	assert.Equal(t, "dev", db.Name)
+	assert.Equal(t, "posthog_dev", *db.Cluster)
+	assert.Equal(t, "/clickhouse/dev/tables/{shard}/sharded_events", db.Tables[0].Engine.Decoded.(EngineReplicatedMergeTree).ZooPath)
+	dist := db.Tables[1].Engine.Decoded.(EngineDistributed)
+	assert.Equal(t, "posthog_dev", dist.ClusterName)
+	assert.Equal(t, "dev", dist.RemoteDatabase)
+	assert.Equal(t, "dev.events", db.MaterializedViews[0].ToTable)
+	assert.Equal(t, "SELECT *\nFROM dev_stream.kafka_events", db.MaterializedViews[0].Query, "longest prefix wins")
+	assert.Equal(t, "SELECT id FROM other.t", db.Views[0].Query, "untouched query is kept verbatim")
+	assert.Equal(t, "dev", *db.Dictionaries[0].Source.Decoded.(SourceClickHouse).DB)
+	assert.Equal(t, "dev_stream", s.Databases[1].Name)
+	assert.Equal(t, "other", s.Databases[2].Name)
+	assert.Equal(t, "posthog_dev", *s.NamedCollections[0].Cluster)
+	assert.Equal(t, "posthog_dev", s.Clusters[0].Name)
+	assert.Equal(t, "posthog_writable", s.Clusters[1].Name)
+
+	meta := &DumpMetadata{Tables: []TableStats{{Database: "posthog_kafka", Name: "kafka_events"}}}
+	r.ApplyMetadata(meta)
+	assert.Equal(t, "dev_stream", meta.Tables[0].Database)
+
+	var nilR *Rewrite
+	assert.NoError(t, nilR.Apply(s))
+	nilR.ApplyMetadata(meta)
+}
+
+func TestLoadRewriteConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := writeHCL(t, dir, "rewrite.hcl", `
+rewrite {
+  database_prefix = { "posthog" = "dev" }
+  cluster         = { "posthog" = "posthog_dev" }
+}`)
+	r, err := LoadRewriteConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "dev_test", r.database("posthog_test"))
+	assert.Equal(t, "/clickhouse/x", replacePrefix("/clickhouse/x", r.zooPathPrefix))
+
+	path = writeHCL(t, dir, "empty_key.hcl", `
+rewrite {
+  database_prefix = { "" = "dev_" }
+}`)
+	_, err = LoadRewriteConfig(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "database_prefix has an empty key")
+}
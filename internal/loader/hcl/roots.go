@@ -0,0 +1,214 @@
+package hcl
+
+import (
+	"fmt"
+	"os"
+)
+
+// LoadRoots loads several independent config roots — e.g. a core schema repo
+// and a team-specific one — and merges them into one desired state. A root is
+// a single HCL file (any extension, like ParseFile) or a directory (every *.hcl
+// in it, loaded as one layer).
+//
+// Unlike a layer stack, roots are peers with no precedence between them, so an
+// object declared in two roots is always an error: override = true only
+// replaces within an ordered stack, and there is no "later" root to win.
+// patch_table/patch_view/patch_dictionary blocks accumulate in root order, so a
+// team root may patch a table the core root declares. Node blocks follow the
+// layer rule (last declaration wins).
+//
+// Like LoadLayers, LoadRoots does NOT call Resolve.
+func LoadRoots(roots []string) (*Schema, error) {
+	return LoadRootsOpts(roots, LoadOptions{})
+}
+
+// LoadRootsOpts is LoadRoots with the variables in opts visible to every
+// file's expressions.
+func LoadRootsOpts(roots []string, opts LoadOptions) (*Schema, error) {
+	out := &Schema{}
+	owner := map[string]string{}
+	for _, root := range roots {
+		files, err := RootFiles(root)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := loadRootFiles(files, opts)
+		if err != nil {
+			return nil, err
+		}
+		if err := mergeRoot(out, parsed, root, owner); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// RootFiles returns the HCL files a config root contributes: for a directory,
+// what LayerFiles lists; for a regular file, the file itself whatever its
+// extension (a single -config has always accepted e.g. node.conf); for a glob,
+// the .hcl files it matches.
+func RootFiles(root string) ([]string, error) {
+	if isGlob(root) {
+		return LayerFiles(root)
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("read config %q: %w", root, err)
+	}
+	if info.IsDir() {
+		return LayerFiles(root)
+	}
+	return []string{root}, nil
+}
+
+// loadRootFiles merges one root's files with the layer rules, overlays
+// included. A lone file goes straight through parseSchemaFile, so a non-.hcl
+// config is accepted.
+func loadRootFiles(files []string, opts LoadOptions) (*Schema, error) {
+	files = selectOverlays(files, opts.Overlay)
+	if len(files) == 1 {
+		return parseSchemaFile(files[0], opts)
+	}
+	return LoadLayersOpts(files, opts)
+}
+
+// mergeRoot folds one root's schema into out. owner maps each object's
+// identity to the root that first declared it, so a duplicate names both.
+func mergeRoot(out, in *Schema, root string, owner map[string]string) error {
+	claim := func(kind, database, name string) error {
+		key := kind + "\x00" + database + "\x00" + name
+		if prev, ok := owner[key]; ok {
+			return fmt.Errorf("%s %q declared in both %s and %s (config roots cannot override each other)",
+				kind, qualifiedObjectName(database, name), prev, root)
+		}
+		owner[key] = root
+		return nil
+	}
+
+	for _, db := range in.Databases {
+		for _, t := range db.Tables {
+			if err := claim(KindTable, db.Name, t.Name); err != nil {
+				return err
+			}
+		}
+		for _, mv := range db.MaterializedViews {
+			if err := claim(KindMaterializedView, db.Name, mv.Name); err != nil {
+				return err
+			}
+		}
+		for _, v := range db.Views {
+			if err := claim(KindView, db.Name, v.Name); err != nil {
+				return err
+			}
+		}
+		for _, d := range db.Dictionaries {
+			if err := claim(KindDictionary, db.Name, d.Name); err != nil {
+				return err
+			}
+		}
+		for _, r := range db.Raws {
+			if err := claim(KindRaw+":"+r.Kind, db.Name, r.Name); err != nil {
+				return err
+			}
+		}
+
+		target := findDatabase(out, db.Name)
+		if target == nil {
+			out.Databases = append(out.Databases, db)
+			continue
+		}
+		if db.Cluster != nil {
+			if target.Cluster != nil && *target.Cluster != *db.Cluster {
+				return fmt.Errorf("database %q: cluster %q in %s conflicts with %q declared elsewhere",
+					db.Name, *db.Cluster, root, *target.Cluster)
+			}
+			target.Cluster = db.Cluster
+		}
+		// Every name was claimed above, so the merge cannot hit a redeclaration.
+		if err := mergeIntoDatabase(target, db); err != nil {
+			return fmt.Errorf("%s: %w", root, err)
+		}
+	}
+
+	for _, nc := range in.NamedCollections {
+		if err := claim(KindNamedCollection, "", nc.Name); err != nil {
+			return err
+		}
+		out.NamedCollections = append(out.NamedCollections, nc)
+	}
+
+	for _, sp := range in.SettingsProfiles {
+		if err := claim(KindSettingsProfile, "", sp.Name); err != nil {
+			return err
+		}
+		out.SettingsProfiles = append(out.SettingsProfiles, sp)
+	}
+
+	for _, fn := range in.Functions {
+		if err := claim(KindFunction, "", fn.Name); err != nil {
+			return err
+		}
+		out.Functions = append(out.Functions, fn)
+	}
+
+	for _, ta := range in.TypeAliases {
+		if err := claim(KindTypeAlias, "", ta.Name); err != nil {
+			return err
+		}
+		out.TypeAliases = append(out.TypeAliases, ta)
+	}
+
+	for _, m := range in.Mixins {
+		if err := claim(KindMixin, "", m.Name); err != nil {
+			return err
+		}
+		out.Mixins = append(out.Mixins, m)
+	}
+
+	if in.DefaultOnCluster != nil {
+		if out.DefaultOnCluster != nil && *out.DefaultOnCluster != *in.DefaultOnCluster {
+			return fmt.Errorf("default_on_cluster is %q in %s but %q in an earlier root (config roots cannot override each other)",
+				*in.DefaultOnCluster, root, *out.DefaultOnCluster)
+		}
+		out.DefaultOnCluster = in.DefaultOnCluster
+	}
+
+	out.Experimental = mergeExperimental(out.Experimental, in.Experimental)
+
+	for _, n := range in.Nodes {
+		replaced := false
+		for i := range out.Nodes {
+			if out.Nodes[i].Name == n.Name {
+				out.Nodes[i] = n // last declaration wins, as across layers
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			out.Nodes = append(out.Nodes, n)
+		}
+	}
+	for _, c := range in.Clusters {
+		replaced := false
+		for i := range out.Clusters {
+			if out.Clusters[i].Name == c.Name {
+				out.Clusters[i] = c
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			out.Clusters = append(out.Clusters, c)
+		}
+	}
+	return nil
+}
+
+// qualifiedObjectName renders db.name, or the bare name for cluster-scoped
+// objects (named collections) that have no database.
+func qualifiedObjectName(database, name string) string {
+	if database == "" {
+		return name
+	}
+	return database + "." + name
+}
@@ -0,0 +1,138 @@
+package hcl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rootDir creates a config root directory holding the given files.
+func rootDir(t *testing.T, parent, name string, files map[string]string) string {
+	t.Helper()
+	dir := filepath.Join(parent, name)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	for file, content := range files {
+		writeHCL(t, dir, file, content)
+	}
+	return dir
+}
+
+func TestLoadRoots_MergesPeerDirectories(t *testing.T) {
+	parent := t.TempDir()
+	core := rootDir(t, parent, "core", map[string]string{"events.hcl": `
+database "posthog" {
+  cluster = "main"
+  table "events" {
+    order_by = ["id"]
+    column "id" { type = "UUID" }
+    engine "merge_tree" {}
+  }
+}`})
+	team := rootDir(t, parent, "team", map[string]string{"flags.hcl": `
+database "posthog" {
+  table "flags" {
+    order_by = ["id"]
+    column "id" { type = "UUID" }
+    engine "merge_tree" {}
+  }
+  patch_table "events" {
+    column "flag" { type = "String" }
+  }
+}`})
+
+	schema, err := LoadRoots([]string{core, team})
+	require.NoError(t, err)
+	require.NoError(t, Resolve(schema))
+
+	require.Len(t, schema.Databases, 1)
+	db := schema.Databases[0]
+	require.NotNil(t, db.Cluster)
+	assert.Equal(t, "main", *db.Cluster)
+	require.Len(t, db.Tables, 2)
+	assert.Equal(t, "events", db.Tables[0].Name)
+	assert.Equal(t, []ColumnSpec{{Name: "id", Type: "UUID"}, {Name: "flag", Type: "String"}}, db.Tables[0].Columns,
+		"a team root may patch a table the core root declares")
+	assert.Equal(t, "flags", db.Tables[1].Name)
+}
+
+func TestLoadRoots_DuplicateAcrossRootsErrors(t *testing.T) {
+	parent := t.TempDir()
+	table := `
+database "posthog" {
+  table "events" {
+    %s
+    column "id" { type = "UUID" }
+    engine "merge_tree" {}
+  }
+}`
+	core := rootDir(t, parent, "core", map[string]string{"events.hcl": fmt.Sprintf(table, "")})
+	team := rootDir(t, parent, "team", map[string]string{"events.hcl": fmt.Sprintf(table, "override = true")})
+
+	_, err := LoadRoots([]string{core, team})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `table "posthog.events" declared in both`)
+	assert.Contains(t, err.Error(), core)
+	assert.Contains(t, err.Error(), team, "override = true does not apply across peer roots")
+}
+
+func TestLoadRoots_DuplicateNamedCollectionErrors(t *testing.T) {
+	parent := t.TempDir()
+	nc := `named_collection "kafka" {
+  param "broker" { value = "b:9092" }
+}`
+	a := rootDir(t, parent, "a", map[string]string{"nc.hcl": nc})
+	b := rootDir(t, parent, "b", map[string]string{"nc.hcl": nc})
+
+	_, err := LoadRoots([]string{a, b})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `named_collection "kafka" declared in both`)
+}
+
+func TestLoadRoots_ConflictingDatabaseClusterErrors(t *testing.T) {
+	parent := t.TempDir()
+	a := rootDir(t, parent, "a", map[string]string{"db.hcl": `database "posthog" { cluster = "one" }`})
+	b := rootDir(t, parent, "b", map[string]string{"db.hcl": `database "posthog" { cluster = "two" }`})
+
+	_, err := LoadRoots([]string{a, b})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "conflicts")
+}
+
+func TestLoadRoots_DefaultOnCluster(t *testing.T) {
+	parent := t.TempDir()
+	a := rootDir(t, parent, "a", map[string]string{"project.hcl": `default_on_cluster = "posthog"`})
+	b := rootDir(t, parent, "b", map[string]string{"db.hcl": `database "posthog" {}`})
+	c := rootDir(t, parent, "c", map[string]string{"project.hcl": `default_on_cluster = "other"`})
+
+	schema, err := LoadRoots([]string{a, b})
+	require.NoError(t, err)
+	assert.Equal(t, ptr("posthog"), schema.DefaultOnCluster)
+
+	_, err = LoadRoots([]string{a, c})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "default_on_cluster")
+}
+
+func TestLoadRoots_SingleFileAnyExtension(t *testing.T) {
+	path := writeHCL(t, t.TempDir(), "node.conf", `
+database "posthog" {
+  table "events" {
+    column "id" { type = "UUID" }
+    engine "merge_tree" {}
+  }
+}`)
+	schema, err := LoadRoots([]string{path})
+	require.NoError(t, err)
+	require.Len(t, schema.Databases, 1)
+	assert.Equal(t, "events", schema.Databases[0].Tables[0].Name)
+}
+
+func TestLoadRoots_MissingRootErrors(t *testing.T) {
+	_, err := LoadRoots([]string{filepath.Join(t.TempDir(), "nope")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "read config")
+}
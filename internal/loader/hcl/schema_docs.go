@@ -0,0 +1,212 @@
+package hcl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RenderMarkdown writes reference documentation for a resolved schema: a
+// Mermaid diagram of DataFlowEdges, then one section per database listing each
+// object with its engine, keys, columns and comments. It documents what the
+// HCL declares, so regenerating it after every schema change keeps the docs
+// and the schema from disagreeing.
+func RenderMarkdown(w io.Writer, s *Schema) error {
+	deps, err := CollectDependencies(s.Databases)
+	if err != nil {
+		return err
+	}
+	edges, err := DataFlowEdges(s.Databases)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# Schema")
+	if len(edges) > 0 {
+		fmt.Fprintln(bw, "\n## Data flow\n\n```mermaid")
+		if err := RenderGraph(bw, s.Databases, edges, GraphMermaid); err != nil {
+			return err
+		}
+		fmt.Fprintln(bw, "```")
+	}
+
+	for i := range s.Databases {
+		db := &s.Databases[i]
+		fmt.Fprintf(bw, "\n## `%s`\n", db.Name)
+		if len(db.Tables) > 0 {
+			fmt.Fprintln(bw, "\n### Tables")
+			for _, t := range db.Tables {
+				writeTableDoc(bw, db.Name, t, deps)
+			}
+		}
+		if len(db.MaterializedViews) > 0 {
+			fmt.Fprintln(bw, "\n### Materialized views")
+			for _, mv := range db.MaterializedViews {
+				writeMVDoc(bw, db.Name, mv, deps)
+			}
+		}
+		if len(db.Views) > 0 {
+			fmt.Fprintln(bw, "\n### Views")
+			for _, v := range db.Views {
+				fmt.Fprintf(bw, "\n#### `%s`\n", v.Name)
+				writeCommentDoc(bw, v.Comment)
+				fmt.Fprintln(bw)
+				writeRefsDoc(bw, "Reads", dependencyTargets(deps, ObjectRef{Database: db.Name, Name: v.Name}, DepViewSource))
+			}
+		}
+		if len(db.Dictionaries) > 0 {
+			fmt.Fprintln(bw, "\n### Dictionaries")
+			for _, d := range db.Dictionaries {
+				writeDictionaryDoc(bw, d)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+func writeTableDoc(w io.Writer, database string, t TableSpec, deps []Dependency) {
+	ref := ObjectRef{Database: database, Name: t.Name}
+	fmt.Fprintf(w, "\n#### `%s`\n", t.Name)
+	writeCommentDoc(w, t.Comment)
+	fmt.Fprintln(w)
+	if e := engineOf(t); e != nil {
+		if clause, _ := engineSQL(e); clause != "" {
+			fmt.Fprintf(w, "- **Engine:** `%s`\n", clause)
+		}
+	}
+	if len(t.OrderBy) > 0 {
+		fmt.Fprintf(w, "- **Order by:** `%s`\n", strings.Join(t.OrderBy, ", "))
+	}
+	if t.PartitionBy != nil {
+		fmt.Fprintf(w, "- **Partition by:** `%s`\n", *t.PartitionBy)
+	}
+	if t.TTL != nil {
+		fmt.Fprintf(w, "- **TTL:** `%s`\n", oneLine(*t.TTL))
+	}
+	writeRefsDoc(w, "Written by", dependents(deps, ref, DepMVDest))
+	writeRefsDoc(w, "Read by", dependents(deps, ref, DepMVSource))
+	for _, d := range deps {
+		if d.From == ref && (d.Kind == DepDistributedRemote || d.Kind == DepBufferDestination) {
+			writeRefsDoc(w, "Forwards to", []ObjectRef{d.To})
+		}
+	}
+
+	if len(t.Columns) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\n| Column | Type | Default | Comment |\n|--------|------|---------|---------|")
+	for _, c := range t.Columns {
+		comment := ""
+		if c.Comment != nil {
+			comment = *c.Comment
+		}
+		if c.Deprecated {
+			comment = strings.TrimSpace("**Deprecated.** " + comment)
+		}
+		fmt.Fprintf(w, "| `%s` | `%s` | %s | %s |\n",
+			c.Name, markdownCell(columnTypeString(c)), columnDefaultDoc(c), markdownCell(comment))
+	}
+}
+
+func writeMVDoc(w io.Writer, database string, mv MaterializedViewSpec, deps []Dependency) {
+	ref := ObjectRef{Database: database, Name: mv.Name}
+	fmt.Fprintf(w, "\n#### `%s`\n", mv.Name)
+	writeCommentDoc(w, mv.Comment)
+	fmt.Fprintln(w)
+	writeRefsDoc(w, "Reads", dependencyTargets(deps, ref, DepMVSource))
+	writeRefsDoc(w, "Writes to", dependencyTargets(deps, ref, DepMVDest))
+	if mv.Refresh != nil {
+		fmt.Fprintln(w, "- **Refreshable**")
+	}
+}
+
+func writeDictionaryDoc(w io.Writer, d DictionarySpec) {
+	fmt.Fprintf(w, "\n#### `%s`\n", d.Name)
+	writeCommentDoc(w, d.Comment)
+	fmt.Fprintln(w)
+	if len(d.PrimaryKey) > 0 {
+		fmt.Fprintf(w, "- **Primary key:** `%s`\n", strings.Join(d.PrimaryKey, ", "))
+	}
+	if d.Source != nil {
+		fmt.Fprintf(w, "- **Source:** %s\n", d.Source.Kind)
+	}
+	if d.Layout != nil {
+		fmt.Fprintf(w, "- **Layout:** %s\n", d.Layout.Kind)
+	}
+	if len(d.Attributes) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\n| Attribute | Type | Default |\n|-----------|------|---------|")
+	for _, a := range d.Attributes {
+		def := ""
+		if a.Default != nil {
+			def = "`" + markdownCell(*a.Default) + "`"
+		}
+		fmt.Fprintf(w, "| `%s` | `%s` | %s |\n", a.Name, markdownCell(a.Type), def)
+	}
+}
+
+func writeCommentDoc(w io.Writer, comment *string) {
+	if comment != nil && *comment != "" {
+		fmt.Fprintf(w, "\n%s\n", *comment)
+	}
+}
+
+func writeRefsDoc(w io.Writer, label string, refs []ObjectRef) {
+	if len(refs) == 0 {
+		return
+	}
+	names := make([]string, len(refs))
+	for i, r := range refs {
+		names[i] = "`" + r.String() + "`"
+	}
+	fmt.Fprintf(w, "- **%s:** %s\n", label, strings.Join(names, ", "))
+}
+
+// columnDefaultDoc renders the column's DEFAULT/MATERIALIZED/EPHEMERAL/ALIAS
+// expression, prefixed by its kind unless it is a plain DEFAULT.
+func columnDefaultDoc(c ColumnSpec) string {
+	switch {
+	case c.Default != nil:
+		return "`" + markdownCell(*c.Default) + "`"
+	case c.Materialized != nil:
+		return "materialized `" + markdownCell(*c.Materialized) + "`"
+	case c.Alias != nil:
+		return "alias `" + markdownCell(*c.Alias) + "`"
+	case c.Ephemeral != nil:
+		return "ephemeral"
+	}
+	return ""
+}
+
+// markdownCell makes s safe inside a table cell: one line, pipes escaped.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(oneLine(s), "|", `\|`)
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// dependents returns the objects whose kind-dependency points at ref.
+func dependents(deps []Dependency, ref ObjectRef, kind string) []ObjectRef {
+	var out []ObjectRef
+	for _, d := range deps {
+		if d.Kind == kind && d.To == ref {
+			out = append(out, d.From)
+		}
+	}
+	return out
+}
+
+// dependencyTargets returns what ref's kind-dependencies point at.
+func dependencyTargets(deps []Dependency, ref ObjectRef, kind string) []ObjectRef {
+	var out []ObjectRef
+	for _, d := range deps {
+		if d.Kind == kind && d.From == ref {
+			out = append(out, d.To)
+		}
+	}
+	return out
+}
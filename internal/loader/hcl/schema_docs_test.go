@@ -0,0 +1,45 @@
+package hcl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	events := mkTable("events", EngineMergeTree{},
+		ColumnSpec{Name: "id", Type: "UInt64", Comment: strPtr("event id")},
+		ColumnSpec{Name: "props", Type: "Map(String, String)", Default: strPtr("map()")},
+		ColumnSpec{Name: "legacy", Type: "String", Nullable: true, Deprecated: true},
+	)
+	events.OrderBy = []string{"id"}
+	events.Comment = strPtr("Raw events.")
+	counts := mkTable("counts", EngineSummingMergeTree{}, ColumnSpec{Name: "id", Type: "UInt64"})
+	s := &Schema{Databases: []DatabaseSpec{
+		mkDBMixed("posthog", []TableSpec{events, counts},
+			[]MaterializedViewSpec{mkMV("counts_mv", "posthog.counts", "SELECT id FROM posthog.events")}),
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderMarkdown(&buf, s))
+	out := buf.String()
+
+	assert.Contains(t, out, "```mermaid\nflowchart LR\n")
+	assert.Contains(t, out, `n0(["posthog.counts_mv"])`)
+	assert.Contains(t, out, `n1["posthog.counts"]`)
+	assert.Contains(t, out, `n2["posthog.events"]`)
+	assert.Contains(t, out, "  n0 --> n1\n  n2 --> n0\n", "edges follow the data: events -> counts_mv -> counts")
+
+	assert.Contains(t, out, "#### `events`\n\nRaw events.\n\n- **Engine:** `MergeTree()`\n- **Order by:** `id`\n- **Read by:** `posthog.counts_mv`\n")
+	assert.Contains(t, out, "| `id` | `UInt64` |  | event id |")
+	assert.Contains(t, out, "| `props` | `Map(String, String)` | `map()` |  |")
+	assert.Contains(t, out, "| `legacy` | `Nullable(String)` |  | **Deprecated.** |")
+	assert.Contains(t, out, "- **Written by:** `posthog.counts_mv`")
+	assert.Contains(t, out, "#### `counts_mv`\n\n- **Reads:** `posthog.events`\n- **Writes to:** `posthog.counts`\n")
+}
+
+func TestMarkdownCell(t *testing.T) {
+	assert.Equal(t, `a \| b c`, markdownCell("a | b\n  c"))
+}
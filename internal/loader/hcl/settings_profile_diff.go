@@ -0,0 +1,140 @@
+package hcl
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SettingsProfileChange describes a planned change to a settings profile.
+type SettingsProfileChange struct {
+	Name string
+
+	// Add is set for a fresh profile; Drop for one that is gone.
+	Add  *SettingsProfileSpec
+	Drop bool
+
+	// In-place changes, applied by one ALTER SETTINGS PROFILE. ClickHouse's
+	// SETTINGS clause replaces the whole element list, so any settings or
+	// inherit change re-sends Target's full list; SetSettings/DropSettings/
+	// InheritChange say what actually differs, for rendering.
+	SetSettings   []SettingsProfileSetting
+	DropSettings  []string
+	InheritChange *StringChange
+	ToChange      *StringChange // rendered TO clause, old/new
+	Target        *SettingsProfileSpec
+
+	// Error is non-empty for an unsupported transition (external↔managed).
+	// sqlgen emits no DDL; the CLI surfaces it.
+	Error string
+}
+
+func (c SettingsProfileChange) IsEmpty() bool {
+	return c.Add == nil && !c.Drop && c.Error == "" && !c.settingsChanged() && c.ToChange == nil
+}
+
+// settingsChanged reports whether the element list (settings or inherit)
+// differs, i.e. whether the ALTER must carry a SETTINGS clause.
+func (c SettingsProfileChange) settingsChanged() bool {
+	return len(c.SetSettings) > 0 || len(c.DropSettings) > 0 || c.InheritChange != nil
+}
+
+// diffSettingsProfiles returns the per-profile changes between two schemas,
+// sorted by name. External-on-both-sides profiles are omitted;
+// external-on-one-side transitions surface as Error entries, as for named
+// collections.
+func diffSettingsProfiles(from, to []SettingsProfileSpec) []SettingsProfileChange {
+	fromIdx := map[string]*SettingsProfileSpec{}
+	for i := range from {
+		fromIdx[from[i].Name] = &from[i]
+	}
+	toIdx := map[string]*SettingsProfileSpec{}
+	for i := range to {
+		toIdx[to[i].Name] = &to[i]
+	}
+	names := map[string]bool{}
+	for n := range fromIdx {
+		names[n] = true
+	}
+	for n := range toIdx {
+		names[n] = true
+	}
+
+	var out []SettingsProfileChange
+	for _, n := range sortedKeys(names) {
+		f, t := fromIdx[n], toIdx[n]
+		switch {
+		case f == nil:
+			if t.External {
+				continue
+			}
+			cp := *t
+			out = append(out, SettingsProfileChange{Name: n, Add: &cp})
+		case t == nil:
+			if f.External {
+				continue
+			}
+			out = append(out, SettingsProfileChange{Name: n, Drop: true})
+		default:
+			if f.External && t.External {
+				continue
+			}
+			if f.External != t.External {
+				out = append(out, SettingsProfileChange{
+					Name:  n,
+					Error: "external↔managed migration not supported; promote/demote manually",
+				})
+				continue
+			}
+			if c := diffOneSettingsProfile(f, t); !c.IsEmpty() {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+func diffOneSettingsProfile(f, t *SettingsProfileSpec) SettingsProfileChange {
+	c := SettingsProfileChange{Name: t.Name}
+
+	fromSettings := map[string]SettingsProfileSetting{}
+	for _, s := range f.Settings {
+		fromSettings[s.Name] = s
+	}
+	toSettings := map[string]bool{}
+	for _, s := range t.Settings {
+		toSettings[s.Name] = true
+		if old, ok := fromSettings[s.Name]; !ok || !reflect.DeepEqual(old, s) {
+			c.SetSettings = append(c.SetSettings, s)
+		}
+	}
+	for _, s := range f.Settings {
+		if !toSettings[s.Name] {
+			c.DropSettings = append(c.DropSettings, s.Name)
+		}
+	}
+	sort.Slice(c.SetSettings, func(i, j int) bool { return c.SetSettings[i].Name < c.SetSettings[j].Name })
+	sort.Strings(c.DropSettings)
+
+	if !reflect.DeepEqual(nonNil(f.Inherit), nonNil(t.Inherit)) {
+		o, n := strings.Join(f.Inherit, ", "), strings.Join(t.Inherit, ", ")
+		c.InheritChange = &StringChange{Old: &o, New: &n}
+	}
+	if o, n := settingsProfileToSQL(*f), settingsProfileToSQL(*t); o != n {
+		c.ToChange = &StringChange{Old: &o, New: &n}
+	}
+	if !c.IsEmpty() {
+		cp := *t
+		c.Target = &cp
+	}
+	return c
+}
+
+// nonNil maps a nil slice to an empty one, so an absent list and an empty
+// one compare equal.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,45 @@
+package hcl
+
+import (
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func writeSettingsProfile(body *hclwrite.Body, p SettingsProfileSpec) {
+	if p.External {
+		body.SetAttributeValue("external", cty.True)
+	}
+	if p.Override {
+		body.SetAttributeValue("override", cty.True)
+	}
+	if p.Cluster != nil {
+		body.SetAttributeValue("cluster", cty.StringVal(*p.Cluster))
+	}
+	if len(p.Inherit) > 0 {
+		body.SetAttributeValue("inherit", stringList(p.Inherit))
+	}
+	if len(p.To) > 0 {
+		body.SetAttributeValue("to", stringList(p.To))
+	}
+	if p.ToAll {
+		body.SetAttributeValue("to_all", cty.True)
+	}
+	if len(p.ToExcept) > 0 {
+		body.SetAttributeValue("to_except", stringList(p.ToExcept))
+	}
+	for _, s := range p.Settings {
+		sb := body.AppendNewBlock("setting", []string{s.Name}).Body()
+		if s.Value != nil {
+			sb.SetAttributeValue("value", cty.StringVal(*s.Value))
+		}
+		if s.Min != nil {
+			sb.SetAttributeValue("min", cty.StringVal(*s.Min))
+		}
+		if s.Max != nil {
+			sb.SetAttributeValue("max", cty.StringVal(*s.Max))
+		}
+		if s.Writability != nil {
+			sb.SetAttributeValue("writability", cty.StringVal(*s.Writability))
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package hcl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// settingsProfileStorageXML is the system.settings_profiles.storage value of
+// a profile defined in the server's users.xml. Such profiles are read-only to
+// SQL, so they are introspected as external declarations.
+const settingsProfileStorageXML = "users_xml"
+
+// IntrospectSettingsProfiles returns every settings profile of the live
+// server, from system.settings_profiles (identity and TO assignment) and
+// system.settings_profile_elements (settings and inherited profiles, in
+// declaration order). Profiles stored in users.xml come back as External
+// with no elements: they cannot be changed through DDL.
+func IntrospectSettingsProfiles(ctx context.Context, conn driver.Conn) ([]SettingsProfileSpec, error) {
+	const pq = `SELECT name, storage, apply_to_all, apply_to_list, apply_to_except
+		FROM system.settings_profiles
+		ORDER BY name`
+	rows, err := conn.Query(ctx, pq)
+	if err != nil {
+		return nil, fmt.Errorf("query system.settings_profiles: %w", err)
+	}
+	profiles, err := processSettingsProfileRows(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	const eq = `SELECT profile_name, setting_name, value, min, max,
+			CAST(writability, 'Nullable(String)'), inherit_profile
+		FROM system.settings_profile_elements
+		WHERE profile_name IS NOT NULL
+		ORDER BY profile_name, index`
+	rows, err = conn.Query(ctx, eq)
+	if err != nil {
+		return nil, fmt.Errorf("query system.settings_profile_elements: %w", err)
+	}
+	defer rows.Close()
+	if err := processSettingsProfileElementRows(profiles, rows); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// processSettingsProfileRows builds one spec per (name, storage,
+// apply_to_all, apply_to_list, apply_to_except) row.
+func processSettingsProfileRows(rows rowScanner) ([]SettingsProfileSpec, error) {
+	var out []SettingsProfileSpec
+	for rows.Next() {
+		var (
+			name, storage  string
+			toAll          uint8
+			toList, except []string
+		)
+		if err := rows.Scan(&name, &storage, &toAll, &toList, &except); err != nil {
+			return nil, fmt.Errorf("scan system.settings_profiles: %w", err)
+		}
+		p := SettingsProfileSpec{Name: name}
+		if storage == settingsProfileStorageXML {
+			p.External = true
+		} else {
+			p.ToAll = toAll != 0
+			p.To = sortedCopy(toList)
+			p.ToExcept = sortedCopy(except)
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return out, nil
+}
+
+// processSettingsProfileElementRows attaches each (profile_name,
+// setting_name, value, min, max, writability, inherit_profile) row to its
+// profile. A row carries either an inherited profile or a setting. Rows of
+// external or unknown profiles are ignored.
+func processSettingsProfileElementRows(profiles []SettingsProfileSpec, rows rowScanner) error {
+	idx := make(map[string]*SettingsProfileSpec, len(profiles))
+	for i := range profiles {
+		if !profiles[i].External {
+			idx[profiles[i].Name] = &profiles[i]
+		}
+	}
+	for rows.Next() {
+		var profile string
+		var setting, value, lo, hi, writability, inherit *string
+		if err := rows.Scan(&profile, &setting, &value, &lo, &hi, &writability, &inherit); err != nil {
+			return fmt.Errorf("scan system.settings_profile_elements: %w", err)
+		}
+		p := idx[profile]
+		if p == nil {
+			continue
+		}
+		if inherit != nil && *inherit != "" {
+			p.Inherit = append(p.Inherit, *inherit)
+			continue
+		}
+		if setting == nil {
+			continue
+		}
+		s := SettingsProfileSetting{Name: *setting, Value: value, Min: lo, Max: hi}
+		if writability != nil {
+			s.Writability = strPtr(strings.ToLower(*writability))
+		}
+		p.Settings = append(p.Settings, s)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+	return nil
+}
+
+// sortedCopy returns a sorted copy of ss, or nil when it is empty.
+func sortedCopy(ss []string) []string {
+	if len(ss) == 0 {
+		return nil
+	}
+	out := append([]string(nil), ss...)
+	sortStrings(out)
+	return out
+}
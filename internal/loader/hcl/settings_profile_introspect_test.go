@@ -0,0 +1,61 @@
+package hcl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// anyRows is a rowScanner over rows of arbitrary column types; Scan assigns
+// each value to the matching destination pointer.
+type anyRows struct {
+	rows [][]any
+	pos  int
+}
+
+func (r *anyRows) Next() bool {
+	r.pos++
+	return r.pos <= len(r.rows)
+}
+
+func (r *anyRows) Scan(dest ...any) error {
+	for i, v := range r.rows[r.pos-1] {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+func (r *anyRows) Err() error { return nil }
+
+func TestProcessSettingsProfileRows(t *testing.T) {
+	var nilStr *string
+	profiles, err := processSettingsProfileRows(&anyRows{rows: [][]any{
+		{"default", "users_xml", uint8(0), []string{}, []string{}},
+		{"everyone", "local_directory", uint8(1), []string{}, []string{"admin"}},
+		{"svc_limits", "local_directory", uint8(0), []string{"query_svc", "ingestion_svc"}, []string{}},
+	}})
+	require.NoError(t, err)
+	require.Len(t, profiles, 3)
+
+	err = processSettingsProfileElementRows(profiles, &anyRows{rows: [][]any{
+		{"default", ptr("max_threads"), ptr("8"), nilStr, nilStr, nilStr, nilStr},
+		{"svc_limits", nilStr, nilStr, nilStr, nilStr, nilStr, ptr("readonly")},
+		{"svc_limits", ptr("max_memory_usage"), ptr("1000"), nilStr, ptr("2000"), nilStr, nilStr},
+		{"svc_limits", ptr("max_execution_time"), ptr("60"), nilStr, nilStr, ptr("CONST"), nilStr},
+	}})
+	require.NoError(t, err)
+
+	assert.Equal(t, SettingsProfileSpec{Name: "default", External: true}, profiles[0])
+	assert.Equal(t, SettingsProfileSpec{Name: "everyone", ToAll: true, ToExcept: []string{"admin"}}, profiles[1])
+	assert.Equal(t, SettingsProfileSpec{
+		Name:    "svc_limits",
+		Inherit: []string{"readonly"},
+		Settings: []SettingsProfileSetting{
+			{Name: "max_memory_usage", Value: ptr("1000"), Max: ptr("2000")},
+			{Name: "max_execution_time", Value: ptr("60"), Writability: ptr("const")},
+		},
+		To: []string{"ingestion_svc", "query_svc"},
+	}, profiles[2])
+}
@@ -0,0 +1,95 @@
+package hcl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/posthog/chschema/test/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCHLive_SettingsProfile_ApplyRoundTrip creates a profile from a spec,
+// introspects it back, alters it to a second spec and checks that each
+// introspected state diffs clean against the spec it was generated from.
+func TestCHLive_SettingsProfile_ApplyRoundTrip(t *testing.T) {
+	if !*clickhouseLive {
+		t.Skip("pass -clickhouse to run against a live ClickHouse")
+	}
+	t.Parallel()
+	conn := testhelpers.RequireClickHouse(t)
+	ctx := context.Background()
+	name := uniqueNCName("hclexp_sp_rt")
+	user := name + "_user"
+	t.Cleanup(func() {
+		_ = conn.Exec(ctx, "DROP SETTINGS PROFILE IF EXISTS "+quoteAccessName(name))
+		_ = conn.Exec(ctx, "DROP USER IF EXISTS "+quoteAccessName(user))
+	})
+	require.NoError(t, conn.Exec(ctx, "CREATE USER "+quoteAccessName(user)+" IDENTIFIED WITH no_password"))
+
+	introspect := func() *Schema {
+		t.Helper()
+		all, err := IntrospectSettingsProfiles(ctx, conn)
+		require.NoError(t, err)
+		s := &Schema{}
+		for _, p := range all {
+			if p.Name == name || p.External {
+				s.SettingsProfiles = append(s.SettingsProfiles, p)
+			}
+		}
+		return s
+	}
+	external := func(s *Schema) []SettingsProfileSpec {
+		var out []SettingsProfileSpec
+		for _, p := range s.SettingsProfiles {
+			if p.External {
+				out = append(out, p)
+			}
+		}
+		return out
+	}
+	apply := func(from, to *Schema) {
+		t.Helper()
+		gen := GenerateSQL(Diff(from, to))
+		require.Empty(t, gen.Unsafe)
+		for _, stmt := range gen.Statements {
+			require.NoError(t, conn.Exec(ctx, stmt), stmt)
+		}
+	}
+
+	live := introspect()
+	first := &Schema{SettingsProfiles: append(external(live), SettingsProfileSpec{
+		Name:    name,
+		Inherit: []string{"readonly"},
+		Settings: []SettingsProfileSetting{
+			{Name: "max_memory_usage", Value: ptr("10000000000"), Max: ptr("20000000000")},
+			{Name: "max_execution_time", Value: ptr("60"), Writability: ptr("const")},
+			{Name: "max_threads", Min: ptr("1"), Max: ptr("16")},
+		},
+		To: []string{user},
+	})}
+	require.NoError(t, Resolve(first))
+	apply(live, first)
+	live = introspect()
+	cs := Diff(live, first)
+	assert.True(t, cs.IsEmpty(), "create round trip drifted: %+v", cs.SettingsProfiles)
+
+	second := &Schema{SettingsProfiles: append(external(live), SettingsProfileSpec{
+		Name: name,
+		Settings: []SettingsProfileSetting{
+			{Name: "max_threads", Value: ptr("8")},
+		},
+		ToAll:    true,
+		ToExcept: []string{user},
+	})}
+	require.NoError(t, Resolve(second))
+	apply(live, second)
+	live = introspect()
+	cs = Diff(live, second)
+	assert.True(t, cs.IsEmpty(), "alter round trip drifted: %+v", cs.SettingsProfiles)
+
+	apply(live, &Schema{SettingsProfiles: external(live)})
+	for _, p := range introspect().SettingsProfiles {
+		assert.NotEqual(t, name, p.Name, "profile should be dropped")
+	}
+}
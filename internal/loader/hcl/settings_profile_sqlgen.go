@@ -0,0 +1,116 @@
+package hcl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// settingsProfileWritability maps the HCL writability values to their DDL
+// keywords.
+var settingsProfileWritability = map[string]string{
+	"const":                  "CONST",
+	"writable":               "WRITABLE",
+	"changeable_in_readonly": "CHANGEABLE_IN_READONLY",
+}
+
+func createSettingsProfileSQL(p SettingsProfileSpec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE SETTINGS PROFILE %s", quoteAccessName(p.Name))
+	if p.Cluster != nil {
+		fmt.Fprintf(&b, " ON CLUSTER %s", *p.Cluster)
+	}
+	if elems := settingsProfileElementsSQL(p); elems != "" {
+		fmt.Fprintf(&b, " SETTINGS %s", elems)
+	}
+	if to := settingsProfileToSQL(p); to != "NONE" {
+		fmt.Fprintf(&b, " TO %s", to)
+	}
+	return b.String()
+}
+
+// alterSettingsProfileSQL renders the in-place change c as one ALTER. The
+// SETTINGS clause replaces the profile's whole element list, so it carries
+// the full target list (or NONE); TO likewise replaces the assignment.
+func alterSettingsProfileSQL(c SettingsProfileChange) string {
+	p := *c.Target
+	var b strings.Builder
+	fmt.Fprintf(&b, "ALTER SETTINGS PROFILE %s", quoteAccessName(p.Name))
+	if p.Cluster != nil {
+		fmt.Fprintf(&b, " ON CLUSTER %s", *p.Cluster)
+	}
+	if c.settingsChanged() {
+		elems := settingsProfileElementsSQL(p)
+		if elems == "" {
+			elems = "NONE"
+		}
+		fmt.Fprintf(&b, " SETTINGS %s", elems)
+	}
+	if c.ToChange != nil {
+		fmt.Fprintf(&b, " TO %s", settingsProfileToSQL(p))
+	}
+	return b.String()
+}
+
+func dropSettingsProfileSQL(name string) string {
+	return fmt.Sprintf("DROP SETTINGS PROFILE %s", quoteAccessName(name))
+}
+
+// settingsProfileElementsSQL renders the SETTINGS element list: inherited
+// profiles first (in order), then each setting with its value, bounds and
+// writability. Empty when the profile has neither.
+func settingsProfileElementsSQL(p SettingsProfileSpec) string {
+	var parts []string
+	for _, inh := range p.Inherit {
+		parts = append(parts, "INHERIT "+quoteString(inh))
+	}
+	for _, s := range p.Settings {
+		e := s.Name
+		if s.Value != nil {
+			e += " = " + formatSettingValue(*s.Value)
+		}
+		if s.Min != nil {
+			e += " MIN " + formatSettingValue(*s.Min)
+		}
+		if s.Max != nil {
+			e += " MAX " + formatSettingValue(*s.Max)
+		}
+		if s.Writability != nil {
+			e += " " + settingsProfileWritability[*s.Writability]
+		}
+		parts = append(parts, e)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// settingsProfileToSQL renders the TO clause body: "ALL [EXCEPT ...]", the
+// listed users/roles, or "NONE". Names are sorted — an assignment is a set —
+// so the rendering doubles as the comparison key for the differ.
+func settingsProfileToSQL(p SettingsProfileSpec) string {
+	names := func(ns []string) string {
+		sorted := append([]string(nil), ns...)
+		sort.Strings(sorted)
+		q := make([]string, len(sorted))
+		for i, n := range sorted {
+			q[i] = quoteAccessName(n)
+		}
+		return strings.Join(q, ", ")
+	}
+	switch {
+	case p.ToAll && len(p.ToExcept) > 0:
+		return "ALL EXCEPT " + names(p.ToExcept)
+	case p.ToAll:
+		return "ALL"
+	case len(p.To) > 0:
+		return names(p.To)
+	default:
+		return "NONE"
+	}
+}
+
+// quoteAccessName backquotes a profile, user or role name. Access entity
+// names are free-form (service accounts often contain '-' or '.'), so they
+// are always quoted rather than emitted bare.
+func quoteAccessName(n string) string {
+	return "`" + strings.ReplaceAll(n, "`", "\\`") + "`"
+}
@@ -0,0 +1,125 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSettingsProfileSQL(t *testing.T) {
+	cluster := "posthog"
+	p := SettingsProfileSpec{
+		Name:    "service-limits",
+		Cluster: &cluster,
+		Inherit: []string{"readonly"},
+		Settings: []SettingsProfileSetting{
+			{Name: "max_memory_usage", Value: ptr("10000000000"), Max: ptr("20000000000")},
+			{Name: "max_execution_time", Value: ptr("60"), Writability: ptr("const")},
+			{Name: "max_threads", Min: ptr("1"), Max: ptr("16")},
+		},
+		To: []string{"query_svc", "ingestion_svc"},
+	}
+	want := "CREATE SETTINGS PROFILE `service-limits` ON CLUSTER posthog SETTINGS " +
+		"INHERIT 'readonly', " +
+		"max_memory_usage = 10000000000 MAX 20000000000, " +
+		"max_execution_time = 60 CONST, " +
+		"max_threads MIN 1 MAX 16 " +
+		"TO `ingestion_svc`, `query_svc`"
+	assert.Equal(t, want, createSettingsProfileSQL(p))
+}
+
+func TestCreateSettingsProfileSQL_ToAllExcept(t *testing.T) {
+	p := SettingsProfileSpec{Name: "p", ToAll: true, ToExcept: []string{"admin"},
+		Settings: []SettingsProfileSetting{{Name: "max_threads", Value: ptr("4")}}}
+	assert.Equal(t, "CREATE SETTINGS PROFILE `p` SETTINGS max_threads = 4 TO ALL EXCEPT `admin`", createSettingsProfileSQL(p))
+}
+
+func TestDropSettingsProfileSQL(t *testing.T) {
+	assert.Equal(t, "DROP SETTINGS PROFILE `p`", dropSettingsProfileSQL("p"))
+}
+
+func TestDiffSettingsProfiles(t *testing.T) {
+	from := []SettingsProfileSpec{
+		{Name: "kept", Settings: []SettingsProfileSetting{
+			{Name: "max_threads", Value: ptr("4")},
+			{Name: "max_execution_time", Value: ptr("60")},
+		}, To: []string{"svc"}},
+		{Name: "gone"},
+		{Name: "readonly", External: true},
+		{Name: "promoted", External: true},
+	}
+	to := []SettingsProfileSpec{
+		{Name: "kept", Settings: []SettingsProfileSetting{
+			{Name: "max_threads", Value: ptr("8")},
+			{Name: "max_memory_usage", Value: ptr("1000")},
+		}, To: []string{"svc"}},
+		{Name: "fresh", To: []string{"svc"}},
+		{Name: "readonly", External: true},
+		{Name: "promoted"},
+	}
+	changes := diffSettingsProfiles(from, to)
+	require.Len(t, changes, 4)
+
+	assert.Equal(t, "fresh", changes[0].Name)
+	require.NotNil(t, changes[0].Add)
+
+	assert.Equal(t, "gone", changes[1].Name)
+	assert.True(t, changes[1].Drop)
+
+	kept := changes[2]
+	assert.Equal(t, "kept", kept.Name)
+	require.Len(t, kept.SetSettings, 2)
+	assert.Equal(t, "max_memory_usage", kept.SetSettings[0].Name)
+	assert.Equal(t, "max_threads", kept.SetSettings[1].Name)
+	assert.Equal(t, []string{"max_execution_time"}, kept.DropSettings)
+	assert.Nil(t, kept.ToChange)
+	require.NotNil(t, kept.Target)
+
+	assert.Equal(t, "promoted", changes[3].Name)
+	assert.Contains(t, changes[3].Error, "external")
+}
+
+func TestDiffSettingsProfiles_SettingOrderIgnored(t *testing.T) {
+	a := SettingsProfileSpec{Name: "p", Settings: []SettingsProfileSetting{
+		{Name: "x", Value: ptr("1")}, {Name: "y", Value: ptr("2")},
+	}, To: []string{"b", "a"}}
+	b := SettingsProfileSpec{Name: "p", Settings: []SettingsProfileSetting{
+		{Name: "y", Value: ptr("2")}, {Name: "x", Value: ptr("1")},
+	}, To: []string{"a", "b"}}
+	assert.Empty(t, diffSettingsProfiles([]SettingsProfileSpec{a}, []SettingsProfileSpec{b}))
+}
+
+func TestGenerateSQL_SettingsProfiles(t *testing.T) {
+	from := &Schema{SettingsProfiles: []SettingsProfileSpec{
+		{Name: "kept", Settings: []SettingsProfileSetting{{Name: "max_threads", Value: ptr("4")}}, To: []string{"svc"}},
+		{Name: "gone"},
+		{Name: "promoted", External: true},
+	}}
+	to := &Schema{SettingsProfiles: []SettingsProfileSpec{
+		{Name: "kept", Inherit: []string{"readonly"}, To: []string{"svc", "svc2"}},
+		{Name: "fresh", Settings: []SettingsProfileSetting{{Name: "max_threads", Value: ptr("2")}}},
+		{Name: "promoted"},
+	}}
+	gen := GenerateSQL(Diff(from, to))
+	assert.Equal(t, []string{
+		"CREATE SETTINGS PROFILE `fresh` SETTINGS max_threads = 2",
+		"ALTER SETTINGS PROFILE `kept` SETTINGS INHERIT 'readonly' TO `svc`, `svc2`",
+		"DROP SETTINGS PROFILE `gone`",
+	}, gen.Statements)
+	require.Len(t, gen.Unsafe, 1)
+	assert.Equal(t, "promoted", gen.Unsafe[0].Table)
+	for _, op := range gen.Ops {
+		assert.Equal(t, KindSettingsProfile, op.ObjectType)
+		assert.Empty(t, op.Database)
+	}
+}
+
+func TestGenerateSQL_SettingsProfileClearsSettings(t *testing.T) {
+	from := &Schema{SettingsProfiles: []SettingsProfileSpec{
+		{Name: "p", Settings: []SettingsProfileSetting{{Name: "max_threads", Value: ptr("4")}}},
+	}}
+	to := &Schema{SettingsProfiles: []SettingsProfileSpec{{Name: "p"}}}
+	gen := GenerateSQL(Diff(from, to))
+	assert.Equal(t, []string{"ALTER SETTINGS PROFILE `p` SETTINGS NONE"}, gen.Statements)
+}
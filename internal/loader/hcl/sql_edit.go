@@ -15,8 +15,10 @@ import (
 //
 //   - CREATE TABLE | MATERIALIZED VIEW | VIEW | DICTIONARY — adds the object, or
 //     replaces an existing object of the same name.
-//   - ALTER TABLE … ADD/DROP/MODIFY/RENAME COLUMN, ADD/DROP INDEX,
-//     MODIFY/REMOVE TTL, MODIFY/RESET SETTING — edits the matching table block.
+//   - CREATE DATABASE — declares the database (a no-op when it exists).
+//   - ALTER TABLE … ADD/DROP/MODIFY/RENAME COLUMN, MODIFY COLUMN … REMOVE TTL,
+//     ADD/DROP INDEX, MODIFY/REMOVE TTL, MODIFY/RESET SETTING — edits the
+//     matching table block.
 //   - ALTER TABLE <mv> MODIFY QUERY … — replaces a materialized view's query.
 //   - DROP TABLE | VIEW | DICTIONARY | DATABASE — removes the object.
 //   - RENAME TABLE a TO b — renames (and moves across databases).
@@ -56,6 +58,8 @@ func applyStatement(schema *Schema, stmt chparser.Expr, defaultDatabase string,
 	case *chparser.CreateTable, *chparser.CreateMaterializedView,
 		*chparser.CreateView, *chparser.CreateDictionary:
 		return applyCreate(schema, stmt, defaultDatabase, allowRaw)
+	case *chparser.CreateDatabase:
+		return applyCreateDatabase(schema, s)
 	case *chparser.AlterTable:
 		return applyAlter(schema, s, defaultDatabase)
 	case *chparser.DropStmt:
@@ -143,7 +147,21 @@ func applyAlterClause(t *TableSpec, clause chparser.AlterTableClause) error {
 		insertColumnAfter(t, col, after)
 	case *chparser.AlterTableModifyColumn:
 		if c.RemovePropertyType != nil {
-			return fmt.Errorf("MODIFY COLUMN %s REMOVE …: not a representable declarative change", identName(c.Column.Name))
+			name := identName(c.Column.Name)
+			// REMOVE TTL is the one property removal the schema language
+			// expresses: the column's ttl attribute becomes unset.
+			if !strings.EqualFold(formatNode(c.RemovePropertyType.PropertyType), "TTL") {
+				return fmt.Errorf("MODIFY COLUMN %s REMOVE …: not a representable declarative change", name)
+			}
+			idx := findColumn(t, name)
+			if idx < 0 {
+				if c.IfExists {
+					return nil
+				}
+				return fmt.Errorf("MODIFY COLUMN %q: no such column", name)
+			}
+			t.Columns[idx].TTL = nil
+			return nil
 		}
 		col := columnFromAST(c.Column)
 		idx := findColumn(t, col.Name)
@@ -220,8 +238,8 @@ func applyAlterClause(t *TableSpec, clause chparser.AlterTableClause) error {
 		}
 		t.Projections = append(t.Projections[:i], t.Projections[i+1:]...)
 	case *chparser.AlterTableModifyTTL:
-		if c.TTL != nil && len(c.TTL.Items) > 0 {
-			t.TTL = strPtr(formatNode(c.TTL.Items[0].Expr))
+		if ttl := ttlClauseString(c.TTL); ttl != "" {
+			t.TTL = strPtr(ttl)
 		}
 	case *chparser.AlterTableRemoveTTL:
 		t.TTL = nil
@@ -277,6 +295,17 @@ func applyDrop(schema *Schema, d *chparser.DropStmt, defaultDatabase string) err
 }
 
 // applyDropDatabase removes a whole database block.
+// applyCreateDatabase declares the database. An existing one is left as it
+// is: CREATE DATABASE carries nothing else the schema models.
+func applyCreateDatabase(schema *Schema, c *chparser.CreateDatabase) error {
+	ident, ok := c.Name.(*chparser.Ident)
+	if !ok || ident.Name == "" {
+		return fmt.Errorf("CREATE DATABASE: unsupported name %v", c.Name)
+	}
+	findOrCreateDatabase(schema, ident.Name)
+	return nil
+}
+
 func applyDropDatabase(schema *Schema, d *chparser.DropDatabase) error {
 	name := ""
 	if d.Name != nil {
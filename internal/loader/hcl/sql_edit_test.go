@@ -159,6 +159,29 @@ func TestApplySQL_AlterTTL(t *testing.T) {
 	assert.Nil(t, s.Databases[0].Tables[0].TTL)
 }
 
+func TestApplySQL_ModifyColumnRemoveTTL(t *testing.T) {
+	s := baseSchema()
+	_, err := ApplySQL(s, `ALTER TABLE db.events MODIFY COLUMN ts DateTime TTL ts + INTERVAL 1 DAY`, "", false)
+	require.NoError(t, err)
+	require.NotNil(t, s.Databases[0].Tables[0].Columns[1].TTL)
+
+	_, err = ApplySQL(s, `ALTER TABLE db.events MODIFY COLUMN ts REMOVE TTL`, "", false)
+	require.NoError(t, err)
+	assert.Nil(t, s.Databases[0].Tables[0].Columns[1].TTL)
+	assert.Equal(t, "DateTime", s.Databases[0].Tables[0].Columns[1].Type, "REMOVE TTL keeps the rest of the column")
+
+	_, err = ApplySQL(s, `ALTER TABLE db.events MODIFY COLUMN ts REMOVE COMMENT`, "", false)
+	require.Error(t, err, "other property removals stay unrepresentable")
+}
+
+func TestApplySQL_AlterTTL_KeepsEveryRule(t *testing.T) {
+	s := baseSchema()
+	_, err := ApplySQL(s, `ALTER TABLE db.events MODIFY TTL ts + INTERVAL 7 DAY TO VOLUME 'cold', ts + INTERVAL 30 DAY DELETE`, "", false)
+	require.NoError(t, err)
+	require.NotNil(t, s.Databases[0].Tables[0].TTL)
+	assert.Equal(t, "ts + INTERVAL 7 DAY TO VOLUME 'cold', ts + INTERVAL 30 DAY DELETE", *s.Databases[0].Tables[0].TTL)
+}
+
 func TestApplySQL_AlterMaterializedViewModifyQuery(t *testing.T) {
 	s := &Schema{Databases: []DatabaseSpec{{
 		Name: "db",
@@ -227,9 +250,9 @@ func TestApplySQL_RejectsDataOperations(t *testing.T) {
 }
 
 func TestApplySQL_AllowRawCapturesUnexpressibleCreate(t *testing.T) {
-	// An inner-engine (non-TO-form) materialized view is parseable but not
-	// expressible in the typed model. Strict mode fails; allow-raw captures it.
-	sql := `CREATE MATERIALIZED VIEW db.mv ENGINE = MergeTree ORDER BY id AS SELECT id FROM db.events`
+	// A dictionary over a source kind the model does not know is parseable
+	// but not expressible. Strict mode fails; allow-raw captures it.
+	sql := `CREATE DICTIONARY db.d (id UInt64) PRIMARY KEY id SOURCE(NOSUCH()) LAYOUT(FLAT()) LIFETIME(0)`
 
 	s := &Schema{}
 	_, err := ApplySQL(s, sql, "", false)
@@ -241,8 +264,8 @@ func TestApplySQL_AllowRawCapturesUnexpressibleCreate(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, s.Databases, 1)
 	require.Len(t, s.Databases[0].Raws, 1)
-	assert.Equal(t, "materialized_view", s.Databases[0].Raws[0].Kind)
-	assert.Equal(t, "mv", s.Databases[0].Raws[0].Name)
+	assert.Equal(t, "dictionary", s.Databases[0].Raws[0].Kind)
+	assert.Equal(t, "d", s.Databases[0].Raws[0].Name)
 }
 
 func TestApplySQL_ParseErrorAborts(t *testing.T) {
@@ -0,0 +1,81 @@
+package hcl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	chparser "github.com/orian/clickhouse-sql-parser/parser"
+)
+
+// sqlFileDatabase is the database unqualified names in a .sql schema file
+// belong to, as they would when the script runs in clickhouse-client.
+const sqlFileDatabase = "default"
+
+// ParseSQLFile reads a .sql file of ClickHouse DDL — the CREATE statements
+// many teams already keep in git — into a schema, so a layer can be SQL while
+// the rest of the stack is HCL and a migration to HCL can be gradual. The
+// statements are applied in order like `hclexp sql2hcl` applies them
+// (ApplySQL): CREATE DATABASE/TABLE/MATERIALIZED VIEW/VIEW/DICTIONARY, plus
+// any ALTER/DROP/RENAME that follows. Unqualified names belong to the default
+// database. A CREATE the model cannot express is an error naming the file,
+// not a raw{} block.
+func ParseSQLFile(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	schema := &Schema{}
+	if _, err := ApplySQL(schema, string(data), sqlFileDatabase, false); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return schema, nil
+}
+
+// parseSchemaFile parses one schema file by its extension: .sql through
+// ParseSQLFile, anything else as HCL.
+func parseSchemaFile(path string, opts LoadOptions) (*Schema, error) {
+	if filepath.Ext(path) == ".sql" {
+		return ParseSQLFile(path)
+	}
+	return ParseFileOpts(path, opts)
+}
+
+// scanSQLDeclarations records the CREATE statements of a .sql schema file as
+// declarations, each at the line its statement starts on, so locate finds
+// objects a SQL layer declares.
+func scanSQLDeclarations(path string) ([]Declaration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	sql := string(data)
+	stmts, err := chparser.NewParser(sql).ParseStmts()
+	if err != nil {
+		return nil, fmt.Errorf("%s: parse SQL: %w", path, err)
+	}
+	var out []Declaration
+	for _, stmt := range stmts {
+		var kind string
+		switch stmt.(type) {
+		case *chparser.CreateTable:
+			kind = KindTable
+		case *chparser.CreateMaterializedView:
+			kind = KindMaterializedView
+		case *chparser.CreateView:
+			kind = KindView
+		case *chparser.CreateDictionary:
+			kind = KindDictionary
+		default:
+			continue
+		}
+		database, name := createTarget(stmt)
+		if database == "" {
+			database = sqlFileDatabase
+		}
+		line := 1 + strings.Count(sql[:min(int(stmt.Pos()), len(sql))], "\n")
+		out = append(out, Declaration{ObjectType: kind, Database: database, Name: name, File: path, Line: line})
+	}
+	return out, nil
+}
@@ -41,14 +41,33 @@ const (
 // materialized_view, view, dictionary, raw).
 const KindNamedCollection = "named_collection"
 
+// KindSettingsProfile is the object_type for settings profiles, which are
+// cluster-scoped like named collections.
+const KindSettingsProfile = "settings_profile"
+
+// KindFunction is the object_type for SQL user-defined functions, also
+// cluster-scoped.
+const KindFunction = "function"
+
+// KindTypeAlias and KindMixin name type_alias and mixin blocks when roots are
+// merged. Both are load-time only and never appear in an Operation.
+const (
+	KindTypeAlias = "type_alias"
+	KindMixin     = "mixin"
+)
+
 // Operation is the typed description of one generated DDL statement.
 type Operation struct {
 	Kind       string // OpCreate | OpAlter | OpDrop | OpRename
-	ObjectType string // table | materialized_view | view | dictionary | named_collection | raw
-	Database   string // empty for named collections (cluster-scoped)
+	ObjectType string // table | materialized_view | view | dictionary | named_collection | settings_profile | raw
+	Database   string // empty for named collections and settings profiles (cluster-scoped)
 	Object     string
 	SQL        string // the statement, without a trailing ';'
 	Manual     bool   // operator-run only (heavy mutation, e.g. MATERIALIZE INDEX); never execute automatically
+
+	// Settings are query-level settings the statement must run with — the
+	// allow_experimental_* flags of the experimental features it uses.
+	Settings map[string]string
 }
 
 // UnsafeChange describes a diff entry that can't be expressed as an ALTER.
@@ -70,6 +89,7 @@ type UnsafeChange struct {
 // etc.) are collected into Unsafe; the generator does not synthesize a
 // recreate-and-swap procedure.
 func GenerateSQL(cs ChangeSet) GeneratedSQL {
+	cs = withDefaultOnCluster(cs)
 	var out GeneratedSQL
 	// emit records one statement and its structured Operation in lockstep, so
 	// Ops[i] always describes Statements[i].
@@ -81,7 +101,14 @@ func GenerateSQL(cs ChangeSet) GeneratedSQL {
 	// a reason that names the field; this is the last line of defence for every
 	// object kind — notably named collections, whose per-param handling still
 	// lets the marker reach an ADD or a one-sided SET (#141).
+	//
+	// Once the project declares its experimental features, a statement using
+	// one it did not enable is dropped the same way, and one using enabled
+	// features carries their settings.
 	emit := func(kind, objType, db, object, sql string) {
+		if cs.IfExists {
+			sql = guardStatement(kind, sql)
+		}
 		if strings.Contains(sql, RedactedValue) {
 			out.Unsafe = append(out.Unsafe, UnsafeChange{
 				Database: db, Table: object,
@@ -89,8 +116,21 @@ func GenerateSQL(cs ChangeSet) GeneratedSQL {
 			})
 			return
 		}
+		var settings map[string]string
+		if cs.Experimental != nil {
+			var missing []string
+			settings, missing = experimentalSettings(sql, cs.Experimental)
+			if len(missing) > 0 {
+				out.Unsafe = append(out.Unsafe, UnsafeChange{
+					Database: db, Table: object,
+					Reason: fmt.Sprintf("statement uses experimental feature(s) %s, which the project does not enable; add them to experimental",
+						strings.Join(missing, ", ")),
+				})
+				return
+			}
+		}
 		out.Statements = append(out.Statements, sql)
-		out.Ops = append(out.Ops, Operation{Kind: kind, ObjectType: objType, Database: db, Object: object, SQL: sql})
+		out.Ops = append(out.Ops, Operation{Kind: kind, ObjectType: objType, Database: db, Object: object, SQL: sql, Settings: settings})
 	}
 	// emitManual records an operator-run statement: kept in the same ordered
 	// stream, but flagged so executors skip it and text output comments it out.
@@ -132,6 +172,30 @@ func GenerateSQL(cs ChangeSet) GeneratedSQL {
 		}
 	}
 
+	// Settings-profile adds. Profiles hold no data and nothing in the schema
+	// references them, so they are created up front with the NCs.
+	for _, spc := range cs.SettingsProfiles {
+		if spc.Error != "" {
+			out.Unsafe = append(out.Unsafe, UnsafeChange{
+				Database: "",
+				Table:    spc.Name,
+				Reason:   "settings profile: " + spc.Error,
+			})
+			continue
+		}
+		if spc.Add != nil {
+			emit(OpCreate, KindSettingsProfile, "", spc.Name, createSettingsProfileSQL(*spc.Add))
+		}
+	}
+
+	// Function adds, before any materialized view or view whose query may
+	// call them.
+	for _, fc := range cs.Functions {
+		if fc.Add != nil {
+			emit(OpCreate, KindFunction, "", fc.Name, createFunctionSQL(*fc.Add))
+		}
+	}
+
 	// Tables, materialized views, views, and dictionaries are emitted in one
 	// dependency-respecting order so a referenced object is always created
 	// before the object that references it (Distributed→remote, MV→source/
@@ -170,9 +234,19 @@ func GenerateSQL(cs ChangeSet) GeneratedSQL {
 			if td.IsUnsafe() {
 				out.Unsafe = append(out.Unsafe, unsafeReasons(dc.Database, td)...)
 			}
-			if stmt := alterTableSQL(dc.Database, td); stmt != "" {
+			if stmt := alterTableSQL(dc.Database, td, cs.IfExists); stmt != "" {
 				emit(OpAlter, KindTable, dc.Database, td.Table, stmt)
 			}
+			// MODIFY COLUMN keeps a TTL the new definition omits, so dropping
+			// a column TTL needs its own REMOVE or the column stays expiring
+			// (and diffs against its own dump forever). It runs as a separate
+			// ALTER after the redefinition: ClickHouse rejects two commands
+			// on the same column within one ALTER.
+			for _, c := range td.ModifyColumns {
+				if !c.IsUnsafe() && c.Old.TTL != nil && c.New.TTL == nil {
+					emit(OpAlter, KindTable, dc.Database, td.Table, removeColumnTTLSQL(dc.Database, td.Table, c.Name))
+				}
+			}
 			// A newly added skip index only covers parts written after the
 			// ALTER; MATERIALIZE INDEX rebuilds it for existing parts. That
 			// mutation is heavy and unpredictable, so it is emitted as a
@@ -190,12 +264,21 @@ func GenerateSQL(cs ChangeSet) GeneratedSQL {
 	for _, dc := range cs.Databases {
 		for _, mvd := range dc.AlterMaterializedViews {
 			if mvd.Recreate {
+				reason := "materialized view to_table or column list change requires recreating the view"
+				switch {
+				case mvd.InnerChange != nil:
+					reason = "materialized view storage (inner engine) change requires recreating the view, which drops the rows it stores"
+				case mvd.ToTableChange == nil && !mvd.ColumnsChanged:
+					reason = "materialized view gaining or losing its refresh schedule, or changing APPEND, requires recreating the view"
+				}
 				out.Unsafe = append(out.Unsafe, UnsafeChange{
-					Database: dc.Database, Table: mvd.Name,
-					Reason: "materialized view to_table or column list change requires recreating the view",
+					Database: dc.Database, Table: mvd.Name, Reason: reason,
 				})
 				continue
 			}
+			if mvd.RefreshChange != nil && mvd.NewRefresh != nil {
+				emit(OpAlter, KindMaterializedView, dc.Database, mvd.Name, modifyRefreshSQL(dc.Database, mvd.Name, *mvd.NewRefresh))
+			}
 			if mvd.QueryChange != nil && mvd.QueryChange.New != nil {
 				emit(OpAlter, KindMaterializedView, dc.Database, mvd.Name, modifyQuerySQL(dc.Database, mvd.Name, *mvd.QueryChange.New))
 			}
@@ -268,6 +351,16 @@ func GenerateSQL(cs ChangeSet) GeneratedSQL {
 			emit(OpAlter, KindNamedCollection, "", ncc.Name, stmt)
 		}
 	}
+	for _, spc := range cs.SettingsProfiles {
+		if spc.Target != nil {
+			emit(OpAlter, KindSettingsProfile, "", spc.Name, alterSettingsProfileSQL(spc))
+		}
+	}
+	for _, fc := range cs.Functions {
+		if fc.Target != nil {
+			emit(OpAlter, KindFunction, "", fc.Name, replaceFunctionSQL(*fc.Target))
+		}
+	}
 
 	for _, dc := range cs.Databases {
 		for _, name := range dc.DropMaterializedViews {
@@ -305,9 +398,108 @@ func GenerateSQL(cs ChangeSet) GeneratedSQL {
 			emit(OpDrop, KindNamedCollection, "", ncc.Name, dropNamedCollectionSQL(ncc.Name))
 		}
 	}
+	for _, spc := range cs.SettingsProfiles {
+		if spc.Drop {
+			emit(OpDrop, KindSettingsProfile, "", spc.Name, dropSettingsProfileSQL(spc.Name))
+		}
+	}
+	// Function drops last: the views that called them are gone by now.
+	for _, fc := range cs.Functions {
+		if fc.Drop {
+			emit(OpDrop, KindFunction, "", fc.Name, dropFunctionSQL(fc.Name))
+		}
+	}
 	return out
 }
 
+// withDefaultOnCluster fills cs.DefaultOnCluster into every object GenerateSQL
+// renders with an ON CLUSTER clause — created tables, views, materialized
+// views, dictionaries and named collections, recreated dictionaries, and
+// created or altered settings profiles and functions — that names no cluster
+// itself. Database-level clusters were already pushed down by Resolve, so they
+// win over the default. The slices of cs are copied, never written through.
+//
+// The default stands in for a missing cluster attribute and nothing more:
+// statements that carry no ON CLUSTER even for an object with its own
+// cluster (table ALTERs, MODIFY QUERY, MODIFY REFRESH, DROPs) gain none from it.
+// Rendering ON CLUSTER there would change the generated DDL for every
+// clustered schema, not just for projects that set a default.
+func withDefaultOnCluster(cs ChangeSet) ChangeSet {
+	if cs.DefaultOnCluster == nil {
+		return cs
+	}
+	fill := func(c **string) {
+		if *c == nil {
+			v := *cs.DefaultOnCluster
+			*c = &v
+		}
+	}
+
+	dbs := make([]DatabaseChange, len(cs.Databases))
+	for i, dc := range cs.Databases {
+		dc.AddTables = append([]TableSpec(nil), dc.AddTables...)
+		for j := range dc.AddTables {
+			fill(&dc.AddTables[j].Cluster)
+		}
+		dc.AddMaterializedViews = append([]MaterializedViewSpec(nil), dc.AddMaterializedViews...)
+		for j := range dc.AddMaterializedViews {
+			fill(&dc.AddMaterializedViews[j].Cluster)
+		}
+		dc.AddViews = append([]ViewSpec(nil), dc.AddViews...)
+		for j := range dc.AddViews {
+			fill(&dc.AddViews[j].Cluster)
+		}
+		dc.AddDictionaries = append([]DictionarySpec(nil), dc.AddDictionaries...)
+		for j := range dc.AddDictionaries {
+			fill(&dc.AddDictionaries[j].Cluster)
+		}
+		dc.AlterDictionaries = append([]DictionaryDiff(nil), dc.AlterDictionaries...)
+		for j := range dc.AlterDictionaries {
+			fill(&dc.AlterDictionaries[j].New.Cluster)
+		}
+		dbs[i] = dc
+	}
+	cs.Databases = dbs
+
+	ncs := make([]NamedCollectionChange, len(cs.NamedCollections))
+	for i, c := range cs.NamedCollections {
+		if c.Add != nil {
+			cp := *c.Add
+			fill(&cp.Cluster)
+			c.Add = &cp
+		}
+		ncs[i] = c
+	}
+	cs.NamedCollections = ncs
+
+	sps := make([]SettingsProfileChange, len(cs.SettingsProfiles))
+	for i, c := range cs.SettingsProfiles {
+		for _, p := range []**SettingsProfileSpec{&c.Add, &c.Target} {
+			if *p != nil {
+				cp := **p
+				fill(&cp.Cluster)
+				*p = &cp
+			}
+		}
+		sps[i] = c
+	}
+	cs.SettingsProfiles = sps
+
+	fns := make([]FunctionChange, len(cs.Functions))
+	for i, c := range cs.Functions {
+		for _, p := range []**FunctionSpec{&c.Add, &c.Target} {
+			if *p != nil {
+				cp := **p
+				fill(&cp.Cluster)
+				*p = &cp
+			}
+		}
+		fns[i] = c
+	}
+	cs.Functions = fns
+	return cs
+}
+
 // dbTable pairs a table with its database, so a flat slice can carry tables
 // drawn from every database in a ChangeSet.
 type dbTable struct {
@@ -576,15 +768,24 @@ func orderTablesByDependency(tables []dbTable, reverse bool) []dbTable {
 }
 
 // createMaterializedViewSQL renders a CREATE MATERIALIZED VIEW in its
-// `TO <table>` form. The column list, when present, is emitted between the
-// destination table and AS — matching ClickHouse's accepted syntax.
+// `TO <table>` form, or with its own storage when Inner is set. The column
+// list, when present, is emitted between the destination table (or before
+// ENGINE) and AS — matching ClickHouse's accepted syntax.
 func createMaterializedViewSQL(database string, mv MaterializedViewSpec) string {
 	var b strings.Builder
 	fmt.Fprintf(&b, "CREATE MATERIALIZED VIEW %s.%s", database, mv.Name)
 	if mv.Cluster != nil {
 		fmt.Fprintf(&b, " ON CLUSTER %s", *mv.Cluster)
 	}
-	fmt.Fprintf(&b, " TO %s", mv.ToTable)
+	if mv.Refresh != nil {
+		fmt.Fprintf(&b, " %s", refreshClauseSQL(*mv.Refresh))
+		if mv.Refresh.Append {
+			b.WriteString(" APPEND")
+		}
+	}
+	if mv.Inner == nil {
+		fmt.Fprintf(&b, " TO %s", mv.ToTable)
+	}
 	if len(mv.Columns) > 0 {
 		parts := make([]string, len(mv.Columns))
 		for i, c := range mv.Columns {
@@ -592,6 +793,9 @@ func createMaterializedViewSQL(database string, mv MaterializedViewSpec) string
 		}
 		fmt.Fprintf(&b, " (%s)", strings.Join(parts, ", "))
 	}
+	if mv.Inner != nil {
+		b.WriteString(storageClauseSQL(mv.Inner.table()))
+	}
 	fmt.Fprintf(&b, " AS %s", mv.Query)
 	// COMMENT comes last, after AS SELECT, per the CREATE MATERIALIZED VIEW grammar.
 	if mv.Comment != nil {
@@ -600,6 +804,52 @@ func createMaterializedViewSQL(database string, mv MaterializedViewSpec) string
 	return b.String()
 }
 
+// table returns the inner storage as a column-less TableSpec, so the table
+// storage renderer applies to it unchanged.
+func (s MVInnerSpec) table() TableSpec {
+	return TableSpec{
+		Engine:      s.Engine,
+		PrimaryKey:  s.PrimaryKey,
+		OrderBy:     s.OrderBy,
+		PartitionBy: s.PartitionBy,
+		SampleBy:    s.SampleBy,
+		TTL:         s.TTL,
+		Settings:    s.Settings,
+	}
+}
+
+// refreshClauseSQL renders a refreshable MV's schedule — REFRESH EVERY|AFTER
+// with its OFFSET, RANDOMIZE FOR, DEPENDS ON and SETTINGS modifiers — in the
+// form shared by CREATE MATERIALIZED VIEW and ALTER TABLE ... MODIFY REFRESH.
+// APPEND is not part of it: it can only be set at CREATE time.
+func refreshClauseSQL(r RefreshSpec) string {
+	var b strings.Builder
+	if r.After != nil {
+		fmt.Fprintf(&b, "REFRESH AFTER %s", *r.After)
+	} else if r.Every != nil {
+		fmt.Fprintf(&b, "REFRESH EVERY %s", *r.Every)
+	}
+	if r.Offset != nil {
+		fmt.Fprintf(&b, " OFFSET %s", *r.Offset)
+	}
+	if r.RandomizeFor != nil {
+		fmt.Fprintf(&b, " RANDOMIZE FOR %s", *r.RandomizeFor)
+	}
+	if len(r.DependsOn) > 0 {
+		fmt.Fprintf(&b, " DEPENDS ON %s", strings.Join(r.DependsOn, ", "))
+	}
+	if len(r.Settings) > 0 {
+		fmt.Fprintf(&b, " SETTINGS %s", formatSettingsList(r.Settings))
+	}
+	return b.String()
+}
+
+// modifyRefreshSQL renders an in-place schedule update for a refreshable
+// materialized view.
+func modifyRefreshSQL(database, name string, r RefreshSpec) string {
+	return fmt.Sprintf("ALTER TABLE %s.%s MODIFY %s", database, name, refreshClauseSQL(r))
+}
+
 // modifyQuerySQL renders an in-place query update for a materialized view
 // or plain view (the syntax is identical: ALTER TABLE ... MODIFY QUERY).
 func modifyQuerySQL(database, name, query string) string {
@@ -719,17 +969,23 @@ func createTableSQL(database string, t TableSpec) string {
 	}
 	b.WriteString(strings.Join(parts, ",\n"))
 	b.WriteString("\n)")
+	b.WriteString(storageClauseSQL(t))
+
+	// COMMENT must come after all storage clauses (per docs).
+	if t.Comment != nil {
+		fmt.Fprintf(&b, " COMMENT %s", quoteString(*t.Comment))
+	}
+	return b.String()
+}
 
+// storageClauseSQL renders " ENGINE = ..." and the storage clauses that
+// follow it (PRIMARY KEY, ORDER BY, PARTITION BY, SAMPLE BY, TTL, SETTINGS),
+// shared by CREATE TABLE and the inner-engine CREATE MATERIALIZED VIEW.
+func storageClauseSQL(t TableSpec) string {
+	var b strings.Builder
 	clause, extraSettings := engineSQL(engineOf(t))
 	fmt.Fprintf(&b, " ENGINE = %s", clause)
 
-	// TimeSeries: emit the SAMPLES/TAGS/METRICS tail clauses between the
-	// ENGINE clause and the storage clauses (which are mostly inapplicable
-	// to TimeSeries anyway — the outer table has no ORDER BY etc.).
-	if ts, ok := engineOf(t).(EngineTimeSeries); ok {
-		b.WriteString(timeSeriesTailSQL(ts))
-	}
-
 	if len(t.PrimaryKey) > 0 {
 		fmt.Fprintf(&b, " PRIMARY KEY (%s)", strings.Join(t.PrimaryKey, ", "))
 	}
@@ -751,9 +1007,12 @@ func createTableSQL(database string, t TableSpec) string {
 		fmt.Fprintf(&b, " SETTINGS %s", formatSettingsList(settings))
 	}
 
-	// COMMENT must come after all storage clauses (per docs).
-	if t.Comment != nil {
-		fmt.Fprintf(&b, " COMMENT %s", quoteString(*t.Comment))
+	// TimeSeries: the SAMPLES/TAGS/METRICS target clauses come last, after
+	// the engine's SETTINGS (ENGINE = TimeSeries SETTINGS ... DATA ... TAGS
+	// ...). The storage clauses before them are inapplicable to TimeSeries
+	// anyway — the outer table has no ORDER BY etc.
+	if ts, ok := engineOf(t).(EngineTimeSeries); ok {
+		b.WriteString(timeSeriesTailSQL(ts))
 	}
 	return b.String()
 }
@@ -793,6 +1052,48 @@ func dropRawSQL(kind, database, name string) string {
 	return fmt.Sprintf("DROP %s IF EXISTS %s.%s", form, database, name)
 }
 
+// guardPrefixes are the statement heads guardStatement extends, each with the
+// guard it takes. Dictionaries are created with CREATE OR REPLACE, which is
+// already safe to re-run.
+var guardPrefixes = []struct{ head, guard string }{
+	{"CREATE TABLE ", "IF NOT EXISTS "},
+	{"CREATE MATERIALIZED VIEW ", "IF NOT EXISTS "},
+	{"CREATE VIEW ", "IF NOT EXISTS "},
+	{"CREATE DICTIONARY ", "IF NOT EXISTS "},
+	{"CREATE NAMED COLLECTION ", "IF NOT EXISTS "},
+	{"CREATE SETTINGS PROFILE ", "IF NOT EXISTS "},
+	{"CREATE FUNCTION ", "IF NOT EXISTS "},
+	{"DROP TABLE ", "IF EXISTS "},
+	{"DROP VIEW ", "IF EXISTS "},
+	{"DROP DICTIONARY ", "IF EXISTS "},
+	{"DROP NAMED COLLECTION ", "IF EXISTS "},
+	{"DROP SETTINGS PROFILE ", "IF EXISTS "},
+	{"DROP FUNCTION ", "IF EXISTS "},
+}
+
+// guardStatement adds IF NOT EXISTS to a CREATE and IF EXISTS to a DROP, so
+// the statement is a no-op when an earlier, interrupted run already got that
+// far. A statement already guarded (raw DDL often is) is returned unchanged,
+// as is any other kind: table ALTERs are guarded per clause by alterTableSQL,
+// and the remaining ALTERs (MODIFY QUERY, MODIFY COMMENT, SET) converge on
+// their own.
+func guardStatement(kind, sql string) string {
+	if kind != OpCreate && kind != OpDrop {
+		return sql
+	}
+	for _, p := range guardPrefixes {
+		rest, ok := strings.CutPrefix(sql, p.head)
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(rest, p.guard) {
+			return sql
+		}
+		return p.head + p.guard + rest
+	}
+	return sql
+}
+
 // projectionClause renders the keyword-less body of one projection (the
 // caller prefixes PROJECTION / ADD PROJECTION, matching indexClause). The
 // query embeds verbatim (canonical form); settings render as the newer
@@ -821,6 +1122,12 @@ func materializeProjectionSQL(database, table, projection string) string {
 	return fmt.Sprintf("ALTER TABLE %s.%s MATERIALIZE PROJECTION %s", database, table, projection)
 }
 
+// removeColumnTTLSQL renders the MODIFY COLUMN ... REMOVE TTL that drops a
+// column's TTL, which a MODIFY COLUMN without one leaves in place.
+func removeColumnTTLSQL(database, table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s.%s MODIFY COLUMN %s REMOVE TTL", database, table, column)
+}
+
 // materializeIndexSQL renders the MATERIALIZE INDEX statement that rebuilds a
 // newly added skip index for existing parts. Always emitted as a manual
 // (operator-run) operation.
@@ -828,16 +1135,22 @@ func materializeIndexSQL(database, table, index string) string {
 	return fmt.Sprintf("ALTER TABLE %s.%s MATERIALIZE INDEX %s", database, table, index)
 }
 
-func alterTableSQL(database string, td TableDiff) string {
+// alterTableSQL renders td as one ALTER TABLE. With guard set, every clause
+// that adds, drops or renames carries IF [NOT] EXISTS (ChangeSet.IfExists).
+func alterTableSQL(database string, td TableDiff, guard bool) string {
+	exists, notExists := "", ""
+	if guard {
+		exists, notExists = "IF EXISTS ", "IF NOT EXISTS "
+	}
 	var ops []string
 	for _, r := range td.RenameColumns {
-		ops = append(ops, fmt.Sprintf("RENAME COLUMN %s TO %s", r.Old, r.New))
+		ops = append(ops, fmt.Sprintf("RENAME COLUMN %s%s TO %s", exists, r.Old, r.New))
 	}
 	for _, c := range td.AddColumns {
-		ops = append(ops, "ADD COLUMN "+columnDefSQL(c))
+		ops = append(ops, "ADD COLUMN "+notExists+columnDefSQL(c))
 	}
 	for _, n := range td.DropColumns {
-		ops = append(ops, fmt.Sprintf("DROP COLUMN %s", n))
+		ops = append(ops, fmt.Sprintf("DROP COLUMN %s%s", exists, n))
 	}
 	for _, c := range td.ModifyColumns {
 		// A storage-class switch (to/from ALIAS/MATERIALIZED/EPHEMERAL) is
@@ -848,16 +1161,16 @@ func alterTableSQL(database string, td TableDiff) string {
 		ops = append(ops, "MODIFY COLUMN "+columnDefSQL(c.New))
 	}
 	for _, n := range td.DropIndexes {
-		ops = append(ops, fmt.Sprintf("DROP INDEX %s", n))
+		ops = append(ops, fmt.Sprintf("DROP INDEX %s%s", exists, n))
 	}
 	for _, idx := range td.AddIndexes {
-		ops = append(ops, fmt.Sprintf("ADD INDEX %s", indexClause(idx)))
+		ops = append(ops, fmt.Sprintf("ADD INDEX %s%s", notExists, indexClause(idx)))
 	}
 	for _, n := range td.DropProjections {
-		ops = append(ops, fmt.Sprintf("DROP PROJECTION %s", n))
+		ops = append(ops, fmt.Sprintf("DROP PROJECTION %s%s", exists, n))
 	}
 	for _, p := range td.AddProjections {
-		ops = append(ops, fmt.Sprintf("ADD PROJECTION %s", projectionClause(p)))
+		ops = append(ops, fmt.Sprintf("ADD PROJECTION %s%s", notExists, projectionClause(p)))
 	}
 	for _, k := range sortedKeys(td.SettingsAdded) {
 		ops = append(ops, fmt.Sprintf("MODIFY SETTING %s = %s", k, formatSettingValue(td.SettingsAdded[k])))
@@ -875,16 +1188,19 @@ func alterTableSQL(database string, td TableDiff) string {
 			ops = append(ops, "REMOVE TTL")
 		}
 	}
+	addConstraint := func(c ConstraintSpec) string {
+		return "ADD CONSTRAINT " + notExists + strings.TrimPrefix(constraintClause(c), "CONSTRAINT ")
+	}
 	for _, n := range td.DropConstraints {
-		ops = append(ops, fmt.Sprintf("DROP CONSTRAINT %s", n))
+		ops = append(ops, fmt.Sprintf("DROP CONSTRAINT %s%s", exists, n))
 	}
 	for _, c := range td.AddConstraints {
-		ops = append(ops, "ADD "+constraintClause(c))
+		ops = append(ops, addConstraint(c))
 	}
 	for _, c := range td.ModifyConstraints {
 		// ClickHouse has no MODIFY CONSTRAINT; drop then re-add.
-		ops = append(ops, fmt.Sprintf("DROP CONSTRAINT %s", c.Name))
-		ops = append(ops, "ADD "+constraintClause(c.New))
+		ops = append(ops, fmt.Sprintf("DROP CONSTRAINT %s%s", exists, c.Name))
+		ops = append(ops, addConstraint(c.New))
 	}
 	if td.CommentChange != nil {
 		comment := ""
@@ -928,8 +1244,8 @@ func columnDefSQL(c ColumnSpec) string {
 		fmt.Fprintf(&sb, " ALIAS %s", *c.Alias)
 	}
 
-	if c.Comment != nil {
-		fmt.Fprintf(&sb, " COMMENT %s", quoteString(*c.Comment))
+	if comment := columnSQLComment(c); comment != nil {
+		fmt.Fprintf(&sb, " COMMENT %s", quoteString(*comment))
 	}
 	if c.Codec != nil {
 		fmt.Fprintf(&sb, " CODEC(%s)", *c.Codec)
@@ -1015,6 +1331,16 @@ func engineSQL(e Engine) (clause string, extraSettings map[string]string) {
 		return "AggregatingMergeTree()", nil
 	case EngineReplicatedAggregatingMergeTree:
 		return fmt.Sprintf("ReplicatedAggregatingMergeTree('%s', '%s')", v.ZooPath, v.ReplicaName), nil
+	case EngineVersionedCollapsingMergeTree:
+		return fmt.Sprintf("VersionedCollapsingMergeTree(%s, %s)", v.SignColumn, v.VersionColumn), nil
+	case EngineReplicatedVersionedCollapsingMergeTree:
+		return fmt.Sprintf("ReplicatedVersionedCollapsingMergeTree('%s', '%s', %s, %s)", v.ZooPath, v.ReplicaName, v.SignColumn, v.VersionColumn), nil
+	case EngineGraphiteMergeTree:
+		// The rollup config section is a string literal, unlike the bare
+		// column identifiers of the collapsing engines.
+		return fmt.Sprintf("GraphiteMergeTree('%s')", v.ConfigSection), nil
+	case EngineReplicatedGraphiteMergeTree:
+		return fmt.Sprintf("ReplicatedGraphiteMergeTree('%s', '%s', '%s')", v.ZooPath, v.ReplicaName, v.ConfigSection), nil
 	case EngineDistributed:
 		if v.ShardingKey != nil {
 			if v.PolicyName != nil {
@@ -1037,6 +1363,29 @@ func engineSQL(e Engine) (clause string, extraSettings map[string]string) {
 		return "Null()", nil
 	case EngineMemory:
 		return "Memory()", nil
+	case EngineSet:
+		return "Set()", nil
+	case EngineUnmanaged:
+		return v.Full, nil
+	case EngineS3:
+		return objectStorageSQL("S3", v.storageArgs()), nil
+	case EngineS3Queue:
+		return objectStorageSQL("S3Queue", v.storageArgs()), nil
+	case EngineURL:
+		// URL and File take the format as a bare identifier.
+		args := []string{quoteString(v.URL)}
+		if v.Format != nil {
+			args = append(args, *v.Format)
+		}
+		if v.Compression != nil {
+			args = append(args, quoteString(*v.Compression))
+		}
+		return fmt.Sprintf("URL(%s)", strings.Join(args, ", ")), nil
+	case EngineFile:
+		if v.Compression != nil {
+			return fmt.Sprintf("File(%s, %s)", v.Format, quoteString(*v.Compression)), nil
+		}
+		return fmt.Sprintf("File(%s)", v.Format), nil
 	case EngineMerge:
 		return fmt.Sprintf("Merge('%s', '%s')", v.DBRegex, v.TableRegex), nil
 	case EngineBuffer:
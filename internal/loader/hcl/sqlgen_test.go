@@ -194,6 +194,32 @@ func TestSQLGen_AlterTTLChange(t *testing.T) {
 	assert.Equal(t, []string{"ALTER TABLE posthog.events REMOVE TTL"}, out.Statements)
 }
 
+// MODIFY COLUMN keeps a column TTL its new definition omits, so removing one
+// emits an explicit REMOVE TTL, in its own ALTER after the redefinition;
+// changing it does not.
+func TestSQLGen_AlterColumnTTL(t *testing.T) {
+	pt := func(s string) *string { return &s }
+	withTTL := ColumnSpec{Name: "payload", Type: "String", TTL: pt("ts + INTERVAL 1 DAY")}
+	plain := ColumnSpec{Name: "payload", Type: "String"}
+
+	td := TableDiff{Table: "events", ModifyColumns: []ColumnChange{{Name: "payload", Old: withTTL, New: plain}}}
+	out := GenerateSQL(ChangeSet{Databases: []DatabaseChange{
+		{Database: "posthog", AlterTables: []TableDiff{td}},
+	}})
+	assert.Equal(t, []string{
+		"ALTER TABLE posthog.events MODIFY COLUMN payload String",
+		"ALTER TABLE posthog.events MODIFY COLUMN payload REMOVE TTL",
+	}, out.Statements)
+
+	longer := withTTL
+	longer.TTL = pt("ts + INTERVAL 7 DAY")
+	td = TableDiff{Table: "events", ModifyColumns: []ColumnChange{{Name: "payload", Old: withTTL, New: longer}}}
+	out = GenerateSQL(ChangeSet{Databases: []DatabaseChange{
+		{Database: "posthog", AlterTables: []TableDiff{td}},
+	}})
+	assert.Equal(t, []string{"ALTER TABLE posthog.events MODIFY COLUMN payload String TTL ts + INTERVAL 7 DAY"}, out.Statements)
+}
+
 func TestSQLGen_AlterIndexes(t *testing.T) {
 	td := TableDiff{
 		Table:       "events",
@@ -308,6 +334,130 @@ func TestSQLGen_CreateMaterializedViewNoColumns(t *testing.T) {
 	}, out.Statements)
 }
 
+func TestSQLGen_DefaultOnCluster(t *testing.T) {
+	engine := &EngineSpec{Kind: "merge_tree", Decoded: EngineMergeTree{}}
+	cols := []ColumnSpec{{Name: "id", Type: "UInt64"}}
+	cs := ChangeSet{
+		DefaultOnCluster: ptr("posthog"),
+		Databases: []DatabaseChange{{
+			Database: "db",
+			AddTables: []TableSpec{
+				{Name: "a", Columns: cols, Engine: engine, OrderBy: []string{"id"}},
+				{Name: "b", Columns: cols, Engine: engine, OrderBy: []string{"id"}, Cluster: ptr("ops")},
+			},
+			AddViews: []ViewSpec{{Name: "v", Query: "SELECT 1"}},
+		}},
+		SettingsProfiles: []SettingsProfileChange{{Name: "p", Add: &SettingsProfileSpec{Name: "p"}}},
+	}
+	out := GenerateSQL(cs)
+	assert.Contains(t, out.Statements, "CREATE TABLE db.a ON CLUSTER posthog (\n  id UInt64\n) ENGINE = MergeTree() ORDER BY (id)")
+	assert.Contains(t, out.Statements, "CREATE TABLE db.b ON CLUSTER ops (\n  id UInt64\n) ENGINE = MergeTree() ORDER BY (id)")
+	assert.Contains(t, out.Statements, "CREATE VIEW db.v ON CLUSTER posthog AS SELECT 1")
+	assert.Contains(t, out.Statements, "CREATE SETTINGS PROFILE `p` ON CLUSTER posthog")
+
+	// The change set itself is left untouched.
+	assert.Nil(t, cs.Databases[0].AddTables[0].Cluster)
+	assert.Nil(t, cs.SettingsProfiles[0].Add.Cluster)
+}
+
+// Statements sqlgen never renders with ON CLUSTER — table ALTERs and DROPs
+// among them — do not gain one from the default, just as they carry none
+// for an object with its own cluster.
+func TestSQLGen_DefaultOnCluster_OnlyWhereClusterIsRendered(t *testing.T) {
+	out := GenerateSQL(ChangeSet{
+		DefaultOnCluster: ptr("posthog"),
+		Databases: []DatabaseChange{{
+			Database:    "db",
+			AlterTables: []TableDiff{{Table: "a", AddColumns: []ColumnSpec{{Name: "ts", Type: "DateTime"}}}},
+			DropTables:  []TableSpec{{Name: "old", Cluster: ptr("ops")}},
+		}},
+	})
+	assert.Equal(t, []string{
+		"ALTER TABLE db.a ADD COLUMN ts DateTime",
+		"DROP TABLE db.old",
+	}, out.Statements)
+}
+
+func TestSQLGen_IfExists(t *testing.T) {
+	engine := &EngineSpec{Kind: "merge_tree", Decoded: EngineMergeTree{}}
+	cols := []ColumnSpec{{Name: "id", Type: "UInt64"}}
+	cs := ChangeSet{
+		IfExists: true,
+		Databases: []DatabaseChange{{
+			Database:   "db",
+			AddTables:  []TableSpec{{Name: "a", Columns: cols, Engine: engine, OrderBy: []string{"id"}}},
+			AddViews:   []ViewSpec{{Name: "v", Query: "SELECT 1"}},
+			AddRaws:    []RawSpec{{Kind: "table", Name: "r", SQL: "CREATE TABLE IF NOT EXISTS db.r (k UInt64) ENGINE = Log\n"}},
+			DropTables: []TableSpec{{Name: "old"}},
+			DropViews:  []string{"old_v"},
+			DropRaws:   []RawSpec{{Kind: "dictionary", Name: "old_d"}},
+		}},
+		NamedCollections: []NamedCollectionChange{{Name: "nc", Add: &NamedCollectionSpec{Name: "nc", Params: []NamedCollectionParam{{Key: "k", Value: "v"}}}}},
+	}
+	out := GenerateSQL(cs)
+	assert.Contains(t, out.Statements, "CREATE TABLE IF NOT EXISTS db.a (\n  id UInt64\n) ENGINE = MergeTree() ORDER BY (id)")
+	assert.Contains(t, out.Statements, "CREATE VIEW IF NOT EXISTS db.v AS SELECT 1")
+	assert.Contains(t, out.Statements, "CREATE TABLE IF NOT EXISTS db.r (k UInt64) ENGINE = Log")
+	assert.Contains(t, out.Statements, "DROP TABLE IF EXISTS db.old")
+	assert.Contains(t, out.Statements, "DROP VIEW IF EXISTS db.old_v")
+	assert.Contains(t, out.Statements, "DROP DICTIONARY IF EXISTS db.old_d")
+	assert.Contains(t, strings.Join(out.Statements, "\n"), "CREATE NAMED COLLECTION IF NOT EXISTS nc AS")
+
+	// The guarded CREATE still parses back to the same table.
+	var createA string
+	for _, op := range out.Ops {
+		if op.Kind == OpCreate && op.Object == "a" {
+			createA = op.SQL
+		}
+	}
+	db := &DatabaseSpec{Name: "db"}
+	require.NoError(t, introspectOneObject(db, "db", "a", createA))
+	require.Len(t, db.Tables, 1)
+	assert.Equal(t, "a", db.Tables[0].Name)
+}
+
+func TestSQLGen_IfExists_AlterClauses(t *testing.T) {
+	td := TableDiff{
+		Table:           "events",
+		RenameColumns:   []RenameColumn{{Old: "a", New: "b"}},
+		AddColumns:      []ColumnSpec{{Name: "new_col", Type: "UInt64"}},
+		DropColumns:     []string{"old_col"},
+		ModifyColumns:   []ColumnChange{{Name: "count", Old: ColumnSpec{Name: "count", Type: "UInt32"}, New: ColumnSpec{Name: "count", Type: "UInt64"}}},
+		DropIndexes:     []string{"idx_old"},
+		AddIndexes:      []IndexSpec{{Name: "idx_new", Expr: "new_col", Type: "minmax", Granularity: 1}},
+		DropConstraints: []string{"c_old"},
+		AddConstraints:  []ConstraintSpec{{Name: "c_new", Check: ptr("new_col > 0")}},
+	}
+	out := GenerateSQL(ChangeSet{IfExists: true, Databases: []DatabaseChange{
+		{Database: "posthog", AlterTables: []TableDiff{td}},
+	}})
+	require.NotEmpty(t, out.Statements)
+	assert.Equal(t, "ALTER TABLE posthog.events RENAME COLUMN IF EXISTS a TO b, ADD COLUMN IF NOT EXISTS new_col UInt64, "+
+		"DROP COLUMN IF EXISTS old_col, MODIFY COLUMN count UInt64, DROP INDEX IF EXISTS idx_old, "+
+		"ADD INDEX IF NOT EXISTS idx_new new_col TYPE minmax GRANULARITY 1, "+
+		"DROP CONSTRAINT IF EXISTS c_old, ADD CONSTRAINT IF NOT EXISTS c_new CHECK new_col > 0", out.Statements[0])
+}
+
+func TestSQLGen_CreateMaterializedViewInnerEngine(t *testing.T) {
+	mv := MaterializedViewSpec{
+		Name:  "metrics_mv",
+		Query: "SELECT id FROM default.src",
+		Inner: &MVInnerSpec{
+			Engine:   &EngineSpec{Kind: "merge_tree", Decoded: EngineMergeTree{}},
+			OrderBy:  []string{"id"},
+			Settings: map[string]string{"index_granularity": "8192"},
+		},
+		Columns: []ColumnSpec{{Name: "id", Type: "UInt64"}},
+	}
+	out := GenerateSQL(ChangeSet{Databases: []DatabaseChange{
+		{Database: "posthog", AddMaterializedViews: []MaterializedViewSpec{mv}},
+	}})
+	assert.Equal(t, []string{
+		"CREATE MATERIALIZED VIEW posthog.metrics_mv (id UInt64) ENGINE = MergeTree() ORDER BY (id) " +
+			"SETTINGS index_granularity = 8192 AS SELECT id FROM default.src",
+	}, out.Statements)
+}
+
 func TestSQLGen_CreateMaterializedViewWithClusterAndComment(t *testing.T) {
 	pt := func(s string) *string { return &s }
 	mv := MaterializedViewSpec{
@@ -1233,6 +1383,26 @@ func TestSQLGen_TimeSeries_External_DefaultsToSamplesKeyword(t *testing.T) {
 	assert.NotContains(t, stmt, "DATA default.m_data")
 }
 
+// ClickHouse reads the engine's SETTINGS before the target clauses; emitting
+// them after the targets produced DDL neither ClickHouse nor the parser accepts.
+func TestSQLGen_TimeSeries_SettingsBeforeTargets(t *testing.T) {
+	tgt := "default.m_data"
+	ts := TableSpec{Name: "m",
+		Engine: &EngineSpec{Kind: "time_series", Decoded: EngineTimeSeries{
+			Samples: &TimeSeriesTarget{Target: &tgt},
+		}},
+		Columns:  []ColumnSpec{{Name: "metric_name", Type: "LowCardinality(String)"}},
+		Settings: map[string]string{"id_generator": "sipHash64(metric_name, all_tags)"},
+	}
+	out := GenerateSQL(ChangeSet{Databases: []DatabaseChange{{
+		Database: "default", AddTables: []TableSpec{ts},
+	}}})
+	stmt := out.Statements[0]
+	assert.Contains(t, stmt, "ENGINE = TimeSeries SETTINGS id_generator = 'sipHash64(metric_name, all_tags)' SAMPLES default.m_data")
+	_, err := parseCreateStatement(stmt)
+	require.NoError(t, err)
+}
+
 func TestSQLGen_TimeSeries_Inner(t *testing.T) {
 	inner := &TimeSeriesInnerTable{
 		Columns: []ColumnSpec{
@@ -1334,7 +1504,13 @@ func TestSQLGen_Null_Memory_Merge(t *testing.T) {
 	}{
 		{EngineNull{}, "ENGINE = Null()"},
 		{EngineMemory{}, "ENGINE = Memory()"},
+		{EngineSet{}, "ENGINE = Set()"},
+		{EngineVersionedCollapsingMergeTree{SignColumn: "sign", VersionColumn: "ver"}, "ENGINE = VersionedCollapsingMergeTree(sign, ver)"},
+		{EngineReplicatedVersionedCollapsingMergeTree{ZooPath: "/zk/t", ReplicaName: "{replica}", SignColumn: "sign", VersionColumn: "ver"}, "ENGINE = ReplicatedVersionedCollapsingMergeTree('/zk/t', '{replica}', sign, ver)"},
+		{EngineGraphiteMergeTree{ConfigSection: "graphite_rollup"}, "ENGINE = GraphiteMergeTree('graphite_rollup')"},
+		{EngineReplicatedGraphiteMergeTree{ZooPath: "/zk/t", ReplicaName: "{replica}", ConfigSection: "graphite_rollup"}, "ENGINE = ReplicatedGraphiteMergeTree('/zk/t', '{replica}', 'graphite_rollup')"},
 		{EngineMerge{DBRegex: "default", TableRegex: "^shard_.*"}, "ENGINE = Merge('default', '^shard_.*')"},
+		{EngineUnmanaged{Name: "EmbeddedRocksDB", Full: "EmbeddedRocksDB(0, '/x')"}, "ENGINE = EmbeddedRocksDB(0, '/x')"},
 	} {
 		ts := TableSpec{Name: "t",
 			Columns: []ColumnSpec{{Name: "id", Type: "UUID"}},
@@ -0,0 +1,238 @@
+package hcl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// Support levels reported by `hclexp support-check`.
+const (
+	// SupportFull: the object introspects into a typed spec whose generated
+	// CREATE reproduces it exactly, so chschema can create, alter and drop it.
+	SupportFull = "supported"
+	// SupportPartial: the object is captured, but not losslessly — as a raw{}
+	// block (verbatim SQL, recreated wholesale on change), with redacted
+	// secrets, or as a typed spec whose regenerated DDL drifts from the live
+	// one.
+	SupportPartial = "partial"
+	// SupportSkipped: the object never takes part in a plan — an unmodelled
+	// table engine the differ ignores, or a name matching -exclude.
+	SupportSkipped = "skipped"
+)
+
+// SupportEntry classifies one object found in system.tables.
+type SupportEntry struct {
+	Database string `json:"database"`
+	Object   string `json:"object"`
+	Kind     string `json:"kind"`   // table | materialized_view | view | dictionary
+	Engine   string `json:"engine"` // system.tables.engine
+	Level    string `json:"level"`  // supported | partial | skipped
+	Reason   string `json:"reason,omitempty"`
+}
+
+// SupportEngineCount tallies the objects of one engine by support level.
+type SupportEngineCount struct {
+	Engine    string `json:"engine"`
+	Supported int    `json:"supported"`
+	Partial   int    `json:"partial"`
+	Skipped   int    `json:"skipped"`
+}
+
+// SupportSummary aggregates a whole support-check run.
+type SupportSummary struct {
+	Objects   int `json:"objects"`
+	Supported int `json:"supported"`
+	Partial   int `json:"partial"`
+	Skipped   int `json:"skipped"`
+}
+
+// SupportReport is the document emitted by `hclexp support-check -format
+// json`. Objects is sorted by database then name; Engines by engine name.
+// Both are non-nil so an empty cluster marshals as [] (not null).
+type SupportReport struct {
+	Objects []SupportEntry       `json:"objects"`
+	Engines []SupportEngineCount `json:"engines"`
+	Summary SupportSummary       `json:"summary"`
+}
+
+// CheckSupport inventories every non-temporary object of databases on a live
+// server and classifies how completely chschema manages each one. An empty
+// databases list checks every database except the system ones.
+func CheckSupport(ctx context.Context, conn driver.Conn, databases []string, exclude *ExcludeMatcher) (SupportReport, error) {
+	if len(databases) == 0 {
+		const dq = `SELECT name FROM system.databases
+			WHERE name NOT IN ('system', 'INFORMATION_SCHEMA', 'information_schema')
+			ORDER BY name`
+		rows, err := conn.Query(ctx, dq)
+		if err != nil {
+			return SupportReport{}, fmt.Errorf("query system.databases: %w", err)
+		}
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				rows.Close()
+				return SupportReport{}, fmt.Errorf("scan system.databases: %w", err)
+			}
+			databases = append(databases, name)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return SupportReport{}, fmt.Errorf("rows iteration error: %w", err)
+		}
+	}
+
+	const q = `SELECT database, name, create_table_query, engine
+		FROM system.tables
+		WHERE database IN ? AND NOT is_temporary
+		ORDER BY database, name`
+	rows, err := conn.Query(ctx, q, databases)
+	if err != nil {
+		return SupportReport{}, fmt.Errorf("query system.tables: %w", err)
+	}
+	defer rows.Close()
+	return processSupportRows(rows, exclude)
+}
+
+// processSupportRows classifies each (database, name, create_table_query,
+// engine) row and builds the report. Split from CheckSupport so the
+// classification can be tested without a live ClickHouse connection.
+func processSupportRows(rows rowScanner, exclude *ExcludeMatcher) (SupportReport, error) {
+	var entries []SupportEntry
+	for rows.Next() {
+		var database, name, createSQL, engine string
+		if err := rows.Scan(&database, &name, &createSQL, &engine); err != nil {
+			return SupportReport{}, fmt.Errorf("scan system.tables: %w", err)
+		}
+		entries = append(entries, classifySupport(database, name, createSQL, engine, exclude))
+	}
+	if err := rows.Err(); err != nil {
+		return SupportReport{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return buildSupportReport(entries), nil
+}
+
+// classifySupport decides the support level of one object. The checks run
+// cheapest first: exclusion, then parsing into a typed spec (the same path
+// introspection takes), then the engine, then redaction, and last a static
+// round trip — generate the CREATE for the spec, introspect that DDL back and
+// diff the two — which catches clauses the parser accepts but the spec drops.
+func classifySupport(database, name, createSQL, engine string, exclude *ExcludeMatcher) SupportEntry {
+	e := SupportEntry{Database: database, Object: name, Kind: rawKindForEngine(engine), Engine: engine}
+	if pattern, ok := exclude.Match(database, name); ok {
+		e.Level, e.Reason = SupportSkipped, fmt.Sprintf("excluded by pattern %q", pattern)
+		return e
+	}
+	if exclude.MatchesObject(e.Kind, database, name) {
+		e.Level, e.Reason = SupportSkipped, fmt.Sprintf("excluded by object_type %q", e.Kind)
+		return e
+	}
+	if isInnerMVTable(name) {
+		e.Level, e.Reason = SupportSkipped, "storage of an inner-engine materialized view; managed through the view"
+		return e
+	}
+
+	live := &DatabaseSpec{Name: database}
+	if err := introspectOneObject(live, database, name, createSQL); err != nil {
+		e.Level = SupportPartial
+		e.Reason = fmt.Sprintf("captured only as raw SQL with -allow-raw: %v", err)
+		return e
+	}
+	if len(live.Tables) == 1 {
+		if u, ok := engineOf(live.Tables[0]).(EngineUnmanaged); ok {
+			e.Level = SupportSkipped
+			e.Reason = fmt.Sprintf("engine %s is not modelled; recorded verbatim but never created, altered or dropped", u.Name)
+			return e
+		}
+	}
+	if strings.Contains(createSQL, RedactedValue) {
+		e.Level = SupportPartial
+		e.Reason = "secrets are redacted as " + RedactedValue + "; re-run with -show-secrets to manage them"
+		return e
+	}
+	if drift := supportRoundTrip(live, name); drift != "" {
+		e.Level, e.Reason = SupportPartial, drift
+		return e
+	}
+	e.Level = SupportFull
+	return e
+}
+
+// supportRoundTrip regenerates the CREATE for the single object in live,
+// introspects it back and reports what the round trip lost, or "" when the
+// regenerated object diffs clean against the live one.
+func supportRoundTrip(live *DatabaseSpec, name string) string {
+	canonicalize(live)
+	want := &Schema{Databases: []DatabaseSpec{*live}}
+	gen := GenerateSQL(Diff(&Schema{}, want))
+
+	got := &DatabaseSpec{Name: live.Name}
+	for _, op := range gen.Ops {
+		if op.Kind != OpCreate || op.Object != name {
+			continue
+		}
+		if err := introspectOneObject(got, live.Name, name, op.SQL); err != nil {
+			return fmt.Sprintf("generated CREATE does not parse back: %v", err)
+		}
+	}
+	canonicalize(got)
+	have := &Schema{Databases: []DatabaseSpec{*got}}
+
+	cs := Diff(want, have)
+	if cs.IsEmpty() {
+		return ""
+	}
+	var fields []string
+	for _, oc := range BuildObjectComparisons(cs, GenerateSQL(cs), want, have) {
+		if oc.Status != StatusAltered {
+			return "generated CREATE does not reproduce the object"
+		}
+		for _, fc := range oc.Changes {
+			fields = append(fields, fc.Field)
+		}
+	}
+	return "generated CREATE does not reproduce: " + strings.Join(fields, ", ")
+}
+
+// buildSupportReport sorts entries and tallies them per engine and overall.
+func buildSupportReport(entries []SupportEntry) SupportReport {
+	r := SupportReport{Objects: []SupportEntry{}, Engines: []SupportEngineCount{}}
+	r.Objects = append(r.Objects, entries...)
+	sort.SliceStable(r.Objects, func(i, j int) bool {
+		a, b := r.Objects[i], r.Objects[j]
+		if a.Database != b.Database {
+			return a.Database < b.Database
+		}
+		return a.Object < b.Object
+	})
+
+	byEngine := map[string]*SupportEngineCount{}
+	for _, e := range r.Objects {
+		c := byEngine[e.Engine]
+		if c == nil {
+			c = &SupportEngineCount{Engine: e.Engine}
+			byEngine[e.Engine] = c
+		}
+		r.Summary.Objects++
+		switch e.Level {
+		case SupportFull:
+			c.Supported++
+			r.Summary.Supported++
+		case SupportPartial:
+			c.Partial++
+			r.Summary.Partial++
+		case SupportSkipped:
+			c.Skipped++
+			r.Summary.Skipped++
+		}
+	}
+	for _, c := range byEngine {
+		r.Engines = append(r.Engines, *c)
+	}
+	sort.Slice(r.Engines, func(i, j int) bool { return r.Engines[i].Engine < r.Engines[j].Engine })
+	return r
+}
@@ -0,0 +1,35 @@
+package hcl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/posthog/chschema/test/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCHLive_CheckSupport(t *testing.T) {
+	if !*clickhouseLive {
+		t.Skip("pass -clickhouse to run against a live ClickHouse")
+	}
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
+	ctx := context.Background()
+
+	for _, ddl := range []string{
+		"CREATE TABLE " + dbName + ".events (id UInt64, ts DateTime) ENGINE = MergeTree ORDER BY id",
+		"CREATE TABLE " + dbName + ".kv (k String, v String) ENGINE = EmbeddedRocksDB PRIMARY KEY k",
+	} {
+		require.NoError(t, conn.Exec(ctx, ddl))
+	}
+
+	r, err := CheckSupport(ctx, conn, []string{dbName}, nil)
+	require.NoError(t, err)
+	levels := map[string]string{}
+	for _, e := range r.Objects {
+		levels[e.Object] = e.Level
+	}
+	assert.Equal(t, map[string]string{"events": SupportFull, "kv": SupportSkipped}, levels)
+	assert.Equal(t, 2, r.Summary.Objects)
+}
@@ -0,0 +1,89 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// supportRows is a rowScanner yielding (database, name, create_table_query,
+// engine) rows, the shape CheckSupport queries.
+type supportRows struct {
+	rows [][4]string
+	pos  int
+}
+
+func (r *supportRows) Next() bool {
+	r.pos++
+	return r.pos <= len(r.rows)
+}
+
+func (r *supportRows) Scan(dest ...any) error {
+	for i, v := range r.rows[r.pos-1] {
+		*dest[i].(*string) = v
+	}
+	return nil
+}
+
+func (r *supportRows) Err() error { return nil }
+
+func TestProcessSupportRows_Classifies(t *testing.T) {
+	rows := &supportRows{rows: [][4]string{
+		{"posthog", "events", "CREATE TABLE posthog.events (`id` UInt64, `ts` DateTime) ENGINE = MergeTree ORDER BY id", "MergeTree"},
+		{"posthog", "kv", "CREATE TABLE posthog.kv (`k` String, `v` String) ENGINE = EmbeddedRocksDB PRIMARY KEY k", "EmbeddedRocksDB"},
+		{"posthog", "broken", "CREATE TABLE posthog.broken (((", "MergeTree"},
+		{"posthog", "tmp_migrate", "CREATE TABLE posthog.tmp_migrate (`id` UInt64) ENGINE = MergeTree ORDER BY id", "MergeTree"},
+		{"posthog", "users_dict", "CREATE DICTIONARY posthog.users_dict (`id` UInt64, `name` String) PRIMARY KEY id SOURCE(MYSQL(HOST 'h' PORT 3306 USER 'u' PASSWORD '[HIDDEN]' DB 'd' TABLE 't')) LIFETIME(MIN 0 MAX 300) LAYOUT(HASHED())", "Dictionary"},
+		{"analytics", "daily", "CREATE VIEW analytics.daily (`id` UInt64) AS SELECT id FROM posthog.events", "View"},
+	}}
+	exclude, err := LoadExcludeConfig(writeTempExclude(t, `exclude { patterns = ["tmp_*"] }`))
+	require.NoError(t, err)
+
+	r, err := processSupportRows(rows, exclude)
+	require.NoError(t, err)
+
+	levels := map[string]string{}
+	reasons := map[string]string{}
+	for _, e := range r.Objects {
+		levels[e.Database+"."+e.Object] = e.Level
+		reasons[e.Database+"."+e.Object] = e.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"posthog.events":      SupportFull,
+		"posthog.kv":          SupportSkipped,
+		"posthog.broken":      SupportPartial,
+		"posthog.tmp_migrate": SupportSkipped,
+		"posthog.users_dict":  SupportPartial,
+		"analytics.daily":     SupportFull,
+	}, levels)
+	assert.Contains(t, reasons["posthog.kv"], "EmbeddedRocksDB is not modelled")
+	assert.Contains(t, reasons["posthog.broken"], "-allow-raw")
+	assert.Contains(t, reasons["posthog.tmp_migrate"], `"tmp_*"`)
+	assert.Contains(t, reasons["posthog.users_dict"], "-show-secrets")
+
+	assert.Equal(t, "analytics", r.Objects[0].Database, "objects sorted by database then name")
+	assert.Equal(t, SupportSummary{Objects: 6, Supported: 2, Partial: 2, Skipped: 2}, r.Summary)
+	assert.Equal(t, []SupportEngineCount{
+		{Engine: "Dictionary", Partial: 1},
+		{Engine: "EmbeddedRocksDB", Skipped: 1},
+		{Engine: "MergeTree", Supported: 1, Partial: 1, Skipped: 1},
+		{Engine: "View", Supported: 1},
+	}, r.Engines)
+}
+
+func TestProcessSupportRows_EmptyMarshalsAsArrays(t *testing.T) {
+	r, err := processSupportRows(&supportRows{}, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, r.Objects)
+	assert.NotNil(t, r.Engines)
+}
+
+// TestSupportRoundTrip_CleanTable pins that a typed spec whose regenerated
+// CREATE reproduces it reports no drift.
+func TestSupportRoundTrip_CleanTable(t *testing.T) {
+	live := &DatabaseSpec{Name: "db"}
+	require.NoError(t, introspectOneObject(live, "db", "t",
+		"CREATE TABLE db.t (`id` UInt64 CODEC(ZSTD(1)), `ts` DateTime TTL ts + toIntervalDay(1)) ENGINE = ReplacingMergeTree(ts) PARTITION BY toYYYYMM(ts) ORDER BY id TTL ts + toIntervalDay(30) SETTINGS index_granularity = 8192 COMMENT 'c'"))
+	assert.Equal(t, "", supportRoundTrip(live, "t"))
+}
@@ -0,0 +1,39 @@
+node "ch-0-0" {
+  macros = {
+    shard   = "0"
+    replica = "ch-0-0"
+  }
+}
+
+cluster "posthog" {
+  shard {
+    replica {
+      host = "ch-0-0"
+      port = 9000
+    }
+    replica {
+      host = "ch-0-1"
+      port = 9000
+    }
+  }
+  shard {
+    weight = 2
+    replica {
+      host = "ch-1-0"
+      port = 9000
+    }
+  }
+}
+
+cluster "posthog_single" {
+  shard {
+    replica {
+      host = "ch-0-0"
+      port = 9000
+    }
+  }
+}
+
+database "posthog" {
+  cluster = "posthog"
+}
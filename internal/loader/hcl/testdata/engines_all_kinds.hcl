@@ -158,6 +158,45 @@ database "posthog" {
     engine "memory" {}
   }
 
+  table "t_versioned_collapsing_merge_tree" {
+    column "id" { type = "UUID" }
+    engine "versioned_collapsing_merge_tree" {
+      sign_column    = "sign"
+      version_column = "ver"
+    }
+  }
+
+  table "t_replicated_versioned_collapsing_merge_tree" {
+    column "id" { type = "UUID" }
+    engine "replicated_versioned_collapsing_merge_tree" {
+      zoo_path       = "/clickhouse/tables/{shard}/t_rvcmt"
+      replica_name   = "{replica}"
+      sign_column    = "sign"
+      version_column = "ver"
+    }
+  }
+
+  table "t_graphite_merge_tree" {
+    column "id" { type = "UUID" }
+    engine "graphite_merge_tree" {
+      config_section = "graphite_rollup"
+    }
+  }
+
+  table "t_replicated_graphite_merge_tree" {
+    column "id" { type = "UUID" }
+    engine "replicated_graphite_merge_tree" {
+      zoo_path       = "/clickhouse/tables/{shard}/t_rgmt"
+      replica_name   = "{replica}"
+      config_section = "graphite_rollup"
+    }
+  }
+
+  table "t_set" {
+    column "id" { type = "UUID" }
+    engine "set" {}
+  }
+
   table "t_merge" {
     column "id" { type = "UUID" }
     engine "merge" {
@@ -166,6 +205,14 @@ database "posthog" {
     }
   }
 
+  table "t_unmanaged" {
+    column "k" { type = "String" }
+    engine "unmanaged" {
+      name = "EmbeddedRocksDB"
+      full = "EmbeddedRocksDB(0, '/var/lib/rocks')"
+    }
+  }
+
   table "t_buffer" {
     column "id" { type = "UUID" }
     engine "buffer" {
@@ -182,6 +229,40 @@ database "posthog" {
     }
   }
 
+  table "t_s3" {
+    column "id" { type = "UUID" }
+    engine "s3" {
+      path              = "https://bucket.s3.amazonaws.com/data/*.parquet"
+      access_key_id     = "AKIAEXAMPLE"
+      secret_access_key = "[HIDDEN]"
+      format            = "Parquet"
+    }
+  }
+
+  table "t_s3_queue" {
+    column "id" { type = "UUID" }
+    engine "s3_queue" {
+      collection = "s3_ingest"
+      overrides  = { format = "JSONEachRow" }
+    }
+  }
+
+  table "t_url" {
+    column "id" { type = "UUID" }
+    engine "url" {
+      url    = "https://example.com/data.csv"
+      format = "CSV"
+    }
+  }
+
+  table "t_file" {
+    column "id" { type = "UUID" }
+    engine "file" {
+      format      = "TabSeparated"
+      compression = "gzip"
+    }
+  }
+
   table "t_time_series" {
     column "metric_name" { type = "LowCardinality(String)" }
     engine "time_series" {
@@ -0,0 +1,8 @@
+function "is_bot" {
+  as = "(ua) -> match(ua, '(?i)bot|crawler|spider')"
+}
+
+function "linear" {
+  cluster = "posthog"
+  as      = "(x, k, b) -> ((k * x) + b)"
+}
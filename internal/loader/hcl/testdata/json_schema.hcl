@@ -0,0 +1,16 @@
+database "posthog" {
+  table "events" {
+    order_by = ["timestamp", "team_id"]
+    settings = { index_granularity = "8192" }
+
+    column "timestamp" { type = "DateTime" }
+    column "team_id" {
+      type  = "UInt64"
+      codec = "ZSTD(1)"
+    }
+
+    engine "replacing_merge_tree" {
+      version_column = "timestamp"
+    }
+  }
+}
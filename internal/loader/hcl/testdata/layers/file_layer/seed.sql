@@ -1,2 +1,2 @@
--- Not an HCL layer: naming this file as a layer entry must fail loudly.
+-- A SQL layer: plain CREATE statements merge like an .hcl layer would.
 CREATE TABLE posthog.seed (id UInt64) ENGINE = MergeTree ORDER BY id;
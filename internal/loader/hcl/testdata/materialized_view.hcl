@@ -7,4 +7,29 @@ database "posthog" {
     column "team_id"  { type = "Int64" }
     column "category" { type = "LowCardinality(String)" }
   }
+
+  materialized_view "daily_rollup_mv" {
+    to_table = "default.daily_rollup"
+    query    = "SELECT team_id, count() AS c FROM default.events GROUP BY team_id"
+    refresh {
+      every         = "1 DAY"
+      offset        = "2 HOUR"
+      randomize_for = "10 MINUTE"
+      depends_on    = ["posthog.app_metrics_mv"]
+      settings      = { refresh_retries = "3" }
+      append        = true
+    }
+  }
+
+  materialized_view "team_counts_mv" {
+    query = "SELECT team_id, count() AS c FROM default.events GROUP BY team_id"
+    inner {
+      engine "summing_merge_tree" {}
+      order_by     = ["team_id"]
+      partition_by = "tuple()"
+      settings     = { index_granularity = "8192" }
+    }
+    column "team_id" { type = "Int64" }
+    column "c"       { type = "UInt64" }
+  }
 }
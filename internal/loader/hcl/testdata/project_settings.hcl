@@ -0,0 +1,11 @@
+default_on_cluster = "posthog"
+experimental       = ["json_type"]
+
+database "posthog" {
+  table "events" {
+    column "id" { type = "UInt64" }
+    column "props" { type = "JSON" }
+    engine "merge_tree" {}
+    order_by = ["id"]
+  }
+}
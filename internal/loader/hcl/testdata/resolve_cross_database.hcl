@@ -0,0 +1,28 @@
+database "posthog" {
+  table "_team_base" {
+    abstract = true
+    column "team_id" { type = "UInt64" }
+    settings = { index_granularity = "8192" }
+  }
+
+  table "_event_base" {
+    abstract = true
+    extend   = "_team_base"
+    column "timestamp" { type = "DateTime" }
+  }
+
+  table "events" {
+    extend = "_event_base"
+    engine "merge_tree" {}
+    order_by = ["team_id", "timestamp"]
+  }
+}
+
+database "analytics" {
+  table "sessions" {
+    extend = "posthog._event_base"
+    column "session_id" { type = "UUID" }
+    engine "merge_tree" {}
+    order_by = ["team_id", "session_id"]
+  }
+}
@@ -0,0 +1,14 @@
+database "posthog" {
+  table "events" {
+    column "team_id" { type = "UInt64" }
+    engine "merge_tree" {}
+    order_by = ["team_id"]
+  }
+}
+
+database "analytics" {
+  table "events_copy" {
+    extend = "posthog.events"
+    engine "merge_tree" {}
+  }
+}
@@ -0,0 +1,37 @@
+mixin "timestamps" {
+  column "created_at" {
+    type    = "DateTime64(3)"
+    default = "now64(3)"
+  }
+  column "updated_at" {
+    type    = "DateTime64(3)"
+    default = "now64(3)"
+  }
+}
+
+mixin "ingest" {
+  column "_timestamp" {
+    type  = "DateTime"
+    codec = "DoubleDelta, ZSTD"
+  }
+}
+
+database "app" {
+  table "_base" {
+    abstract = true
+    mixins   = ["ingest"]
+    column "team_id" { type = "UInt64" }
+  }
+
+  table "users" {
+    extend = "_base"
+    mixins = ["timestamps"]
+    column "id" { type = "UInt64" }
+    engine "merge_tree" {}
+    order_by = ["team_id", "id"]
+  }
+
+  patch_table "users" {
+    drop_columns = ["updated_at"]
+  }
+}
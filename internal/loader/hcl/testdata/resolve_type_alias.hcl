@@ -0,0 +1,33 @@
+type_alias "Money" {
+  type = "Decimal(18, 4)"
+}
+
+type_alias "ID" {
+  type  = "UInt64"
+  codec = "Delta, ZSTD"
+}
+
+database "billing" {
+  table "_base" {
+    abstract = true
+    column "id" { type = "ID" }
+  }
+
+  table "invoices" {
+    extend = "_base"
+    column "amount" {
+      type     = "Money"
+      nullable = true
+    }
+    column "customer_id" {
+      type  = "ID"
+      codec = "ZSTD(3)"
+    }
+    engine "merge_tree" {}
+    order_by = ["id"]
+  }
+
+  patch_table "invoices" {
+    column "refunded" { type = "Money" }
+  }
+}
@@ -0,0 +1,28 @@
+settings_profile "readonly" {
+  external = true
+}
+
+settings_profile "service_limits" {
+  cluster = "posthog"
+  inherit = ["readonly"]
+  to      = ["ingestion_svc", "query_svc"]
+
+  setting "max_memory_usage" {
+    value = "10000000000"
+    max   = "20000000000"
+  }
+  setting "max_execution_time" {
+    value       = "60"
+    writability = "const"
+  }
+}
+
+settings_profile "everyone_but_admin" {
+  to_all    = true
+  to_except = ["admin"]
+
+  setting "max_threads" {
+    min = "1"
+    max = "16"
+  }
+}
@@ -0,0 +1,57 @@
+package hcl
+
+import "fmt"
+
+// expandTypeAliases validates the schema's type_alias blocks and rewrites
+// every table and materialized-view column whose type names one.
+// It runs after patches, so columns a patch_table adds or modifies expand
+// too, and before extend, so children inherit expanded columns.
+//
+// Only a whole type matches: "Money" expands, "Nullable(Money)" does not (use
+// nullable = true). An alias cannot name another alias, which keeps the
+// expansion a single lookup.
+func expandTypeAliases(s *Schema) error {
+	if len(s.TypeAliases) == 0 {
+		return nil
+	}
+	aliases := make(map[string]*TypeAliasSpec, len(s.TypeAliases))
+	for i := range s.TypeAliases {
+		ta := &s.TypeAliases[i]
+		if _, dup := aliases[ta.Name]; dup {
+			return fmt.Errorf("type_alias %q: duplicate", ta.Name)
+		}
+		if ta.Type == "" {
+			return fmt.Errorf("type_alias %q: type is empty", ta.Name)
+		}
+		aliases[ta.Name] = ta
+	}
+	for _, ta := range s.TypeAliases {
+		if _, ok := aliases[ta.Type]; ok {
+			return fmt.Errorf("type_alias %q: type %q is itself an alias (aliases cannot chain)", ta.Name, ta.Type)
+		}
+	}
+
+	expand := func(cols []ColumnSpec) {
+		for i := range cols {
+			ta, ok := aliases[cols[i].Type]
+			if !ok {
+				continue
+			}
+			cols[i].Type = ta.Type
+			if cols[i].Codec == nil && ta.Codec != nil {
+				codec := *ta.Codec
+				cols[i].Codec = &codec
+			}
+		}
+	}
+	for di := range s.Databases {
+		db := &s.Databases[di]
+		for ti := range db.Tables {
+			expand(db.Tables[ti].Columns)
+		}
+		for mi := range db.MaterializedViews {
+			expand(db.MaterializedViews[mi].Columns)
+		}
+	}
+	return nil
+}
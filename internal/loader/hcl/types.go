@@ -75,9 +75,10 @@ func normalizeRawSQL(s string) string {
 
 // MaterializedViewSpec models a ClickHouse materialized view in its
 // `TO <table>` form: the MV reads from its source (referenced in Query) and
-// writes rows into an existing destination table. Inner-engine MVs
-// (ENGINE = ...), refreshable MVs (REFRESH ...), and window views are not
-// supported and are rejected with a clear error during introspection.
+// writes rows into an existing destination table. A refreshable MV
+// (REFRESH EVERY|AFTER ...) carries its schedule in Refresh. Inner-engine MVs
+// (ENGINE = ...) and window views are not supported and are rejected with a
+// clear error during introspection.
 type MaterializedViewSpec struct {
 	Name string `hcl:"name,label"`
 
@@ -88,18 +89,50 @@ type MaterializedViewSpec struct {
 	Extend   *string `hcl:"extend,optional"   diff:"-"`
 	Abstract bool    `hcl:"abstract,optional" diff:"-"`
 
-	ToTable string       `hcl:"to_table,optional"` // TO <db.>table target (required when not abstract)
+	ToTable string       `hcl:"to_table,optional"` // TO <db.>table target (exactly one of to_table and inner when not abstract)
 	Columns []ColumnSpec `hcl:"column,block"`      // explicit column list (may be empty; merged with parent on extend)
 	Query   string       `hcl:"query,optional"`    // the AS SELECT ... body (required when not abstract)
 	Cluster *string      `hcl:"cluster,optional"`  // ON CLUSTER
 	Comment *string      `hcl:"comment,optional"`
+	Refresh *RefreshSpec `hcl:"refresh,block"` // nil for an ordinary (insert-triggered) MV
+	Inner   *MVInnerSpec `hcl:"inner,block"`   // storage of an MV without TO; nil for the TO form
+}
+
+// MVInnerSpec is the storage of a materialized view created without TO:
+// ClickHouse keeps the rows in a hidden `.inner.<mv>` (or `.inner_id.<uuid>`)
+// table built from this engine and the view's column list. The hidden table
+// is never declared on its own — it lives and dies with its view.
+type MVInnerSpec struct {
+	Engine      *EngineSpec       `hcl:"engine,block"`
+	PrimaryKey  []string          `hcl:"primary_key,optional"`
+	OrderBy     []string          `hcl:"order_by,optional"`
+	PartitionBy *string           `hcl:"partition_by,optional"`
+	SampleBy    *string           `hcl:"sample_by,optional"`
+	TTL         *string           `hcl:"ttl,optional"`
+	Settings    map[string]string `hcl:"settings,optional"`
+}
+
+// RefreshSpec is the schedule of a refreshable materialized view (ClickHouse
+// 23.12+), which re-runs its query periodically instead of on each insert.
+// Exactly one of Every (fixed wall-clock cadence) and After (delay since the
+// previous refresh) is set; intervals are written as ClickHouse prints them,
+// e.g. "1 DAY" or "30 MINUTE". Offset is only valid with Every. Append keeps
+// old rows in the target table instead of replacing them on each refresh.
+type RefreshSpec struct {
+	Every        *string           `hcl:"every,optional"`
+	After        *string           `hcl:"after,optional"`
+	Offset       *string           `hcl:"offset,optional"`
+	RandomizeFor *string           `hcl:"randomize_for,optional"`
+	DependsOn    []string          `hcl:"depends_on,optional"` // other refreshable MVs, as db.name
+	Settings     map[string]string `hcl:"settings,optional"`   // refresh settings (refresh_retries, ...)
+	Append       bool              `hcl:"append,optional"`
 }
 
 // ViewSpec models a ClickHouse plain (non-materialized) view: a saved
 // SELECT executed on every read of the view. The Query is stored
-// verbatim as text. Live views, refreshable materialized views, and
-// window views are unsupported and rejected with a clear error during
-// introspection (they parse into different AST types).
+// verbatim as text. Live views and window views are unsupported and
+// rejected with a clear error during introspection (they parse into
+// different AST types).
 //
 // SQLSecurity is the canonical lowercase form of the SQL SECURITY
 // clause: one of "definer", "invoker", or "none". Definer is the user
@@ -176,6 +209,11 @@ type TableSpec struct {
 	Abstract bool    `hcl:"abstract,optional" diff:"-"`
 	Override bool    `hcl:"override,optional" diff:"-"`
 
+	// Mixins names top-level mixin blocks whose columns the table takes,
+	// appended after its own in list order. Unlike extend a table may use
+	// several. Consumed during resolution.
+	Mixins []string `hcl:"mixins,optional" diff:"-"`
+
 	PrimaryKey  []string          `hcl:"primary_key,optional"`
 	OrderBy     []string          `hcl:"order_by,optional"`
 	PartitionBy *string           `hcl:"partition_by,optional"`
@@ -232,6 +270,12 @@ type ColumnSpec struct {
 	// schema being compared.
 	RenamedFrom *string `hcl:"renamed_from,optional" diff:"-"`
 
+	// Deprecated marks the column for removal: the first step of dropping it.
+	// It is stored on the live column as a DeprecatedColumnMarker prefix on
+	// its COMMENT, so introspection reads it back, and a later plan that
+	// drops the column can tell whether readers were given that warning.
+	Deprecated bool `hcl:"deprecated,optional"`
+
 	// After / First position a patch_table column add: the new column is
 	// inserted immediately after the named column (or at the front) instead
 	// of appended, so an env's mid-table extras can interleave without
@@ -343,12 +387,38 @@ type DictionaryLayoutSpec struct {
 
 type DictionaryLayout interface{ Kind() string }
 
-// Schema is what ParseFile returns. It carries both top-level kinds
-// hclexp tracks: databases (with their tables/MVs/dictionaries) and
-// named collections (cluster-scoped, separate from any database).
+// Schema is what ParseFile returns. It carries every top-level kind
+// hclexp tracks: databases (with their tables/MVs/dictionaries), and the
+// cluster-scoped named collections and settings profiles.
 type Schema struct {
+	// DefaultOnCluster is the project-wide ON CLUSTER target. sqlgen applies
+	// it to every statement whose object sets no cluster of its own (tables
+	// and materialized views inherit their database's first), so a whole
+	// config root can target one cluster without annotating each object.
+	// Diff ignores it.
+	DefaultOnCluster *string
+
+	// Experimental lists the opt-in ClickHouse features (json_type,
+	// refreshable_mvs, time_series_table) the project may use. nil means
+	// undeclared: DDL is generated as-is. Once declared, sqlgen gates every
+	// statement on it — a statement using an unlisted feature is reported
+	// unsafe, one using a listed feature carries its allow_experimental_*
+	// setting.
+	Experimental []string
+
 	Databases        []DatabaseSpec
 	NamedCollections []NamedCollectionSpec
+	SettingsProfiles []SettingsProfileSpec
+	Functions        []FunctionSpec
+
+	// TypeAliases are the project's named column types (type_alias blocks).
+	// Resolve expands every column whose type names one, so nothing past
+	// resolution — diff, sqlgen, dump — ever sees an alias.
+	TypeAliases []TypeAliasSpec
+
+	// Mixins are the project's reusable column sets (mixin blocks), merged
+	// into the tables that list them by Resolve.
+	Mixins []MixinSpec
 
 	// Nodes carries per-node identity captured at introspection time:
 	// the node hostname (label) and its ClickHouse macros (shard,
@@ -356,6 +426,12 @@ type Schema struct {
 	// — Diff() ignores it — and exists so multi-node drift analysis can
 	// group nodes by their authoritative macros rather than by filename.
 	Nodes []NodeSpec
+
+	// Clusters carries the topology of the clusters captured at
+	// introspection time (system.clusters). Like Nodes it is metadata only
+	// — Diff() ignores it — recording which hosts an ON CLUSTER statement
+	// reaches.
+	Clusters []ClusterSpec
 }
 
 // NodeSpec records the identity of a single physical ClickHouse node,
@@ -366,6 +442,35 @@ type NodeSpec struct {
 	Macros map[string]string `hcl:"macros,optional"`
 }
 
+// ClusterSpec records the topology of a ClickHouse cluster as the server's
+// remote_servers config defines it: its shards in shard_num order, each with
+// its replicas in replica_num order.
+//
+//	cluster "posthog" {
+//	  shard {
+//	    replica { host = "ch-0-0"  port = 9000 }
+//	    replica { host = "ch-0-1"  port = 9000 }
+//	  }
+//	}
+type ClusterSpec struct {
+	Name   string         `hcl:"name,label"`
+	Shards []ClusterShard `hcl:"shard,block"`
+}
+
+// ClusterShard is one shard of a cluster. Weight is nil for the default
+// weight of 1.
+type ClusterShard struct {
+	Weight   *int             `hcl:"weight,optional"`
+	Replicas []ClusterReplica `hcl:"replica,block"`
+}
+
+// ClusterReplica is one replica of a shard: the host as system.clusters
+// reports it (host_name) and its native-protocol port.
+type ClusterReplica struct {
+	Host string `hcl:"host"`
+	Port int    `hcl:"port"`
+}
+
 // NamedCollectionSpec models a ClickHouse named collection — a
 // cluster-scoped key/value bag of configuration values that other
 // objects (most notably Kafka tables) can reference by name.
@@ -391,3 +496,85 @@ type NamedCollectionParam struct {
 	Value       string `hcl:"value"`
 	Overridable *bool  `hcl:"overridable,optional"`
 }
+
+// SettingsProfileSpec models a ClickHouse settings profile — a named set of
+// setting values and constraints (query limits and the like) applied to the
+// users and roles listed in To. Settings is compared by setting name;
+// Inherit order is significant (later profiles override earlier ones).
+//
+// External = true marks a profile defined outside hclexp (users.xml, e.g.
+// the built-in "default" and "readonly"): it is declared only so Inherit
+// references resolve, and no DDL is ever emitted for it. Cluster is the ON
+// CLUSTER target for generated DDL; ClickHouse does not expose it, so it is
+// never introspected nor compared.
+type SettingsProfileSpec struct {
+	Name     string                   `hcl:"name,label"`
+	External bool                     `hcl:"external,optional"`
+	Override bool                     `hcl:"override,optional" diff:"-"`
+	Cluster  *string                  `hcl:"cluster,optional"`
+	Inherit  []string                 `hcl:"inherit,optional"`
+	Settings []SettingsProfileSetting `hcl:"setting,block"`
+	To       []string                 `hcl:"to,optional"`        // users/roles the profile applies to
+	ToAll    bool                     `hcl:"to_all,optional"`    // TO ALL [EXCEPT to_except]
+	ToExcept []string                 `hcl:"to_except,optional"` // only with to_all
+}
+
+// FunctionSpec models a ClickHouse SQL user-defined function — a named
+// lambda that materialized views and other queries call like a builtin:
+//
+//	function "linear" { as = "(x, k, b) -> k * x + b" }
+//
+// As is the lambda, kept in the parser's canonical form so a hand-written
+// body and an introspected one compare equal. Cluster is the ON CLUSTER
+// target for generated DDL; ClickHouse does not expose it, so it is never
+// introspected nor compared.
+type FunctionSpec struct {
+	Name     string  `hcl:"name,label"`
+	Override bool    `hcl:"override,optional" diff:"-"`
+	Cluster  *string `hcl:"cluster,optional"`
+	As       string  `hcl:"as"`
+}
+
+// TypeAliasSpec names a column type, optionally with the codec that goes
+// with it, so a project spells e.g. its money type once:
+//
+//	type_alias "Money" { type = "Decimal(18, 4)" }
+//	type_alias "ID"    { type = "UInt64"  codec = "Delta, ZSTD" }
+//
+// A column whose type is exactly an alias name takes the alias's type, and
+// its codec unless the column sets its own. Override = true lets a later
+// layer redefine an alias, like named_collection.
+type TypeAliasSpec struct {
+	Name     string  `hcl:"name,label"`
+	Type     string  `hcl:"type"`
+	Codec    *string `hcl:"codec,optional"`
+	Override bool    `hcl:"override,optional"`
+}
+
+// MixinSpec is a named set of columns — with their defaults, codecs and
+// comments — that tables pull in with mixins = ["name"], so boilerplate such
+// as created_at/updated_at is declared once:
+//
+//	mixin "timestamps" {
+//	  column "created_at" { type = "DateTime64(3)"  default = "now64(3)" }
+//	  column "updated_at" { type = "DateTime64(3)"  default = "now64(3)" }
+//	}
+//
+// Override = true lets a later layer redefine a mixin.
+type MixinSpec struct {
+	Name     string       `hcl:"name,label"`
+	Override bool         `hcl:"override,optional"`
+	Columns  []ColumnSpec `hcl:"column,block"`
+}
+
+// SettingsProfileSetting is one setting of a profile: an optional value plus
+// optional MIN/MAX bounds and writability constraint ("const", "writable" or
+// "changeable_in_readonly"). Values are kept as the strings ClickHouse
+// reports in system.settings_profile_elements.
+type SettingsProfileSetting struct {
+	Name        string  `hcl:"name,label"`
+	Value       *string `hcl:"value,optional"`
+	Min         *string `hcl:"min,optional"`
+	Max         *string `hcl:"max,optional"`
+	Writability *string `hcl:"writability,optional"`
+}
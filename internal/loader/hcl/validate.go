@@ -126,11 +126,15 @@ func CollectDependencies(dbs []DatabaseSpec) ([]Dependency, error) {
 		for _, mv := range db.MaterializedViews {
 			from := ObjectRef{Database: db.Name, Name: mv.Name}
 
-			deps = append(deps, Dependency{
-				From: from,
-				To:   splitQualified(mv.ToTable, db.Name),
-				Kind: DepMVDest,
-			})
+			// An inner-engine MV stores its own rows; only the TO form
+			// depends on a destination table.
+			if mv.ToTable != "" {
+				deps = append(deps, Dependency{
+					From: from,
+					To:   splitQualified(mv.ToTable, db.Name),
+					Kind: DepMVDest,
+				})
+			}
 
 			sources, err := extractSourceTables(mv.Query)
 			if err != nil {
@@ -578,6 +582,18 @@ func ValidateOpts(dbs []DatabaseSpec, skip SkipSet, clusters ClusterSet, opts Va
 		}
 	}
 
+	// Column references in each table's own key clauses and engine
+	// parameters. Same skip rules.
+	for _, db := range dbs {
+		for _, t := range db.Tables {
+			from := ObjectRef{Database: db.Name, Name: t.Name}
+			if skip.Skips(from) {
+				continue
+			}
+			errs = append(errs, validateTableColumns(from, t, resolver)...)
+		}
+	}
+
 	sort.Slice(errs, func(i, j int) bool {
 		if errs[i].Object != errs[j].Object {
 			return errs[i].Object.String() < errs[j].Object.String()
@@ -0,0 +1,246 @@
+package hcl
+
+import (
+	"fmt"
+	"strings"
+
+	chparser "github.com/orian/clickhouse-sql-parser/parser"
+)
+
+// KindTableColumn flags a table whose own clauses name a column it does not
+// provide (declared columns plus the engine's virtual columns): an identifier
+// in order_by, primary_key, partition_by or sample_by, or an engine parameter
+// such as a ReplacingMergeTree version_column or a CollapsingMergeTree
+// sign_column. ClickHouse rejects such a CREATE outright, so the check catches
+// a renamed or misspelled column before apply does.
+const KindTableColumn = "table_column"
+
+// validateTableColumns checks the column references in t's key clauses and
+// engine parameters. A table that declares no columns (e.g. one whose
+// structure is inferred from its source) is not checked, and neither is a key
+// expression the parser cannot read.
+func validateTableColumns(from ObjectRef, t TableSpec, r TableResolver) []ValidationError {
+	if len(t.Columns) == 0 {
+		return nil
+	}
+	provided := map[string]bool{}
+	for _, c := range ColumnsProvidedBy(t, r) {
+		provided[c.Name] = true
+	}
+
+	var errs []ValidationError
+	flag := func(clause, col string) {
+		errs = append(errs, ValidationError{
+			Object:  from,
+			Missing: ObjectRef{Database: from.Database, Name: from.Name + "." + col},
+			Kind:    KindTableColumn,
+			Reason: fmt.Sprintf("%s references column %q, which the table does not declare (declared: %s)",
+				clause, col, strings.Join(tableColumnNames(t.Columns), ", ")),
+		})
+	}
+
+	clauses := []struct {
+		name  string
+		exprs []string
+	}{
+		{"primary_key", t.PrimaryKey},
+		{"order_by", t.OrderBy},
+		{"partition_by", optionalExpr(t.PartitionBy)},
+		{"sample_by", optionalExpr(t.SampleBy)},
+	}
+	for _, c := range clauses {
+		seen := map[string]bool{}
+		for _, expr := range c.exprs {
+			refs, ok := expressionColumnRefs(expr)
+			if !ok {
+				continue
+			}
+			for _, ref := range refs {
+				if seen[ref] || providesColumn(provided, ref) {
+					continue
+				}
+				seen[ref] = true
+				flag(c.name, ref)
+			}
+		}
+	}
+
+	if t.Engine != nil {
+		for _, p := range engineColumnParams(t.Engine.Decoded) {
+			if !provided[p.column] {
+				flag(p.param, p.column)
+			}
+		}
+	}
+	return errs
+}
+
+func optionalExpr(s *string) []string {
+	if s == nil {
+		return nil
+	}
+	return []string{*s}
+}
+
+func tableColumnNames(cols []ColumnSpec) []string {
+	out := make([]string, len(cols))
+	for i, c := range cols {
+		out[i] = c.Name
+	}
+	return out
+}
+
+// providesColumn reports whether ref names a provided column. A dotted ref
+// (a Nested subcolumn or tuple element) also resolves through its first
+// segment.
+func providesColumn(provided map[string]bool, ref string) bool {
+	if provided[ref] {
+		return true
+	}
+	head, _, dotted := strings.Cut(ref, ".")
+	return dotted && provided[head]
+}
+
+type engineColumnParam struct {
+	param  string
+	column string
+}
+
+// engineColumnParams returns the columns an engine names in its parameters.
+func engineColumnParams(e Engine) []engineColumnParam {
+	var out []engineColumnParam
+	add := func(param string, col *string) {
+		if col != nil && *col != "" {
+			out = append(out, engineColumnParam{param: param, column: *col})
+		}
+	}
+	switch e := e.(type) {
+	case EngineReplacingMergeTree:
+		add("version_column", e.VersionColumn)
+		add("is_deleted_column", e.IsDeletedColumn)
+	case EngineReplicatedReplacingMergeTree:
+		add("version_column", e.VersionColumn)
+		add("is_deleted_column", e.IsDeletedColumn)
+	case EngineSummingMergeTree:
+		for i := range e.SumColumns {
+			add("sum_columns", &e.SumColumns[i])
+		}
+	case EngineReplicatedSummingMergeTree:
+		for i := range e.SumColumns {
+			add("sum_columns", &e.SumColumns[i])
+		}
+	case EngineCollapsingMergeTree:
+		add("sign_column", &e.SignColumn)
+	case EngineReplicatedCollapsingMergeTree:
+		add("sign_column", &e.SignColumn)
+	case EngineVersionedCollapsingMergeTree:
+		add("sign_column", &e.SignColumn)
+		add("version_column", &e.VersionColumn)
+	case EngineReplicatedVersionedCollapsingMergeTree:
+		add("sign_column", &e.SignColumn)
+		add("version_column", &e.VersionColumn)
+	}
+	return out
+}
+
+// expressionColumnRefs parses a key expression and returns the column names
+// it references, in order of appearance. Function names are not refs, and a
+// dotted path (a Nested subcolumn) is returned whole. ok=false signals the
+// expression could not be parsed and the caller should skip it.
+func expressionColumnRefs(expr string) ([]string, bool) {
+	stmts, err := chparser.NewParser("SELECT " + expr).ParseStmts()
+	if err != nil || len(stmts) != 1 {
+		return nil, false
+	}
+
+	isIdent := func(e chparser.Expr) bool {
+		_, ok := e.(*chparser.Ident)
+		return ok
+	}
+	// Idents that are not column refs: function names, INTERVAL units, CAST
+	// target types and aliases. A lambda's parameters are not columns either,
+	// and telling them apart from real refs is not worth it: bail.
+	skip := map[*chparser.Ident]bool{}
+	skipUnder := func(e chparser.Expr) {
+		if e == nil {
+			return
+		}
+		for _, n := range chparser.FindAll(e, isIdent) {
+			skip[n.(*chparser.Ident)] = true
+		}
+	}
+	lambda := false
+	for _, n := range chparser.FindAll(stmts[0], func(e chparser.Expr) bool {
+		switch e.(type) {
+		case *chparser.FunctionExpr, *chparser.IntervalExpr, *chparser.CastExpr,
+			*chparser.AliasExpr, *chparser.SelectItem, *chparser.BinaryOperation:
+			return true
+		}
+		return false
+	}) {
+		switch n := n.(type) {
+		case *chparser.FunctionExpr:
+			if n.Name != nil {
+				skip[n.Name] = true
+			}
+		case *chparser.IntervalExpr:
+			if n.Unit != nil {
+				skip[n.Unit] = true
+			}
+		case *chparser.CastExpr:
+			skipUnder(n.AsType)
+		case *chparser.AliasExpr:
+			skipUnder(n.Alias)
+		case *chparser.SelectItem:
+			if n.Alias != nil {
+				skip[n.Alias] = true
+			}
+		case *chparser.BinaryOperation:
+			switch string(n.Operation) {
+			case "::":
+				skipUnder(n.RightExpr)
+			case "->":
+				lambda = true
+			}
+		}
+	}
+	if lambda {
+		return nil, false
+	}
+
+	var refs []string
+	for _, n := range chparser.FindAll(stmts[0], func(e chparser.Expr) bool {
+		switch e.(type) {
+		case *chparser.Path, *chparser.NestedIdentifier:
+			return true
+		}
+		return false
+	}) {
+		var parts []*chparser.Ident
+		switch p := n.(type) {
+		case *chparser.Path:
+			parts = p.Fields
+		case *chparser.NestedIdentifier:
+			parts = []*chparser.Ident{p.Ident, p.DotIdent}
+		}
+		names := make([]string, 0, len(parts))
+		for _, id := range parts {
+			if id == nil {
+				continue
+			}
+			skip[id] = true
+			names = append(names, stripBackticks(id.Name))
+		}
+		if len(names) > 0 {
+			refs = append(refs, strings.Join(names, "."))
+		}
+	}
+	for _, n := range chparser.FindAll(stmts[0], isIdent) {
+		id := n.(*chparser.Ident)
+		if skip[id] {
+			continue
+		}
+		refs = append(refs, stripBackticks(id.Name))
+	}
+	return refs, true
+}
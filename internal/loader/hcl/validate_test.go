@@ -993,3 +993,81 @@ func TestValidate_Buffer_DestExists_OK(t *testing.T) {
 		assert.NotEqual(t, DepBufferDestination, e.Kind, "dest exists; no error: %s", e.Reason)
 	}
 }
+
+// tableColumnErrs returns the KindTableColumn errors in errs.
+func tableColumnErrs(errs []ValidationError) []ValidationError {
+	var out []ValidationError
+	for _, e := range errs {
+		if e.Kind == KindTableColumn {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestValidate_TableColumn_KeysReferenceDeclaredColumns(t *testing.T) {
+	tbl := mkTable("events", EngineMergeTree{},
+		ColumnSpec{Name: "team_id", Type: "UInt64"},
+		ColumnSpec{Name: "timestamp", Type: "DateTime64(6)"},
+		ColumnSpec{Name: "uuid", Type: "UUID"},
+		ColumnSpec{Name: "props", Type: "Nested(key String, value String)"},
+	)
+	tbl.OrderBy = []string{"team_id", "toDate(timestamp)", "cityHash64(uuid)", "props.key"}
+	tbl.PrimaryKey = []string{"team_id", "toDate(timestamp)"}
+	tbl.PartitionBy = strPtr("toStartOfInterval(timestamp, INTERVAL 1 DAY)")
+	tbl.SampleBy = strPtr("cityHash64(uuid)")
+
+	errs := Validate([]DatabaseSpec{mkDB("posthog", tbl)}, ParseSkipSet(""), ClusterSet{})
+	assert.Empty(t, tableColumnErrs(errs))
+}
+
+func TestValidate_TableColumn_UnknownKeyColumn(t *testing.T) {
+	tbl := mkTable("events", EngineMergeTree{},
+		ColumnSpec{Name: "team_id", Type: "UInt64"},
+		ColumnSpec{Name: "timestamp", Type: "DateTime"},
+	)
+	tbl.OrderBy = []string{"team", "toDate(timestamp)"}
+	tbl.PartitionBy = strPtr("toYYYYMM(ts)")
+
+	errs := tableColumnErrs(Validate([]DatabaseSpec{mkDB("posthog", tbl)}, ParseSkipSet(""), ClusterSet{}))
+	require.Len(t, errs, 2)
+	assert.Equal(t, ObjectRef{Database: "posthog", Name: "events"}, errs[0].Object)
+	assert.Equal(t, ObjectRef{Database: "posthog", Name: "events.team"}, errs[0].Missing)
+	assert.Equal(t, `order_by references column "team", which the table does not declare (declared: team_id, timestamp)`, errs[0].Reason)
+	assert.Equal(t, ObjectRef{Database: "posthog", Name: "events.ts"}, errs[1].Missing)
+	assert.Contains(t, errs[1].Reason, "partition_by")
+}
+
+func TestValidate_TableColumn_EngineColumns(t *testing.T) {
+	replacing := mkTable("persons", EngineReplacingMergeTree{VersionColumn: strPtr("ver")},
+		ColumnSpec{Name: "id", Type: "UUID"},
+		ColumnSpec{Name: "version", Type: "UInt64"},
+	)
+	collapsing := mkTable("sessions", EngineVersionedCollapsingMergeTree{SignColumn: "sign", VersionColumn: "version"},
+		ColumnSpec{Name: "id", Type: "UUID"},
+		ColumnSpec{Name: "sign", Type: "Int8"},
+		ColumnSpec{Name: "version", Type: "UInt64"},
+	)
+	summing := mkTable("totals", EngineSummingMergeTree{SumColumns: []string{"count", "bytes"}},
+		ColumnSpec{Name: "count", Type: "UInt64"},
+	)
+
+	errs := tableColumnErrs(Validate([]DatabaseSpec{mkDB("posthog", replacing, collapsing, summing)}, ParseSkipSet(""), ClusterSet{}))
+	require.Len(t, errs, 2)
+	assert.Equal(t, "persons", errs[0].Object.Name)
+	assert.Contains(t, errs[0].Reason, `version_column references column "ver"`)
+	assert.Equal(t, "totals", errs[1].Object.Name)
+	assert.Contains(t, errs[1].Reason, `sum_columns references column "bytes"`)
+}
+
+func TestValidate_TableColumn_SkippedAndUncheckable(t *testing.T) {
+	skipped := mkTable("events", EngineMergeTree{}, ColumnSpec{Name: "team_id", Type: "UInt64"})
+	skipped.OrderBy = []string{"missing"}
+	lambda := mkTable("arrays", EngineMergeTree{}, ColumnSpec{Name: "arr", Type: "Array(UInt8)"})
+	lambda.OrderBy = []string{"arraySum(arrayMap(x -> x * 2, arr))"}
+	noColumns := mkTable("inferred", EngineMergeTree{})
+	noColumns.OrderBy = []string{"anything"}
+
+	dbs := []DatabaseSpec{mkDB("posthog", skipped, lambda, noColumns)}
+	assert.Empty(t, tableColumnErrs(Validate(dbs, ParseSkipSet("events"), ClusterSet{})))
+}
@@ -0,0 +1,101 @@
+package hcl
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// LoadOptions tunes how schema files are parsed.
+type LoadOptions struct {
+	// Vars are the values schema expressions read as var.<name>, so one
+	// layer can serve every environment while zoo paths, cluster names or
+	// shard counts differ:
+	//
+	//	table "events" {
+	//	  engine "replicated_merge_tree" {
+	//	    zoo_path = "/clickhouse/${var.cluster}/tables/{shard}/events"
+	//	  }
+	//	}
+	//
+	// Referencing a variable that is not set is an error naming it.
+	Vars map[string]cty.Value
+
+	// AllowEnv enables env("NAME"), which reads an environment variable —
+	// Kafka broker lists, S3 buckets and other values a deploy injects:
+	//
+	//	kafka_broker_list = env("KAFKA_BROKERS")
+	//	url               = "https://${env("EVENTS_BUCKET")}.s3.amazonaws.com/events/*"
+	//
+	// It is opt-in so a schema never depends on the caller's environment by
+	// accident. An unset variable is an error, never an empty string.
+	AllowEnv bool
+
+	// Overlay is the environment whose overlay files are loaded: beside
+	// events.hcl, events.<Overlay>.hcl is merged right after it, and every
+	// other events.<name>.hcl is skipped (see selectOverlays). Empty loads no
+	// overlays.
+	Overlay string
+}
+
+// Merge returns o with over applied on top: over's variables replace o's of
+// the same name, env() is allowed when either allows it, and over's overlay
+// wins when set.
+func (o LoadOptions) Merge(over LoadOptions) LoadOptions {
+	overlay := o.Overlay
+	if over.Overlay != "" {
+		overlay = over.Overlay
+	}
+	return LoadOptions{Vars: MergeVars(o.Vars, over.Vars), AllowEnv: o.AllowEnv || over.AllowEnv, Overlay: overlay}
+}
+
+// LoadVarFile reads a variables file: top-level attributes only, each a
+// constant expression (string, number, bool, list or object).
+//
+//	cluster = "posthog"
+//	shards  = 4
+func LoadVarFile(path string) (map[string]cty.Value, error) {
+	parser := hclparse.NewParser()
+	f, diags := parser.ParseHCLFile(path)
+	if diags.HasErrors() {
+		return nil, formatDiagnostics(parser, diags)
+	}
+	attrs, diags := f.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, formatDiagnostics(parser, diags)
+	}
+	vars := make(map[string]cty.Value, len(attrs))
+	for name, attr := range attrs {
+		v, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, formatDiagnostics(parser, diags)
+		}
+		vars[name] = v
+	}
+	return vars, nil
+}
+
+// MergeVars returns base with every variable in over replacing the same name.
+// Neither input is modified.
+func MergeVars(base, over map[string]cty.Value) map[string]cty.Value {
+	out := make(map[string]cty.Value, len(base)+len(over))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range over {
+		out[k] = v
+	}
+	return out
+}
+
+// VarNames returns the names in vars, sorted; for logging which variables a
+// load saw without printing their values.
+func VarNames(vars map[string]cty.Value) []string {
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
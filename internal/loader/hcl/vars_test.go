@@ -0,0 +1,104 @@
+package hcl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const varsLayer = `
+database "posthog" {
+  table "events" {
+    order_by = ["day"]
+    column "day" { type = "Date" }
+    engine "replicated_merge_tree" {
+      zoo_path     = "/clickhouse/${var.cluster}/tables/{shard}/events"
+      replica_name = "{replica}"
+    }
+  }
+  table "events_dist" {
+    column "day" { type = "Date" }
+    engine "distributed" {
+      cluster_name    = var.cluster
+      remote_database = "posthog"
+      remote_table    = "events"
+    }
+  }
+}`
+
+// Variables reach top-level attributes and the engine bodies decoded after
+// the parse, which is where zoo paths and cluster names live.
+func TestLoadLayersOpts_Vars(t *testing.T) {
+	dir := t.TempDir()
+	writeLayerFile(t, dir, "events.hcl", varsLayer)
+
+	schema, err := LoadLayersOpts([]string{dir}, LoadOptions{Vars: map[string]cty.Value{"cluster": cty.StringVal("posthog_prod")}})
+	require.NoError(t, err)
+	tables := schema.Databases[0].Tables
+	require.Len(t, tables, 2)
+	assert.Equal(t, "/clickhouse/posthog_prod/tables/{shard}/events", tables[0].Engine.Decoded.(EngineReplicatedMergeTree).ZooPath)
+	assert.Equal(t, "posthog_prod", tables[1].Engine.Decoded.(EngineDistributed).ClusterName)
+}
+
+func TestLoadLayersOpts_MissingVarErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeLayerFile(t, dir, "events.hcl", varsLayer)
+
+	_, err := LoadLayers([]string{dir})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"cluster"`, "the error names the unset variable")
+}
+
+func TestLoadVarFile(t *testing.T) {
+	dir := t.TempDir()
+	writeLayerFile(t, dir, "prod.vars.hcl", "cluster = \"posthog_prod\"\nshards  = 4\n")
+
+	vars, err := LoadVarFile(filepath.Join(dir, "prod.vars.hcl"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cluster", "shards"}, VarNames(vars))
+	assert.True(t, vars["shards"].RawEquals(cty.NumberIntVal(4)))
+
+	merged := MergeVars(vars, map[string]cty.Value{"cluster": cty.StringVal("posthog_dev")})
+	assert.Equal(t, "posthog_dev", merged["cluster"].AsString(), "the override wins")
+	assert.Equal(t, "posthog_prod", vars["cluster"].AsString(), "inputs are not modified")
+
+	writeLayerFile(t, dir, "bad.vars.hcl", "cluster = var.other\n")
+	_, err = LoadVarFile(filepath.Join(dir, "bad.vars.hcl"))
+	require.Error(t, err, "a vars file holds constants only")
+}
+
+// env() is opt-in and never reads an unset variable as empty.
+func TestParseFileOpts_Env(t *testing.T) {
+	dir := t.TempDir()
+	writeLayerFile(t, dir, "kafka.hcl", `
+database "posthog" {
+  table "events" {
+    order_by = ["day"]
+    column "day" { type = "Date" }
+    engine "replicated_merge_tree" {
+      zoo_path     = "/clickhouse/${env("CHSCHEMA_TEST_CLUSTER")}/events"
+      replica_name = "{replica}"
+    }
+  }
+}`)
+	path := filepath.Join(dir, "kafka.hcl")
+
+	t.Setenv("CHSCHEMA_TEST_CLUSTER", "posthog_prod")
+	_, err := ParseFile(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "-allow-env", "the error says how to opt in")
+
+	schema, err := ParseFileOpts(path, LoadOptions{AllowEnv: true})
+	require.NoError(t, err)
+	assert.Equal(t, "/clickhouse/posthog_prod/events", schema.Databases[0].Tables[0].Engine.Decoded.(EngineReplicatedMergeTree).ZooPath)
+
+	require.NoError(t, os.Unsetenv("CHSCHEMA_TEST_CLUSTER"))
+	_, err = ParseFileOpts(path, LoadOptions{AllowEnv: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CHSCHEMA_TEST_CLUSTER")
+	assert.Contains(t, err.Error(), "not set")
+}
@@ -0,0 +1,290 @@
+// Package corpus is a differential test harness for a directory of ClickHouse
+// CREATE statements: apply them, round-trip the schema through chschema, and
+// check ClickHouse's canonical CREATE of every object is unchanged. It lives
+// outside test/testhelpers because it drives the HCL loader, whose own tests
+// import testhelpers.
+package corpus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	hclload "github.com/posthog/chschema/internal/loader/hcl"
+	"github.com/posthog/chschema/test/testhelpers"
+)
+
+// Statement is one CREATE statement read from a corpus directory.
+type Statement struct {
+	File string // path relative to the corpus root, for reporting
+	SQL  string // statement text, without the trailing ';'
+}
+
+// Options tunes Run for a corpus captured from a real cluster.
+type Options struct {
+	// SourceDatabase is the database the corpus statements are qualified
+	// with ("default" when empty). Every qualifier of it, bare or backquoted, is
+	// rewritten to the isolated test database, so objects and the references
+	// between them (MV TO/FROM, dictionary sources) land side by side.
+	SourceDatabase string
+
+	// Rewrite, when set, is applied to every statement after the database
+	// rewrite — e.g. to replace redacted '[HIDDEN]' secrets with values the
+	// server accepts.
+	Rewrite func(sql string) string
+
+	// AllowRaw accepts objects that introspection can only capture as raw{}
+	// SQL blocks. By default such an object fails its subtest: the corpus
+	// is meant to prove the schema language models every object.
+	AllowRaw bool
+}
+
+// statementEnd matches a ';' that ends a line — the separator between
+// statements in a corpus file (and the trailing terminator of the last one).
+var statementEnd = regexp.MustCompile(`(?m);[ \t]*$`)
+
+// Load reads every *.sql file under dir, recursively and in lexical
+// path order. A file holds one or more statements, each terminated by a ';'
+// at the end of a line (a final statement may omit it); `--` comment lines
+// are dropped. This is the layout of `hclexp dump-sql` output and of
+// test/testdata/posthog-create-statements (one object per file).
+func Load(dir string) ([]Statement, error) {
+	var out []Statement
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".sql") {
+			return nil
+		}
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			rel = path
+		}
+		for _, s := range splitStatements(string(body)) {
+			out = append(out, Statement{File: filepath.ToSlash(rel), SQL: s})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no statements in %s", dir)
+	}
+	return out, nil
+}
+
+// splitStatements cuts a corpus file into statements, dropping comment lines
+// and empty chunks.
+func splitStatements(content string) []string {
+	var keep []string
+	for _, l := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(l), "--") {
+			continue
+		}
+		keep = append(keep, l)
+	}
+	var out []string
+	for _, chunk := range statementEnd.Split(strings.Join(keep, "\n"), -1) {
+		if s := strings.TrimSpace(chunk); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// RewriteDatabase replaces every qualifier of database from in sql, bare or
+// backquoted, with to. A qualifier must start an identifier, so xdefault.t
+// or a.default.t are left alone.
+func RewriteDatabase(sql, from, to string) string {
+	re := regexp.MustCompile("(^|[^A-Za-z0-9_.`])(?:`" + regexp.QuoteMeta(from) + "`|" + regexp.QuoteMeta(from) + `)\.`)
+	return re.ReplaceAllString(sql, "${1}"+to+".")
+}
+
+// Run is a differential test of chschema against a corpus of CREATE
+// statements: everything the schema language claims to model must survive
+// the full round trip unchanged. Downstream users point it at a dump of their
+// own cluster (`hclexp dump-sql`) to check chschema supports their schema
+// before adopting it:
+//
+//	func TestMySchema(t *testing.T) {
+//		conn := testhelpers.RequireClickHouse(t)
+//		corpus.Run(t, conn, "testdata/schema", corpus.Options{})
+//	}
+//
+// It applies the corpus to an isolated database, records ClickHouse's
+// canonical create_table_query for every object, then goes Introspect -> dump
+// HCL -> reparse -> Resolve -> GenerateSQL, recreates the database from the
+// generated DDL, and compares each object's canonical CREATE again — one
+// subtest per object, so a report names exactly what does not round-trip.
+//
+// Statements are applied in file order, retrying ones that fail until no
+// more succeed, so a corpus need not be sorted by dependency (an MV ahead of
+// its target table). A statement that never applies fails the test.
+func Run(t *testing.T, conn driver.Conn, dir string, opts Options) {
+	t.Helper()
+	stmts, err := Load(dir)
+	if err != nil {
+		t.Fatalf("load corpus: %v", err)
+	}
+	from := opts.SourceDatabase
+	if from == "" {
+		from = "default"
+	}
+	dbName := testhelpers.CreateTestDatabase(t, conn)
+	ctx := context.Background()
+
+	seed := make([]string, len(stmts))
+	for i, s := range stmts {
+		sql := RewriteDatabase(s.SQL, from, dbName)
+		if opts.Rewrite != nil {
+			sql = opts.Rewrite(sql)
+		}
+		seed[i] = sql
+	}
+	if failed := applyUntilStable(ctx, conn, seed); len(failed) > 0 {
+		for _, i := range sortedIndexes(failed) {
+			t.Errorf("corpus statement from %s rejected by ClickHouse: %v", stmts[i].File, failed[i])
+		}
+		t.FailNow()
+	}
+	golden := canonicalCreates(t, ctx, conn, dbName)
+
+	// Round-trip through the HCL text, where fidelity bugs live.
+	db, err := hclload.Introspect(ctx, conn, dbName, true)
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	raw := map[string]bool{}
+	for _, r := range db.Raws {
+		raw[r.Name] = true
+	}
+	var buf bytes.Buffer
+	if err := hclload.Write(&buf, &hclload.Schema{Databases: []hclload.DatabaseSpec{*db}}); err != nil {
+		t.Fatalf("dump: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "corpus.hcl")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reparsed, err := hclload.ParseFile(path)
+	if err != nil {
+		t.Fatalf("reparse dumped HCL: %v\n%s", err, buf.String())
+	}
+	if err := hclload.Resolve(reparsed); err != nil {
+		t.Fatalf("resolve dumped HCL: %v", err)
+	}
+	gen := hclload.GenerateSQL(hclload.Diff(&hclload.Schema{}, reparsed))
+	for _, u := range gen.Unsafe {
+		t.Errorf("%s: unsafe change on a fresh create: %s", u.Table, u.Reason)
+	}
+
+	if err := conn.Exec(ctx, fmt.Sprintf("DROP DATABASE %s SYNC", dbName)); err != nil {
+		t.Fatalf("reset database: %v", err)
+	}
+	if err := conn.Exec(ctx, "CREATE DATABASE "+dbName); err != nil {
+		t.Fatalf("reset database: %v", err)
+	}
+	regenFailed := applyUntilStable(ctx, conn, gen.Statements)
+	rebuilt := canonicalCreates(t, ctx, conn, dbName)
+
+	names := make([]string, 0, len(golden))
+	for name := range golden {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			if raw[name] && !opts.AllowRaw {
+				t.Errorf("introspected only as a raw SQL block (not modeled by the schema language)")
+			}
+			got, ok := rebuilt[name]
+			if !ok {
+				t.Fatalf("missing after regenerating from HCL")
+			}
+			if got != golden[name] {
+				t.Errorf("canonical CREATE changed after the HCL round trip\n--- corpus ---\n%s\n--- regenerated ---\n%s", golden[name], got)
+			}
+		})
+	}
+	for _, i := range sortedIndexes(regenFailed) {
+		t.Errorf("regenerated statement rejected by ClickHouse: %v\n%s", regenFailed[i], gen.Statements[i])
+	}
+}
+
+// applyUntilStable executes stmts, retrying the failures pass after pass while
+// any of them succeeds, so dependency order is discovered rather than
+// required. It returns the last error of each statement that never applied,
+// keyed by its index.
+func applyUntilStable(ctx context.Context, conn driver.Conn, stmts []string) map[int]error {
+	pending := make([]int, len(stmts))
+	for i := range stmts {
+		pending[i] = i
+	}
+	failed := map[int]error{}
+	for len(pending) > 0 {
+		var next []int
+		for _, i := range pending {
+			if err := conn.Exec(ctx, stmts[i]); err != nil {
+				failed[i] = err
+				next = append(next, i)
+				continue
+			}
+			delete(failed, i)
+		}
+		if len(next) == len(pending) {
+			break
+		}
+		pending = next
+	}
+	return failed
+}
+
+// sortedIndexes returns the statement indexes of failed in ascending order, so
+// failures are reported in corpus order on every run.
+func sortedIndexes(failed map[int]error) []int {
+	idx := make([]int, 0, len(failed))
+	for i := range failed {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	return idx
+}
+
+// canonicalCreates returns name -> create_table_query for every object in db:
+// ClickHouse's own canonical rendering, the reference both sides compare by.
+func canonicalCreates(t *testing.T, ctx context.Context, conn driver.Conn, db string) map[string]string {
+	t.Helper()
+	rows, err := conn.Query(ctx,
+		"SELECT name, create_table_query FROM system.tables WHERE database = ? AND NOT is_temporary AND create_table_query != ''",
+		db)
+	if err != nil {
+		t.Fatalf("query system.tables: %v", err)
+	}
+	defer rows.Close()
+	out := map[string]string{}
+	for rows.Next() {
+		var name, create string
+		if err := rows.Scan(&name, &create); err != nil {
+			t.Fatalf("scan system.tables: %v", err)
+		}
+		out[name] = create
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("rows iteration error: %v", err)
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/posthog/chschema/test/corpus"
+	"github.com/posthog/chschema/test/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var posthogCorpus = filepath.Join("testdata", "posthog-create-statements")
+
+// TestLive_Corpus runs the differential corpus harness over the directory of
+// CREATE statements named by CORPUS_DIR (qualified with CORPUS_DATABASE,
+// "default" when unset): every object must come back from introspect -> HCL ->
+// generate with an identical canonical CREATE. It is opt-in rather than a CI
+// gate, like ROUNDTRIP_FIXTURE: a real cluster's corpus carries known gaps
+// (see docs/roundtrip-fidelity.md) that the report is meant to surface.
+//
+//	CORPUS_DIR=$PWD/test/testdata/posthog-create-statements \
+//	  go test ./test -run TestLive_Corpus -v -clickhouse
+func TestLive_Corpus(t *testing.T) {
+	if !*clickhouse {
+		t.SkipNow()
+	}
+	dir := os.Getenv("CORPUS_DIR")
+	if dir == "" {
+		t.Skip("set CORPUS_DIR to a directory of CREATE statements")
+	}
+	t.Parallel()
+	conn := testhelpers.RequireClickHouse(t)
+	corpus.Run(t, conn, dir, corpus.Options{
+		SourceDatabase: os.Getenv("CORPUS_DATABASE"),
+		// Dumped dictionary sources carry redacted passwords.
+		Rewrite: redactedPasswords,
+	})
+}
+
+// TestLive_PosthogDictionaryCorpus runs the corpus harness over the PostHog
+// dictionary fixtures: every real-world dictionary shape must survive the
+// round trip with an identical canonical CREATE. Sources are not validated at
+// CREATE time, so the dictionaries apply without their source tables.
+func TestLive_PosthogDictionaryCorpus(t *testing.T) {
+	if !*clickhouse {
+		t.SkipNow()
+	}
+	t.Parallel()
+	conn := testhelpers.RequireClickHouse(t)
+	corpus.Run(t, conn, filepath.Join(posthogCorpus, "Dictionary"), corpus.Options{
+		Rewrite: redactedPasswords,
+	})
+}
+
+// redactedPasswords replaces the '[HIDDEN]' passwords of dumped dictionary
+// sources with an empty one the server accepts.
+func redactedPasswords(sql string) string {
+	return strings.ReplaceAll(sql, "PASSWORD '[HIDDEN]'", "PASSWORD ''")
+}
+
+// TestLoadCorpus validates the corpus reader against the checked-in PostHog
+// corpus. It is not gated — it runs in the normal test job.
+func TestLoadCorpus(t *testing.T) {
+	stmts, err := corpus.Load(posthogCorpus)
+	require.NoError(t, err)
+	require.Len(t, stmts, 152, "one statement per corpus file")
+	for _, s := range stmts {
+		assert.True(t, strings.HasPrefix(s.SQL, "CREATE "), "%s: statement should start with CREATE", s.File)
+		assert.False(t, strings.HasSuffix(s.SQL, ";"), "%s: terminator must be stripped", s.File)
+	}
+	assert.Equal(t, "Dictionary/channel_definition_dict.sql", stmts[0].File)
+}
+
+func TestLoadCorpus_MultiStatementFile(t *testing.T) {
+	dir := t.TempDir()
+	writeCorpusFile(t, filepath.Join(dir, "nested", "schema.sql"), `-- database: x
+CREATE TABLE default.a (id UInt64) ENGINE = Memory;
+
+CREATE VIEW default.v AS SELECT ';' AS semi FROM default.a;
+CREATE TABLE default.b (id UInt64) ENGINE = Memory
+`)
+	writeCorpusFile(t, filepath.Join(dir, "notes.txt"), "CREATE TABLE ignored")
+
+	stmts, err := corpus.Load(dir)
+	require.NoError(t, err)
+	require.Len(t, stmts, 3)
+	assert.Equal(t, "nested/schema.sql", stmts[0].File)
+	assert.Equal(t, "CREATE VIEW default.v AS SELECT ';' AS semi FROM default.a", stmts[1].SQL)
+	assert.Equal(t, "CREATE TABLE default.b (id UInt64) ENGINE = Memory", stmts[2].SQL)
+
+	_, err = corpus.Load(t.TempDir())
+	require.Error(t, err, "an empty corpus is an error")
+}
+
+func TestRewriteDatabase(t *testing.T) {
+	sql := "CREATE MATERIALIZED VIEW default.mv TO `default`.target AS SELECT * FROM default.src JOIN xdefault.t USING id"
+	assert.Equal(t,
+		"CREATE MATERIALIZED VIEW iso.mv TO iso.target AS SELECT * FROM iso.src JOIN xdefault.t USING id",
+		corpus.RewriteDatabase(sql, "default", "iso"))
+}
+
+func writeCorpusFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
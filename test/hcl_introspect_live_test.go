@@ -21,9 +21,9 @@ func TestLive_HCLIntrospect(t *testing.T) {
 	if !*clickhouse {
 		t.SkipNow()
 	}
+	t.Parallel()
 
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 
 	stmts := []string{
@@ -177,9 +177,9 @@ func TestLive_HCLIntrospect_TimeSeries(t *testing.T) {
 	if !*clickhouse {
 		t.SkipNow()
 	}
+	t.Parallel()
 
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 
 	// External-target form: stand up the three target tables, then point
@@ -251,8 +251,8 @@ func TestLive_HCLIntrospect_Join(t *testing.T) {
 	if !*clickhouse {
 		t.SkipNow()
 	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 
 	stmts := []string{
@@ -300,8 +300,8 @@ func TestLive_HCLIntrospect_CommonEngines(t *testing.T) {
 	if !*clickhouse {
 		t.SkipNow()
 	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 
 	stmts := []string{
@@ -354,8 +354,8 @@ func TestLive_ViewRoundTrip_StarReplace(t *testing.T) {
 	if !*clickhouse {
 		t.SkipNow()
 	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 
 	require.NoError(t, conn.Exec(ctx, `CREATE TABLE `+dbName+`.custom_metrics_test (
@@ -402,8 +402,8 @@ func TestLive_CreateTableRoundTrip_ColumnModifiers(t *testing.T) {
 	if !*clickhouse {
 		t.SkipNow()
 	}
-	conn := testhelpers.RequireClickHouse(t)
-	dbName := testhelpers.CreateTestDatabase(t, conn)
+	t.Parallel()
+	conn, dbName := testhelpers.IsolatedDatabase(t)
 	ctx := context.Background()
 
 	require.NoError(t, conn.Exec(ctx, `CREATE TABLE `+dbName+`.archive (
@@ -23,6 +23,7 @@ func TestLive_Issue136_IntrospectDiffsCleanAgainstLoad(t *testing.T) {
 	if !*clickhouse {
 		t.SkipNow()
 	}
+	// Not parallel: the HCL fixture names a fixed database.
 	conn := testhelpers.RequireClickHouse(t)
 	ctx := context.Background()
 	const dbName = "issue136_roundtrip"
@@ -88,6 +89,7 @@ func TestLive_Issue136_SystemProxyColumnSubset(t *testing.T) {
 	if !*clickhouse {
 		t.SkipNow()
 	}
+	// Not parallel: the HCL fixture names a fixed database.
 	conn := testhelpers.RequireClickHouse(t)
 	ctx := context.Background()
 	const dbName = "issue136_system_proxy"
@@ -30,6 +30,7 @@ func TestLive_RoundTripFidelity(t *testing.T) {
 	if !*clickhouse {
 		t.SkipNow()
 	}
+	// Not parallel: the fixture header names a fixed database.
 	conn := testhelpers.RequireClickHouse(t)
 	ctx := context.Background()
 
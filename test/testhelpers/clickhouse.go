@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"os"
 	"sync"
 	"testing"
 
@@ -19,10 +20,9 @@ import (
 )
 
 var (
-	testConn     driver.Conn
-	testConnOnce sync.Once
-	testConnErr  error
-	loggerOnce   sync.Once
+	probeOnce  sync.Once
+	probeErr   error
+	loggerOnce sync.Once
 )
 
 // InitTestLogger initializes the logger in test mode (suppresses output)
@@ -33,29 +33,65 @@ func InitTestLogger() {
 	})
 }
 
-// GetTestConnection returns a shared ClickHouse connection for tests
-// The connection is initialized once and reused across all tests
+// GetTestConnection opens a ClickHouse connection owned by t and closes it
+// when t (and its subtests) finish. Every test gets its own connection, so
+// live tests can call t.Parallel: nothing is shared between them, and one
+// test's teardown can never close or reset another's session.
+//
+// Availability is probed once per test binary; when ClickHouse is down every
+// caller skips without dialing again. A dial failure after a successful probe
+// fails the test instead — the server went away mid-run.
 func GetTestConnection(t *testing.T) driver.Conn {
+	t.Helper()
 	// Initialize test logger first
 	InitTestLogger()
 
-	testConnOnce.Do(func() {
-		cfg := config.GetDefaultConfig()
-		testConn, testConnErr = config.NewConnection(cfg)
+	cfg := testConfig()
+	probeOnce.Do(func() {
+		var conn driver.Conn
+		if conn, probeErr = config.NewConnection(cfg); probeErr == nil {
+			_ = conn.Close()
+		}
 	})
+	if probeErr != nil {
+		t.Skipf("ClickHouse not available: %v", probeErr)
+	}
 
-	if testConnErr != nil {
-		t.Skipf("ClickHouse not available: %v", testConnErr)
+	conn, err := config.NewConnection(cfg)
+	if err != nil {
+		t.Fatalf("connect to ClickHouse: %v", err)
 	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
 
-	return testConn
+// testConfig is the connection config for live tests: the environment
+// defaults, with docker-compose's user1/pass1 credentials when
+// CLICKHOUSE_USER and CLICKHOUSE_PASSWORD are unset.
+func testConfig() config.ClickHouseConfig {
+	cfg := config.GetDefaultConfig()
+	if os.Getenv("CLICKHOUSE_USER") == "" && os.Getenv("CLICKHOUSE_PASSWORD") == "" {
+		cfg.User, cfg.Password = "user1", "pass1"
+	}
+	return cfg
 }
 
-// RequireClickHouse skips the test if ClickHouse is not available
+// RequireClickHouse skips the test if ClickHouse is not available, and
+// otherwise returns a connection owned by t (see GetTestConnection).
 func RequireClickHouse(t *testing.T) driver.Conn {
+	t.Helper()
 	return GetTestConnection(t)
 }
 
+// IsolatedDatabase is the usual preamble of a parallel-safe live test: a
+// connection owned by t plus a uniquely-named database that is dropped
+// before the connection closes.
+func IsolatedDatabase(t *testing.T) (driver.Conn, string) {
+	t.Helper()
+	conn := GetTestConnection(t)
+	return conn, CreateTestDatabase(t, conn)
+}
+
 // PingClickHouse performs a basic connectivity test
 func PingClickHouse(conn driver.Conn) error {
 	ctx := context.Background()
@@ -75,6 +111,10 @@ func PingClickHouse(conn driver.Conn) error {
 // TestEnd2End vs. TestLive_Introspection_AllStatements) because their
 // ZK paths embed dbName.
 //
+// The drop is registered after the connection's own Close (see
+// GetTestConnection), and cleanups run last-in first-out, so it always runs
+// on an open connection.
+//
 // Cleanup uses DROP DATABASE ... SYNC so the database's ZooKeeper
 // metadata is released before the test returns — without SYNC,
 // ClickHouse delays the drop by `database_atomic_delay_before_drop_table_sec`